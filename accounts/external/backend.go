@@ -153,7 +153,8 @@ func (api *ExternalSigner) SelfDerive(bases []accounts.DerivationPath, chain eth
 	log.Error("operation SelfDerive not supported on external signers")
 }
 
-// SignData signs keccak256(data). The mimetype parameter describes the type of data being signed
+// SignData signs keccak256(data). The mimetype parameter describes the type of data being signed.
+// Post-quantum accounts return a signature of their own algorithm, without a V value.
 func (api *ExternalSigner) SignData(account accounts.Account, mimeType string, data []byte) ([]byte, error) {
 	var res hexutil.Bytes
 	var signAddress = common.NewMixedcaseAddress(account.Address)
@@ -164,7 +165,7 @@ func (api *ExternalSigner) SignData(account accounts.Account, mimeType string, d
 		return nil, err
 	}
 	// If V is on 27/28-form, convert to 0/1 for Clique
-	if mimeType == accounts.MimetypeClique && (res[64] == 27 || res[64] == 28) {
+	if mimeType == accounts.MimetypeClique && isLegacySignature(res) {
 		res[64] -= 27 // Transform V from 27/28 to 0/1 for Clique use
 	}
 	return res, nil
@@ -179,7 +180,7 @@ func (api *ExternalSigner) SignText(account accounts.Account, text []byte) ([]by
 		hexutil.Encode(text)); err != nil {
 		return nil, err
 	}
-	if signature[64] == 27 || signature[64] == 28 {
+	if isLegacySignature(signature) {
 		// If clef is used as a backend, it may already have transformed
 		// the signature to ethereum-type signature.
 		signature[64] -= 27 // Transform V from Ethereum-legacy to 0/1
@@ -187,6 +188,12 @@ func (api *ExternalSigner) SignText(account accounts.Account, text []byte) ([]by
 	return signature, nil
 }
 
+// isLegacySignature reports whether sig is a secp256k1 signature with a V value
+// of 27 or 28. Post-quantum signatures are never 65 bytes long.
+func isLegacySignature(sig []byte) bool {
+	return len(sig) == 65 && (sig[64] == 27 || sig[64] == 28)
+}
+
 // signTransactionResult represents the signinig result returned by clef.
 type signTransactionResult struct {
 	Raw hexutil.Bytes      `json:"raw"`
@@ -218,6 +225,12 @@ func (api *ExternalSigner) SignTx(account accounts.Account, tx *types.Transactio
 	case types.DynamicFeeTxType, types.BlobTxType, types.SetCodeTxType:
 		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
 		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+	case types.PQTxType:
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+		algo, _, _ := tx.PQSignature()
+		args.PQAlgorithm = (*hexutil.Uint64)(new(uint64))
+		*args.PQAlgorithm = hexutil.Uint64(algo)
 	default:
 		return nil, fmt.Errorf("unsupported tx type %d", tx.Type())
 	}
@@ -250,9 +250,7 @@ func (ac *accountCache) scanAccounts() error {
 	// Create a helper method to scan the contents of the key files
 	var (
 		buf = new(bufio.Reader)
-		key struct {
-			Address string `json:"address"`
-		}
+		key keyFileHeader
 	)
 	readAccount := func(path string) *accounts.Account {
 		fd, err := os.Open(path)
@@ -262,10 +260,14 @@ func (ac *accountCache) scanAccounts() error {
 		}
 		defer fd.Close()
 		buf.Reset(fd)
-		// Parse the address.
-		key.Address = ""
+		// Parse the address. Post-quantum keys must also carry a public key
+		// of a supported algorithm matching the address.
+		key = keyFileHeader{}
 		err = json.NewDecoder(buf).Decode(&key)
 		addr := common.HexToAddress(key.Address)
+		if err == nil && key.postQuantum() {
+			_, _, err = key.check()
+		}
 		switch {
 		case err != nil:
 			log.Debug("Failed to decode keystore key", "path", path, "err", err)
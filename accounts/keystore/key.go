@@ -45,6 +45,8 @@ type Key struct {
 	// we only store privkey as pubkey/address can be derived from it
 	// privkey in this struct is always in plaintext
 	PrivateKey *ecdsa.PrivateKey
+	// post-quantum accounts carry their key here instead of in PrivateKey
+	PostQuantum *PostQuantumKey
 }
 
 type keyStore interface {
@@ -91,6 +93,9 @@ type cipherparamsJSON struct {
 }
 
 func (k *Key) MarshalJSON() (j []byte, err error) {
+	if k.PostQuantum != nil {
+		return k.marshalPQJSON()
+	}
 	jStruct := plainKeyJSON{
 		hex.EncodeToString(k.Address[:]),
 		hex.EncodeToString(crypto.FromECDSA(k.PrivateKey)),
@@ -107,6 +112,9 @@ func (k *Key) UnmarshalJSON(j []byte) (err error) {
 	if err != nil {
 		return err
 	}
+	if keyJSON.Version == pqVersion {
+		return k.unmarshalPQJSON(j)
+	}
 
 	u := new(uuid.UUID)
 	*u, err = uuid.Parse(keyJSON.Id)
@@ -181,7 +189,7 @@ func storeNewKey(ks keyStore, rand io.Reader, auth string) (*Key, accounts.Accou
 		URL:     accounts.URL{Scheme: KeyStoreScheme, Path: ks.JoinPath(keyFileName(key.Address))},
 	}
 	if err := ks.StoreKey(a.URL.Path, key, auth); err != nil {
-		zeroKey(key.PrivateKey)
+		zeroKey(key)
 		return nil, a, err
 	}
 	return key, a, err
@@ -4,19 +4,21 @@
 package keystore
 
 import (
-	"crypto/ecdsa"
-	"crypto/rand"
+	"bytes"
+	crand "crypto/rand"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 
-	"github.com/luxfi/geth/common"
-	"github.com/luxfi/crypto"
 	"github.com/google/uuid"
-	"github.com/luxfi/crypto/mldsa"
-	"github.com/luxfi/crypto/mlkem"
-	"github.com/luxfi/crypto/slhdsa"
+	"github.com/luxfi/geth/accounts"
+	"github.com/luxfi/geth/common"
+	"github.com/luxfi/geth/core/types"
+	"github.com/luxfi/geth/crypto/pqcrypto"
 )
 
 // SignatureAlgorithm represents the type of signature algorithm
@@ -34,254 +36,398 @@ const (
 	SignatureSLHDSA256s
 	SignatureSLHDSA256f
 	SignatureBLS // For validator keys
+	SignatureHybridMLDSA65
+)
+
+// pqVersion is the key file version of post-quantum keys. It extends version 3
+// with the signature algorithm and the public key, and stores the private key
+// seed in place of the secp256k1 scalar.
+const pqVersion = 4
+
+var (
+	// ErrAlgorithmNotSupported is returned for signature algorithms the keystore
+	// cannot generate or load keys for.
+	ErrAlgorithmNotSupported = errors.New("signature algorithm not supported")
+
+	errPQTxRequired = errors.New("post-quantum accounts can only sign post-quantum transactions")
 )
 
-// PostQuantumKey represents a key that can use different signature algorithms
+// pqScheme maps a keystore algorithm to its pqcrypto implementation.
+type pqScheme struct {
+	name string             // Short name accepted by ParseSignatureAlgorithm
+	algo pqcrypto.Algorithm // Implementation and algorithm ID in transactions
+	tx   bool               // Whether the algorithm is accepted in transactions
+}
+
+// pqSchemes are the post-quantum algorithms the keystore can manage keys for.
+// Only the ML-DSA and the small SLH-DSA parameter sets can sign transactions.
+var pqSchemes = map[SignatureAlgorithm]pqScheme{
+	SignatureHybridMLDSA65: {"hybrid", pqcrypto.AlgoHybridSecp256k1MLDSA, false},
+	SignatureMLDSA44:       {"mldsa44", pqcrypto.AlgoMLDSA44, true},
+	SignatureMLDSA65:       {"mldsa65", pqcrypto.AlgoMLDSA65, true},
+	SignatureMLDSA87:       {"mldsa87", pqcrypto.AlgoMLDSA87, true},
+	SignatureSLHDSA128s:    {"slhdsa128s", pqcrypto.AlgoSLHDSA128s, true},
+	SignatureSLHDSA128f:    {"slhdsa128f", pqcrypto.AlgoSLHDSA128f, false},
+	SignatureSLHDSA192s:    {"slhdsa192s", pqcrypto.AlgoSLHDSA192s, true},
+	SignatureSLHDSA192f:    {"slhdsa192f", pqcrypto.AlgoSLHDSA192f, false},
+	SignatureSLHDSA256s:    {"slhdsa256s", pqcrypto.AlgoSLHDSA256s, true},
+	SignatureSLHDSA256f:    {"slhdsa256f", pqcrypto.AlgoSLHDSA256f, false},
+}
+
+// String returns the short name of the algorithm, as accepted by
+// ParseSignatureAlgorithm.
+func (alg SignatureAlgorithm) String() string {
+	switch alg {
+	case SignatureECDSA:
+		return "secp256k1"
+	case SignatureBLS:
+		return "bls"
+	}
+	if scheme, ok := pqSchemes[alg]; ok {
+		return scheme.name
+	}
+	return "unknown"
+}
+
+// TxAlgorithm returns the algorithm ID of post-quantum transactions signed by
+// keys of the algorithm, or false if they cannot sign transactions.
+func (alg SignatureAlgorithm) TxAlgorithm() (pqcrypto.Algorithm, bool) {
+	scheme, ok := pqSchemes[alg]
+	if !ok || !scheme.tx {
+		return 0, false
+	}
+	return scheme.algo, true
+}
+
+// PQAlgorithm returns the pqcrypto algorithm implementing a post-quantum
+// algorithm, or false for the classical ones.
+func (alg SignatureAlgorithm) PQAlgorithm() (pqcrypto.Algorithm, bool) {
+	scheme, ok := pqSchemes[alg]
+	return scheme.algo, ok
+}
+
+// ParseSignatureAlgorithm returns the algorithm with the given short name, e.g.
+// "secp256k1" or "mldsa65".
+func ParseSignatureAlgorithm(name string) (SignatureAlgorithm, error) {
+	name = strings.ToLower(name)
+	if name == "secp256k1" || name == "ecdsa" {
+		return SignatureECDSA, nil
+	}
+	for alg, scheme := range pqSchemes {
+		if scheme.name == name {
+			return alg, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %q", ErrAlgorithmNotSupported, name)
+}
+
+// PostQuantumKey is the private key of a post-quantum account. The seed is the
+// only secret: the public and expanded private keys are derived from it with
+// ML-DSA.KeyGen_internal (FIPS 204) or slh_keygen_internal (FIPS 205).
 type PostQuantumKey struct {
-	Id              uuid.UUID          `json:"id"`
-	Address         common.Address     `json:"address"`
-	Algorithm       SignatureAlgorithm `json:"algorithm"`
-	
-	// Traditional ECDSA (optional, for backward compatibility)
-	ECDSAPrivateKey *ecdsa.PrivateKey  `json:"-"`
-	
-	// Post-quantum keys (one of these will be set based on Algorithm)
-	MLDSAPrivateKey *mldsa.PrivateKey  `json:"-"`
-	SLHDSAPrivateKey *slhdsa.PrivateKey `json:"-"`
-	MLKEMPrivateKey *mlkem.PrivateKey  `json:"-"`
-	
-	// Serialized form for storage
-	PrivateKeyBytes []byte             `json:"-"`
-	PublicKeyBytes  []byte             `json:"-"`
+	Algorithm SignatureAlgorithm
+	Seed      []byte // private key seed, always in plaintext in memory
+	PublicKey []byte // encoded public key
+
+	signer *pqcrypto.PQSigner // expanded private key
+}
+
+// NewPostQuantumKey generates a new post-quantum key
+func NewPostQuantumKey(algorithm SignatureAlgorithm) (*PostQuantumKey, error) {
+	return newPostQuantumKey(crand.Reader, algorithm)
 }
 
-// PostQuantumKeyJSON is the JSON representation
-type postQuantumKeyJSON struct {
+func newPostQuantumKey(rand io.Reader, algorithm SignatureAlgorithm) (*PostQuantumKey, error) {
+	scheme, ok := pqSchemes[algorithm]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrAlgorithmNotSupported, algorithm)
+	}
+	seed := make([]byte, pqcrypto.SeedSize(scheme.algo))
+	if _, err := io.ReadFull(rand, seed); err != nil {
+		return nil, err
+	}
+	return PostQuantumKeyFromSeed(algorithm, seed)
+}
+
+// PostQuantumKeyFromSeed derives the post-quantum key of the given algorithm
+// from its private key seed.
+func PostQuantumKeyFromSeed(algorithm SignatureAlgorithm, seed []byte) (*PostQuantumKey, error) {
+	scheme, ok := pqSchemes[algorithm]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrAlgorithmNotSupported, algorithm)
+	}
+	if size := pqcrypto.SeedSize(scheme.algo); len(seed) != size {
+		return nil, fmt.Errorf("invalid seed length %d for %s, want %d", len(seed), GetAlgorithmName(algorithm), size)
+	}
+	signer, err := pqcrypto.NewPQSignerFromSeed(scheme.algo, seed)
+	if err != nil {
+		return nil, err
+	}
+	return &PostQuantumKey{
+		Algorithm: algorithm,
+		Seed:      common.CopyBytes(seed),
+		PublicKey: signer.PublicKey(),
+		signer:    signer,
+	}, nil
+}
+
+// Address returns the account address of the key, the last 20 bytes of the
+// Keccak256 hash of the public key.
+func (k *PostQuantumKey) Address() common.Address {
+	addr, _ := pqcrypto.PubkeyToAddress(pqSchemes[k.Algorithm].algo, k.PublicKey)
+	return addr
+}
+
+// Sign signs a message with the deterministic variant of the key's algorithm
+// and an empty context string.
+func (k *PostQuantumKey) Sign(message []byte) ([]byte, error) {
+	if k.signer == nil {
+		return nil, errors.New("post-quantum private key not set")
+	}
+	return k.signer.Sign(message)
+}
+
+// zero clears the seed and drops the expanded private key.
+func (k *PostQuantumKey) zero() {
+	clear(k.Seed)
+	k.signer = nil
+}
+
+// signPQTx signs a post-quantum transaction. The algorithm committed to by the
+// transaction must be the one of the key.
+func signPQTx(tx *types.Transaction, signer types.Signer, key *PostQuantumKey) (*types.Transaction, error) {
+	if tx.Type() != types.PQTxType {
+		return nil, errPQTxRequired
+	}
+	want, ok := key.Algorithm.TxAlgorithm()
+	if !ok {
+		return nil, fmt.Errorf("%s keys cannot sign transactions", GetAlgorithmName(key.Algorithm))
+	}
+	if algo, _, _ := tx.PQSignature(); pqcrypto.Algorithm(algo) != want {
+		return nil, fmt.Errorf("transaction algorithm %d does not match key algorithm %s", algo, GetAlgorithmName(key.Algorithm))
+	}
+	h := signer.Hash(tx)
+	sig, err := key.Sign(h[:])
+	if err != nil {
+		return nil, err
+	}
+	return tx.WithPQSignature(signer, key.PublicKey, sig)
+}
+
+func newKeyFromPostQuantum(pq *PostQuantumKey) *Key {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		panic(fmt.Sprintf("Could not create random uuid: %v", err))
+	}
+	return &Key{
+		Id:          id,
+		Address:     pq.Address(),
+		PostQuantum: pq,
+	}
+}
+
+func storeNewPostQuantumKey(ks keyStore, rand io.Reader, algorithm SignatureAlgorithm, auth string) (*Key, accounts.Account, error) {
+	pq, err := newPostQuantumKey(rand, algorithm)
+	if err != nil {
+		return nil, accounts.Account{}, err
+	}
+	key := newKeyFromPostQuantum(pq)
+	a := accounts.Account{
+		Address: key.Address,
+		URL:     accounts.URL{Scheme: KeyStoreScheme, Path: ks.JoinPath(keyFileName(key.Address))},
+	}
+	if err := ks.StoreKey(a.URL.Path, key, auth); err != nil {
+		zeroKey(key)
+		return nil, a, err
+	}
+	return key, a, err
+}
+
+// StorePostQuantumKey generates a post-quantum key, encrypts with 'auth' and
+// stores in the given directory
+func StorePostQuantumKey(dir, auth string, algorithm SignatureAlgorithm, scryptN, scryptP int) (accounts.Account, error) {
+	_, a, err := storeNewPostQuantumKey(&keyStorePassphrase{dir, scryptN, scryptP, false}, crand.Reader, algorithm, auth)
+	return a, err
+}
+
+type plainPQKeyJSON struct {
 	Address    string `json:"address"`
 	Algorithm  uint8  `json:"algorithm"`
-	PrivateKey string `json:"privatekey"`
 	PublicKey  string `json:"publickey"`
+	PrivateKey string `json:"privatekey"`
 	Id         string `json:"id"`
 	Version    int    `json:"version"`
 }
 
-// NewPostQuantumKey generates a new post-quantum key
-func NewPostQuantumKey(algorithm SignatureAlgorithm) (*PostQuantumKey, error) {
-	key := &PostQuantumKey{
-		Id:        uuid.New(),
-		Algorithm: algorithm,
+type encryptedKeyJSONV4 struct {
+	Address   string     `json:"address"`
+	Algorithm uint8      `json:"algorithm"`
+	PublicKey string     `json:"publickey"`
+	Crypto    CryptoJSON `json:"crypto"`
+	Id        string     `json:"id"`
+	Version   int        `json:"version"`
+}
+
+// pqKeyHeader holds the unencrypted fields of a post-quantum key file.
+type pqKeyHeader struct {
+	Address   string `json:"address"`
+	Algorithm uint8  `json:"algorithm"`
+	PublicKey string `json:"publickey"`
+}
+
+// check verifies that the algorithm is supported and that the address and the
+// public key belong together.
+func (h *pqKeyHeader) check() (SignatureAlgorithm, []byte, error) {
+	alg := SignatureAlgorithm(h.Algorithm)
+	scheme, ok := pqSchemes[alg]
+	if !ok {
+		return 0, nil, fmt.Errorf("%w: %d", ErrAlgorithmNotSupported, h.Algorithm)
 	}
-	
-	switch algorithm {
-	case SignatureECDSA:
-		// Generate ECDSA key as before
-		privateKeyECDSA, err := ecdsa.GenerateKey(crypto.S256(), rand.Reader)
-		if err != nil {
-			return nil, err
-		}
-		key.ECDSAPrivateKey = privateKeyECDSA
-		key.Address = common.BytesToAddress(crypto.PubkeyToAddress(privateKeyECDSA.PublicKey).Bytes())
-		
-	case SignatureMLDSA44:
-		privKey, err := mldsa.GenerateKey(rand.Reader, mldsa.MLDSA44)
-		if err != nil {
-			return nil, err
-		}
-		key.MLDSAPrivateKey = privKey
-		key.PrivateKeyBytes = privKey.Bytes()
-		key.PublicKeyBytes = privKey.PublicKey.Bytes()
-		// Derive address from public key hash
-		key.Address = common.BytesToAddress(crypto.Keccak256(key.PublicKeyBytes)[:20])
-		
-	case SignatureMLDSA65:
-		privKey, err := mldsa.GenerateKey(rand.Reader, mldsa.MLDSA65)
-		if err != nil {
-			return nil, err
-		}
-		key.MLDSAPrivateKey = privKey
-		key.PrivateKeyBytes = privKey.Bytes()
-		key.PublicKeyBytes = privKey.PublicKey.Bytes()
-		key.Address = common.BytesToAddress(crypto.Keccak256(key.PublicKeyBytes)[:20])
-		
-	case SignatureMLDSA87:
-		privKey, err := mldsa.GenerateKey(rand.Reader, mldsa.MLDSA87)
-		if err != nil {
-			return nil, err
-		}
-		key.MLDSAPrivateKey = privKey
-		key.PrivateKeyBytes = privKey.Bytes()
-		key.PublicKeyBytes = privKey.PublicKey.Bytes()
-		key.Address = common.BytesToAddress(crypto.Keccak256(key.PublicKeyBytes)[:20])
-		
-	case SignatureSLHDSA128f:
-		privKey, err := slhdsa.GenerateKey(rand.Reader, slhdsa.SLHDSA128f)
-		if err != nil {
-			return nil, err
-		}
-		key.SLHDSAPrivateKey = privKey
-		key.PrivateKeyBytes = privKey.Bytes()
-		key.PublicKeyBytes = privKey.PublicKey.Bytes()
-		key.Address = common.BytesToAddress(crypto.Keccak256(key.PublicKeyBytes)[:20])
-		
-	case SignatureSLHDSA192f:
-		privKey, err := slhdsa.GenerateKey(rand.Reader, slhdsa.SLHDSA192f)
-		if err != nil {
-			return nil, err
-		}
-		key.SLHDSAPrivateKey = privKey
-		key.PrivateKeyBytes = privKey.Bytes()
-		key.PublicKeyBytes = privKey.PublicKey.Bytes()
-		key.Address = common.BytesToAddress(crypto.Keccak256(key.PublicKeyBytes)[:20])
-		
-	case SignatureSLHDSA256f:
-		privKey, err := slhdsa.GenerateKey(rand.Reader, slhdsa.SLHDSA256f)
-		if err != nil {
-			return nil, err
-		}
-		key.SLHDSAPrivateKey = privKey
-		key.PrivateKeyBytes = privKey.Bytes()
-		key.PublicKeyBytes = privKey.PublicKey.Bytes()
-		key.Address = common.BytesToAddress(crypto.Keccak256(key.PublicKeyBytes)[:20])
-		
-	default:
-		return nil, fmt.Errorf("unsupported signature algorithm: %d", algorithm)
+	pub, err := hex.DecodeString(h.PublicKey)
+	if err != nil {
+		return 0, nil, err
+	}
+	addr, err := pqcrypto.PubkeyToAddress(scheme.algo, pub)
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid public key length %d for %s", len(pub), GetAlgorithmName(alg))
+	}
+	if addr != common.HexToAddress(h.Address) {
+		return 0, nil, fmt.Errorf("public key does not match address: have %x, want %s", addr, h.Address)
 	}
-	
-	return key, nil
+	return alg, pub, nil
 }
 
-// Sign signs a message with the appropriate algorithm
-func (k *PostQuantumKey) Sign(message []byte) ([]byte, error) {
-	switch k.Algorithm {
-	case SignatureECDSA:
-		if k.ECDSAPrivateKey == nil {
-			return nil, errors.New("ECDSA private key not set")
-		}
-		hash := crypto.Keccak256(message)
-		return crypto.Sign(hash, k.ECDSAPrivateKey)
-		
-	case SignatureMLDSA44, SignatureMLDSA65, SignatureMLDSA87:
-		if k.MLDSAPrivateKey == nil {
-			return nil, errors.New("ML-DSA private key not set")
-		}
-		return k.MLDSAPrivateKey.Sign(rand.Reader, message, nil)
-		
-	case SignatureSLHDSA128f, SignatureSLHDSA192f, SignatureSLHDSA256f:
-		if k.SLHDSAPrivateKey == nil {
-			return nil, errors.New("SLH-DSA private key not set")
-		}
-		return k.SLHDSAPrivateKey.Sign(rand.Reader, message, nil)
-		
-	default:
-		return nil, fmt.Errorf("unsupported signature algorithm: %d", k.Algorithm)
+// keyFileHeader holds the unencrypted fields common to all key files, along with
+// the post-quantum header, which is only filled in for version 4 files.
+type keyFileHeader struct {
+	pqKeyHeader
+	Version any `json:"version"` // string in version 1, number afterwards
+}
+
+// postQuantum reports whether the header belongs to a post-quantum key file.
+func (h *keyFileHeader) postQuantum() bool {
+	return h.Version == float64(pqVersion)
+}
+
+// readKeyAlgorithm returns the signature algorithm of a key file without
+// decrypting it.
+func readKeyAlgorithm(path string) (SignatureAlgorithm, error) {
+	keyjson, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	var header keyFileHeader
+	if err := json.Unmarshal(keyjson, &header); err != nil {
+		return 0, err
+	}
+	if !header.postQuantum() {
+		return SignatureECDSA, nil
 	}
+	alg, _, err := header.check()
+	return alg, err
 }
 
-// MarshalJSON serializes the key for storage
-func (k *PostQuantumKey) MarshalJSON() ([]byte, error) {
-	var privKeyHex string
-	var pubKeyHex string
-	
-	switch k.Algorithm {
-	case SignatureECDSA:
-		if k.ECDSAPrivateKey != nil {
-			privKeyHex = hex.EncodeToString(crypto.FromECDSA(k.ECDSAPrivateKey))
-			pubKeyHex = hex.EncodeToString(crypto.FromECDSAPub(&k.ECDSAPrivateKey.PublicKey))
-		}
-	default:
-		privKeyHex = hex.EncodeToString(k.PrivateKeyBytes)
-		pubKeyHex = hex.EncodeToString(k.PublicKeyBytes)
-	}
-	
-	return json.Marshal(&postQuantumKeyJSON{
-		Address:    k.Address.Hex(),
-		Algorithm:  uint8(k.Algorithm),
-		PrivateKey: privKeyHex,
-		PublicKey:  pubKeyHex,
+// fromSeed loads the key described by the header from its seed.
+func (h *pqKeyHeader) fromSeed(seed []byte) (*PostQuantumKey, error) {
+	alg, pub, err := h.check()
+	if err != nil {
+		return nil, err
+	}
+	pq, err := PostQuantumKeyFromSeed(alg, seed)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(pq.PublicKey, pub) {
+		pq.zero()
+		return nil, errors.New("key content mismatch: seed does not match public key")
+	}
+	return pq, nil
+}
+
+func (k *Key) marshalPQJSON() ([]byte, error) {
+	return json.Marshal(&plainPQKeyJSON{
+		Address:    hex.EncodeToString(k.Address[:]),
+		Algorithm:  uint8(k.PostQuantum.Algorithm),
+		PublicKey:  hex.EncodeToString(k.PostQuantum.PublicKey),
+		PrivateKey: hex.EncodeToString(k.PostQuantum.Seed),
 		Id:         k.Id.String(),
-		Version:    4, // New version for PQ keys
+		Version:    pqVersion,
 	})
 }
 
-// UnmarshalJSON deserializes the key from storage
-func (k *PostQuantumKey) UnmarshalJSON(data []byte) error {
-	var keyJSON postQuantumKeyJSON
-	if err := json.Unmarshal(data, &keyJSON); err != nil {
+func (k *Key) unmarshalPQJSON(j []byte) error {
+	keyJSON := new(plainPQKeyJSON)
+	if err := json.Unmarshal(j, keyJSON); err != nil {
 		return err
 	}
-	
-	k.Id, _ = uuid.Parse(keyJSON.Id)
-	k.Address = common.HexToAddress(keyJSON.Address)
-	k.Algorithm = SignatureAlgorithm(keyJSON.Algorithm)
-	
-	privKeyBytes, err := hex.DecodeString(keyJSON.PrivateKey)
+	id, err := uuid.Parse(keyJSON.Id)
 	if err != nil {
 		return err
 	}
-	
-	pubKeyBytes, err := hex.DecodeString(keyJSON.PublicKey)
+	seed, err := hex.DecodeString(keyJSON.PrivateKey)
 	if err != nil {
 		return err
 	}
-	
-	k.PrivateKeyBytes = privKeyBytes
-	k.PublicKeyBytes = pubKeyBytes
-	
-	// Reconstruct the actual key objects based on algorithm
-	switch k.Algorithm {
-	case SignatureECDSA:
-		key, err := crypto.ToECDSA(privKeyBytes)
-		if err != nil {
-			return err
-		}
-		k.ECDSAPrivateKey = key
-		
-	case SignatureMLDSA44:
-		key, err := mldsa.PrivateKeyFromBytes(privKeyBytes, mldsa.MLDSA44)
-		if err != nil {
-			return err
-		}
-		k.MLDSAPrivateKey = key
-		
-	case SignatureMLDSA65:
-		key, err := mldsa.PrivateKeyFromBytes(privKeyBytes, mldsa.MLDSA65)
-		if err != nil {
-			return err
-		}
-		k.MLDSAPrivateKey = key
-		
-	case SignatureMLDSA87:
-		key, err := mldsa.PrivateKeyFromBytes(privKeyBytes, mldsa.MLDSA87)
-		if err != nil {
-			return err
-		}
-		k.MLDSAPrivateKey = key
-		
-	case SignatureSLHDSA128f:
-		key, err := slhdsa.PrivateKeyFromBytes(privKeyBytes, slhdsa.SLHDSA128f)
-		if err != nil {
-			return err
-		}
-		k.SLHDSAPrivateKey = key
-		
-	case SignatureSLHDSA192f:
-		key, err := slhdsa.PrivateKeyFromBytes(privKeyBytes, slhdsa.SLHDSA192f)
-		if err != nil {
-			return err
-		}
-		k.SLHDSAPrivateKey = key
-		
-	case SignatureSLHDSA256f:
-		key, err := slhdsa.PrivateKeyFromBytes(privKeyBytes, slhdsa.SLHDSA256f)
-		if err != nil {
-			return err
-		}
-		k.SLHDSAPrivateKey = key
+	header := pqKeyHeader{keyJSON.Address, keyJSON.Algorithm, keyJSON.PublicKey}
+	pq, err := header.fromSeed(seed)
+	if err != nil {
+		return err
 	}
-	
+	k.Id = id
+	k.Address = pq.Address()
+	k.PostQuantum = pq
 	return nil
 }
 
+// encryptPQKey encrypts a post-quantum key into a version 4 key file. The seed
+// is encrypted the same way as version 3 keys; the algorithm and public key are
+// stored in the clear so the account can be inspected without the password.
+func encryptPQKey(key *Key, auth string, scryptN, scryptP int) ([]byte, error) {
+	cryptoStruct, err := EncryptDataV3(key.PostQuantum.Seed, []byte(auth), scryptN, scryptP)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&encryptedKeyJSONV4{
+		Address:   hex.EncodeToString(key.Address[:]),
+		Algorithm: uint8(key.PostQuantum.Algorithm),
+		PublicKey: hex.EncodeToString(key.PostQuantum.PublicKey),
+		Crypto:    cryptoStruct,
+		Id:        key.Id.String(),
+		Version:   pqVersion,
+	})
+}
+
+// decryptPQKey decrypts a version 4 key file.
+func decryptPQKey(keyjson []byte, auth string) (*Key, error) {
+	k := new(encryptedKeyJSONV4)
+	if err := json.Unmarshal(keyjson, k); err != nil {
+		return nil, err
+	}
+	id, err := uuid.Parse(k.Id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid UUID: %w", err)
+	}
+	header := pqKeyHeader{k.Address, k.Algorithm, k.PublicKey}
+	if _, _, err := header.check(); err != nil {
+		return nil, err
+	}
+	seed, err := DecryptDataV3(k.Crypto, auth)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(seed)
+
+	pq, err := header.fromSeed(seed)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{
+		Id:          id,
+		Address:     pq.Address(),
+		PostQuantum: pq,
+	}, nil
+}
+
 // GetAlgorithmName returns human-readable algorithm name
 func GetAlgorithmName(alg SignatureAlgorithm) string {
 	switch alg {
@@ -307,6 +453,8 @@ func GetAlgorithmName(alg SignatureAlgorithm) string {
 		return "SLH-DSA-256f (Fast)"
 	case SignatureBLS:
 		return "BLS12-381"
+	case SignatureHybridMLDSA65:
+		return "Hybrid secp256k1 + ML-DSA-65"
 	default:
 		return "Unknown"
 	}
@@ -314,22 +462,11 @@ func GetAlgorithmName(alg SignatureAlgorithm) string {
 
 // GetKeySizes returns the key and signature sizes for an algorithm
 func GetKeySizes(alg SignatureAlgorithm) (privKeySize, pubKeySize, sigSize int) {
-	switch alg {
-	case SignatureECDSA:
+	if alg == SignatureECDSA {
 		return 32, 64, 65
-	case SignatureMLDSA44:
-		return mldsa.MLDSA44PrivateKeySize, mldsa.MLDSA44PublicKeySize, mldsa.MLDSA44SignatureSize
-	case SignatureMLDSA65:
-		return mldsa.MLDSA65PrivateKeySize, mldsa.MLDSA65PublicKeySize, mldsa.MLDSA65SignatureSize
-	case SignatureMLDSA87:
-		return mldsa.MLDSA87PrivateKeySize, mldsa.MLDSA87PublicKeySize, mldsa.MLDSA87SignatureSize
-	case SignatureSLHDSA128f:
-		return slhdsa.SLHDSA128fPrivateKeySize, slhdsa.SLHDSA128fPublicKeySize, slhdsa.SLHDSA128fSignatureSize
-	case SignatureSLHDSA192f:
-		return slhdsa.SLHDSA192fPrivateKeySize, slhdsa.SLHDSA192fPublicKeySize, slhdsa.SLHDSA192fSignatureSize
-	case SignatureSLHDSA256f:
-		return slhdsa.SLHDSA256fPrivateKeySize, slhdsa.SLHDSA256fPublicKeySize, slhdsa.SLHDSA256fSignatureSize
-	default:
-		return 0, 0, 0
 	}
-}
\ No newline at end of file
+	if scheme, ok := pqSchemes[alg]; ok {
+		return pqcrypto.PrivateKeySize(scheme.algo), pqcrypto.PublicKeySize(scheme.algo), pqcrypto.SignatureSize(scheme.algo)
+	}
+	return 0, 0, 0
+}
@@ -0,0 +1,319 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// Post-quantum key support for keystore
+
+package keystore
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/circl/sign/mldsa/mldsa44"
+	"github.com/holiman/uint256"
+	"github.com/luxfi/geth/accounts"
+	"github.com/luxfi/geth/core/types"
+	"github.com/luxfi/geth/crypto/pqcrypto"
+	"github.com/luxfi/geth/crypto/slhdsa"
+)
+
+// Tests that post-quantum keys survive an encryption round trip for every
+// supported algorithm, and that the key file never contains the seed.
+func TestPQKeyEncryptDecrypt(t *testing.T) {
+	t.Parallel()
+
+	for alg := range pqSchemes {
+		pq, err := NewPostQuantumKey(alg)
+		if err != nil {
+			t.Fatalf("%s: failed to generate key: %v", GetAlgorithmName(alg), err)
+		}
+		key := newKeyFromPostQuantum(pq)
+		keyjson, err := EncryptKey(key, "foo", veryLightScryptN, veryLightScryptP)
+		if err != nil {
+			t.Fatalf("%s: failed to encrypt key: %v", GetAlgorithmName(alg), err)
+		}
+		if strings.Contains(string(keyjson), hex.EncodeToString(pq.Seed)) {
+			t.Fatalf("%s: key file contains the plaintext seed", GetAlgorithmName(alg))
+		}
+		if _, err := DecryptKey(keyjson, "bar"); !errors.Is(err, ErrDecrypt) {
+			t.Errorf("%s: decrypt with wrong password: have %v, want %v", GetAlgorithmName(alg), err, ErrDecrypt)
+		}
+		dec, err := DecryptKey(keyjson, "foo")
+		if err != nil {
+			t.Fatalf("%s: failed to decrypt key: %v", GetAlgorithmName(alg), err)
+		}
+		if dec.Id != key.Id || dec.Address != key.Address || dec.PrivateKey != nil {
+			t.Fatalf("%s: decrypted key mismatch", GetAlgorithmName(alg))
+		}
+		if dec.PostQuantum.Algorithm != alg || !bytes.Equal(dec.PostQuantum.Seed, pq.Seed) || !bytes.Equal(dec.PostQuantum.PublicKey, pq.PublicKey) {
+			t.Fatalf("%s: decrypted post-quantum key mismatch", GetAlgorithmName(alg))
+		}
+	}
+}
+
+// Tests that tampering with the unencrypted fields of a key file is detected.
+func TestPQKeyTampering(t *testing.T) {
+	t.Parallel()
+
+	pq, _ := NewPostQuantumKey(SignatureMLDSA44)
+	other, _ := NewPostQuantumKey(SignatureMLDSA44)
+	keyjson, err := EncryptKey(newKeyFromPostQuantum(pq), "foo", veryLightScryptN, veryLightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tamper := func(fn func(k *encryptedKeyJSONV4)) []byte {
+		var k encryptedKeyJSONV4
+		if err := json.Unmarshal(keyjson, &k); err != nil {
+			t.Fatal(err)
+		}
+		fn(&k)
+		blob, _ := json.Marshal(&k)
+		return blob
+	}
+	for name, blob := range map[string][]byte{
+		"swapped key": tamper(func(k *encryptedKeyJSONV4) {
+			k.PublicKey, k.Address = hex.EncodeToString(other.PublicKey), hex.EncodeToString(other.Address().Bytes())
+		}),
+		"wrong address":   tamper(func(k *encryptedKeyJSONV4) { k.Address = hex.EncodeToString(other.Address().Bytes()) }),
+		"wrong algorithm": tamper(func(k *encryptedKeyJSONV4) { k.Algorithm = uint8(SignatureMLDSA65) }),
+		"bad algorithm":   tamper(func(k *encryptedKeyJSONV4) { k.Algorithm = uint8(SignatureBLS) }),
+	} {
+		if _, err := DecryptKey(blob, "foo"); err == nil {
+			t.Errorf("%s: tampered key file decrypted", name)
+		}
+	}
+}
+
+func TestPQKeyStore(t *testing.T) {
+	t.Parallel()
+	dir, ks := tmpKeyStore(t)
+
+	a, err := ks.NewPostQuantumAccount(SignatureMLDSA44, "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(a.URL.Path, dir) {
+		t.Errorf("account file %s doesn't have dir prefix", a.URL)
+	}
+	if !ks.HasAddress(a.Address) {
+		t.Errorf("HasAccount(%x) should've returned true", a.Address)
+	}
+	if alg, err := ks.Algorithm(a); err != nil || alg != SignatureMLDSA44 {
+		t.Errorf("algorithm mismatch: have %v (%v), want %v", alg, err, SignatureMLDSA44)
+	}
+	// The account must show up as a wallet like any other key
+	wallets := ks.Wallets()
+	if len(wallets) != 1 || !wallets[0].Contains(a) {
+		t.Fatalf("post-quantum account not listed as wallet: %v", wallets)
+	}
+	if err := ks.Update(a, "foo", "bar"); err != nil {
+		t.Errorf("Update error: %v", err)
+	}
+	if err := ks.Unlock(a, "foo"); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("unlock with old password: have %v, want %v", err, ErrDecrypt)
+	}
+	if err := ks.Delete(a, "bar"); err != nil {
+		t.Errorf("Delete error: %v", err)
+	}
+	if ks.HasAddress(a.Address) {
+		t.Errorf("HasAccount(%x) should've returned false after Delete", a.Address)
+	}
+	if _, err := ks.NewPostQuantumAccount(SignatureBLS, "foo"); !errors.Is(err, ErrAlgorithmNotSupported) {
+		t.Errorf("BLS account: have %v, want %v", err, ErrAlgorithmNotSupported)
+	}
+}
+
+func TestPQTimedUnlockSign(t *testing.T) {
+	t.Parallel()
+	_, ks := tmpKeyStore(t)
+
+	a, err := ks.NewPostQuantumAccount(SignatureSLHDSA128f, "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ks.SignHash(a, testSigData); err != ErrLocked {
+		t.Fatal("Signing should've failed with ErrLocked before unlocking, got ", err)
+	}
+	if err := ks.TimedUnlock(a, "foo", 100*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	sig, err := ks.SignHash(a, testSigData)
+	if err != nil {
+		t.Fatal("Signing shouldn't return an error after unlocking, got ", err)
+	}
+	pub := ks.unlocked[a.Address].PostQuantum.PublicKey
+	if !slhdsa.SHAKE128f.Verify(pub, testSigData, sig, nil) {
+		t.Fatal("signature does not verify")
+	}
+	// SLH-DSA fast variants are not accepted in transactions
+	tx := types.NewTx(&types.PQTx{ChainID: uint256.NewInt(1), Algorithm: uint8(pqcrypto.AlgoSLHDSA128s)})
+	if _, err := ks.SignTx(a, tx, big.NewInt(1)); err == nil {
+		t.Error("transaction signed with SLH-DSA-128f key")
+	}
+	time.Sleep(250 * time.Millisecond)
+	if _, err := ks.SignHash(a, testSigData); err != ErrLocked {
+		t.Fatal("Signing should've failed with ErrLocked timeout expired, got ", err)
+	}
+}
+
+func TestPQSignTx(t *testing.T) {
+	t.Parallel()
+	_, ks := tmpKeyStore(t)
+
+	a, err := ks.NewPostQuantumAccount(SignatureMLDSA44, "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainID := big.NewInt(1337)
+	tx := types.NewTx(&types.PQTx{
+		ChainID:   uint256.MustFromBig(chainID),
+		GasTipCap: uint256.NewInt(1),
+		GasFeeCap: uint256.NewInt(1),
+		Gas:       100000,
+		Value:     uint256.NewInt(1),
+		Algorithm: uint8(pqcrypto.AlgoMLDSA44),
+	})
+	signed, err := ks.SignTxWithPassphrase(a, "foo", tx, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(chainID), signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from != a.Address {
+		t.Fatalf("sender mismatch: have %x, want %x", from, a.Address)
+	}
+	if _, pub, _ := signed.PQSignature(); len(pub) != mldsa44.PublicKeySize {
+		t.Fatalf("public key size mismatch: have %d, want %d", len(pub), mldsa44.PublicKeySize)
+	}
+	// Legacy transactions and mismatching algorithms must be rejected
+	if err := ks.Unlock(a, "foo"); err != nil {
+		t.Fatal(err)
+	}
+	legacy := types.NewTransaction(0, a.Address, big.NewInt(1), 21000, big.NewInt(1), nil)
+	if _, err := ks.SignTx(a, legacy, chainID); !errors.Is(err, errPQTxRequired) {
+		t.Errorf("legacy transaction: have %v, want %v", err, errPQTxRequired)
+	}
+	mismatch := types.NewTx(&types.PQTx{ChainID: uint256.MustFromBig(chainID), Algorithm: uint8(pqcrypto.AlgoMLDSA65)})
+	if _, err := ks.SignTx(a, mismatch, chainID); err == nil {
+		t.Error("transaction signed for a different algorithm")
+	}
+}
+
+func TestPQImportExport(t *testing.T) {
+	t.Parallel()
+	_, ks := tmpKeyStore(t)
+
+	pq, err := NewPostQuantumKey(SignatureMLDSA65)
+	if err != nil {
+		t.Fatal(err)
+	}
+	acc, err := ks.ImportPostQuantum(pq, "old")
+	if err != nil {
+		t.Fatalf("failed to import key: %v", err)
+	}
+	if acc.Address != pq.Address() {
+		t.Fatalf("address mismatch: have %x, want %x", acc.Address, pq.Address())
+	}
+	if _, err := ks.ImportPostQuantum(pq, "old"); !errors.Is(err, ErrAccountAlreadyExists) {
+		t.Errorf("importing a key twice: have %v, want %v", err, ErrAccountAlreadyExists)
+	}
+	json, err := ks.Export(acc, "old", "new")
+	if err != nil {
+		t.Fatalf("failed to export account: %v", err)
+	}
+	_, ks2 := tmpKeyStore(t)
+	if _, err = ks2.Import(json, "old", "old"); err == nil {
+		t.Errorf("importing with invalid password succeeded")
+	}
+	acc2, err := ks2.Import(json, "new", "new")
+	if err != nil {
+		t.Fatalf("importing failed: %v", err)
+	}
+	if acc.Address != acc2.Address {
+		t.Error("imported account does not match exported account")
+	}
+	if alg, _ := ks2.Algorithm(acc2); alg != SignatureMLDSA65 {
+		t.Errorf("imported algorithm mismatch: have %v, want %v", alg, SignatureMLDSA65)
+	}
+}
+
+// Tests that the account cache picks up post-quantum key files dropped into
+// the keystore directory, and skips files whose public key does not match.
+func TestPQWatchNewFile(t *testing.T) {
+	t.Parallel()
+	dir, ks := tmpKeyStore(t)
+
+	ks.Accounts()
+	if !waitWatcherStart(ks) {
+		t.Fatal("keystore watcher didn't start in time")
+	}
+	good, _ := NewPostQuantumKey(SignatureMLDSA87)
+	bad, _ := NewPostQuantumKey(SignatureMLDSA87)
+
+	goodJSON, _ := EncryptKey(newKeyFromPostQuantum(good), "", veryLightScryptN, veryLightScryptP)
+	badKey := newKeyFromPostQuantum(bad)
+	badKey.Address = good.Address()
+	badJSON, _ := EncryptKey(badKey, "", veryLightScryptN, veryLightScryptP)
+
+	if err := os.WriteFile(filepath.Join(dir, "bad"), badJSON, 0600); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "good")
+	if err := os.WriteFile(path, goodJSON, 0600); err != nil {
+		t.Fatal(err)
+	}
+	want := []accounts.Account{{Address: good.Address(), URL: accounts.URL{Scheme: KeyStoreScheme, Path: path}}}
+	if err := waitForAccounts(want, ks); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Tests that the plaintext keystore can hold post-quantum keys as well.
+func TestPQKeyStorePlain(t *testing.T) {
+	t.Parallel()
+	_, ks := tmpKeyStoreIface(t, false)
+
+	pq, _ := NewPostQuantumKey(SignatureMLDSA44)
+	k1 := newKeyFromPostQuantum(pq)
+	path := ks.JoinPath(keyFileName(k1.Address))
+	if err := ks.StoreKey(path, k1, ""); err != nil {
+		t.Fatal(err)
+	}
+	k2, err := ks.GetKey(k1.Address, path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k2.Id != k1.Id || !bytes.Equal(k2.PostQuantum.Seed, pq.Seed) {
+		t.Fatal("plaintext post-quantum key mismatch")
+	}
+}
+
+func TestParseSignatureAlgorithm(t *testing.T) {
+	for name, want := range map[string]SignatureAlgorithm{
+		"secp256k1":  SignatureECDSA,
+		"MLDSA65":    SignatureMLDSA65,
+		"slhdsa256f": SignatureSLHDSA256f,
+		"hybrid":     SignatureHybridMLDSA65,
+	} {
+		if have, err := ParseSignatureAlgorithm(name); err != nil || have != want {
+			t.Errorf("%s: have %v (%v), want %v", name, have, err, want)
+		}
+	}
+	if _, err := ParseSignatureAlgorithm("bls"); !errors.Is(err, ErrAlgorithmNotSupported) {
+		t.Errorf("bls: have %v, want %v", err, ErrAlgorithmNotSupported)
+	}
+	// Names round trip through String
+	for alg := range pqSchemes {
+		if have, err := ParseSignatureAlgorithm(alg.String()); err != nil || have != alg {
+			t.Errorf("%s: have %v (%v), want %v", alg, have, err, alg)
+		}
+	}
+}
@@ -14,7 +14,8 @@
 // You should have received a copy of the GNU Lesser General Public License
 // along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
 
-// Package keystore implements encrypted storage of secp256k1 private keys.
+// Package keystore implements encrypted storage of secp256k1 and post-quantum
+// private keys.
 //
 // Keys are stored as encrypted JSON files according to the Web3 Secret Storage specification.
 // See https://ethereum.org/en/developers/docs/data-structures-and-encoding/web3-secret-storage/ for more information.
@@ -233,7 +234,7 @@ func (ks *KeyStore) Delete(a accounts.Account, passphrase string) error {
 	// immediately afterwards.
 	a, key, err := ks.getDecryptedKey(a, passphrase)
 	if key != nil {
-		zeroKey(key.PrivateKey)
+		zeroKey(key)
 	}
 	if err != nil {
 		return err
@@ -250,7 +251,8 @@ func (ks *KeyStore) Delete(a accounts.Account, passphrase string) error {
 }
 
 // SignHash calculates a ECDSA signature for the given hash. The produced
-// signature is in the [R || S || V] format where V is 0 or 1.
+// signature is in the [R || S || V] format where V is 0 or 1. Post-quantum
+// accounts sign the hash with their own algorithm instead.
 func (ks *KeyStore) SignHash(a accounts.Account, hash []byte) ([]byte, error) {
 	// Look up the key to sign with and abort if it cannot be found
 	ks.mu.RLock()
@@ -260,8 +262,7 @@ func (ks *KeyStore) SignHash(a accounts.Account, hash []byte) ([]byte, error) {
 	if !found {
 		return nil, ErrLocked
 	}
-	// Sign the hash using plain ECDSA operations
-	return crypto.Sign(hash, unlockedKey.PrivateKey)
+	return signHash(unlockedKey.Key, hash)
 }
 
 // SignTx signs the given transaction with the requested account.
@@ -276,19 +277,19 @@ func (ks *KeyStore) SignTx(a accounts.Account, tx *types.Transaction, chainID *b
 	}
 	// Depending on the presence of the chain ID, sign with 2718 or homestead
 	signer := types.LatestSignerForChainID(chainID)
-	return types.SignTx(tx, signer, unlockedKey.PrivateKey)
+	return signTx(unlockedKey.Key, tx, signer)
 }
 
 // SignHashWithPassphrase signs hash if the private key matching the given address
 // can be decrypted with the given passphrase. The produced signature is in the
-// [R || S || V] format where V is 0 or 1.
+// [R || S || V] format where V is 0 or 1, unless the account is post-quantum.
 func (ks *KeyStore) SignHashWithPassphrase(a accounts.Account, passphrase string, hash []byte) (signature []byte, err error) {
 	_, key, err := ks.getDecryptedKey(a, passphrase)
 	if err != nil {
 		return nil, err
 	}
-	defer zeroKey(key.PrivateKey)
-	return crypto.Sign(hash, key.PrivateKey)
+	defer zeroKey(key)
+	return signHash(key, hash)
 }
 
 // SignTxWithPassphrase signs the transaction if the private key matching the
@@ -298,9 +299,25 @@ func (ks *KeyStore) SignTxWithPassphrase(a accounts.Account, passphrase string,
 	if err != nil {
 		return nil, err
 	}
-	defer zeroKey(key.PrivateKey)
+	defer zeroKey(key)
 	// Depending on the presence of the chain ID, sign with or without replay protection.
 	signer := types.LatestSignerForChainID(chainID)
+	return signTx(key, tx, signer)
+}
+
+// signHash signs the hash with the ECDSA or the post-quantum key.
+func signHash(key *Key, hash []byte) ([]byte, error) {
+	if key.PostQuantum != nil {
+		return key.PostQuantum.Sign(hash)
+	}
+	return crypto.Sign(hash, key.PrivateKey)
+}
+
+// signTx signs the transaction with the ECDSA or the post-quantum key.
+func signTx(key *Key, tx *types.Transaction, signer types.Signer) (*types.Transaction, error) {
+	if key.PostQuantum != nil {
+		return signPQTx(tx, signer, key.PostQuantum)
+	}
 	return types.SignTx(tx, signer, key.PrivateKey)
 }
 
@@ -340,7 +357,7 @@ func (ks *KeyStore) TimedUnlock(a accounts.Account, passphrase string, timeout t
 		if u.abort == nil {
 			// The address was unlocked indefinitely, so unlocking
 			// it with a timeout would be confusing.
-			zeroKey(key.PrivateKey)
+			zeroKey(key)
 			return nil
 		}
 		// Terminate the expire goroutine and replace it below.
@@ -387,7 +404,7 @@ func (ks *KeyStore) expire(addr common.Address, u *unlocked, timeout time.Durati
 		// because the map stores a new pointer every time the key is
 		// unlocked.
 		if ks.unlocked[addr] == u {
-			zeroKey(u.PrivateKey)
+			zeroKey(u.Key)
 			delete(ks.unlocked, addr)
 		}
 		ks.mu.Unlock()
@@ -408,6 +425,30 @@ func (ks *KeyStore) NewAccount(passphrase string) (accounts.Account, error) {
 	return account, nil
 }
 
+// NewPostQuantumAccount generates a new post-quantum key of the given algorithm
+// and stores it into the key directory, encrypting it with the passphrase.
+func (ks *KeyStore) NewPostQuantumAccount(algorithm SignatureAlgorithm, passphrase string) (accounts.Account, error) {
+	key, account, err := storeNewPostQuantumKey(ks.storage, crand.Reader, algorithm, passphrase)
+	if err != nil {
+		return accounts.Account{}, err
+	}
+	zeroKey(key)
+
+	ks.cache.add(account)
+	ks.refreshWallets()
+	return account, nil
+}
+
+// Algorithm returns the signature algorithm of the account's key. It only reads
+// the unencrypted part of the key file.
+func (ks *KeyStore) Algorithm(a accounts.Account) (SignatureAlgorithm, error) {
+	a, err := ks.Find(a)
+	if err != nil {
+		return 0, err
+	}
+	return readKeyAlgorithm(a.URL.Path)
+}
+
 // Export exports as a JSON key, encrypted with newPassphrase.
 func (ks *KeyStore) Export(a accounts.Account, passphrase, newPassphrase string) (keyJSON []byte, err error) {
 	_, key, err := ks.getDecryptedKey(a, passphrase)
@@ -426,8 +467,8 @@ func (ks *KeyStore) Export(a accounts.Account, passphrase, newPassphrase string)
 // Import stores the given encrypted JSON key into the key directory.
 func (ks *KeyStore) Import(keyJSON []byte, passphrase, newPassphrase string) (accounts.Account, error) {
 	key, err := DecryptKey(keyJSON, passphrase)
-	if key != nil && key.PrivateKey != nil {
-		defer zeroKey(key.PrivateKey)
+	if key != nil {
+		defer zeroKey(key)
 	}
 	if err != nil {
 		return accounts.Account{}, err
@@ -457,6 +498,21 @@ func (ks *KeyStore) ImportECDSA(priv *ecdsa.PrivateKey, passphrase string) (acco
 	return ks.importKey(key, passphrase)
 }
 
+// ImportPostQuantum stores the given post-quantum key into the key directory,
+// encrypting it with the passphrase.
+func (ks *KeyStore) ImportPostQuantum(pq *PostQuantumKey, passphrase string) (accounts.Account, error) {
+	ks.importMu.Lock()
+	defer ks.importMu.Unlock()
+
+	key := newKeyFromPostQuantum(pq)
+	if ks.cache.hasAddress(key.Address) {
+		return accounts.Account{
+			Address: key.Address,
+		}, ErrAccountAlreadyExists
+	}
+	return ks.importKey(key, passphrase)
+}
+
 func (ks *KeyStore) importKey(key *Key, passphrase string) (accounts.Account, error) {
 	a := accounts.Account{Address: key.Address, URL: accounts.URL{Scheme: KeyStoreScheme, Path: ks.storage.JoinPath(keyFileName(key.Address))}}
 	if err := ks.storage.StoreKey(a.URL.Path, key, passphrase); err != nil {
@@ -496,8 +552,12 @@ func (ks *KeyStore) isUpdating() bool {
 	return ks.updating
 }
 
-// zeroKey zeroes a private key in memory.
-func zeroKey(k *ecdsa.PrivateKey) {
-	b := k.D.Bits()
-	clear(b)
+// zeroKey zeroes the private key material of a key in memory.
+func zeroKey(k *Key) {
+	if k.PrivateKey != nil {
+		clear(k.PrivateKey.D.Bits())
+	}
+	if k.PostQuantum != nil {
+		k.PostQuantum.zero()
+	}
 }
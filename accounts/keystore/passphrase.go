@@ -180,8 +180,12 @@ func EncryptDataV3(data, auth []byte, scryptN, scryptP int) (CryptoJSON, error)
 }
 
 // EncryptKey encrypts a key using the specified scrypt parameters into a json
-// blob that can be decrypted later on.
+// blob that can be decrypted later on. Post-quantum keys are encoded in the
+// version 4 format, all others in version 3.
 func EncryptKey(key *Key, auth string, scryptN, scryptP int) ([]byte, error) {
+	if key.PostQuantum != nil {
+		return encryptPQKey(key, auth, scryptN, scryptP)
+	}
 	keyBytes := math.PaddedBigBytes(key.PrivateKey.D, 32)
 	cryptoStruct, err := EncryptDataV3(keyBytes, []byte(auth), scryptN, scryptP)
 	if err != nil {
@@ -203,6 +207,9 @@ func DecryptKey(keyjson []byte, auth string) (*Key, error) {
 	if err := json.Unmarshal(keyjson, &m); err != nil {
 		return nil, err
 	}
+	if version, ok := m["version"].(float64); ok && version == pqVersion {
+		return decryptPQKey(keyjson, auth)
+	}
 	// Depending on the version try to parse one way or another
 	var (
 		keyBytes, keyId []byte
@@ -87,6 +87,11 @@ var (
 		Name:  "suppress-bootwarn",
 		Usage: "If set, does not show the warning during boot",
 	}
+	algorithmFlag = &cli.StringFlag{
+		Name:  "algorithm",
+		Value: "secp256k1",
+		Usage: "Signature algorithm of the new account (secp256k1, mldsa44, mldsa65, mldsa87, slhdsa128s, ...)",
+	}
 	keystoreFlag = &cli.StringFlag{
 		Name:  "keystore",
 		Value: filepath.Join(node.DefaultDataDir(), "keystore"),
@@ -202,10 +207,12 @@ The delpw command removes a password for a given address (keyfile).
 			keystoreFlag,
 			utils.LightKDFFlag,
 			acceptFlag,
+			algorithmFlag,
 		},
 		Description: `
 The newaccount command creates a new keystore-backed account. It is a convenience-method
-which can be used in lieu of an external UI.
+which can be used in lieu of an external UI. The --algorithm flag selects the signature
+algorithm of the account; post-quantum accounts can only sign post-quantum transactions.
 `}
 	gendocCommand = &cli.Command{
 		Action: GenDoc,
@@ -503,7 +510,8 @@ func newAccount(c *cli.Context) error {
 	if err != nil {
 		return err
 	}
-	addr, err := internalApi.New(context.Background())
+	algorithm := c.String(algorithmFlag.Name)
+	addr, err := internalApi.New(context.Background(), &algorithm)
 	if err == nil {
 		fmt.Printf("Generated account %v\n", addr.String())
 	}
@@ -1097,12 +1105,14 @@ func GenDoc(ctx *cli.Context) error {
 		desc := "SignDataRequest contains information about a pending request to sign some data. " +
 			"The data to be signed can be of various types, defined by content-type. Clef has done most " +
 			"of the work in canonicalizing and making sense of the data, and it's up to the UI to present" +
-			"the user with the contents of the `message`"
+			"the user with the contents of the `message`. The `algorithm` is the signature algorithm of the " +
+			"account, e.g. `secp256k1` or `mldsa65`"
 		sighash, msg := accounts.TextAndHash([]byte("hello world"))
 		messages := []*apitypes.NameValueType{{Name: "message", Value: msg, Typ: accounts.MimetypeTextPlain}}
 
 		add("SignDataRequest", desc, &core.SignDataRequest{
 			Address:     common.NewMixedcaseAddress(a),
+			Algorithm:   "secp256k1",
 			Meta:        meta,
 			ContentType: accounts.MimetypeTextPlain,
 			Rawdata:     []byte(msg),
@@ -1124,11 +1134,15 @@ func GenDoc(ctx *cli.Context) error {
 			"\n\n" +
 			"The `transaction` (on input into clef) can have either `data` or `input` -- if both are set, " +
 			"they must be identical, otherwise an error is generated. " +
-			"However, Clef will always use `data` when passing this struct on (if Clef does otherwise, please file a ticket)"
+			"However, Clef will always use `data` when passing this struct on (if Clef does otherwise, please file a ticket)" +
+			"\n\n" +
+			"Transactions from post-quantum accounts are signed as post-quantum transactions, with the `pqAlgorithm` " +
+			"of the transaction set by Clef. The `algorithm` is the signature algorithm of the sender."
 
 		data := hexutil.Bytes([]byte{0x01, 0x02, 0x03, 0x04})
 		add("SignTxRequest", desc, &core.SignTxRequest{
-			Meta: meta,
+			Algorithm: "secp256k1",
+			Meta:      meta,
 			Callinfo: []apitypes.ValidationInfo{
 				{Typ: "Warning", Message: "Something looks odd, show this message as a warning"},
 				{Typ: "Info", Message: "User should see this as well"},
@@ -78,7 +78,7 @@ func enrdump(ctx *cli.Context) error {
 
 // dumpRecord creates a human-readable description of the given node record.
 func dumpRecord(out io.Writer, r *enr.Record) {
-	n, err := enode.New(enode.ValidSchemes, r)
+	n, err := enode.New(enode.ValidSchemesPostQuantum, r)
 	if err != nil {
 		fmt.Fprintf(out, "INVALID: %v\n", err)
 	} else {
@@ -180,6 +180,8 @@ var attrFormatters = map[string]func(rlp.RawValue) (string, bool){
 	"tcp6": formatAttrUint,
 	"udp":  formatAttrUint,
 	"udp6": formatAttrUint,
+	// ML-DSA public keys are too long to be printed in full.
+	"mldsa": formatAttrLongBytes,
 }
 
 func formatAttrRaw(v rlp.RawValue) (string, bool) {
@@ -187,6 +189,17 @@ func formatAttrRaw(v rlp.RawValue) (string, bool) {
 	return hex.EncodeToString(content), err == nil
 }
 
+func formatAttrLongBytes(v rlp.RawValue) (string, bool) {
+	content, _, err := rlp.SplitString(v)
+	if err != nil {
+		return "", false
+	}
+	if len(content) <= 32 {
+		return hex.EncodeToString(content), true
+	}
+	return fmt.Sprintf("%x... (%d bytes)", content[:16], len(content)), true
+}
+
 func formatAttrString(v rlp.RawValue) (string, bool) {
 	content, _, err := rlp.SplitString(v)
 	return strconv.Quote(string(content)), err == nil
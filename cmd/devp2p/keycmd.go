@@ -17,11 +17,15 @@
 package main
 
 import (
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net"
+	"os"
+	"strings"
 
 	"github.com/luxfi/crypto"
+	"github.com/luxfi/geth/crypto/pqcrypto"
 	"github.com/luxfi/geth/p2p/enode"
 	"github.com/luxfi/geth/p2p/enr"
 	"github.com/urfave/cli/v2"
@@ -43,6 +47,7 @@ var (
 		Usage:     "Generates node key files",
 		ArgsUsage: "keyfile",
 		Action:    genkey,
+		Flags:     []cli.Flag{mldsaFlag},
 	}
 	keyToIDCommand = &cli.Command{
 		Name:      "to-id",
@@ -63,7 +68,7 @@ var (
 		Usage:     "Creates an ENR from a node key file",
 		ArgsUsage: "keyfile",
 		Action:    keyToRecord,
-		Flags:     []cli.Flag{hostFlag, tcpPortFlag, udpPortFlag},
+		Flags:     []cli.Flag{hostFlag, tcpPortFlag, udpPortFlag, mldsaFlag},
 	}
 )
 
@@ -83,6 +88,10 @@ var (
 		Usage: "UDP port of the node",
 		Value: 30303,
 	}
+	mldsaFlag = &cli.BoolFlag{
+		Name:  "mldsa",
+		Usage: "Use the post-quantum \"mldsa\" identity scheme, with the ML-DSA-65 key stored in <keyfile>.mldsa",
+	}
 )
 
 func genkey(ctx *cli.Context) error {
@@ -95,7 +104,37 @@ func genkey(ctx *cli.Context) error {
 	if err != nil {
 		return fmt.Errorf("could not generate key: %v", err)
 	}
-	return crypto.SaveECDSA(file, key)
+	if err := crypto.SaveECDSA(file, key); err != nil {
+		return err
+	}
+	if !ctx.Bool(mldsaFlag.Name) {
+		return nil
+	}
+	pqkey, err := pqcrypto.NewPQSigner(pqcrypto.AlgoMLDSA65)
+	if err != nil {
+		return fmt.Errorf("could not generate ML-DSA key: %v", err)
+	}
+	seed, _ := pqkey.MarshalBinary()
+	return os.WriteFile(mldsaKeyFile(file), []byte(hex.EncodeToString(seed[1:])), 0600)
+}
+
+// mldsaKeyFile returns the file holding the ML-DSA-65 identity key that
+// belongs to a node key file.
+func mldsaKeyFile(file string) string {
+	return file + ".mldsa"
+}
+
+// loadMLDSAKey loads a hex-encoded ML-DSA-65 seed from a file.
+func loadMLDSAKey(file string) (*pqcrypto.PQSigner, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	seed, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ML-DSA key file %s: %v", file, err)
+	}
+	return pqcrypto.NewPQSignerFromSeed(pqcrypto.AlgoMLDSA65, seed)
 }
 
 func keyToID(ctx *cli.Context) error {
@@ -156,6 +195,16 @@ func makeRecord(ctx *cli.Context) (*enode.Node, error) {
 		r.Set(enr.TCP(tcp))
 	}
 
+	if ctx.Bool(mldsaFlag.Name) {
+		pqkey, err := loadMLDSAKey(mldsaKeyFile(file))
+		if err != nil {
+			return nil, err
+		}
+		if err := enode.SignMLDSA(&r, key, pqkey); err != nil {
+			return nil, err
+		}
+		return enode.New(enode.ValidSchemesPostQuantum, &r)
+	}
 	if err := enode.SignV4(&r, key); err != nil {
 		return nil, err
 	}
@@ -36,7 +36,7 @@ var commandChangePassphrase = &cli.Command{
 	Usage:     "change the password on a keyfile",
 	ArgsUsage: "<keyfile>",
 	Description: `
-Change the password of a keyfile.`,
+Change the password of a keyfile. Post-quantum keyfiles keep their algorithm.`,
 	Flags: []cli.Flag{
 		passphraseFlag,
 		newPassphraseFlag,
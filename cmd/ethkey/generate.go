@@ -33,6 +33,7 @@ import (
 type outputGenerate struct {
 	Address      string
 	AddressEIP55 string
+	outputKeyInfo
 }
 
 var (
@@ -55,12 +56,17 @@ Generate a new keyfile.
 
 If you want to encrypt an existing private key, it can be specified by setting
 --privatekey with the location of the file containing the private key.
+
+Use --algorithm to generate a post-quantum key instead of a secp256k1 one. The
+private key file of a post-quantum key contains the hex-encoded seed the key is
+derived from.
 `,
 	Flags: []cli.Flag{
 		passphraseFlag,
 		jsonFlag,
 		privateKeyFlag,
 		lightKDFFlag,
+		algorithmFlag,
 	},
 	Action: func(ctx *cli.Context) error {
 		// Check if keyfile path given and make sure it doesn't already exist.
@@ -74,17 +80,27 @@ If you want to encrypt an existing private key, it can be specified by setting
 			utils.Fatalf("Error checking if keyfile exists: %v", err)
 		}
 
+		algorithm := getAlgorithm(ctx)
 		var privateKey *ecdsa.PrivateKey
+		var pqKey *keystore.PostQuantumKey
 		var err error
 		if file := ctx.String(privateKeyFlag.Name); file != "" {
 			// Load private key from file.
-			privateKey, err = crypto.LoadECDSA(file)
+			if algorithm == keystore.SignatureECDSA {
+				privateKey, err = crypto.LoadECDSA(file)
+			} else {
+				pqKey, err = loadPostQuantumKey(file, algorithm)
+			}
 			if err != nil {
 				utils.Fatalf("Can't load private key: %v", err)
 			}
 		} else {
 			// If not loaded, generate random.
-			privateKey, err = crypto.GenerateKey()
+			if algorithm == keystore.SignatureECDSA {
+				privateKey, err = crypto.GenerateKey()
+			} else {
+				pqKey, err = keystore.NewPostQuantumKey(algorithm)
+			}
 			if err != nil {
 				utils.Fatalf("Failed to generate random private key: %v", err)
 			}
@@ -95,11 +111,14 @@ If you want to encrypt an existing private key, it can be specified by setting
 		if err != nil {
 			utils.Fatalf("Failed to generate random uuid: %v", err)
 		}
-		addr := common.Address(crypto.PubkeyToAddress(privateKey.PublicKey))
-		key := &keystore.Key{
-			Id:         UUID,
-			Address:    common.BytesToAddress(addr[:]),
-			PrivateKey: privateKey,
+		key := &keystore.Key{Id: UUID}
+		if pqKey != nil {
+			key.Address = pqKey.Address()
+			key.PostQuantum = pqKey
+		} else {
+			addr := common.Address(crypto.PubkeyToAddress(privateKey.PublicKey))
+			key.Address = common.BytesToAddress(addr[:])
+			key.PrivateKey = privateKey
 		}
 
 		// Encrypt key with passphrase.
@@ -123,12 +142,16 @@ If you want to encrypt an existing private key, it can be specified by setting
 
 		// Output some information.
 		out := outputGenerate{
-			Address: key.Address.Hex(),
+			Address:       key.Address.Hex(),
+			outputKeyInfo: keyInfo(key),
 		}
 		if ctx.Bool(jsonFlag.Name) {
 			mustPrintJSON(out)
 		} else {
 			fmt.Println("Address:", out.Address)
+			if key.PostQuantum != nil {
+				fmt.Println("Algorithm:", out.Algorithm)
+			}
 		}
 		return nil
 	},
@@ -31,6 +31,7 @@ type outputInspect struct {
 	Address    string
 	PublicKey  string
 	PrivateKey string
+	outputKeyInfo
 }
 
 var (
@@ -48,7 +49,8 @@ var commandInspect = &cli.Command{
 Print various information about the keyfile.
 
 Private key information can be printed by using the --private flag;
-make sure to use this feature with great caution!`,
+make sure to use this feature with great caution! The private key of a
+post-quantum keyfile is the seed the key is derived from.`,
 	Flags: []cli.Flag{
 		passphraseFlag,
 		jsonFlag,
@@ -73,18 +75,29 @@ make sure to use this feature with great caution!`,
 		// Output all relevant information we can retrieve.
 		showPrivate := ctx.Bool(privateFlag.Name)
 		out := outputInspect{
-			Address: key.Address.Hex(),
-			PublicKey: hex.EncodeToString(
-				crypto.FromECDSAPub(&key.PrivateKey.PublicKey)),
+			Address:       key.Address.Hex(),
+			outputKeyInfo: keyInfo(key),
 		}
-		if showPrivate {
-			out.PrivateKey = hex.EncodeToString(crypto.FromECDSA(key.PrivateKey))
+		if key.PostQuantum != nil {
+			out.PublicKey = hex.EncodeToString(key.PostQuantum.PublicKey)
+			if showPrivate {
+				out.PrivateKey = hex.EncodeToString(key.PostQuantum.Seed)
+			}
+		} else {
+			out.PublicKey = hex.EncodeToString(crypto.FromECDSAPub(&key.PrivateKey.PublicKey))
+			if showPrivate {
+				out.PrivateKey = hex.EncodeToString(crypto.FromECDSA(key.PrivateKey))
+			}
 		}
 
 		if ctx.Bool(jsonFlag.Name) {
 			mustPrintJSON(out)
 		} else {
 			fmt.Println("Address:       ", out.Address)
+			if key.PostQuantum != nil {
+				fmt.Println("Algorithm:     ", out.Algorithm)
+				fmt.Printf("Key sizes:      public %d, private %d, signature %d bytes\n", out.PublicKeySize, out.PrivateKeySize, out.SignatureSize)
+			}
 			fmt.Println("Public key:    ", out.PublicKey)
 			if showPrivate {
 				fmt.Println("Private key:   ", out.PrivateKey)
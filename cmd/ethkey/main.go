@@ -51,6 +51,11 @@ var (
 		Name:  "json",
 		Usage: "output JSON instead of human-readable format",
 	}
+	algorithmFlag = &cli.StringFlag{
+		Name:  "algorithm",
+		Usage: "signature algorithm: secp256k1, mldsa44, mldsa65, mldsa87, slhdsa{128,192,256}{s,f} or hybrid (secp256k1 + ML-DSA-65)",
+		Value: "secp256k1",
+	}
 )
 
 func main() {
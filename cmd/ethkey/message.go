@@ -26,17 +26,26 @@ import (
 	"github.com/luxfi/geth/cmd/utils"
 	"github.com/luxfi/geth/common"
 	"github.com/luxfi/crypto"
+	"github.com/luxfi/geth/crypto/pqcrypto"
 	"github.com/urfave/cli/v2"
 )
 
 type outputSign struct {
 	Signature string
+	Algorithm string
+	PublicKey string `json:",omitempty"`
 }
 
-var msgfileFlag = &cli.StringFlag{
-	Name:  "msgfile",
-	Usage: "file containing the message to sign/verify",
-}
+var (
+	msgfileFlag = &cli.StringFlag{
+		Name:  "msgfile",
+		Usage: "file containing the message to sign/verify",
+	}
+	publicKeyFlag = &cli.StringFlag{
+		Name:  "publickey",
+		Usage: "hex-encoded public key of the signer, required for post-quantum signatures",
+	}
+)
 
 var commandSignMessage = &cli.Command{
 	Name:      "signmessage",
@@ -46,6 +55,9 @@ var commandSignMessage = &cli.Command{
 Sign the message with a keyfile.
 
 To sign a message contained in a file, use the --msgfile flag.
+
+Post-quantum signatures don't allow recovering the public key of the signer, so
+it is printed along with signatures of post-quantum keyfiles.
 `,
 	Flags: []cli.Flag{
 		passphraseFlag,
@@ -69,15 +81,29 @@ To sign a message contained in a file, use the --msgfile flag.
 			utils.Fatalf("Error decrypting key: %v", err)
 		}
 
-		signature, err := crypto.Sign(accounts.TextHash(message), key.PrivateKey)
+		var signature []byte
+		if key.PostQuantum != nil {
+			signature, err = key.PostQuantum.Sign(accounts.TextHash(message))
+		} else {
+			signature, err = crypto.Sign(accounts.TextHash(message), key.PrivateKey)
+		}
 		if err != nil {
 			utils.Fatalf("Failed to sign message: %v", err)
 		}
-		out := outputSign{Signature: hex.EncodeToString(signature)}
+		out := outputSign{
+			Signature: hex.EncodeToString(signature),
+			Algorithm: keyInfo(key).Algorithm,
+		}
+		if key.PostQuantum != nil {
+			out.PublicKey = hex.EncodeToString(key.PostQuantum.PublicKey)
+		}
 		if ctx.Bool(jsonFlag.Name) {
 			mustPrintJSON(out)
 		} else {
 			fmt.Println("Signature:", out.Signature)
+			if out.PublicKey != "" {
+				fmt.Println("Public key:", out.PublicKey)
+			}
 		}
 		return nil
 	},
@@ -85,6 +111,7 @@ To sign a message contained in a file, use the --msgfile flag.
 
 type outputVerify struct {
 	Success            bool
+	Algorithm          string
 	RecoveredAddress   string
 	RecoveredPublicKey string
 }
@@ -95,10 +122,15 @@ var commandVerifyMessage = &cli.Command{
 	ArgsUsage: "<address> <signature> <message>",
 	Description: `
 Verify the signature of the message.
-It is possible to refer to a file containing the message.`,
+It is possible to refer to a file containing the message.
+
+Post-quantum signatures are verified against the public key given by --publickey,
+which must belong to the address.`,
 	Flags: []cli.Flag{
 		jsonFlag,
 		msgfileFlag,
+		algorithmFlag,
+		publicKeyFlag,
 	},
 	Action: func(ctx *cli.Context) error {
 		addressStr := ctx.Args().First()
@@ -113,6 +145,10 @@ It is possible to refer to a file containing the message.`,
 		if err != nil {
 			utils.Fatalf("Signature encoding is not hexadecimal: %v", err)
 		}
+		if algorithm := getAlgorithm(ctx); algorithm != keystore.SignatureECDSA {
+			verifyPostQuantum(ctx, algorithm, address, signature, message)
+			return nil
+		}
 
 		recoveredPubkey, err := crypto.SigToPub(accounts.TextHash(message), signature)
 		if err != nil || recoveredPubkey == nil {
@@ -125,6 +161,7 @@ It is possible to refer to a file containing the message.`,
 
 		out := outputVerify{
 			Success:            success,
+			Algorithm:          keystore.SignatureECDSA.String(),
 			RecoveredPublicKey: hex.EncodeToString(recoveredPubkeyBytes),
 			RecoveredAddress:   recoveredAddress.Hex(),
 		}
@@ -143,6 +180,40 @@ It is possible to refer to a file containing the message.`,
 	},
 }
 
+// verifyPostQuantum verifies a post-quantum signature against the public key
+// given by --publickey, and checks that the key belongs to the address.
+func verifyPostQuantum(ctx *cli.Context, algorithm keystore.SignatureAlgorithm, address common.Address, signature, message []byte) {
+	algo, _ := algorithm.PQAlgorithm()
+	pubkeyHex := ctx.String(publicKeyFlag.Name)
+	if pubkeyHex == "" {
+		utils.Fatalf("Post-quantum signatures require --%s", publicKeyFlag.Name)
+	}
+	pubkey, err := hex.DecodeString(pubkeyHex)
+	if err != nil {
+		utils.Fatalf("Public key encoding is not hexadecimal: %v", err)
+	}
+	pubkeyAddress, err := pqcrypto.PubkeyToAddress(algo, pubkey)
+	if err != nil {
+		utils.Fatalf("Invalid public key: %v", err)
+	}
+	out := outputVerify{
+		Success:            pubkeyAddress == address && pqcrypto.Verify(algo, pubkey, accounts.TextHash(message), signature),
+		Algorithm:          algorithm.String(),
+		RecoveredPublicKey: hex.EncodeToString(pubkey),
+		RecoveredAddress:   pubkeyAddress.Hex(),
+	}
+	if ctx.Bool(jsonFlag.Name) {
+		mustPrintJSON(out)
+	} else {
+		if out.Success {
+			fmt.Println("Signature verification successful!")
+		} else {
+			fmt.Println("Signature verification failed!")
+		}
+		fmt.Println("Public key address:", out.RecoveredAddress)
+	}
+}
+
 func getMessage(ctx *cli.Context, msgarg int) []byte {
 	if file := ctx.String(msgfileFlag.Name); file != "" {
 		if ctx.NArg() > msgarg {
@@ -17,6 +17,7 @@
 package main
 
 import (
+	"os"
 	"path/filepath"
 	"testing"
 )
@@ -63,3 +64,52 @@ Recovered address: (0x[0-9a-fA-F]{40})
 		t.Error("recovered address doesn't match generated key")
 	}
 }
+
+func TestMessageSignVerifyPostQuantum(t *testing.T) {
+	t.Parallel()
+	tmpdir := t.TempDir()
+
+	keyfile := filepath.Join(tmpdir, "the-keyfile")
+	passfile := filepath.Join(tmpdir, "password")
+	newpassfile := filepath.Join(tmpdir, "newpassword")
+	message := "test message"
+	os.WriteFile(passfile, []byte("foobar"), 0600)
+	os.WriteFile(newpassfile, []byte("barfoo"), 0600)
+
+	// Create the key.
+	generate := runEthkey(t, "generate", "--lightkdf", "--algorithm", "mldsa44", "--passwordfile", passfile, keyfile)
+	_, matches := generate.ExpectRegexp(`Address: (0x[0-9a-fA-F]{40})\nAlgorithm: mldsa44\n`)
+	address := matches[1]
+	generate.ExpectExit()
+
+	// Change the password, the key must keep its algorithm.
+	change := runEthkey(t, "changepassword", "--passwordfile", passfile, "--newpasswordfile", newpassfile, keyfile)
+	change.Expect("Please provide a new password\n")
+	change.ExpectExit()
+
+	inspect := runEthkey(t, "inspect", "--json", "--passwordfile", newpassfile, keyfile)
+	inspect.ExpectRegexp(`"Algorithm": "mldsa44",\s+"PublicKeySize": 1312,\s+"PrivateKeySize": 32,\s+"SignatureSize": 2420`)
+	inspect.ExpectExit()
+
+	// Sign a message.
+	sign := runEthkey(t, "signmessage", "--passwordfile", newpassfile, keyfile, message)
+	_, matches = sign.ExpectRegexp(`Signature: ([0-9a-f]+)\nPublic key: ([0-9a-f]+)\n`)
+	signature, pubkey := matches[1], matches[2]
+	sign.ExpectExit()
+
+	// Verify the message.
+	verify := runEthkey(t, "verifymessage", "--algorithm", "mldsa44", "--publickey", pubkey, address, signature, message)
+	_, matches = verify.ExpectRegexp(`
+Signature verification successful!
+Public key address: (0x[0-9a-fA-F]{40})
+`)
+	verify.ExpectExit()
+	if matches[1] != address {
+		t.Error("public key address doesn't match generated key")
+	}
+
+	// Verification of a different message fails.
+	verify = runEthkey(t, "verifymessage", "--algorithm", "mldsa44", "--publickey", pubkey, address, signature, "other message")
+	verify.ExpectRegexp(`Signature verification failed!\nPublic key address: 0x[0-9a-fA-F]{40}\n`)
+	verify.ExpectExit()
+}
@@ -17,15 +17,69 @@
 package main
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
 
+	"github.com/luxfi/crypto"
+	"github.com/luxfi/geth/accounts/keystore"
 	"github.com/luxfi/geth/cmd/utils"
 	"github.com/urfave/cli/v2"
 )
 
+// outputKeyInfo describes the algorithm of a key and the sizes of its public
+// key, private key and signatures as printed by ethkey. The private key of
+// post-quantum algorithms is the seed it is derived from.
+type outputKeyInfo struct {
+	Algorithm      string
+	PublicKeySize  int
+	PrivateKeySize int
+	SignatureSize  int
+}
+
+func keyInfo(key *keystore.Key) outputKeyInfo {
+	if key.PostQuantum == nil {
+		return outputKeyInfo{
+			Algorithm:      keystore.SignatureECDSA.String(),
+			PublicKeySize:  65,
+			PrivateKeySize: 32,
+			SignatureSize:  crypto.SignatureLength,
+		}
+	}
+	_, _, sigSize := keystore.GetKeySizes(key.PostQuantum.Algorithm)
+	return outputKeyInfo{
+		Algorithm:      key.PostQuantum.Algorithm.String(),
+		PublicKeySize:  len(key.PostQuantum.PublicKey),
+		PrivateKeySize: len(key.PostQuantum.Seed),
+		SignatureSize:  sigSize,
+	}
+}
+
+// getAlgorithm returns the signature algorithm selected by --algorithm.
+func getAlgorithm(ctx *cli.Context) keystore.SignatureAlgorithm {
+	algorithm, err := keystore.ParseSignatureAlgorithm(ctx.String(algorithmFlag.Name))
+	if err != nil {
+		utils.Fatalf("Invalid algorithm: %v", err)
+	}
+	return algorithm
+}
+
+// loadPostQuantumKey loads a post-quantum key from a file containing the
+// hex-encoded seed of the private key.
+func loadPostQuantumKey(file string, algorithm keystore.SignatureAlgorithm) (*keystore.PostQuantumKey, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	seed, err := hex.DecodeString(strings.TrimSpace(string(content)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex seed: %v", err)
+	}
+	return keystore.PostQuantumKeyFromSeed(algorithm, seed)
+}
+
 // getPassphrase obtains a passphrase given by the user.  It first checks the
 // --passfile command line flag and ultimately prompts the user for a
 // passphrase.
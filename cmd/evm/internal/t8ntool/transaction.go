@@ -140,6 +140,15 @@ func Transaction(ctx *cli.Context) error {
 			results = append(results, r)
 			continue
 		}
+		if _, pub, sig := tx.PQSignature(); tx.Type() == types.PQTxType {
+			pqGas, err := core.PQSignatureGas(uint64(len(pub) + len(sig)))
+			if err != nil {
+				r.Error = err
+				results = append(results, r)
+				continue
+			}
+			gas += pqGas
+		}
 		r.IntrinsicGas = gas
 		if tx.Gas() < gas {
 			r.Error = fmt.Errorf("%w: have %d, want %d", core.ErrIntrinsicGas, tx.Gas(), gas)
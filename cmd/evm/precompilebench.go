@@ -0,0 +1,517 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// Gas calibration benchmarks for precompiled contracts
+
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"text/tabwriter"
+	"time"
+
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fp"
+	"github.com/luxfi/crypto"
+	"github.com/luxfi/crypto/bn256"
+	"github.com/luxfi/crypto/kzg4844"
+	"github.com/luxfi/geth/common"
+	"github.com/luxfi/geth/core/vm"
+	"github.com/luxfi/geth/crypto/pqcrypto"
+	"github.com/luxfi/geth/tests"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	PrecompileForkFlag = &cli.StringFlag{
+		Name:  "fork",
+		Usage: "Fork whose precompiled contracts are benchmarked",
+		Value: "OsakaPostQuantum",
+	}
+	TargetMgasFlag = &cli.Float64Flag{
+		Name:  "mgas",
+		Usage: "Target throughput in Mgas/s used to derive gas costs from timings (0 = calibrate on ecrecover)",
+	}
+	ToleranceFlag = &cli.Float64Flag{
+		Name:  "tolerance",
+		Usage: "Maximum relative deviation of the gas cost from the derived cost before a contract is flagged",
+		Value: 0.5,
+	}
+	BenchTimeFlag = &cli.DurationFlag{
+		Name:  "benchtime",
+		Usage: "Minimum benchmarking time of every input",
+		Value: time.Second,
+	}
+	BenchJSONFlag = &cli.BoolFlag{
+		Name:  "json",
+		Usage: "Output the results as JSON",
+	}
+)
+
+var precompileBenchCommand = &cli.Command{
+	Action: precompileBenchCmd,
+	Name:   "precompile-bench",
+	Usage:  "Benchmarks the precompiled contracts and checks their gas costs",
+	Description: `
+The precompile-bench command benchmarks every precompiled contract of a fork
+across a range of input sizes. For each input it reports the time per call, the
+gas charged by the contract and the gas cost derived from the time at the target
+throughput. Inputs whose gas cost deviates from the derived cost by more than
+the tolerance are flagged as under- or overpriced.
+
+By default the target throughput is the measured throughput of ecrecover, the
+reference most gas schedules are calibrated against.`,
+	Flags: []cli.Flag{
+		RunFlag,
+		PrecompileForkFlag,
+		TargetMgasFlag,
+		ToleranceFlag,
+		BenchTimeFlag,
+		BenchJSONFlag,
+	},
+}
+
+// precompileBenchResult is the outcome of benchmarking a contract on one input.
+type precompileBenchResult struct {
+	Contract   string         `json:"contract"`
+	Address    common.Address `json:"address"`
+	Input      string         `json:"input"`
+	InputSize  int            `json:"inputSize"`
+	NsPerOp    int64          `json:"nsPerOp"`
+	Gas        uint64         `json:"gas"`
+	DerivedGas uint64         `json:"derivedGas"`
+	Deviation  float64        `json:"deviation"` // gas / derived gas - 1
+	Mispriced  string         `json:"mispriced,omitempty"`
+	Error      string         `json:"error,omitempty"`
+}
+
+// precompileBenchCase is an input to benchmark a contract on.
+type precompileBenchCase struct {
+	name     string
+	input    []byte
+	verifies bool // whether the input holds a valid signature the contract must accept
+}
+
+// precompileBenchInput generates the benchmark inputs of a precompiled contract.
+type precompileBenchInput struct {
+	name   string
+	inputs func(rng *rand.Rand) []precompileBenchCase
+}
+
+var ecrecoverAddress = common.BytesToAddress([]byte{0x01})
+
+func precompileBenchCmd(ctx *cli.Context) error {
+	config, _, err := tests.GetChainConfig(ctx.String(PrecompileForkFlag.Name))
+	if err != nil {
+		return err
+	}
+	filter, err := regexp.Compile(ctx.String(RunFlag.Name))
+	if err != nil {
+		return fmt.Errorf("invalid --run regexp: %v", err)
+	}
+	testing.Init()
+	if err := flag.Set("test.benchtime", ctx.Duration(BenchTimeFlag.Name).String()); err != nil {
+		return err
+	}
+
+	var (
+		contracts = vm.ActivePrecompiledContracts(config.Rules(common.Big0, true, math.MaxUint64))
+		addrs     = make([]common.Address, 0, len(contracts))
+		rng       = rand.New(rand.NewSource(1))
+		results   []*precompileBenchResult
+	)
+	for addr := range contracts {
+		addrs = append(addrs, addr)
+	}
+	slices.SortFunc(addrs, common.Address.Cmp)
+
+	targetMgas := ctx.Float64(TargetMgasFlag.Name)
+	if targetMgas == 0 {
+		p, ok := contracts[ecrecoverAddress]
+		if !ok {
+			return errors.New("ecrecover is not active, set the target throughput with --mgas")
+		}
+		in := precompileBenchInputs[ecrecoverAddress]
+		calibration := benchmarkPrecompile(p, in.inputs(rng)[0])
+		if calibration.Error != "" {
+			return fmt.Errorf("ecrecover calibration failed: %v", calibration.Error)
+		}
+		targetMgas = float64(calibration.Gas) * 1000 / float64(calibration.NsPerOp)
+	}
+	for _, addr := range addrs {
+		in, ok := precompileBenchInputs[addr]
+		if !ok {
+			in = precompileBenchInput{name: strings.TrimPrefix(fmt.Sprintf("%T", contracts[addr]), "*vm."), inputs: randomInputs}
+		}
+		if !filter.MatchString(in.name) {
+			continue
+		}
+		for _, c := range in.inputs(rng) {
+			res := benchmarkPrecompile(contracts[addr], c)
+			res.Contract, res.Address = in.name, addr
+			res.derive(targetMgas, ctx.Float64(ToleranceFlag.Name))
+			results = append(results, res)
+		}
+	}
+
+	if ctx.Bool(BenchJSONFlag.Name) {
+		out, err := json.MarshalIndent(map[string]any{"targetMgas": targetMgas, "results": results}, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+	fmt.Printf("Target throughput: %.2f Mgas/s\n\n", targetMgas)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Contract\tAddress\tInput\tSize\tns/op\tGas\tDerived\tDeviation\t")
+	for _, r := range results {
+		note := r.Mispriced
+		if r.Error != "" {
+			note = "error: " + r.Error
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%d\t%d\t%+.1f%%\t%s\n", r.Contract, shortAddress(r.Address), r.Input, r.InputSize, r.NsPerOp, r.Gas, r.DerivedGas, r.Deviation*100, note)
+	}
+	return w.Flush()
+}
+
+// benchmarkPrecompile measures the time taken by a contract to process an input,
+// including the computation of its gas cost.
+func benchmarkPrecompile(p vm.PrecompiledContract, c precompileBenchCase) *precompileBenchResult {
+	var (
+		gas  = p.RequiredGas(c.input)
+		data = make([]byte, len(c.input))
+		res  = &precompileBenchResult{Input: c.name, InputSize: len(c.input), Gas: gas}
+	)
+	// Run once to check that the input exercises the intended code path.
+	copy(data, c.input)
+	out, _, err := vm.RunPrecompiledContract(p, data, gas, nil)
+	if err != nil {
+		res.Error = err.Error()
+	} else if c.verifies && !bytes.Equal(out, common.LeftPadBytes([]byte{1}, 32)) {
+		res.Error = "signature rejected"
+	}
+	result := testing.Benchmark(func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			copy(data, c.input)
+			vm.RunPrecompiledContract(p, data, gas, nil)
+		}
+	})
+	res.NsPerOp = max(result.NsPerOp(), 1)
+	return res
+}
+
+// derive computes the gas cost corresponding to the measured time at the target
+// throughput and flags the result if the deviation exceeds the tolerance.
+func (r *precompileBenchResult) derive(targetMgas, tolerance float64) {
+	r.DerivedGas = uint64(math.Round(float64(r.NsPerOp) * targetMgas / 1000))
+	if r.DerivedGas == 0 {
+		r.DerivedGas = 1
+	}
+	ratio := float64(r.Gas) / float64(r.DerivedGas)
+	r.Deviation = ratio - 1
+	switch {
+	case ratio < 1/(1+tolerance):
+		r.Mispriced = "underpriced"
+	case ratio > 1+tolerance:
+		r.Mispriced = "overpriced"
+	}
+}
+
+// shortAddress formats a precompile address without its leading zeros.
+func shortAddress(addr common.Address) string {
+	return fmt.Sprintf("%#x", new(big.Int).SetBytes(addr[:]))
+}
+
+// precompileBenchInputs contains the input generators of the known precompiled
+// contracts. Contracts missing from the list are benchmarked on random inputs.
+var precompileBenchInputs = map[common.Address]precompileBenchInput{
+	ecrecoverAddress:                          {"ecrecover", ecrecoverInputs},
+	common.BytesToAddress([]byte{0x02}):       {"sha256", sizedInputs},
+	common.BytesToAddress([]byte{0x03}):       {"ripemd160", sizedInputs},
+	common.BytesToAddress([]byte{0x04}):       {"identity", sizedInputs},
+	common.BytesToAddress([]byte{0x05}):       {"modexp", modexpInputs},
+	common.BytesToAddress([]byte{0x06}):       {"bn256Add", bn256AddInputs},
+	common.BytesToAddress([]byte{0x07}):       {"bn256ScalarMul", bn256ScalarMulInputs},
+	common.BytesToAddress([]byte{0x08}):       {"bn256Pairing", bn256PairingInputs},
+	common.BytesToAddress([]byte{0x09}):       {"blake2F", blake2FInputs},
+	common.BytesToAddress([]byte{0x0a}):       {"kzgPointEvaluation", kzgPointEvaluationInputs},
+	common.BytesToAddress([]byte{0x0b}):       {"bls12381G1Add", blsG1AddInputs},
+	common.BytesToAddress([]byte{0x0c}):       {"bls12381G1MultiExp", blsG1MultiExpInputs},
+	common.BytesToAddress([]byte{0x0d}):       {"bls12381G2Add", blsG2AddInputs},
+	common.BytesToAddress([]byte{0x0e}):       {"bls12381G2MultiExp", blsG2MultiExpInputs},
+	common.BytesToAddress([]byte{0x0f}):       {"bls12381Pairing", blsPairingInputs},
+	common.BytesToAddress([]byte{0x10}):       {"bls12381MapG1", blsMapG1Inputs},
+	common.BytesToAddress([]byte{0x11}):       {"bls12381MapG2", blsMapG2Inputs},
+	common.BytesToAddress([]byte{0x01, 0x00}): {"p256Verify", p256VerifyInputs},
+
+	common.BytesToAddress([]byte{0x01, 0x10}): {"mldsaVerify44", pqVerifyInputs(pqcrypto.AlgoMLDSA44)},
+	common.BytesToAddress([]byte{0x01, 0x11}): {"mldsaVerify65", pqVerifyInputs(pqcrypto.AlgoMLDSA65)},
+	common.BytesToAddress([]byte{0x01, 0x12}): {"mldsaVerify87", pqVerifyInputs(pqcrypto.AlgoMLDSA87)},
+	common.BytesToAddress([]byte{0x01, 0x20}): {"mlkemEncap512", mlkemEncapInputs(pqcrypto.AlgoMLKEM512)},
+	common.BytesToAddress([]byte{0x01, 0x22}): {"mlkemEncap768", mlkemEncapInputs(pqcrypto.AlgoMLKEM768)},
+	common.BytesToAddress([]byte{0x01, 0x24}): {"mlkemEncap1024", mlkemEncapInputs(pqcrypto.AlgoMLKEM1024)},
+	common.BytesToAddress([]byte{0x01, 0x30}): {"slhdsaVerify128s", pqVerifyInputs(pqcrypto.AlgoSLHDSA128s)},
+	common.BytesToAddress([]byte{0x01, 0x31}): {"slhdsaVerify128f", pqVerifyInputs(pqcrypto.AlgoSLHDSA128f)},
+	common.BytesToAddress([]byte{0x01, 0x32}): {"slhdsaVerify192s", pqVerifyInputs(pqcrypto.AlgoSLHDSA192s)},
+	common.BytesToAddress([]byte{0x01, 0x33}): {"slhdsaVerify192f", pqVerifyInputs(pqcrypto.AlgoSLHDSA192f)},
+	common.BytesToAddress([]byte{0x01, 0x34}): {"slhdsaVerify256s", pqVerifyInputs(pqcrypto.AlgoSLHDSA256s)},
+	common.BytesToAddress([]byte{0x01, 0x35}): {"slhdsaVerify256f", pqVerifyInputs(pqcrypto.AlgoSLHDSA256f)},
+}
+
+func randomBytes(rng *rand.Rand, n int) []byte {
+	b := make([]byte, n)
+	rng.Read(b)
+	return b
+}
+
+func randomScalar(rng *rand.Rand) *big.Int {
+	return new(big.Int).SetBytes(randomBytes(rng, 31))
+}
+
+func randomInputs(rng *rand.Rand) []precompileBenchCase {
+	return []precompileBenchCase{
+		{name: "random", input: randomBytes(rng, 32)},
+		{name: "random", input: randomBytes(rng, 256)},
+		{name: "random", input: randomBytes(rng, 1024)},
+	}
+}
+
+func sizedInputs(rng *rand.Rand) []precompileBenchCase {
+	var cases []precompileBenchCase
+	for _, size := range []int{32, 256, 1024, 8192} {
+		cases = append(cases, precompileBenchCase{name: fmt.Sprintf("%d bytes", size), input: randomBytes(rng, size)})
+	}
+	return cases
+}
+
+func ecrecoverInputs(rng *rand.Rand) []precompileBenchCase {
+	key, _ := crypto.ToECDSA(crypto.Keccak256(randomBytes(rng, 32)))
+	hash := randomBytes(rng, 32)
+	sig, _ := crypto.Sign(hash, key)
+	input := make([]byte, 128)
+	copy(input, hash)
+	input[63] = sig[64] + 27
+	copy(input[64:], sig[:64])
+	return []precompileBenchCase{{name: "valid", input: input}}
+}
+
+func modexpInputs(rng *rand.Rand) []precompileBenchCase {
+	var cases []precompileBenchCase
+	for _, size := range []int{32, 64, 128, 256, 512} {
+		input := make([]byte, 96)
+		for i := 0; i < 3; i++ {
+			binary.BigEndian.PutUint64(input[i*32+24:], uint64(size))
+		}
+		base, exp, mod := randomBytes(rng, size), randomBytes(rng, size), randomBytes(rng, size)
+		exp[0] |= 0x80 // full-length exponent
+		mod[0] |= 0x80
+		mod[size-1] |= 1
+		input = append(append(append(input, base...), exp...), mod...)
+		cases = append(cases, precompileBenchCase{name: fmt.Sprintf("%d-byte operands", size), input: input})
+	}
+	return cases
+}
+
+func bn256G1(rng *rand.Rand) []byte {
+	return new(bn256.G1).ScalarBaseMult(randomScalar(rng)).Marshal()
+}
+
+func bn256G2(rng *rand.Rand) []byte {
+	return new(bn256.G2).ScalarBaseMult(randomScalar(rng)).Marshal()
+}
+
+func bn256AddInputs(rng *rand.Rand) []precompileBenchCase {
+	return []precompileBenchCase{{name: "2 points", input: append(bn256G1(rng), bn256G1(rng)...)}}
+}
+
+func bn256ScalarMulInputs(rng *rand.Rand) []precompileBenchCase {
+	input := append(bn256G1(rng), common.LeftPadBytes(randomBytes(rng, 32), 32)...)
+	return []precompileBenchCase{{name: "point, scalar", input: input}}
+}
+
+func bn256PairingInputs(rng *rand.Rand) []precompileBenchCase {
+	var cases []precompileBenchCase
+	for _, pairs := range []int{1, 2, 4, 8} {
+		var input []byte
+		for i := 0; i < pairs; i++ {
+			input = append(append(input, bn256G1(rng)...), bn256G2(rng)...)
+		}
+		cases = append(cases, precompileBenchCase{name: fmt.Sprintf("%d pairs", pairs), input: input})
+	}
+	return cases
+}
+
+func blake2FInputs(rng *rand.Rand) []precompileBenchCase {
+	var cases []precompileBenchCase
+	for _, rounds := range []uint32{12, 1000, 10000} {
+		input := randomBytes(rng, 213)
+		binary.BigEndian.PutUint32(input, rounds)
+		input[212] = 1
+		cases = append(cases, precompileBenchCase{name: fmt.Sprintf("%d rounds", rounds), input: input})
+	}
+	return cases
+}
+
+func kzgPointEvaluationInputs(rng *rand.Rand) []precompileBenchCase {
+	var (
+		blob  kzg4844.Blob
+		point kzg4844.Point
+	)
+	// Field elements of the blob and the point must be canonical, keep the top
+	// byte of every element clear.
+	for i := 0; i < len(blob); i += 32 {
+		copy(blob[i+1:i+32], randomBytes(rng, 31))
+	}
+	copy(point[1:], randomBytes(rng, 31))
+	commitment, err := kzg4844.BlobToCommitment(&blob)
+	if err != nil {
+		panic(err)
+	}
+	proof, claim, err := kzg4844.ComputeProof(&blob, point)
+	if err != nil {
+		panic(err)
+	}
+	vhash := sha256.Sum256(commitment[:])
+	vhash[0] = 0x01 // blob commitment version
+	input := slices.Concat(vhash[:], point[:], claim[:], commitment[:], proof[:])
+	return []precompileBenchCase{{name: "valid proof", input: input}}
+}
+
+func blsG1(rng *rand.Rand) []byte {
+	_, _, g1, _ := bls12381.Generators()
+	var p bls12381.G1Affine
+	p.ScalarMultiplication(&g1, randomScalar(rng))
+	out := make([]byte, 128)
+	fp.BigEndian.PutElement((*[fp.Bytes]byte)(out[16:]), p.X)
+	fp.BigEndian.PutElement((*[fp.Bytes]byte)(out[64+16:]), p.Y)
+	return out
+}
+
+func blsG2(rng *rand.Rand) []byte {
+	_, _, _, g2 := bls12381.Generators()
+	var p bls12381.G2Affine
+	p.ScalarMultiplication(&g2, randomScalar(rng))
+	out := make([]byte, 256)
+	fp.BigEndian.PutElement((*[fp.Bytes]byte)(out[16:]), p.X.A0)
+	fp.BigEndian.PutElement((*[fp.Bytes]byte)(out[80:]), p.X.A1)
+	fp.BigEndian.PutElement((*[fp.Bytes]byte)(out[144:]), p.Y.A0)
+	fp.BigEndian.PutElement((*[fp.Bytes]byte)(out[208:]), p.Y.A1)
+	return out
+}
+
+func blsFieldElement(rng *rand.Rand) []byte {
+	var e fp.Element
+	e.SetBytes(randomBytes(rng, 47))
+	out := make([]byte, 64)
+	fp.BigEndian.PutElement((*[fp.Bytes]byte)(out[16:]), e)
+	return out
+}
+
+func blsG1AddInputs(rng *rand.Rand) []precompileBenchCase {
+	return []precompileBenchCase{{name: "2 points", input: append(blsG1(rng), blsG1(rng)...)}}
+}
+
+func blsG2AddInputs(rng *rand.Rand) []precompileBenchCase {
+	return []precompileBenchCase{{name: "2 points", input: append(blsG2(rng), blsG2(rng)...)}}
+}
+
+func blsMultiExpInputs(rng *rand.Rand, point func(*rand.Rand) []byte) []precompileBenchCase {
+	var cases []precompileBenchCase
+	for _, pairs := range []int{1, 2, 8, 32, 128} {
+		var input []byte
+		for i := 0; i < pairs; i++ {
+			input = append(append(input, point(rng)...), randomBytes(rng, 32)...)
+		}
+		cases = append(cases, precompileBenchCase{name: fmt.Sprintf("%d pairs", pairs), input: input})
+	}
+	return cases
+}
+
+func blsG1MultiExpInputs(rng *rand.Rand) []precompileBenchCase {
+	return blsMultiExpInputs(rng, blsG1)
+}
+
+func blsG2MultiExpInputs(rng *rand.Rand) []precompileBenchCase {
+	return blsMultiExpInputs(rng, blsG2)
+}
+
+func blsPairingInputs(rng *rand.Rand) []precompileBenchCase {
+	var cases []precompileBenchCase
+	for _, pairs := range []int{1, 2, 4, 8} {
+		var input []byte
+		for i := 0; i < pairs; i++ {
+			input = append(append(input, blsG1(rng)...), blsG2(rng)...)
+		}
+		cases = append(cases, precompileBenchCase{name: fmt.Sprintf("%d pairs", pairs), input: input})
+	}
+	return cases
+}
+
+func blsMapG1Inputs(rng *rand.Rand) []precompileBenchCase {
+	return []precompileBenchCase{{name: "field element", input: blsFieldElement(rng)}}
+}
+
+func blsMapG2Inputs(rng *rand.Rand) []precompileBenchCase {
+	return []precompileBenchCase{{name: "field element", input: append(blsFieldElement(rng), blsFieldElement(rng)...)}}
+}
+
+func p256VerifyInputs(rng *rand.Rand) []precompileBenchCase {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rng)
+	if err != nil {
+		panic(err)
+	}
+	hash := randomBytes(rng, 32)
+	r, s, err := ecdsa.Sign(rng, key, hash)
+	if err != nil {
+		panic(err)
+	}
+	input := slices.Concat(hash, common.LeftPadBytes(r.Bytes(), 32), common.LeftPadBytes(s.Bytes(), 32),
+		common.LeftPadBytes(key.X.Bytes(), 32), common.LeftPadBytes(key.Y.Bytes(), 32))
+	return []precompileBenchCase{{name: "valid", input: input, verifies: true}}
+}
+
+func pqVerifyInputs(algo pqcrypto.Algorithm) func(*rand.Rand) []precompileBenchCase {
+	return func(rng *rand.Rand) []precompileBenchCase {
+		key, err := pqcrypto.NewPQSignerFromSeed(algo, randomBytes(rng, pqcrypto.SeedSize(algo)))
+		if err != nil {
+			panic(err)
+		}
+		var cases []precompileBenchCase
+		for _, size := range []int{32, 1024} {
+			msg := randomBytes(rng, size)
+			sig, err := key.Sign(msg)
+			if err != nil {
+				panic(err)
+			}
+			input := slices.Concat(key.PublicKey(), msg, sig)
+			cases = append(cases, precompileBenchCase{name: fmt.Sprintf("%d-byte message", size), input: input, verifies: true})
+		}
+		return cases
+	}
+}
+
+func mlkemEncapInputs(algo pqcrypto.Algorithm) func(*rand.Rand) []precompileBenchCase {
+	return func(rng *rand.Rand) []precompileBenchCase {
+		key, err := pqcrypto.NewPQSignerFromSeed(algo, randomBytes(rng, pqcrypto.SeedSize(algo)))
+		if err != nil {
+			panic(err)
+		}
+		input := append(key.PublicKey(), randomBytes(rng, pqcrypto.EncapsulationSeedSize(algo))...)
+		return []precompileBenchCase{{name: "key, randomness", input: input}}
+	}
+}
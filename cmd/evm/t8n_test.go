@@ -296,6 +296,22 @@ func TestT8n(t *testing.T) {
 			output: t8nOutput{alloc: true, result: true},
 			expOut: "exp.json",
 		},
+		{ // Lux post-quantum fork, ML-DSA-44 precompile active
+			base: "./testdata/34",
+			input: t8nInput{
+				"alloc.json", "txs.json", "env.json", "OsakaPostQuantum", "",
+			},
+			output: t8nOutput{alloc: true, result: true},
+			expOut: "exp.json",
+		},
+		{ // Lux post-quantum fork scheduled, but not yet active
+			base: "./testdata/34",
+			input: t8nInput{
+				"alloc.json", "txs.json", "env.json", "OsakaToPostQuantumAtTime15k", "",
+			},
+			output: t8nOutput{alloc: true, result: true},
+			expOut: "exp_prefork.json",
+		},
 	} {
 		args := []string{"t8n"}
 		args = append(args, tc.output.get()...)
@@ -833,3 +849,42 @@ func TestEVMTracing(t *testing.T) {
 		}
 	}
 }
+
+func TestPrecompileBench(t *testing.T) {
+	t.Parallel()
+	tt := cmdtest.NewTestCmd(t, nil)
+	tt.Run("evm-test", "precompile-bench", "--run", "^(identity|mldsaVerify44)$", "--benchtime", "1ms", "--mgas", "100", "--json")
+	output := tt.Output()
+	tt.WaitExit()
+	if status := tt.ExitStatus(); status != 0 {
+		t.Fatalf("exit status %d, stderr: %s", status, tt.StderrText())
+	}
+	var out struct {
+		TargetMgas float64 `json:"targetMgas"`
+		Results    []struct {
+			Contract   string `json:"contract"`
+			Gas        uint64 `json:"gas"`
+			DerivedGas uint64 `json:"derivedGas"`
+			Error      string `json:"error"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(output, &out); err != nil {
+		t.Fatalf("invalid output: %v\n%s", err, output)
+	}
+	if out.TargetMgas != 100 {
+		t.Errorf("wrong target throughput %v", out.TargetMgas)
+	}
+	count := make(map[string]int)
+	for _, r := range out.Results {
+		count[r.Contract]++
+		if r.Error != "" {
+			t.Errorf("%s: %s", r.Contract, r.Error)
+		}
+		if r.Gas == 0 || r.DerivedGas == 0 {
+			t.Errorf("%s: missing gas: %d, derived %d", r.Contract, r.Gas, r.DerivedGas)
+		}
+	}
+	if count["identity"] != 4 || count["mldsaVerify44"] != 2 || len(count) != 2 {
+		t.Errorf("wrong results: %v", count)
+	}
+}
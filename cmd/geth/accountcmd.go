@@ -31,6 +31,12 @@ import (
 )
 
 var (
+	accountAlgorithmFlag = &cli.StringFlag{
+		Name:  "algorithm",
+		Usage: "Signature algorithm of the new account (secp256k1, mldsa44, mldsa65, mldsa87, slhdsa128s, slhdsa192s, slhdsa256s, ...)",
+		Value: "secp256k1",
+	}
+
 	walletCommand = &cli.Command{
 		Name:      "wallet",
 		Usage:     "Manage Ethereum presale wallets",
@@ -108,6 +114,7 @@ Print a short summary of all accounts`,
 					utils.KeyStoreDirFlag,
 					utils.PasswordFileFlag,
 					utils.LightKDFFlag,
+					accountAlgorithmFlag,
 				},
 				Description: `
     geth account new
@@ -116,6 +123,10 @@ Creates a new account and prints the address.
 
 The account is saved in encrypted format, you are prompted for a password.
 
+Use --algorithm to create a post-quantum account instead of a secp256k1 one,
+e.g. --algorithm mldsa65. Its address is derived from the public key, which is
+stored next to the encrypted key.
+
 You must remember this password to unlock your account in the future.
 
 For non-interactive use the password can be specified with the --password flag:
@@ -210,10 +221,20 @@ func makeAccountManager(ctx *cli.Context) *accounts.Manager {
 
 func accountList(ctx *cli.Context) error {
 	am := makeAccountManager(ctx)
+	var ks *keystore.KeyStore
+	if backends := am.Backends(keystore.KeyStoreType); len(backends) > 0 {
+		ks = backends[0].(*keystore.KeyStore)
+	}
 	var index int
 	for _, wallet := range am.Wallets() {
 		for _, account := range wallet.Accounts() {
-			fmt.Printf("Account #%d: {%x} %s\n", index, account.Address, &account.URL)
+			fmt.Printf("Account #%d: {%x} %s", index, account.Address, &account.URL)
+			if ks != nil && account.URL.Scheme == keystore.KeyStoreScheme {
+				if alg, err := ks.Algorithm(account); err == nil && alg != keystore.SignatureECDSA {
+					fmt.Printf(" (%s)", keystore.GetAlgorithmName(alg))
+				}
+			}
+			fmt.Println()
 			index++
 		}
 	}
@@ -256,12 +277,20 @@ func accountCreate(ctx *cli.Context) error {
 		scryptP = keystore.LightScryptP
 	}
 
+	algorithm, err := keystore.ParseSignatureAlgorithm(ctx.String(accountAlgorithmFlag.Name))
+	if err != nil {
+		utils.Fatalf("Invalid account algorithm: %v", err)
+	}
 	password, ok := readPasswordFromFile(ctx.Path(utils.PasswordFileFlag.Name))
 	if !ok {
 		password = utils.GetPassPhrase("Your new account is locked with a password. Please give a password. Do not forget this password.", true)
 	}
-	account, err := keystore.StoreKey(keydir, password, scryptN, scryptP)
-
+	var account accounts.Account
+	if algorithm == keystore.SignatureECDSA {
+		account, err = keystore.StoreKey(keydir, password, scryptN, scryptP)
+	} else {
+		account, err = keystore.StorePostQuantumKey(keydir, password, algorithm, scryptN, scryptP)
+	}
 	if err != nil {
 		utils.Fatalf("Failed to create account: %v", err)
 	}
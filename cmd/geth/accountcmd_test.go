@@ -97,6 +97,30 @@ Path of the secret key file: .*UTC--.+--[0-9a-f]{40}
 `)
 }
 
+func TestAccountNewPostQuantum(t *testing.T) {
+	t.Parallel()
+	datadir := t.TempDir()
+	{
+		geth := runGeth(t, "account", "new", "--lightkdf", "--datadir", datadir, "--algorithm", "mldsa44")
+		geth.Expect(`
+Your new account is locked with a password. Please give a password. Do not forget this password.
+!! Unsupported terminal, password will be echoed.
+Password: {{.InputLine "foobar"}}
+Repeat password: {{.InputLine "foobar"}}
+
+Your new key was generated
+`)
+		geth.ExpectRegexp(`
+Public address of the key:   0x[0-9a-fA-F]{40}
+`)
+		geth.WaitExit()
+	}
+	geth := runGeth(t, "account", "list", "--datadir", datadir)
+	geth.ExpectRegexp(`Account #0: \{[0-9a-f]{40}\} keystore://.+ \(ML-DSA-44 \(NIST Level 2\)\)
+`)
+	geth.ExpectExit()
+}
+
 func TestAccountImport(t *testing.T) {
 	t.Parallel()
 	tests := []struct{ name, key, output string }{
@@ -60,6 +60,7 @@ var (
 			utils.CachePreimagesFlag,
 			utils.OverrideOsaka,
 			utils.OverrideVerkle,
+			utils.OverridePostQuantum,
 		}, utils.DatabaseFlags),
 		Description: `
 The init command initializes a new genesis block and definition for the network.
@@ -277,6 +278,10 @@ func initGenesis(ctx *cli.Context) error {
 		v := ctx.Uint64(utils.OverrideVerkle.Name)
 		overrides.OverrideVerkle = &v
 	}
+	if ctx.IsSet(utils.OverridePostQuantum.Name) {
+		v := ctx.Uint64(utils.OverridePostQuantum.Name)
+		overrides.OverridePostQuantum = &v
+	}
 
 	chaindb := utils.MakeChainDatabase(ctx, stack, false)
 	defer chaindb.Close()
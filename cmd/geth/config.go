@@ -231,6 +231,10 @@ func makeFullNode(ctx *cli.Context) *node.Node {
 		v := ctx.Uint64(utils.OverrideVerkle.Name)
 		cfg.Eth.OverrideVerkle = &v
 	}
+	if ctx.IsSet(utils.OverridePostQuantum.Name) {
+		v := ctx.Uint64(utils.OverridePostQuantum.Name)
+		cfg.Eth.OverridePostQuantum = &v
+	}
 
 	// Start metrics export if enabled
 	utils.SetupMetrics(&cfg.Metrics)
@@ -0,0 +1,175 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/luxfi/geth/cmd/utils"
+	"github.com/luxfi/geth/common"
+	"github.com/luxfi/geth/core/rawdb"
+	"github.com/luxfi/geth/ethdb"
+	"github.com/luxfi/geth/ethdb/encdb"
+	"github.com/luxfi/geth/log"
+	"github.com/luxfi/geth/node"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	backupAttachFlag = &cli.StringFlag{
+		Name:  "attach",
+		Usage: "Endpoint of a running node to create the backup through admin_backupDatabase",
+	}
+	dbBackupCmd = &cli.Command{
+		Action:    dbBackup,
+		Name:      "backup",
+		Usage:     "Create a consistent backup of the chain database",
+		ArgsUsage: "<backup directory>",
+		Flags:     slices.Concat([]cli.Flag{backupAttachFlag}, utils.NetworkFlags, utils.DatabaseFlags),
+		Description: `This command creates a checkpoint of the key-value store and a copy of the
+freezer in the given directory, which must not exist yet.
+
+With --attach, the backup is created by the running node behind the given
+endpoint without stopping it, the directory is then resolved on the host of
+that node. Otherwise the database of the local datadir is backed up, which
+requires the node to be stopped.`,
+	}
+	dbRestoreCmd = &cli.Command{
+		Action:    dbRestore,
+		Name:      "restore",
+		Usage:     "Validate a database backup and restore it into the datadir",
+		ArgsUsage: "<backup directory>",
+		Flags:     slices.Concat(utils.NetworkFlags, utils.DatabaseFlags),
+		Description: `This command checks that the given backup created by 'geth db backup' or
+admin_backupDatabase is complete and copies it into the datadir. The datadir
+must not contain a chain database yet.`,
+	}
+)
+
+func dbBackup(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
+	}
+	dir := ctx.Args().First()
+	if endpoint := ctx.String(backupAttachFlag.Name); endpoint != "" {
+		if abs, err := filepath.Abs(dir); err == nil {
+			dir = abs
+		}
+		client, err := utils.DialRPCWithHeaders(endpoint, ctx.StringSlice(utils.HttpHeaderFlag.Name))
+		if err != nil {
+			return fmt.Errorf("failed to attach to %s: %v", endpoint, err)
+		}
+		defer client.Close()
+
+		var manifest rawdb.BackupManifest
+		if err := client.Call(&manifest, "admin_backupDatabase", dir); err != nil {
+			return err
+		}
+		log.Info("Database backup created", "dir", dir, "engine", manifest.Engine, "head", manifest.HeadNumber, "ancients", manifest.Ancients)
+		return nil
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, false)
+	defer db.Close()
+
+	manifest, err := rawdb.Backup(db, dir)
+	if err != nil {
+		return err
+	}
+	log.Info("Database backup created", "dir", dir, "engine", manifest.Engine, "head", manifest.HeadNumber, "ancients", manifest.Ancients)
+	return nil
+}
+
+func dbRestore(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
+	}
+	dir := ctx.Args().First()
+	manifest, err := rawdb.ReadBackupManifest(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read backup manifest: %v", err)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	var (
+		chaindata = stack.ResolvePath("chaindata")
+		ancient   = stack.ResolveAncient("chaindata", ctx.String(utils.AncientFlag.Name))
+	)
+	if common.FileExist(chaindata) {
+		return fmt.Errorf("%s already exists, remove it first", chaindata)
+	}
+	if manifest.HasAncient && common.FileExist(ancient) {
+		return fmt.Errorf("%s already exists, remove it first", ancient)
+	}
+	if err := verifyBackup(dir, manifest, stack.Config().DBEncryptionKey); err != nil {
+		return fmt.Errorf("invalid backup: %v", err)
+	}
+	log.Info("Restoring key-value store", "engine", manifest.Engine, "dir", chaindata)
+	if err := os.CopyFS(chaindata, os.DirFS(filepath.Join(dir, rawdb.BackupKeyValueDir))); err != nil {
+		return err
+	}
+	if manifest.HasAncient {
+		log.Info("Restoring freezer", "dir", ancient)
+		if err := os.CopyFS(ancient, os.DirFS(filepath.Join(dir, rawdb.BackupAncientDir))); err != nil {
+			return err
+		}
+	}
+	log.Info("Database backup restored", "head", manifest.HeadNumber, "hash", manifest.HeadHash)
+	return nil
+}
+
+// verifyBackup opens the backup in dir read-only and checks that it contains
+// the chain described by the manifest. The key is needed for backups of
+// encrypted databases.
+func verifyBackup(dir string, manifest *rawdb.BackupManifest, key []byte) error {
+	kvdb, err := node.OpenKeyValueDatabase(filepath.Join(dir, rawdb.BackupKeyValueDir), manifest.Engine, node.DatabaseOptions{ReadOnly: true, EncryptionKey: key})
+	if err != nil {
+		return fmt.Errorf("failed to open key-value store: %v", err)
+	}
+	var db ethdb.Database
+	if manifest.HasAncient {
+		opts := rawdb.OpenOptions{Ancient: filepath.Join(dir, rawdb.BackupAncientDir), ReadOnly: true}
+		if key != nil {
+			if opts.Cipher, err = encdb.FreezerCipher(key); err != nil {
+				kvdb.Close()
+				return err
+			}
+		}
+		db, err = rawdb.Open(kvdb, opts)
+		if err != nil {
+			kvdb.Close()
+			return fmt.Errorf("failed to open freezer: %v", err)
+		}
+	} else {
+		db = rawdb.NewDatabase(kvdb)
+	}
+	defer db.Close()
+
+	if genesis := rawdb.ReadCanonicalHash(db, 0); genesis != manifest.Genesis {
+		return fmt.Errorf("genesis mismatch: have %x, want %x", genesis, manifest.Genesis)
+	}
+	if head := rawdb.ReadHeadBlockHash(db); head != manifest.HeadHash {
+		return fmt.Errorf("head block mismatch: have %x, want %x", head, manifest.HeadHash)
+	}
+	if rawdb.ReadHeader(db, manifest.HeadHash, manifest.HeadNumber) == nil {
+		return errors.New("head header missing")
+	}
+	if manifest.HasAncient {
+		ancients, err := db.Ancients()
+		if err != nil {
+			return err
+		}
+		if ancients < manifest.Ancients {
+			return fmt.Errorf("freezer incomplete: have %d items, want at least %d", ancients, manifest.Ancients)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Tests that a backup restored into an empty datadir holds the same chain as
+// the original one.
+func TestDBBackupRestore(t *testing.T) {
+	t.Parallel()
+	var (
+		datadir = initGeth(t)
+		backup  = filepath.Join(t.TempDir(), "backup")
+		clone   = t.TempDir()
+	)
+	run := func(args ...string) {
+		t.Helper()
+		geth := runGeth(t, args...)
+		geth.WaitExit()
+		if status := geth.ExitStatus(); status != 0 {
+			t.Fatalf("%v failed with status %d", args, status)
+		}
+	}
+	export := func(datadir string) []byte {
+		t.Helper()
+		file := filepath.Join(t.TempDir(), "export")
+		run("--datadir", datadir, "export", file)
+		blob, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return blob
+	}
+	run("--datadir", datadir, "db", "backup", backup)
+	run("--datadir", clone, "db", "restore", backup)
+
+	if !bytes.Equal(export(clone), export(datadir)) {
+		t.Fatal("restored chain differs from the original")
+	}
+	// Restoring over an existing database must be refused
+	geth := runGeth(t, "--datadir", clone, "db", "restore", backup)
+	geth.WaitExit()
+	if status := geth.ExitStatus(); status == 0 {
+		t.Fatal("restore over existing database succeeded")
+	}
+	if !strings.Contains(geth.StderrText(), "already exists, remove it first") {
+		t.Fatalf("unexpected error: %s", geth.StderrText())
+	}
+}
@@ -83,6 +83,13 @@ Remove blockchain and state databases`,
 			dbMetadataCmd,
 			dbCheckStateContentCmd,
 			dbInspectHistoryCmd,
+			dbConvertCmd,
+			dbBackupCmd,
+			dbRestoreCmd,
+			dbRekeyCmd,
+			dbRecompressCmd,
+			dbServeCmd,
+			dbVerifyAncientsCmd,
 		},
 	}
 	dbInspectCmd = &cli.Command{
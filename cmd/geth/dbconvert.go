@@ -0,0 +1,451 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/luxfi/geth/cmd/utils"
+	"github.com/luxfi/geth/common"
+	"github.com/luxfi/geth/common/hexutil"
+	"github.com/luxfi/geth/core/rawdb"
+	"github.com/luxfi/geth/ethdb"
+	"github.com/luxfi/geth/ethdb/encdb"
+	"github.com/luxfi/geth/log"
+	"github.com/luxfi/geth/node"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	convertEngineFlag = &cli.StringFlag{
+		Name:     "to",
+		Usage:    "Database engine to convert to ('pebble', 'leveldb' or 'badger')",
+		Required: true,
+	}
+	convertVerifyFlag = &cli.BoolFlag{
+		Name:  "verify",
+		Usage: "Compare the converted database with the original before replacing it",
+		Value: true,
+	}
+	dbConvertCmd = &cli.Command{
+		Action: dbConvert,
+		Name:   "convert",
+		Usage:  "Convert the key-value database to another engine",
+		Flags: slices.Concat([]cli.Flag{
+			convertEngineFlag,
+			convertVerifyFlag,
+			utils.CacheFlag,
+			utils.CacheDatabaseFlag,
+		}, utils.NetworkFlags, utils.DatabaseFlags),
+		Description: `This command copies every key-value pair of the chain database into a fresh
+database of the engine given by --to, which is created next to the original in
+<chaindata>.<engine>. Once the copy is complete and verified, the original files
+are moved to <chaindata>.<old engine> and the converted ones take their place.
+The freezer and any other directory inside the chain database are left untouched.
+
+The conversion can be interrupted at any time and is resumed from the last
+checkpoint when the command is run again. The progress is tracked in
+<chaindata>.<engine>.progress. This includes the final swap of the databases:
+if it is interrupted, the next run of the command completes it before doing
+anything else.`,
+	}
+)
+
+// convertCheckpointInterval is the interval at which the converted database is
+// synced to disk and the progress is saved.
+const convertCheckpointInterval = 8 * time.Second
+
+var errConvertInterrupted = errors.New("conversion interrupted")
+
+// convertProgress is the resumption state of a database conversion.
+type convertProgress struct {
+	Source string        `json:"source"` // Engine of the original database
+	Target string        `json:"target"` // Engine of the converted database
+	Marker hexutil.Bytes `json:"marker"` // Last key copied and synced to disk, nil if none
+	Keys   uint64        `json:"keys"`   // Number of keys copied up to the marker
+	Size   uint64        `json:"size"`   // Size of the keys and values copied up to the marker
+	Copied bool          `json:"copied"` // Whether all keys have been copied
+	Swap   string        `json:"swap"`   // Step of the database swap in progress, empty if not started
+}
+
+// The steps of the database swap. The converted database is complete and
+// verified once the swap starts, so an interrupted swap is always completed.
+const (
+	swapBackup  = "backup"  // moving the original files to the backup directory
+	swapInstall = "install" // moving the converted files into chaindata
+)
+
+func loadConvertProgress(file string) (*convertProgress, error) {
+	blob, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var progress convertProgress
+	if err := json.Unmarshal(blob, &progress); err != nil {
+		return nil, fmt.Errorf("invalid progress file %s: %v", file, err)
+	}
+	return &progress, nil
+}
+
+// save atomically replaces the progress file.
+func (p *convertProgress) save(file string) error {
+	blob, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(file+".tmp", blob, 0600); err != nil {
+		return err
+	}
+	return os.Rename(file+".tmp", file)
+}
+
+func dbConvert(ctx *cli.Context) error {
+	engine := ctx.String(convertEngineFlag.Name)
+	if engine != rawdb.DBLeveldb && engine != rawdb.DBPebble && engine != rawdb.DBBadger {
+		return fmt.Errorf("unknown database engine %q", engine)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	var (
+		chaindata    = stack.ResolvePath("chaindata")
+		target       = chaindata + "." + engine
+		progressFile = target + ".progress"
+	)
+	// Complete an interrupted swap first, the chain database holds a mix of
+	// both databases until then.
+	if progress, err := loadConvertProgress(progressFile); err == nil && progress.Swap != "" {
+		log.Warn("Completing interrupted database swap", "source", progress.Source, "target", progress.Target, "step", progress.Swap)
+		return finishConversion(chaindata, target, progressFile, progress)
+	}
+	var (
+		source = rawdb.PreexistingDatabase(chaindata)
+		backup = chaindata + "." + source
+	)
+	if source == "" {
+		return fmt.Errorf("no database found in %s", chaindata)
+	}
+	if source == engine {
+		return fmt.Errorf("database in %s is already a %s database", chaindata, engine)
+	}
+	if _, err := os.Stat(backup); err == nil {
+		return fmt.Errorf("%s already exists, move it out of the way first", backup)
+	}
+	progress, err := loadConvertProgress(progressFile)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		if _, err := os.Stat(target); err == nil {
+			return fmt.Errorf("%s already exists without conversion progress, remove it to start over", target)
+		}
+		progress = &convertProgress{Source: source, Target: engine}
+		if err := progress.save(progressFile); err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	case progress.Source != source || progress.Target != engine:
+		return fmt.Errorf("%s tracks a conversion from %s to %s, remove it and %s to start over", progressFile, progress.Source, progress.Target, target)
+	default:
+		log.Info("Resuming database conversion", "keys", progress.Keys, "size", common.StorageSize(progress.Size), "marker", progress.Marker)
+	}
+	// The cache allowance is split between the two databases
+	opts := node.DatabaseOptions{
+		Cache:         ctx.Int(utils.CacheFlag.Name) * ctx.Int(utils.CacheDatabaseFlag.Name) / 100 / 2,
+		Handles:       utils.MakeDatabaseHandles(ctx.Int(utils.FDLimitFlag.Name)) / 2,
+		EncryptionKey: stack.Config().DBEncryptionKey,
+	}
+	srcOpts := opts
+	srcOpts.ReadOnly = true
+	src, err := node.OpenKeyValueDatabase(chaindata, source, srcOpts)
+	if err != nil {
+		return fmt.Errorf("failed to open %s database: %v", source, err)
+	}
+	defer src.Close()
+
+	// An encrypted database is converted with the same key and key mode
+	if enc, ok := src.(*encdb.Database); ok {
+		opts.EncryptKeys = enc.EncryptsKeys()
+	}
+
+	dst, err := node.OpenKeyValueDatabase(target, engine, opts)
+	if err != nil {
+		return fmt.Errorf("failed to open %s database: %v", engine, err)
+	}
+	defer dst.Close()
+
+	stop, release := stopOnInterrupt("Interrupted during database conversion, stopping at next checkpoint")
+	defer release()
+
+	if !progress.Copied {
+		err := convertKeyValues(src, dst, progress, func() error { return progress.save(progressFile) }, stop)
+		if errors.Is(err, errConvertInterrupted) {
+			log.Info("Database conversion paused, run the command again to resume", "keys", progress.Keys, "size", common.StorageSize(progress.Size))
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+	if ctx.Bool(convertVerifyFlag.Name) {
+		mismatches, err := verifyConversion(src, dst, progress.Keys, stop)
+		if errors.Is(err, errConvertInterrupted) {
+			log.Info("Verification interrupted, run the command again to restart it")
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if len(mismatches) > 0 {
+			for _, m := range mismatches {
+				log.Error("Converted database mismatch", "err", m)
+			}
+			return fmt.Errorf("converted database differs from the original in %d places, remove %s and %s to start over", len(mismatches), target, progressFile)
+		}
+	}
+	src.Close()
+	dst.Close()
+
+	return finishConversion(chaindata, target, progressFile, progress)
+}
+
+// finishConversion replaces the original database with the converted one and
+// removes the progress file.
+func finishConversion(chaindata, target, progressFile string, progress *convertProgress) error {
+	backup := chaindata + "." + progress.Source
+	if err := swapDatabase(chaindata, target, backup, progress, func() error { return progress.save(progressFile) }); err != nil {
+		return fmt.Errorf("failed to replace the original database: %v", err)
+	}
+	if err := os.Remove(progressFile); err != nil {
+		return err
+	}
+	log.Info("Database converted", "engine", progress.Target, "keys", progress.Keys, "size", common.StorageSize(progress.Size), "original", backup)
+	return nil
+}
+
+// stopOnInterrupt returns a channel that is closed when the process receives
+// an interrupt, logging msg, or when release is called.
+func stopOnInterrupt(msg string) (stop chan struct{}, release func()) {
+	interrupt := make(chan os.Signal, 1)
+	stop = make(chan struct{})
+	signal.Notify(interrupt, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		if _, ok := <-interrupt; ok {
+			log.Info(msg)
+		}
+		close(stop)
+	}()
+	return stop, func() {
+		signal.Stop(interrupt)
+		close(interrupt)
+	}
+}
+
+// convertKeyValues copies the key-value pairs of src after the progress marker
+// into dst. At every checkpoint dst is synced to disk and the progress is saved,
+// so the copy can be resumed from there after an interruption or a crash.
+func convertKeyValues(src ethdb.KeyValueStore, dst ethdb.KeyValueStore, progress *convertProgress, save func() error, stop chan struct{}) error {
+	var start []byte
+	if progress.Marker != nil {
+		start = append(common.CopyBytes(progress.Marker), 0)
+	}
+	it := src.NewIterator(nil, start)
+	defer it.Release()
+
+	var (
+		batch      = dst.NewBatch()
+		keys       = progress.Keys
+		size       = progress.Size
+		startTime  = time.Now()
+		checkpoint = time.Now()
+		startKeys  = progress.Keys
+	)
+	commit := func(last []byte) error {
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		batch.Reset()
+		if err := dst.SyncKeyValue(); err != nil {
+			return err
+		}
+		progress.Marker = common.CopyBytes(last)
+		progress.Keys, progress.Size = keys, size
+		return save()
+	}
+	for it.Next() {
+		key, value := it.Key(), it.Value()
+		if err := batch.Put(key, value); err != nil {
+			return err
+		}
+		keys++
+		size += uint64(len(key) + len(value))
+
+		if batch.ValueSize() < ethdb.IdealBatchSize {
+			continue
+		}
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		batch.Reset()
+
+		select {
+		case <-stop:
+			if err := commit(key); err != nil {
+				return err
+			}
+			return errConvertInterrupted
+		default:
+		}
+		if time.Since(checkpoint) > convertCheckpointInterval {
+			if err := commit(key); err != nil {
+				return err
+			}
+			elapsed := time.Since(startTime)
+			log.Info("Converting database", "keys", keys, "size", common.StorageSize(size), "at", fmt.Sprintf("%#x", key),
+				"rate", fmt.Sprintf("%.0f keys/s", float64(keys-startKeys)/elapsed.Seconds()), "elapsed", common.PrettyDuration(elapsed))
+			checkpoint = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	if err := dst.SyncKeyValue(); err != nil {
+		return err
+	}
+	progress.Keys, progress.Size, progress.Copied = keys, size, true
+	if err := save(); err != nil {
+		return err
+	}
+	log.Info("Copied database content", "keys", keys, "size", common.StorageSize(size), "elapsed", common.PrettyDuration(time.Since(startTime)))
+	return nil
+}
+
+// maxConvertMismatches is the number of differences after which verification
+// gives up.
+const maxConvertMismatches = 16
+
+// verifyConversion walks both databases in lockstep and reports the keys that
+// are missing from either one or hold different values. The number of keys is
+// also checked against the number of keys copied.
+func verifyConversion(src ethdb.KeyValueStore, dst ethdb.KeyValueStore, expected uint64, stop chan struct{}) ([]string, error) {
+	srcIt, dstIt := src.NewIterator(nil, nil), dst.NewIterator(nil, nil)
+	defer srcIt.Release()
+	defer dstIt.Release()
+
+	var (
+		mismatches []string
+		keys       uint64
+		start      = time.Now()
+		logged     = time.Now()
+
+		srcOk, dstOk = srcIt.Next(), dstIt.Next()
+	)
+	for (srcOk || dstOk) && len(mismatches) < maxConvertMismatches {
+		var cmp int
+		switch {
+		case !dstOk:
+			cmp = -1
+		case !srcOk:
+			cmp = 1
+		default:
+			cmp = bytes.Compare(srcIt.Key(), dstIt.Key())
+		}
+		switch {
+		case cmp < 0:
+			mismatches = append(mismatches, fmt.Sprintf("key %#x missing from converted database", srcIt.Key()))
+			srcOk = srcIt.Next()
+		case cmp > 0:
+			mismatches = append(mismatches, fmt.Sprintf("key %#x missing from original database", dstIt.Key()))
+			dstOk = dstIt.Next()
+		default:
+			if !bytes.Equal(srcIt.Value(), dstIt.Value()) {
+				mismatches = append(mismatches, fmt.Sprintf("value mismatch for key %#x", srcIt.Key()))
+			}
+			keys++
+			if keys%100000 == 0 {
+				select {
+				case <-stop:
+					return nil, errConvertInterrupted
+				default:
+				}
+				if time.Since(logged) > convertCheckpointInterval {
+					log.Info("Verifying converted database", "keys", keys, "expected", expected, "at", fmt.Sprintf("%#x", srcIt.Key()),
+						"elapsed", common.PrettyDuration(time.Since(start)))
+					logged = time.Now()
+				}
+			}
+			srcOk, dstOk = srcIt.Next(), dstIt.Next()
+		}
+	}
+	if err := srcIt.Error(); err != nil {
+		return nil, err
+	}
+	if err := dstIt.Error(); err != nil {
+		return nil, err
+	}
+	if len(mismatches) == 0 && keys != expected {
+		mismatches = append(mismatches, fmt.Sprintf("found %d keys, copied %d", keys, expected))
+	}
+	log.Info("Verified converted database", "keys", keys, "mismatches", len(mismatches), "elapsed", common.PrettyDuration(time.Since(start)))
+	return mismatches, nil
+}
+
+// swapDatabase moves the files of the original database in chaindata to backup
+// and replaces them with the converted database. Directories inside chaindata,
+// such as the freezer, stay where they are.
+//
+// The current step is saved in the progress before the files of each step are
+// moved, so that an interrupted swap can be resumed by calling swapDatabase
+// again with the saved progress.
+func swapDatabase(chaindata, converted, backup string, progress *convertProgress, save func() error) error {
+	if progress.Swap == "" {
+		if _, err := os.Stat(backup); err == nil {
+			return fmt.Errorf("%s already exists", backup)
+		}
+		progress.Swap = swapBackup
+		if err := save(); err != nil {
+			return err
+		}
+	}
+	if progress.Swap == swapBackup {
+		if err := os.MkdirAll(backup, 0755); err != nil {
+			return err
+		}
+		entries, err := os.ReadDir(chaindata)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			if err := os.Rename(filepath.Join(chaindata, entry.Name()), filepath.Join(backup, entry.Name())); err != nil {
+				return err
+			}
+		}
+		progress.Swap = swapInstall
+		if err := save(); err != nil {
+			return err
+		}
+	}
+	entries, err := os.ReadDir(converted)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := os.Rename(filepath.Join(converted, entry.Name()), filepath.Join(chaindata, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return os.Remove(converted)
+}
@@ -0,0 +1,158 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/luxfi/geth/core/rawdb"
+	"github.com/luxfi/geth/ethdb/memorydb"
+)
+
+// Tests that an interrupted conversion resumes from its last checkpoint and
+// that verification catches differences between the databases.
+func TestConvertKeyValuesResume(t *testing.T) {
+	src := memorydb.New()
+	for i := 0; i < 5000; i++ {
+		src.Put([]byte(fmt.Sprintf("key-%05d", i)), bytes.Repeat([]byte{byte(i)}, 100))
+	}
+	var (
+		dst      = memorydb.New()
+		progress = new(convertProgress)
+		saves    int
+		save     = func() error { saves++; return nil }
+		stop     = make(chan struct{})
+	)
+	close(stop)
+	if err := convertKeyValues(src, dst, progress, save, stop); err != errConvertInterrupted {
+		t.Fatalf("expected interruption, got %v", err)
+	}
+	if progress.Copied || progress.Keys == 0 || progress.Keys >= 5000 || saves != 1 {
+		t.Fatalf("wrong progress after interruption: %+v, %d saves", progress, saves)
+	}
+	if n := dst.Len(); uint64(n) != progress.Keys {
+		t.Fatalf("wrong number of keys copied: have %d, want %d", n, progress.Keys)
+	}
+	if err := convertKeyValues(src, dst, progress, save, make(chan struct{})); err != nil {
+		t.Fatal(err)
+	}
+	if !progress.Copied || progress.Keys != 5000 || progress.Size != 5000*109 {
+		t.Fatalf("wrong progress after completion: %+v", progress)
+	}
+	if mismatches, err := verifyConversion(src, dst, progress.Keys, nil); err != nil || len(mismatches) != 0 {
+		t.Fatalf("unexpected verification failure: %v, %v", mismatches, err)
+	}
+	dst.Put([]byte("key-00010"), nil)
+	dst.Delete([]byte("key-00020"))
+	dst.Put([]byte("key-99999"), nil)
+	mismatches, err := verifyConversion(src, dst, progress.Keys, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mismatches) != 3 {
+		t.Fatalf("wrong mismatches: %v", mismatches)
+	}
+}
+
+// TestDBConvert converts the database of an initialized node to badger and
+// checks that the chain can still be exported.
+func TestDBConvert(t *testing.T) {
+	t.Parallel()
+	datadir := initGeth(t)
+	export := func(file string) []byte {
+		geth := runGeth(t, "--datadir", datadir, "export", file)
+		geth.WaitExit()
+		if status := geth.ExitStatus(); status != 0 {
+			t.Fatalf("export failed with status %d", status)
+		}
+		blob, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return blob
+	}
+	want := export(filepath.Join(t.TempDir(), "before"))
+
+	geth := runGeth(t, "--datadir", datadir, "db", "convert", "--to", "badger")
+	geth.WaitExit()
+	if status := geth.ExitStatus(); status != 0 {
+		t.Fatalf("conversion failed with status %d", status)
+	}
+	chaindata := filepath.Join(datadir, "geth", "chaindata")
+	if engine := rawdb.PreexistingDatabase(chaindata); engine != rawdb.DBBadger {
+		t.Fatalf("wrong database engine after conversion: %q", engine)
+	}
+	if engine := rawdb.PreexistingDatabase(chaindata + ".pebble"); engine != rawdb.DBPebble {
+		t.Fatalf("original database not preserved: %q", engine)
+	}
+	if _, err := os.Stat(filepath.Join(chaindata, "ancient")); err != nil {
+		t.Fatalf("freezer moved: %v", err)
+	}
+	if have := export(filepath.Join(t.TempDir(), "after")); !bytes.Equal(have, want) {
+		t.Fatal("exported chain differs after conversion")
+	}
+}
+
+// Tests that a database swap interrupted at any step is completed when it's
+// resumed with the saved progress.
+func TestSwapDatabaseResume(t *testing.T) {
+	for _, step := range []string{swapBackup, swapInstall} {
+		t.Run(step, func(t *testing.T) {
+			var (
+				dir       = t.TempDir()
+				chaindata = filepath.Join(dir, "chaindata")
+				converted = chaindata + ".badger"
+				backup    = chaindata + ".pebble"
+			)
+			write := func(path, content string) {
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			write(filepath.Join(chaindata, "ancient", "chain", "FLOCK"), "")
+			write(filepath.Join(backup, "000001.log"), "old")
+			write(filepath.Join(chaindata, "MANIFEST"), "old")
+			if step == swapBackup {
+				write(filepath.Join(converted, "000001.vlog"), "new")
+				write(filepath.Join(converted, "MANIFEST"), "new")
+			} else {
+				write(filepath.Join(backup, "MANIFEST"), "old")
+				os.Remove(filepath.Join(chaindata, "MANIFEST"))
+				write(filepath.Join(chaindata, "000001.vlog"), "new")
+				write(filepath.Join(converted, "MANIFEST"), "new")
+			}
+			var (
+				progress = &convertProgress{Source: rawdb.DBPebble, Target: rawdb.DBBadger, Copied: true, Swap: step}
+				steps    []string
+				save     = func() error { steps = append(steps, progress.Swap); return nil }
+			)
+			if err := swapDatabase(chaindata, converted, backup, progress, save); err != nil {
+				t.Fatal(err)
+			}
+			check := func(path, want string) {
+				if have, err := os.ReadFile(path); err != nil || string(have) != want {
+					t.Errorf("%s: have %q, want %q (%v)", path, have, want, err)
+				}
+			}
+			check(filepath.Join(chaindata, "MANIFEST"), "new")
+			check(filepath.Join(chaindata, "000001.vlog"), "new")
+			check(filepath.Join(backup, "MANIFEST"), "old")
+			check(filepath.Join(backup, "000001.log"), "old")
+			check(filepath.Join(chaindata, "ancient", "chain", "FLOCK"), "")
+			if _, err := os.Stat(converted); !os.IsNotExist(err) {
+				t.Errorf("converted database not removed: %v", err)
+			}
+			if step == swapBackup && (len(steps) != 1 || steps[0] != swapInstall) {
+				t.Errorf("wrong saved steps %v", steps)
+			}
+		})
+	}
+}
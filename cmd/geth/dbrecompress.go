@@ -0,0 +1,102 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"crypto/cipher"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/luxfi/geth/cmd/utils"
+	"github.com/luxfi/geth/common"
+	"github.com/luxfi/geth/core/rawdb"
+	"github.com/luxfi/geth/ethdb/encdb"
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	recompressFreezerFlag = &cli.StringFlag{
+		Name:  "freezer",
+		Usage: "Freezer to recompress ('chain', 'state' or 'state_verkle')",
+		Value: rawdb.ChainFreezerName,
+	}
+	recompressTablesFlag = &cli.StringSliceFlag{
+		Name:  "tables",
+		Usage: "Tables to recompress, all compressed tables of the freezer if unset",
+	}
+	recompressDryRunFlag = &cli.BoolFlag{
+		Name:  "dry-run",
+		Usage: "Only estimate the space saved, without modifying the freezer",
+	}
+	dbRecompressCmd = &cli.Command{
+		Action: dbRecompress,
+		Name:   "recompress",
+		Usage:  "Recompress freezer tables with zstd and trained dictionaries",
+		Flags: slices.Concat([]cli.Flag{
+			recompressFreezerFlag,
+			recompressTablesFlag,
+			recompressDryRunFlag,
+		}, utils.NetworkFlags, utils.DatabaseFlags),
+		Description: `This command rewrites the given freezer tables with zstd compression, using a
+dictionary trained on a sample of the table items. The codec is recorded in the
+metadata of each table, so recompressed and snappy tables can be mixed freely;
+items appended later are compressed with zstd as well. Running the command
+again on a zstd table retrains its dictionary.
+
+A report of the space saved is printed at the end. With --dry-run, nothing is
+written and the savings are estimated instead. The node must not be running.`,
+	}
+)
+
+func dbRecompress(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	ancient := stack.ResolveAncient("chaindata", ctx.String(utils.AncientFlag.Name))
+	key := stack.Config().DBEncryptionKey
+	stack.Close()
+
+	var aead cipher.AEAD
+	if key != nil {
+		var err error
+		if aead, err = encdb.FreezerCipher(key); err != nil {
+			return err
+		}
+	}
+	dryRun := ctx.Bool(recompressDryRunFlag.Name)
+	stats, err := rawdb.RecompressFreezer(ancient, ctx.String(recompressFreezerFlag.Name), ctx.StringSlice(recompressTablesFlag.Name), aead, dryRun)
+	if err != nil {
+		return err
+	}
+	var (
+		rows           [][]string
+		before, after  common.StorageSize
+		newSizeHeading = "New size"
+	)
+	if dryRun {
+		newSizeHeading = "Estimated size"
+	}
+	for _, stat := range stats {
+		rows = append(rows, []string{
+			stat.Freezer,
+			stat.Table,
+			stat.Codec,
+			fmt.Sprintf("%d", stat.Items),
+			common.StorageSize(stat.Dict).String(),
+			stat.Size.String(),
+			stat.NewSize.String(),
+			fmt.Sprintf("%.1f%%", 100*stat.Saved()),
+		})
+		before += stat.Size
+		after += stat.NewSize
+	}
+	total := rawdb.FreezerCompressionStats{Size: before, NewSize: after}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.Header("Freezer", "Table", "Previous codec", "Items", "Dictionary", "Size", newSizeHeading, "Saved")
+	table.Footer("", "", "", "", "Total", before.String(), after.String(), fmt.Sprintf("%.1f%%", 100*total.Saved()))
+	table.Bulk(rows)
+	table.Render()
+	return nil
+}
@@ -0,0 +1,211 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/luxfi/geth/cmd/utils"
+	"github.com/luxfi/geth/common"
+	"github.com/luxfi/geth/core/rawdb"
+	"github.com/luxfi/geth/ethdb/encdb"
+	"github.com/luxfi/geth/log"
+	"github.com/luxfi/geth/node"
+	"github.com/urfave/cli/v2"
+)
+
+// rekeyNewKeyEnvVar is the environment variable the new encryption key can be
+// provided in, hex encoded.
+const rekeyNewKeyEnvVar = "GETH_DB_ENCRYPTION_NEWKEY"
+
+var (
+	rekeyNewKeyFileFlag = &cli.StringFlag{
+		Name:  "newkeyfile",
+		Usage: "File holding the hex-encoded 32-byte key to re-encrypt the databases with (alternatively set " + rekeyNewKeyEnvVar + ")",
+	}
+	dbRekeyCmd = &cli.Command{
+		Action: dbRekey,
+		Name:   "rekey",
+		Usage:  "Re-encrypt the chain database and freezer with a new key",
+		Flags: slices.Concat([]cli.Flag{
+			rekeyNewKeyFileFlag,
+			utils.CacheFlag,
+			utils.CacheDatabaseFlag,
+		}, utils.NetworkFlags, utils.DatabaseFlags),
+		Description: `This command rotates the encryption key of an encrypted chain database. The
+current key is given as usual by --db.encryption.keyfile or ` + encdb.KeyEnvVar + `,
+the new one by --newkeyfile or ` + rekeyNewKeyEnvVar + `.
+
+The key-value store is copied into <chaindata>.rekey under the new key and
+verified, then the freezer items are re-encrypted in place. Finally the original
+key-value files are moved to <chaindata>.oldkey and the re-encrypted ones take
+their place. The command can be interrupted and run again to resume; the
+progress is tracked in <chaindata>.rekey.progress.`,
+	}
+)
+
+// loadRekeyNewKey loads the new encryption key from the flag or environment.
+func loadRekeyNewKey(ctx *cli.Context) ([]byte, error) {
+	if ctx.IsSet(rekeyNewKeyFileFlag.Name) {
+		return encdb.LoadKey(ctx.String(rekeyNewKeyFileFlag.Name))
+	}
+	if env := os.Getenv(rekeyNewKeyEnvVar); env != "" {
+		return encdb.ParseKey(env)
+	}
+	return nil, fmt.Errorf("no new encryption key, set --%s or %s", rekeyNewKeyFileFlag.Name, rekeyNewKeyEnvVar)
+}
+
+func dbRekey(ctx *cli.Context) error {
+	newKey, err := loadRekeyNewKey(ctx)
+	if err != nil {
+		return err
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	oldKey := stack.Config().DBEncryptionKey
+	if oldKey == nil {
+		return fmt.Errorf("no current encryption key, set --%s or %s", utils.DBEncryptionKeyFileFlag.Name, encdb.KeyEnvVar)
+	}
+	if bytes.Equal(oldKey, newKey) {
+		return errors.New("new encryption key is the same as the current one")
+	}
+	oldCipher, err := encdb.FreezerCipher(oldKey)
+	if err != nil {
+		return err
+	}
+	newCipher, err := encdb.FreezerCipher(newKey)
+	if err != nil {
+		return err
+	}
+	var (
+		chaindata    = stack.ResolvePath("chaindata")
+		ancient      = stack.ResolveAncient("chaindata", ctx.String(utils.AncientFlag.Name))
+		engine       = rawdb.PreexistingDatabase(chaindata)
+		target       = chaindata + ".rekey"
+		backup       = chaindata + ".oldkey"
+		progressFile = target + ".progress"
+	)
+	// Complete an interrupted swap first, the freezer is re-encrypted already.
+	if progress, err := loadConvertProgress(progressFile); err == nil && progress.Swap != "" {
+		log.Warn("Completing interrupted database swap", "step", progress.Swap)
+		if err := swapDatabase(chaindata, target, backup, progress, func() error { return progress.save(progressFile) }); err != nil {
+			return fmt.Errorf("failed to replace the original database: %v", err)
+		}
+		return finishRekey(ancient, oldCipher, newCipher, progressFile)
+	}
+	if engine == "" {
+		return fmt.Errorf("no database found in %s", chaindata)
+	}
+	// The cache allowance is split between the two databases
+	opts := node.DatabaseOptions{
+		Cache:         ctx.Int(utils.CacheFlag.Name) * ctx.Int(utils.CacheDatabaseFlag.Name) / 100 / 2,
+		Handles:       utils.MakeDatabaseHandles(ctx.Int(utils.FDLimitFlag.Name)) / 2,
+		ReadOnly:      true,
+		EncryptionKey: oldKey,
+	}
+	src, err := node.OpenKeyValueDatabase(chaindata, engine, opts)
+	if errors.Is(err, encdb.ErrWrongKey) {
+		// The key-value store may have been swapped already by an earlier run
+		// that was interrupted before finishing the freezer.
+		opts.EncryptionKey = newKey
+		db, err := node.OpenKeyValueDatabase(chaindata, engine, opts)
+		if err != nil {
+			return fmt.Errorf("database is encrypted with neither key: %v", err)
+		}
+		db.Close()
+		return finishRekey(ancient, oldCipher, newCipher, progressFile)
+	} else if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer src.Close()
+
+	if _, err := os.Stat(backup); err == nil {
+		return fmt.Errorf("%s already exists, move it out of the way first", backup)
+	}
+	progress, err := loadConvertProgress(progressFile)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		if _, err := os.Stat(target); err == nil {
+			return fmt.Errorf("%s already exists without rekey progress, remove it to start over", target)
+		}
+		progress = &convertProgress{Source: engine, Target: engine}
+		if err := progress.save(progressFile); err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	default:
+		log.Info("Resuming database re-encryption", "keys", progress.Keys, "size", common.StorageSize(progress.Size), "marker", progress.Marker)
+	}
+	// The new database keeps the key mode of the original
+	opts.ReadOnly, opts.EncryptionKey = false, newKey
+	opts.EncryptKeys = src.(*encdb.Database).EncryptsKeys()
+	dst, err := node.OpenKeyValueDatabase(target, engine, opts)
+	if err != nil {
+		return fmt.Errorf("failed to open re-encrypted database: %v", err)
+	}
+	defer dst.Close()
+
+	stop, release := stopOnInterrupt("Interrupted during database re-encryption, stopping at next checkpoint")
+	defer release()
+
+	if !progress.Copied {
+		err := convertKeyValues(src, dst, progress, func() error { return progress.save(progressFile) }, stop)
+		if errors.Is(err, errConvertInterrupted) {
+			log.Info("Database re-encryption paused, run the command again to resume", "keys", progress.Keys, "size", common.StorageSize(progress.Size))
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+	mismatches, err := verifyConversion(src, dst, progress.Keys, stop)
+	if errors.Is(err, errConvertInterrupted) {
+		log.Info("Verification interrupted, run the command again to restart it")
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if len(mismatches) > 0 {
+		for _, m := range mismatches {
+			log.Error("Re-encrypted database mismatch", "err", m)
+		}
+		return fmt.Errorf("re-encrypted database differs from the original in %d places, remove %s and %s to start over", len(mismatches), target, progressFile)
+	}
+	src.Close()
+	dst.Close()
+
+	// The freezer is re-encrypted before the swap, so the database is never
+	// left with a key-value store and freezer under different keys on success.
+	if err := rawdb.RekeyFreezers(ancient, oldCipher, newCipher); err != nil {
+		return fmt.Errorf("failed to re-encrypt freezer: %v", err)
+	}
+	if err := swapDatabase(chaindata, target, backup, progress, func() error { return progress.save(progressFile) }); err != nil {
+		return fmt.Errorf("failed to replace the original database: %v", err)
+	}
+	if err := os.Remove(progressFile); err != nil {
+		return err
+	}
+	log.Info("Database re-encrypted", "keys", progress.Keys, "size", common.StorageSize(progress.Size), "original", backup)
+	log.Warn("The original database is still readable with the old key, delete it once the node runs with the new one", "path", backup)
+	return nil
+}
+
+// finishRekey completes a rekey whose key-value store has already been
+// replaced by re-encrypting the remaining freezer items.
+func finishRekey(ancient string, oldCipher, newCipher cipher.AEAD, progressFile string) error {
+	if err := rawdb.RekeyFreezers(ancient, oldCipher, newCipher); err != nil {
+		return fmt.Errorf("failed to re-encrypt freezer: %v", err)
+	}
+	if err := os.Remove(progressFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	log.Info("Database already re-encrypted, freezer is up to date")
+	return nil
+}
@@ -0,0 +1,76 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+
+	"github.com/luxfi/geth/cmd/utils"
+	"github.com/luxfi/geth/ethdb/netdb"
+	"github.com/luxfi/geth/log"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	dbServeSocketFlag = &cli.StringFlag{
+		Name:  "socket",
+		Usage: "Path of the unix socket to serve the database on (default = inside the datadir)",
+	}
+	dbServeCmd = &cli.Command{
+		Action: dbServe,
+		Name:   "serve",
+		Usage:  "Serve the chain database read-only to other processes over a local socket",
+		Flags: slices.Concat([]cli.Flag{
+			dbServeSocketFlag,
+		}, utils.NetworkFlags, utils.DatabaseFlags),
+		Description: `This command opens the chain database read-only, ancients included, and serves
+it over a unix socket until interrupted. Other processes can access it through
+the ethdb/netdb client, which implements ethdb.Database and supports batched
+gets, iterators and consistent snapshots. All writes are rejected.
+
+The command locks the datadir and can only be used while the node is offline.
+To serve the database of a running node, start it with --db.serve instead. The
+socket is only accessible to the user running the server.`,
+	}
+)
+
+func dbServe(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, true)
+	defer db.Close()
+
+	path := ctx.String(dbServeSocketFlag.Name)
+	if path == "" {
+		path = stack.ResolvePath("db.ipc")
+	}
+	listener, err := netdb.Listen(path)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+
+	server := netdb.NewServer(db)
+	defer server.Close()
+
+	errc := make(chan error, 1)
+	go func() { errc <- server.Serve(listener) }()
+	log.Info("Serving database", "socket", path)
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+
+	select {
+	case <-interrupt:
+		log.Info("Interrupted, stopping database server")
+		return nil
+	case err := <-errc:
+		return err
+	}
+}
@@ -0,0 +1,579 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"cmp"
+	"crypto/cipher"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/luxfi/crypto"
+	"github.com/luxfi/geth/cmd/utils"
+	"github.com/luxfi/geth/common"
+	"github.com/luxfi/geth/core/rawdb"
+	"github.com/luxfi/geth/core/types"
+	"github.com/luxfi/geth/ethdb"
+	"github.com/luxfi/geth/ethdb/encdb"
+	"github.com/luxfi/geth/internal/era"
+	"github.com/luxfi/geth/log"
+	"github.com/luxfi/geth/node"
+	"github.com/luxfi/geth/rlp"
+	"github.com/luxfi/geth/trie"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// verifyChunkItems is the number of consecutive items verified at once by
+	// a single thread.
+	verifyChunkItems = 256
+
+	// verifyMaxFailures is the maximum number of failures listed in the report.
+	verifyMaxFailures = 128
+
+	// repairImportBatch is the number of blocks re-imported from era files at
+	// once.
+	repairImportBatch = 1024
+)
+
+var (
+	verifyThreadsFlag = &cli.IntFlag{
+		Name:  "threads",
+		Usage: "Number of threads verifying the ancient items",
+		Value: runtime.NumCPU(),
+	}
+	verifyReportFlag = &cli.StringFlag{
+		Name:  "report",
+		Usage: "File to write the JSON report to (default = stdout)",
+	}
+	verifyRepairFlag = &cli.BoolFlag{
+		Name:  "repair",
+		Usage: "Truncate the ancient store to the last consistent item and re-import the gap from era files",
+	}
+	dbVerifyAncientsCmd = &cli.Command{
+		Action: dbVerifyAncients,
+		Name:   "verify-ancients",
+		Usage:  "Verify the integrity of the chain freezer",
+		Flags: slices.Concat([]cli.Flag{
+			verifyThreadsFlag,
+			verifyReportFlag,
+			verifyRepairFlag,
+		}, utils.NetworkFlags, utils.DatabaseFlags),
+		Description: `This command verifies the chain freezer offline. For every table, the index
+and data files must agree. For every item, the data must decompress, the header
+must hash to the stored canonical hash and link to its parent, and the bodies
+and receipts must match the transaction, uncle, withdrawal and receipt roots of
+the header. The result is written as a JSON report.
+
+With --repair, the freezer is truncated to the last consistent item, and the gap
+up to the previous head is re-imported from the era files (see --era.dir). If
+the era files don't cover the gap, the chain head is rewound so that the
+missing blocks are downloaded again on the next start.`,
+	}
+)
+
+// ancientReport is the JSON report of the chain freezer verification.
+type ancientReport struct {
+	Tables   []rawdb.FreezerTableReport `json:"tables"`
+	Error    string                     `json:"error,omitempty"`  // Reason the items could not be verified
+	Items    uint64                     `json:"items"`            // Number of items in the freezer
+	Tail     uint64                     `json:"tail"`             // First item with bodies and receipts
+	Verified uint64                     `json:"verified"`         // Number of leading consistent items
+	Failures []ancientFailure           `json:"failures"`         // First failures, ordered by item
+	Repair   *ancientRepair             `json:"repair,omitempty"` // Outcome of the repair, if done
+	Elapsed  string                     `json:"elapsed"`
+}
+
+// ancientFailure is an item which failed verification.
+type ancientFailure struct {
+	Item  uint64 `json:"item"`
+	Table string `json:"table"`
+	Error string `json:"error"`
+}
+
+// ancientRepair is the outcome of a freezer repair.
+type ancientRepair struct {
+	Truncated uint64 `json:"truncated"` // Number of items kept after the truncation
+	Imported  uint64 `json:"imported"`  // Number of items re-imported from era files
+	Items     uint64 `json:"items"`     // Number of items after the repair
+	Rewound   bool   `json:"rewound"`   // Whether the chain head was rewound
+}
+
+// consistent returns whether no damage was found.
+func (r *ancientReport) consistent() bool {
+	for _, table := range r.Tables {
+		if table.Error != "" {
+			return false
+		}
+	}
+	return r.Error == "" && len(r.Failures) == 0
+}
+
+// head returns the largest number of items stored in any table.
+func (r *ancientReport) head() uint64 {
+	head := r.Items
+	for _, table := range r.Tables {
+		head = max(head, table.Items)
+	}
+	return head
+}
+
+func dbVerifyAncients(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	var (
+		start   = time.Now()
+		ancient = stack.ResolveAncient("chaindata", ctx.String(utils.AncientFlag.Name))
+		key     = stack.Config().DBEncryptionKey
+		threads = max(ctx.Int(verifyThreadsFlag.Name), 1)
+		aead    cipher.AEAD
+	)
+	if key != nil {
+		var err error
+		if aead, err = encdb.FreezerCipher(key); err != nil {
+			return err
+		}
+	}
+	tables, err := rawdb.VerifyFreezerTables(ancient, rawdb.ChainFreezerName, aead)
+	if err != nil {
+		return err
+	}
+	report := &ancientReport{Tables: tables, Failures: []ancientFailure{}}
+	if report.consistent() {
+		freezer, err := rawdb.OpenChainFreezer(ancient, aead, true)
+		if err != nil {
+			report.Error = err.Error()
+		} else {
+			err = verifyAncientItems(freezer, threads, report)
+			freezer.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if !report.consistent() && ctx.Bool(verifyRepairFlag.Name) {
+		var (
+			chaindata = stack.ResolvePath("chaindata")
+			eraDir    = rawdb.ChainEraDir(ancient, ctx.String(utils.EraFlag.Name))
+		)
+		if report.Repair, err = repairAncients(ancient, aead, eraDir, chaindata, key, threads, report.head()); err != nil {
+			return err
+		}
+	}
+	report.Elapsed = common.PrettyDuration(time.Since(start)).String()
+	if err := writeAncientReport(ctx.String(verifyReportFlag.Name), report); err != nil {
+		return err
+	}
+	if !report.consistent() && report.Repair == nil {
+		return fmt.Errorf("ancient store is damaged, %d of %d items verified", report.Verified, report.head())
+	}
+	return nil
+}
+
+// writeAncientReport writes the JSON report to the given file, or to stdout.
+func writeAncientReport(file string, report *ancientReport) error {
+	blob, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	blob = append(blob, '\n')
+	if file == "" {
+		_, err = os.Stdout.Write(blob)
+		return err
+	}
+	log.Info("Writing verification report", "file", file)
+	return os.WriteFile(file, blob, 0644)
+}
+
+// verifyAncientItems verifies all items of the chain freezer in parallel and
+// fills the results into the report.
+func verifyAncientItems(db ethdb.AncientReaderOp, threads int, report *ancientReport) error {
+	items, err := db.Ancients()
+	if err != nil {
+		return err
+	}
+	tail, err := db.Tail()
+	if err != nil {
+		return err
+	}
+	var (
+		chunks   = make(chan uint64)
+		done     atomic.Uint64
+		lock     sync.Mutex
+		failures []ancientFailure
+		wg       sync.WaitGroup
+	)
+	for i := 0; i < threads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for start := range chunks {
+				end := min(start+verifyChunkItems, items)
+				if failure := verifyAncientChunk(db, start, end, tail); failure != nil {
+					lock.Lock()
+					failures = append(failures, *failure)
+					lock.Unlock()
+				}
+				done.Add(end - start)
+			}
+		}()
+	}
+	logged := time.Now()
+	for start := uint64(0); start < items; start += verifyChunkItems {
+		chunks <- start
+		if time.Since(logged) > 8*time.Second {
+			lock.Lock()
+			log.Info("Verifying ancient items", "verified", done.Load(), "items", items, "failures", len(failures))
+			lock.Unlock()
+			logged = time.Now()
+		}
+	}
+	close(chunks)
+	wg.Wait()
+
+	slices.SortFunc(failures, func(a, b ancientFailure) int {
+		return cmp.Compare(a.Item, b.Item)
+	})
+	report.Items, report.Tail, report.Verified = items, tail, items
+	if len(failures) > 0 {
+		report.Verified = failures[0].Item
+	}
+	if len(failures) > verifyMaxFailures {
+		failures = failures[:verifyMaxFailures]
+	}
+	report.Failures = append(report.Failures[:0], failures...)
+	log.Info("Verified ancient items", "items", items, "verified", report.Verified, "failures", len(failures))
+	return nil
+}
+
+// verifyAncientChunk verifies the items in [start, end) and returns the first
+// failure, if any.
+func verifyAncientChunk(db ethdb.AncientReaderOp, start, end, tail uint64) *ancientFailure {
+	count := end - start
+	hashes, failure := readAncientItems(db, rawdb.ChainFreezerHashTable, start, count)
+	if failure != nil {
+		return failure
+	}
+	headers, failure := readAncientItems(db, rawdb.ChainFreezerHeaderTable, start, count)
+	if failure != nil {
+		return failure
+	}
+	// Bodies and receipts below the tail are pruned
+	var (
+		bodies   = make([][]byte, count)
+		receipts = make([][]byte, count)
+	)
+	if end > tail {
+		from := max(start, tail)
+		items, failure := readAncientItems(db, rawdb.ChainFreezerBodiesTable, from, end-from)
+		if failure != nil {
+			return failure
+		}
+		copy(bodies[from-start:], items)
+
+		if items, failure = readAncientItems(db, rawdb.ChainFreezerReceiptTable, from, end-from); failure != nil {
+			return failure
+		}
+		copy(receipts[from-start:], items)
+	}
+	// The parent of the first item is verified by the previous chunk, don't
+	// check the link if it is unreadable.
+	var parent *common.Hash
+	if start > 0 {
+		if blob, err := db.Ancient(rawdb.ChainFreezerHashTable, start-1); err == nil {
+			hash := common.BytesToHash(blob)
+			parent = &hash
+		}
+	}
+	for i := range count {
+		table, err := verifyAncientItem(start+i, hashes[i], headers[i], bodies[i], receipts[i], parent)
+		if err != nil {
+			return &ancientFailure{Item: start + i, Table: table, Error: err.Error()}
+		}
+		hash := common.BytesToHash(hashes[i])
+		parent = &hash
+	}
+	return nil
+}
+
+// readAncientItems reads a range of items from a table. If the range can't be
+// read, the first unreadable item is located and reported.
+func readAncientItems(db ethdb.AncientReaderOp, kind string, start, count uint64) ([][]byte, *ancientFailure) {
+	items, err := db.AncientRange(kind, start, count, 0)
+	if err == nil && uint64(len(items)) == count {
+		return items, nil
+	}
+	items = items[:0]
+	for n := start; n < start+count; n++ {
+		blob, err := db.Ancient(kind, n)
+		if err != nil {
+			return nil, &ancientFailure{Item: n, Table: kind, Error: err.Error()}
+		}
+		items = append(items, blob)
+	}
+	return items, nil
+}
+
+// verifyAncientItem verifies a single item of the chain freezer against its
+// header. Empty bodies and receipts are pruned or were never stored, they
+// are not verified. The table holding the damaged data is returned along with
+// the error.
+func verifyAncientItem(number uint64, hash, headerBlob, bodyBlob, receiptsBlob []byte, parent *common.Hash) (string, error) {
+	if len(hash) != common.HashLength {
+		return rawdb.ChainFreezerHashTable, fmt.Errorf("invalid hash length %d", len(hash))
+	}
+	var header types.Header
+	if err := rlp.DecodeBytes(headerBlob, &header); err != nil {
+		return rawdb.ChainFreezerHeaderTable, fmt.Errorf("invalid header: %v", err)
+	}
+	if header.Number == nil || !header.Number.IsUint64() || header.Number.Uint64() != number {
+		return rawdb.ChainFreezerHeaderTable, fmt.Errorf("header has number %v", header.Number)
+	}
+	if have := common.BytesToHash(crypto.Keccak256(headerBlob)); have != common.BytesToHash(hash) {
+		return rawdb.ChainFreezerHashTable, fmt.Errorf("hash %x doesn't match header hash %x", hash, have)
+	}
+	if parent != nil && header.ParentHash != *parent {
+		return rawdb.ChainFreezerHeaderTable, fmt.Errorf("parent hash %x doesn't match %x", header.ParentHash, *parent)
+	}
+	if len(bodyBlob) == 0 {
+		if len(receiptsBlob) != 0 {
+			return rawdb.ChainFreezerBodiesTable, errors.New("missing body")
+		}
+		return "", nil
+	}
+	var body types.Body
+	if err := rlp.DecodeBytes(bodyBlob, &body); err != nil {
+		return rawdb.ChainFreezerBodiesTable, fmt.Errorf("invalid body: %v", err)
+	}
+	txs := types.Transactions(body.Transactions)
+	if root := types.DeriveSha(txs, trie.NewStackTrie(nil)); root != header.TxHash {
+		return rawdb.ChainFreezerBodiesTable, fmt.Errorf("transaction root %x doesn't match header %x", root, header.TxHash)
+	}
+	if uncles := types.CalcUncleHash(body.Uncles); uncles != header.UncleHash {
+		return rawdb.ChainFreezerBodiesTable, fmt.Errorf("uncle hash %x doesn't match header %x", uncles, header.UncleHash)
+	}
+	switch {
+	case header.WithdrawalsHash == nil && body.Withdrawals != nil:
+		return rawdb.ChainFreezerBodiesTable, errors.New("unexpected withdrawals")
+	case header.WithdrawalsHash != nil && body.Withdrawals == nil:
+		return rawdb.ChainFreezerBodiesTable, errors.New("missing withdrawals")
+	case header.WithdrawalsHash != nil:
+		if root := types.DeriveSha(types.Withdrawals(body.Withdrawals), trie.NewStackTrie(nil)); root != *header.WithdrawalsHash {
+			return rawdb.ChainFreezerBodiesTable, fmt.Errorf("withdrawal root %x doesn't match header %x", root, *header.WithdrawalsHash)
+		}
+	}
+	if len(receiptsBlob) == 0 {
+		return rawdb.ChainFreezerReceiptTable, errors.New("missing receipts")
+	}
+	var stored []*types.ReceiptForStorage
+	if err := rlp.DecodeBytes(receiptsBlob, &stored); err != nil {
+		return rawdb.ChainFreezerReceiptTable, fmt.Errorf("invalid receipts: %v", err)
+	}
+	if len(stored) != len(txs) {
+		return rawdb.ChainFreezerReceiptTable, fmt.Errorf("%d receipts for %d transactions", len(stored), len(txs))
+	}
+	// The storage format lacks the derived fields needed for the consensus
+	// encoding of the receipts.
+	receipts := make(types.Receipts, len(stored))
+	for i, r := range stored {
+		receipt := (*types.Receipt)(r)
+		receipt.Type = txs[i].Type()
+		receipt.Bloom = types.CreateBloom(receipt)
+		receipts[i] = receipt
+	}
+	if root := types.DeriveSha(receipts, trie.NewStackTrie(nil)); root != header.ReceiptHash {
+		return rawdb.ChainFreezerReceiptTable, fmt.Errorf("receipt root %x doesn't match header %x", root, header.ReceiptHash)
+	}
+	return "", nil
+}
+
+// repairAncients truncates the chain freezer to its last consistent item and
+// re-imports the gap up to head from era files. If the gap can't be filled,
+// the chain head is rewound to the repaired freezer.
+func repairAncients(ancient string, aead cipher.AEAD, eraDir, chaindata string, key []byte, threads int, head uint64) (*ancientRepair, error) {
+	// Opening the freezer for writing repairs the index and data files and
+	// truncates the tables to a common length.
+	freezer, err := rawdb.OpenChainFreezer(ancient, aead, false)
+	if err != nil {
+		return nil, err
+	}
+	defer freezer.Close()
+
+	check := &ancientReport{}
+	if err := verifyAncientItems(freezer, threads, check); err != nil {
+		return nil, err
+	}
+	repair := &ancientRepair{Truncated: check.Verified}
+	if _, err := freezer.TruncateHead(check.Verified); err != nil {
+		return nil, err
+	}
+	log.Warn("Truncated ancient store", "items", check.Verified, "previous", head)
+
+	if repair.Imported, err = importAncientsFromEra(freezer, eraDir, check.Verified, head); err != nil {
+		return nil, err
+	}
+	if repair.Items, err = freezer.Ancients(); err != nil {
+		return nil, err
+	}
+	if repair.Items >= head {
+		return repair, nil
+	}
+	// Rewind the chain head to the last ancient block, so that the missing
+	// blocks are downloaded again.
+	var last common.Hash
+	if repair.Items > 0 {
+		blob, err := freezer.Ancient(rawdb.ChainFreezerHashTable, repair.Items-1)
+		if err != nil {
+			return nil, err
+		}
+		last = common.BytesToHash(blob)
+	}
+	if repair.Rewound, err = rewindChainHead(chaindata, key, repair.Items, last); err != nil {
+		return nil, err
+	}
+	return repair, nil
+}
+
+// importAncientsFromEra appends the blocks [from, to) to the freezer from the
+// era files in dir, as long as they are available and link to the freezer.
+// The number of imported blocks is returned.
+func importAncientsFromEra(freezer *rawdb.Freezer, dir string, from, to uint64) (uint64, error) {
+	var parent *common.Hash
+	if from > 0 {
+		blob, err := freezer.Ancient(rawdb.ChainFreezerHashTable, from-1)
+		if err != nil {
+			return 0, err
+		}
+		hash := common.BytesToHash(blob)
+		parent = &hash
+	}
+	var (
+		next     = from
+		blocks   []*types.Block
+		receipts []rlp.RawValue
+		flush    = func() error {
+			if len(blocks) == 0 {
+				return nil
+			}
+			if _, err := rawdb.WriteAncientBlocks(freezer, blocks, receipts); err != nil {
+				return err
+			}
+			log.Info("Re-imported blocks from era files", "number", blocks[len(blocks)-1].NumberU64())
+			blocks, receipts = blocks[:0], receipts[:0]
+			return nil
+		}
+	)
+	for next < to {
+		epoch := next / uint64(era.MaxEra1Size)
+		matches, err := filepath.Glob(filepath.Join(dir, fmt.Sprintf("*-%05d-*.era1", epoch)))
+		if err != nil {
+			return 0, err
+		}
+		if len(matches) != 1 {
+			log.Warn("No era file to re-import blocks from", "dir", dir, "epoch", epoch, "number", next)
+			break
+		}
+		e, err := era.Open(matches[0])
+		if err != nil {
+			return 0, err
+		}
+		for ; next < to && next < e.Start()+e.Count(); next++ {
+			block, raw, err := readEraBlock(e, next, parent)
+			if err != nil {
+				e.Close()
+				log.Warn("Failed to re-import block from era file", "number", next, "file", matches[0], "err", err)
+				return next - from, flush()
+			}
+			hash := block.Hash()
+			parent = &hash
+			blocks, receipts = append(blocks, block), append(receipts, raw)
+			if len(blocks) >= repairImportBatch {
+				if err := flush(); err != nil {
+					e.Close()
+					return 0, err
+				}
+			}
+		}
+		e.Close()
+		if next < e.Start()+e.Count() {
+			break
+		}
+	}
+	if err := flush(); err != nil {
+		return 0, err
+	}
+	return next - from, nil
+}
+
+// readEraBlock reads a block along with its receipts in storage format from an
+// era file and verifies them like the freezer items.
+func readEraBlock(e *era.Era, number uint64, parent *common.Hash) (*types.Block, rlp.RawValue, error) {
+	block, err := e.GetBlockByNumber(number)
+	if err != nil {
+		return nil, nil, err
+	}
+	blob, err := e.GetRawReceiptsByNumber(number)
+	if err != nil {
+		return nil, nil, err
+	}
+	var receipts types.Receipts
+	if err := rlp.DecodeBytes(blob, &receipts); err != nil {
+		return nil, nil, err
+	}
+	raw := types.EncodeBlockReceiptLists([]types.Receipts{receipts})[0]
+
+	header, err := rlp.EncodeToBytes(block.Header())
+	if err != nil {
+		return nil, nil, err
+	}
+	body, err := rlp.EncodeToBytes(block.Body())
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := verifyAncientItem(number, block.Hash().Bytes(), header, body, raw, parent); err != nil {
+		return nil, nil, err
+	}
+	return block, raw, nil
+}
+
+// rewindChainHead rewinds the head markers of the key-value store to the last
+// block of a repaired freezer holding the given number of items. It returns
+// whether anything was rewound.
+func rewindChainHead(chaindata string, key []byte, items uint64, last common.Hash) (bool, error) {
+	engine := rawdb.PreexistingDatabase(chaindata)
+	if engine == "" || items == 0 {
+		return false, nil
+	}
+	db, err := node.OpenKeyValueDatabase(chaindata, engine, node.DatabaseOptions{EncryptionKey: key})
+	if err != nil {
+		return false, err
+	}
+	defer db.Close()
+
+	head, ok := rawdb.ReadHeaderNumber(db, rawdb.ReadHeadHeaderHash(db))
+	if !ok || head < items {
+		return false, nil
+	}
+	batch := db.NewBatch()
+	rawdb.WriteHeadHeaderHash(batch, last)
+	rawdb.WriteHeadBlockHash(batch, last)
+	rawdb.WriteHeadFastBlockHash(batch, last)
+	if finalized, ok := rawdb.ReadHeaderNumber(db, rawdb.ReadFinalizedBlockHash(db)); ok && finalized >= items {
+		rawdb.WriteFinalizedBlockHash(batch, last)
+	}
+	if err := batch.Write(); err != nil {
+		return false, err
+	}
+	log.Warn("Rewound chain head to the repaired ancient store", "number", items-1, "hash", last, "previous", head)
+	return true, nil
+}
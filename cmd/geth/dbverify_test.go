@@ -0,0 +1,165 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bytes"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/luxfi/crypto"
+	"github.com/luxfi/geth/common"
+	"github.com/luxfi/geth/consensus/beacon"
+	"github.com/luxfi/geth/consensus/ethash"
+	"github.com/luxfi/geth/core"
+	"github.com/luxfi/geth/core/rawdb"
+	"github.com/luxfi/geth/core/types"
+	"github.com/luxfi/geth/internal/era"
+	"github.com/luxfi/geth/params"
+)
+
+// newVerifyTestChain generates a chain with transactions in every block and
+// returns its blocks, genesis included, along with their receipts.
+func newVerifyTestChain(t *testing.T, n int) ([]*types.Block, []types.Receipts) {
+	t.Helper()
+
+	var (
+		key, _ = crypto.GenerateKey()
+		addr   = common.Address(crypto.PubkeyToAddress(key.PublicKey))
+		gspec  = &core.Genesis{
+			Config:  params.TestChainConfig,
+			Alloc:   types.GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	_, blocks, receipts := core.GenerateChainWithGenesis(gspec, beacon.New(ethash.NewFaker()), n, func(i int, b *core.BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(b.TxNonce(addr), common.Address{0x1}, big.NewInt(1), params.TxGas, b.BaseFee(), nil), signer, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.AddTx(tx)
+	})
+	return append([]*types.Block{gspec.ToBlock()}, blocks...), append([]types.Receipts{nil}, receipts...)
+}
+
+// writeVerifyTestFreezer writes the blocks into a new chain freezer.
+func writeVerifyTestFreezer(t *testing.T, ancient string, blocks []*types.Block, receipts []types.Receipts) {
+	t.Helper()
+
+	freezer, err := rawdb.OpenChainFreezer(ancient, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer freezer.Close()
+	if _, err := rawdb.WriteAncientBlocks(freezer, blocks, types.EncodeBlockReceiptLists(receipts)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyAncients(t *testing.T) {
+	t.Parallel()
+
+	blocks, receipts := newVerifyTestChain(t, 32)
+
+	// An intact freezer verifies completely
+	ancient := t.TempDir()
+	writeVerifyTestFreezer(t, ancient, blocks, receipts)
+
+	freezer, err := rawdb.OpenChainFreezer(ancient, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := new(ancientReport)
+	if err := verifyAncientItems(freezer, 4, report); err != nil {
+		t.Fatal(err)
+	}
+	freezer.Close()
+	if !report.consistent() || report.Items != 33 || report.Verified != 33 {
+		t.Fatalf("unexpected report for intact freezer: %+v", report)
+	}
+	// Altered receipts are detected at the right item
+	altered := *receipts[20][0]
+	altered.CumulativeGasUsed++
+
+	damaged := make([]types.Receipts, len(receipts))
+	copy(damaged, receipts)
+	damaged[20] = types.Receipts{&altered}
+
+	ancient = t.TempDir()
+	writeVerifyTestFreezer(t, ancient, blocks, damaged)
+
+	freezer, err = rawdb.OpenChainFreezer(ancient, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report = new(ancientReport)
+	if err := verifyAncientItems(freezer, 4, report); err != nil {
+		t.Fatal(err)
+	}
+	freezer.Close()
+	if report.consistent() || report.Verified != 20 || report.Failures[0].Table != rawdb.ChainFreezerReceiptTable {
+		t.Fatalf("unexpected report for damaged freezer: %+v", report)
+	}
+	// Without era files, the repair truncates to the consistent items
+	repair, err := repairAncients(ancient, nil, t.TempDir(), t.TempDir(), nil, 4, 33)
+	if err != nil {
+		t.Fatalf("repair failed: %v", err)
+	}
+	if repair.Truncated != 20 || repair.Imported != 0 || repair.Items != 20 {
+		t.Fatalf("unexpected repair: %+v", repair)
+	}
+	// With era files, the gap is re-imported
+	eraDir := t.TempDir()
+	writeVerifyTestEra(t, eraDir, blocks, receipts)
+
+	if repair, err = repairAncients(ancient, nil, eraDir, t.TempDir(), nil, 4, 33); err != nil {
+		t.Fatalf("repair failed: %v", err)
+	}
+	if repair.Truncated != 20 || repair.Imported != 13 || repair.Items != 33 {
+		t.Fatalf("unexpected repair: %+v", repair)
+	}
+	freezer, err = rawdb.OpenChainFreezer(ancient, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer freezer.Close()
+
+	report = new(ancientReport)
+	if err := verifyAncientItems(freezer, 4, report); err != nil {
+		t.Fatal(err)
+	}
+	if !report.consistent() || report.Verified != 33 {
+		t.Fatalf("unexpected report for repaired freezer: %+v", report)
+	}
+	want := types.EncodeBlockReceiptLists(receipts[20:21])[0]
+	if have, err := freezer.Ancient(rawdb.ChainFreezerReceiptTable, 20); err != nil || !bytes.Equal(have, want) {
+		t.Fatalf("re-imported receipts differ from the original: %v", err)
+	}
+}
+
+// writeVerifyTestEra exports the blocks into a single era file.
+func writeVerifyTestEra(t *testing.T, dir string, blocks []*types.Block, receipts []types.Receipts) {
+	t.Helper()
+
+	f, err := os.Create(filepath.Join(dir, era.Filename("test", 0, common.Hash{})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	builder := era.NewBuilder(f)
+	td := new(big.Int)
+	for i, block := range blocks {
+		td.Add(td, block.Difficulty())
+		if err := builder.Add(block, receipts[i], new(big.Int).Set(td)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := builder.Finalize(); err != nil {
+		t.Fatal(err)
+	}
+}
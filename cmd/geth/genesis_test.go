@@ -173,6 +173,20 @@ func TestCustomBackend(t *testing.T) {
 			initArgs:   []string{"--db.engine", "pebble"},
 			execExpect: "0x0000000000001338",
 		},
+		{ // Explicit badger
+			initArgs:   []string{"--db.engine", "badger"},
+			execArgs:   []string{"--db.engine", "badger"},
+			execExpect: "0x0000000000001338",
+		},
+		{ // Explicit badger, then auto-discover
+			initArgs:   []string{"--db.engine", "badger"},
+			execExpect: "0x0000000000001338",
+		},
+		{ // Can't start pebble on top of badger
+			initArgs:   []string{"--db.engine", "badger"},
+			execArgs:   []string{"--db.engine", "pebble"},
+			execExpect: `Fatal: Failed to register the Ethereum service: db.engine choice was pebble but found pre-existing badger database in specified data directory`,
+		},
 		{ // Can't start pebble on top of leveldb
 			initArgs:   []string{"--db.engine", "leveldb"},
 			execArgs:   []string{"--db.engine", "pebble"},
@@ -185,7 +199,7 @@ func TestCustomBackend(t *testing.T) {
 		},
 		{ // Reject invalid backend choice
 			initArgs:   []string{"--db.engine", "mssql"},
-			initExpect: `Fatal: Invalid choice for db.engine 'mssql', allowed 'leveldb' or 'pebble'`,
+			initExpect: `Fatal: Invalid choice for db.engine 'mssql', allowed 'leveldb', 'pebble' or 'badger'`,
 			// Since the init fails, this will return the (default) mainnet genesis
 			// block nonce
 			execExpect: `0x0000000000000042`,
@@ -64,6 +64,7 @@ var (
 		utils.SmartCardDaemonPathFlag,
 		utils.OverrideOsaka,
 		utils.OverrideVerkle,
+		utils.OverridePostQuantum,
 		utils.EnablePersonal, // deprecated
 		utils.TxPoolLocalsFlag,
 		utils.TxPoolNoLocalsFlag,
@@ -87,10 +88,13 @@ var (
 		utils.TxLookupLimitFlag, // deprecated
 		utils.TransactionHistoryFlag,
 		utils.ChainHistoryFlag,
+		utils.ChainHistoryWindowFlag,
 		utils.LogHistoryFlag,
 		utils.LogNoHistoryFlag,
 		utils.LogExportCheckpointsFlag,
+		utils.DBServeFlag,
 		utils.StateHistoryFlag,
+		utils.ProofHistoryFlag,
 		utils.LightKDFFlag,
 		utils.EthRequiredBlocksFlag,
 		utils.LegacyWhitelistFlag, // deprecated
@@ -123,6 +127,7 @@ var (
 		utils.DiscoveryV4Flag,
 		utils.DiscoveryV5Flag,
 		utils.LegacyDiscoveryV5Flag, // deprecated
+		utils.PostQuantumHandshakeFlag,
 		utils.NetrestrictFlag,
 		utils.NodeKeyFileFlag,
 		utils.NodeKeyHexFlag,
@@ -32,14 +32,20 @@ import (
 	"github.com/luxfi/geth/core/state/pruner"
 	"github.com/luxfi/geth/core/state/snapshot"
 	"github.com/luxfi/geth/core/types"
+	"github.com/luxfi/geth/ethdb"
 	"github.com/luxfi/crypto"
 	"github.com/luxfi/geth/log"
 	"github.com/luxfi/geth/rlp"
 	"github.com/luxfi/geth/trie"
+	"github.com/luxfi/geth/triedb/pathdb"
 	"github.com/urfave/cli/v2"
 )
 
 var (
+	pruneDryRunFlag = &cli.BoolFlag{
+		Name:  "dry-run",
+		Usage: "Only report the space to be reclaimed, without modifying the database (path scheme only)",
+	}
 	snapshotCommand = &cli.Command{
 		Name:        "snapshot",
 		Usage:       "A set of commands based on the snapshot",
@@ -52,6 +58,8 @@ var (
 				Action:    pruneState,
 				Flags: slices.Concat([]cli.Flag{
 					utils.BloomFilterSizeFlag,
+					utils.StateHistoryFlag,
+					pruneDryRunFlag,
 				}, utils.NetworkFlags, utils.DatabaseFlags),
 				Description: `
 geth snapshot prune-state <state-root>
@@ -62,7 +70,13 @@ two version states are available: genesis and the specific one.
 
 The default pruning target is the HEAD-127 state.
 
-WARNING: it's only supported in hash mode(--state.scheme=hash)".
+In path mode (--state.scheme=path), the persistent state is verified
+against its root instead, and all trie nodes not referenced by it are
+deleted, along with the state histories beyond --history.state blocks.
+Such nodes may be left behind by a crash or a database corruption. The
+state root can be omitted, as only the persistent state is retained.
+With --dry-run, the space to be reclaimed is reported without deleting
+anything.
 `,
 			},
 			{
@@ -159,6 +173,32 @@ block is used.
 				Description: `
 The export-preimages command exports hash preimages to a flat file, in exactly
 the expected order for the overlay tree migration.
+`,
+			},
+			{
+				Action:    snapshotExportFlat,
+				Name:      "export-flat",
+				Usage:     "Export the flat state of a block into a flat state file",
+				ArgsUsage: "<file> [<blockNum|blockHash>]",
+				Flags:     slices.Concat(utils.NetworkFlags, utils.DatabaseFlags),
+				Description: `
+The export-flat command writes the accounts, storage slots and contract codes of
+the state of the given block, the head block by default, into a chunked and
+checksummed flat state file. The file is gzip compressed if its name ends with
+.gz. The flat state of the block must be available.
+`,
+			},
+			{
+				Action:    snapshotImportFlat,
+				Name:      "import-flat",
+				Usage:     "Import the state from a flat state file",
+				ArgsUsage: "<file>",
+				Flags:     slices.Concat(utils.NetworkFlags, utils.DatabaseFlags),
+				Description: `
+The import-flat command loads a file written by export-flat into a database
+which holds no state beyond the genesis, rebuilding the tries and verifying the
+state root. If the database holds the block of the state, e.g. imported with
+import-history, the chain head is moved to it, so the node starts from there.
 `,
 			},
 		},
@@ -174,8 +214,8 @@ func pruneState(ctx *cli.Context) error {
 	chaindb := utils.MakeChainDatabase(ctx, stack, false)
 	defer chaindb.Close()
 
-	if rawdb.ReadStateScheme(chaindb) != rawdb.HashScheme {
-		log.Crit("Offline pruning is not required for path scheme")
+	if rawdb.ReadStateScheme(chaindb) == rawdb.PathScheme {
+		return prunePathState(ctx, chaindb)
 	}
 	prunerconfig := pruner.Config{
 		Datadir:   stack.ResolvePath(""),
@@ -205,6 +245,48 @@ func pruneState(ctx *cli.Context) error {
 	return nil
 }
 
+// prunePathState deletes the trie nodes not referenced by the persistent state
+// of a path-based database, along with the state histories beyond the
+// configured depth.
+func prunePathState(ctx *cli.Context, chaindb ethdb.Database) error {
+	if ctx.NArg() > 1 {
+		log.Error("Too many arguments given")
+		return errors.New("too many arguments")
+	}
+	config := pathdb.PruneConfig{
+		HistoryDepth: ctx.Uint64(utils.StateHistoryFlag.Name),
+		DryRun:       ctx.Bool(pruneDryRunFlag.Name),
+	}
+	if ctx.NArg() == 1 {
+		root, err := parseRoot(ctx.Args().First())
+		if err != nil {
+			log.Error("Failed to resolve state root", "err", err)
+			return err
+		}
+		persistent := types.EmptyRootHash
+		if blob := rawdb.ReadAccountTrieNode(chaindb, nil); len(blob) > 0 {
+			persistent = common.BytesToHash(crypto.Keccak256(blob))
+		}
+		if root != persistent {
+			return fmt.Errorf("state %x is not the persistent state %x", root, persistent)
+		}
+	}
+	start := time.Now()
+	stats, err := pathdb.Prune(chaindb, config)
+	if err != nil {
+		log.Error("Failed to prune state", "err", err)
+		return err
+	}
+	msg := "State pruning successful"
+	if config.DryRun {
+		msg = "State pruning dry run finished"
+	}
+	log.Info(msg, "root", stats.Root, "id", stats.StateID, "nodes", stats.Nodes, "nodesize", stats.NodeSize,
+		"orphans", stats.Orphans, "orphansize", stats.OrphanSize, "histories", stats.Histories, "historysize", stats.HistorySize,
+		"reclaimed", stats.Reclaimed(), "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
 func verifyState(ctx *cli.Context) error {
 	stack, _ := makeConfigNode(ctx)
 	defer stack.Close()
@@ -0,0 +1,196 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/luxfi/geth/cmd/utils"
+	"github.com/luxfi/geth/common"
+	"github.com/luxfi/geth/core/rawdb"
+	"github.com/luxfi/geth/core/state/snapshot"
+	"github.com/luxfi/geth/core/types"
+	"github.com/luxfi/geth/ethdb"
+	"github.com/luxfi/geth/log"
+	"github.com/luxfi/geth/triedb/pathdb"
+	"github.com/urfave/cli/v2"
+)
+
+// resolveFlatBlock resolves the block whose state is exported, the head block
+// if none is specified.
+func resolveFlatBlock(db ethdb.Reader, arg string) (*types.Header, error) {
+	if arg == "" {
+		block := rawdb.ReadHeadBlock(db)
+		if block == nil {
+			return nil, errors.New("no head block")
+		}
+		return block.Header(), nil
+	}
+	var hash common.Hash
+	if hashish(arg) {
+		hash = common.HexToHash(arg)
+	} else {
+		number, err := strconv.ParseUint(arg, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		hash = rawdb.ReadCanonicalHash(db, number)
+	}
+	number, ok := rawdb.ReadHeaderNumber(db, hash)
+	if !ok {
+		return nil, fmt.Errorf("block %s not found", arg)
+	}
+	return rawdb.ReadHeader(db, hash, number), nil
+}
+
+// snapshotExportFlat writes the flat state of a block into a flat state file.
+func snapshotExportFlat(ctx *cli.Context) error {
+	if ctx.NArg() < 1 || ctx.NArg() > 2 {
+		return errors.New("need <file> [<blockNum|blockHash>] args")
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chaindb := utils.MakeChainDatabase(ctx, stack, true)
+	defer chaindb.Close()
+
+	header, err := resolveFlatBlock(chaindb, ctx.Args().Get(1))
+	if err != nil {
+		return err
+	}
+	triedb := utils.MakeTrieDatabase(ctx, chaindb, false, true, false)
+	defer triedb.Close()
+
+	var (
+		root     = header.Root
+		accounts snapshot.AccountIterator
+		storage  func(account common.Hash) (snapshot.StorageIterator, error)
+	)
+	if triedb.Scheme() == rawdb.PathScheme {
+		it, err := triedb.AccountIterator(root, common.Hash{})
+		if err != nil {
+			return err
+		}
+		accounts = it
+		storage = func(account common.Hash) (snapshot.StorageIterator, error) {
+			return triedb.StorageIterator(root, account, common.Hash{})
+		}
+	} else {
+		snapConfig := snapshot.Config{
+			CacheSize:  256,
+			Recovery:   false,
+			NoBuild:    true,
+			AsyncBuild: false,
+		}
+		snaptree, err := snapshot.New(snapConfig, chaindb, triedb, root)
+		if err != nil {
+			return err
+		}
+		if accounts, err = snaptree.AccountIterator(root, common.Hash{}); err != nil {
+			return err
+		}
+		storage = func(account common.Hash) (snapshot.StorageIterator, error) {
+			return snaptree.StorageIterator(root, account, common.Hash{})
+		}
+	}
+	defer accounts.Release()
+
+	fn := ctx.Args().First()
+	fh, err := os.OpenFile(fn, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	// Enable gzip compressing if file name has gz suffix.
+	var writer io.Writer = fh
+	if strings.HasSuffix(fn, ".gz") {
+		gz := gzip.NewWriter(writer)
+		defer gz.Close()
+		writer = gz
+	}
+	buf := bufio.NewWriter(writer)
+	defer buf.Flush()
+
+	log.Info("Exporting flat state", "file", fn, "number", header.Number, "hash", header.Hash(), "root", root)
+	start := time.Now()
+	stats, err := snapshot.ExportFlat(buf, snapshot.FlatHeader{Root: root, Number: header.Number.Uint64(), Hash: header.Hash()}, accounts, storage, chaindb)
+	if err != nil {
+		return err
+	}
+	log.Info("Exported flat state", "file", fn, "accounts", stats.Accounts, "slots", stats.Slots, "codes", stats.Codes,
+		"elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// snapshotImportFlat loads a flat state file into the database, rebuilding the
+// tries, and moves the chain head to the block of the state if it's present.
+func snapshotImportFlat(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return errors.New("need <file> arg")
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chaindb := utils.MakeChainDatabase(ctx, stack, false)
+	defer chaindb.Close()
+
+	if head := rawdb.ReadHeadBlock(chaindb); head != nil && head.NumberU64() != 0 {
+		return fmt.Errorf("database already holds the state of block #%d", head.NumberU64())
+	}
+	scheme, err := rawdb.ParseStateScheme(ctx.String(utils.StateSchemeFlag.Name), chaindb)
+	if err != nil {
+		return err
+	}
+	fn := ctx.Args().First()
+	fh, err := os.Open(fn)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	var reader io.Reader = fh
+	if strings.HasSuffix(fn, ".gz") {
+		if reader, err = gzip.NewReader(reader); err != nil {
+			return err
+		}
+	}
+	log.Info("Importing flat state", "file", fn, "scheme", scheme)
+	header, _, err := snapshot.ImportFlat(reader, chaindb, scheme)
+	if err != nil {
+		return err
+	}
+	// The state histories and journals of the replaced state no longer apply
+	rawdb.DeleteSnapshotJournal(chaindb)
+	if scheme == rawdb.PathScheme {
+		if err := pathdb.ResetHistory(chaindb); err != nil {
+			return err
+		}
+	}
+
+	// Move the chain head to the block of the state, if the chain segment was
+	// imported already, e.g. by import-history.
+	if rawdb.ReadCanonicalHash(chaindb, header.Number) != header.Hash || !rawdb.HasBody(chaindb, header.Hash, header.Number) {
+		log.Warn("Block of the imported state is not available, chain head is not updated", "number", header.Number, "hash", header.Hash)
+		return nil
+	}
+	if block := rawdb.ReadHeader(chaindb, header.Hash, header.Number); block.Root != header.Root {
+		return fmt.Errorf("state root mismatch with block #%d, want %x, got %x", header.Number, block.Root, header.Root)
+	}
+	rawdb.WriteHeadBlockHash(chaindb, header.Hash)
+	rawdb.WriteHeadFastBlockHash(chaindb, header.Hash)
+	if head := rawdb.ReadHeadHeader(chaindb); head == nil || head.Number.Uint64() < header.Number {
+		rawdb.WriteHeadHeaderHash(chaindb, header.Hash)
+	}
+	log.Info("Moved chain head to the imported state", "number", header.Number, "hash", header.Hash)
+	return nil
+}
@@ -55,6 +55,7 @@ import (
 	"github.com/luxfi/geth/eth/syncer"
 	"github.com/luxfi/geth/eth/tracers"
 	"github.com/luxfi/geth/ethdb"
+	"github.com/luxfi/geth/ethdb/encdb"
 	"github.com/luxfi/geth/ethdb/remotedb"
 	"github.com/luxfi/geth/ethstats"
 	"github.com/luxfi/geth/graphql"
@@ -102,10 +103,25 @@ var (
 	}
 	DBEngineFlag = &cli.StringFlag{
 		Name:     "db.engine",
-		Usage:    "Backing database implementation to use ('pebble' or 'leveldb')",
+		Usage:    "Backing database implementation to use ('pebble', 'leveldb' or 'badger')",
 		Value:    node.DefaultConfig.DBEngine,
 		Category: flags.EthCategory,
 	}
+	DBEncryptionKeyFileFlag = &cli.StringFlag{
+		Name:     "db.encryption.keyfile",
+		Usage:    "File holding the hex-encoded 32-byte key to encrypt the databases at rest with (alternatively set " + encdb.KeyEnvVar + ")",
+		Category: flags.EthCategory,
+	}
+	DBEncryptKeysFlag = &cli.BoolFlag{
+		Name:     "db.encryption.keys",
+		Usage:    "Encrypt keys as well as values when creating an encrypted database (reveals the order and common prefixes of keys)",
+		Category: flags.EthCategory,
+	}
+	DBServeFlag = &cli.StringFlag{
+		Name:     "db.serve",
+		Usage:    "Serve the chain database read-only to other local processes over a unix socket at this path (relative paths are inside the datadir)",
+		Category: flags.EthCategory,
+	}
 	AncientFlag = &flags.DirectoryFlag{
 		Name:     "datadir.ancient",
 		Usage:    "Root directory for ancient data (default = inside chaindata)",
@@ -253,6 +269,11 @@ var (
 		Usage:    "Manually specify the Verkle fork timestamp, overriding the bundled setting",
 		Category: flags.EthCategory,
 	}
+	OverridePostQuantum = &cli.Uint64Flag{
+		Name:     "override.postquantum",
+		Usage:    "Manually specify the Lux PostQuantum fork timestamp, overriding the bundled setting",
+		Category: flags.EthCategory,
+	}
 	SyncModeFlag = &cli.StringFlag{
 		Name:     "syncmode",
 		Usage:    `Blockchain sync mode ("snap" or "full")`,
@@ -276,6 +297,11 @@ var (
 		Value:    ethconfig.Defaults.StateHistory,
 		Category: flags.StateCategory,
 	}
+	ProofHistoryFlag = &cli.Uint64Flag{
+		Name:     "history.state.proofs",
+		Usage:    "Number of state histories that may be reverted to serve eth_getProof for historical blocks, only relevant in state.scheme=path with gcmode=archive (0 = disabled)",
+		Category: flags.StateCategory,
+	}
 	TransactionHistoryFlag = &cli.Uint64Flag{
 		Name:     "history.transactions",
 		Usage:    "Number of recent blocks to maintain transactions index for (default = about one year, 0 = entire chain)",
@@ -284,10 +310,16 @@ var (
 	}
 	ChainHistoryFlag = &cli.StringFlag{
 		Name:     "history.chain",
-		Usage:    `Blockchain history retention ("all" or "postmerge")`,
+		Usage:    `Blockchain history retention ("all", "postmerge" or "recent")`,
 		Value:    ethconfig.Defaults.HistoryMode.String(),
 		Category: flags.StateCategory,
 	}
+	ChainHistoryWindowFlag = &cli.Uint64Flag{
+		Name:     "history.chain.window",
+		Usage:    "Number of recent blocks to retain chain history for, only relevant in history.chain=recent",
+		Value:    ethconfig.Defaults.HistoryWindow,
+		Category: flags.StateCategory,
+	}
 	LogHistoryFlag = &cli.Uint64Flag{
 		Name:     "history.logs",
 		Usage:    "Number of recent blocks to maintain log search index for (default = about one year, 0 = entire chain)",
@@ -821,6 +853,11 @@ var (
 		Category: flags.NetworkingCategory,
 		Value:    true,
 	}
+	PostQuantumHandshakeFlag = &cli.BoolFlag{
+		Name:     "pqhandshake",
+		Usage:    "Enables the hybrid post-quantum (ML-KEM-768) RLPx handshake with peers that support it",
+		Category: flags.NetworkingCategory,
+	}
 	NetrestrictFlag = &cli.StringFlag{
 		Name:     "netrestrict",
 		Usage:    "Restricts network communication to the given IP networks (CIDR masks)",
@@ -985,6 +1022,8 @@ var (
 		EraFlag,
 		RemoteDBFlag,
 		DBEngineFlag,
+		DBEncryptionKeyFileFlag,
+		DBEncryptKeysFlag,
 		StateSchemeFlag,
 		HttpHeaderFlag,
 	}
@@ -1329,6 +1368,10 @@ func SetP2PConfig(ctx *cli.Context, cfg *p2p.Config) {
 	cfg.DiscoveryV4 = ctx.Bool(DiscoveryV4Flag.Name)
 	cfg.DiscoveryV5 = ctx.Bool(DiscoveryV5Flag.Name)
 
+	if ctx.IsSet(PostQuantumHandshakeFlag.Name) {
+		cfg.PostQuantumHandshake = ctx.Bool(PostQuantumHandshakeFlag.Name)
+	}
+
 	if netrestrict := ctx.String(NetrestrictFlag.Name); netrestrict != "" {
 		list, err := netutil.ParseNetlist(netrestrict)
 		if err != nil {
@@ -1389,12 +1432,13 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	}
 	if ctx.IsSet(DBEngineFlag.Name) {
 		dbEngine := ctx.String(DBEngineFlag.Name)
-		if dbEngine != "leveldb" && dbEngine != "pebble" {
-			Fatalf("Invalid choice for db.engine '%s', allowed 'leveldb' or 'pebble'", dbEngine)
+		if dbEngine != "leveldb" && dbEngine != "pebble" && dbEngine != "badger" {
+			Fatalf("Invalid choice for db.engine '%s', allowed 'leveldb', 'pebble' or 'badger'", dbEngine)
 		}
 		log.Info(fmt.Sprintf("Using %s as db engine", dbEngine))
 		cfg.DBEngine = dbEngine
 	}
+	setDBEncryption(ctx, cfg)
 	// deprecation notice for log debug flags (TODO: find a more appropriate place to put these?)
 	if ctx.IsSet(LogBacktraceAtFlag.Name) {
 		log.Warn("Option --log.backtrace flag is deprecated")
@@ -1424,6 +1468,24 @@ func setSmartCard(ctx *cli.Context, cfg *node.Config) {
 	cfg.SmartCardDaemonPath = path
 }
 
+// setDBEncryption loads the database encryption key from the key file given
+// by the flag or from the environment.
+func setDBEncryption(ctx *cli.Context, cfg *node.Config) {
+	var err error
+	switch {
+	case ctx.IsSet(DBEncryptionKeyFileFlag.Name):
+		cfg.DBEncryptionKey, err = encdb.LoadKey(ctx.String(DBEncryptionKeyFileFlag.Name))
+	case os.Getenv(encdb.KeyEnvVar) != "":
+		cfg.DBEncryptionKey, err = encdb.ParseKey(os.Getenv(encdb.KeyEnvVar))
+	}
+	if err != nil {
+		Fatalf("Failed to load database encryption key: %v", err)
+	}
+	if ctx.IsSet(DBEncryptKeysFlag.Name) {
+		cfg.DBEncryptKeys = ctx.Bool(DBEncryptKeysFlag.Name)
+	}
+}
+
 func SetDataDir(ctx *cli.Context, cfg *node.Config) {
 	switch {
 	case ctx.IsSet(DataDirFlag.Name):
@@ -1608,6 +1670,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 			Fatalf("--%s: %v", ChainHistoryFlag.Name, err)
 		}
 	}
+	if ctx.IsSet(ChainHistoryWindowFlag.Name) {
+		cfg.HistoryWindow = ctx.Uint64(ChainHistoryWindowFlag.Name)
+	}
 
 	if ctx.IsSet(NetworkIdFlag.Name) {
 		cfg.NetworkId = ctx.Uint64(NetworkIdFlag.Name)
@@ -1641,6 +1706,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(StateHistoryFlag.Name) {
 		cfg.StateHistory = ctx.Uint64(StateHistoryFlag.Name)
 	}
+	if ctx.IsSet(ProofHistoryFlag.Name) {
+		cfg.ProofHistory = ctx.Uint64(ProofHistoryFlag.Name)
+	}
 	if ctx.IsSet(StateSchemeFlag.Name) {
 		cfg.StateScheme = ctx.String(StateSchemeFlag.Name)
 	}
@@ -1671,6 +1739,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(LogExportCheckpointsFlag.Name) {
 		cfg.LogExportCheckpoints = ctx.String(LogExportCheckpointsFlag.Name)
 	}
+	if ctx.IsSet(DBServeFlag.Name) {
+		cfg.DatabaseServe = stack.ResolvePath(ctx.String(DBServeFlag.Name))
+	}
 	if ctx.IsSet(CacheFlag.Name) || ctx.IsSet(CacheTrieFlag.Name) {
 		cfg.TrieCleanCache = ctx.Int(CacheFlag.Name) * ctx.Int(CacheTrieFlag.Name) / 100
 	}
@@ -170,6 +170,7 @@ type BlockChainConfig struct {
 
 	Preimages    bool   // Whether to store preimage of trie key to the disk
 	StateHistory uint64 // Number of blocks from head whose state histories are reserved.
+	ProofHistory uint64 // Number of state histories that may be reverted to prove historic states.
 	StateScheme  string // Scheme used to store ethereum states and merkle tree nodes on top
 	ArchiveMode  bool   // Whether to enable the archive mode
 
@@ -182,6 +183,10 @@ type BlockChainConfig struct {
 	// Blocks before this number may be unavailable in the chain database.
 	ChainHistoryMode history.HistoryMode
 
+	// ChainHistoryWindow is the number of recent blocks whose bodies and
+	// receipts are kept in the history.KeepRecent mode.
+	ChainHistoryWindow uint64
+
 	// Misc options
 	NoPrefetch bool            // Whether to disable heuristic state prefetching when processing blocks
 	Overrides  *ChainOverrides // Optional chain config overrides
@@ -248,6 +253,7 @@ func (cfg *BlockChainConfig) triedbConfig(isVerkle bool) *triedb.Config {
 			TrieCleanSize:       cfg.TrieCleanLimit * 1024 * 1024,
 			StateCleanSize:      cfg.SnapshotLimit * 1024 * 1024,
 			JournalDirectory:    cfg.TrieJournalDirectory,
+			ProofHistory:        cfg.ProofHistory,
 
 			// TODO(rjl493456442): The write buffer represents the memory limit used
 			// for flushing both trie data and state data to disk. The config name
@@ -293,6 +299,7 @@ type BlockChain struct {
 	triedb        *triedb.Database                 // The database handler for maintaining trie nodes.
 	statedb       *state.CachingDB                 // State database to reuse between imports (contains state cache)
 	txIndexer     *txIndexer                       // Transaction indexer, might be nil if not enabled
+	historyPruner *historyPruner                   // Rolling history pruner, nil unless history.KeepRecent
 
 	hc               *HeaderChain
 	rmLogsFeed       event.Feed
@@ -521,7 +528,17 @@ func NewBlockChain(db ethdb.Database, genesis *Genesis, engine consensus.Engine,
 
 	// Start tx indexer if it's enabled.
 	if bc.cfg.TxLookupLimit >= 0 {
-		bc.txIndexer = newTxIndexer(uint64(bc.cfg.TxLookupLimit), bc)
+		limit := uint64(bc.cfg.TxLookupLimit)
+		if bc.cfg.ChainHistoryMode == history.KeepRecent && (limit == 0 || limit > bc.cfg.ChainHistoryWindow) {
+			// Transactions can't be looked up beyond the retained history
+			log.Info("Limiting transaction index to history window", "window", bc.cfg.ChainHistoryWindow)
+			limit = bc.cfg.ChainHistoryWindow
+		}
+		bc.txIndexer = newTxIndexer(limit, bc)
+	}
+	// Start the rolling history pruner if it's enabled.
+	if bc.cfg.ChainHistoryMode == history.KeepRecent {
+		bc.historyPruner = newHistoryPruner(bc.cfg.ChainHistoryWindow, bc)
 	}
 	return bc, nil
 }
@@ -708,6 +725,21 @@ func (bc *BlockChain) initializeHistoryPruning(latest uint64) error {
 		bc.historyPrunePoint.Store(predefinedPoint)
 		return nil
 
+	case history.KeepRecent:
+		if bc.cfg.ChainHistoryWindow == 0 {
+			return errors.New("chain history window must be non-zero")
+		}
+		// Any tail is acceptable, the history is pruned continuously.
+		if freezerTail == 0 {
+			bc.historyPrunePoint.Store(nil)
+			return nil
+		}
+		bc.historyPrunePoint.Store(&history.PrunePoint{
+			BlockNumber: freezerTail,
+			BlockHash:   rawdb.ReadCanonicalHash(bc.db, freezerTail),
+		})
+		return nil
+
 	default:
 		return fmt.Errorf("invalid history mode: %d", bc.cfg.ChainHistoryMode)
 	}
@@ -1243,6 +1275,10 @@ func (bc *BlockChain) stopWithoutSaving() {
 	if bc.txIndexer != nil {
 		bc.txIndexer.close()
 	}
+	// Signal shutdown history pruner.
+	if bc.historyPruner != nil {
+		bc.historyPruner.close()
+	}
 	// Unsubscribe all subscriptions registered from blockchain.
 	bc.scope.Close()
 
@@ -156,10 +156,11 @@ func (f *FilterMaps) WaitIdle() {
 	}
 }
 
-// waitForNewHead blocks until there is a new target head to index and block
-// processing has been finished.
+// waitForNewHead blocks until there is a new target head to index or the history
+// cutoff has moved, and block processing has been finished.
 func (f *FilterMaps) waitForNewHead() {
-	for !f.stop && (f.blockProcessing || f.targetHeadIndexed()) {
+	cutoff := f.historyCutoff
+	for !f.stop && (f.blockProcessing || (f.targetHeadIndexed() && f.historyCutoff == cutoff)) {
 		f.processSingleEvent(true)
 	}
 }
@@ -198,12 +199,14 @@ func (f *FilterMaps) processSingleEvent(blocking bool) bool {
 		case <-f.closeCh:
 			f.stop = true
 		case ch := <-f.waitIdleCh:
+			cutoff := f.historyCutoff
 			select {
 			case target := <-f.targetCh:
 				f.setTarget(target)
 			default:
 			}
-			ch <- !f.blockProcessing && f.targetHeadIndexed()
+			// A moved history cutoff leaves tail maps to unindex.
+			ch <- !f.blockProcessing && f.targetHeadIndexed() && f.historyCutoff == cutoff
 		}
 	} else {
 		select {
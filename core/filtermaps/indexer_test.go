@@ -162,6 +162,32 @@ func TestIndexerRandomRange(t *testing.T) {
 	}
 }
 
+// Tests that the maps of blocks below an advancing history cutoff are unindexed,
+// as done when the chain history is pruned continuously.
+func TestIndexerHistoryCutoff(t *testing.T) {
+	ts := newTestSetup(t)
+	defer ts.close()
+
+	ts.chain.addBlocks(1000, 5, 2, 4, false)
+	ts.setHistory(0, false)
+	ts.fm.WaitIdle()
+	if first := ts.fm.indexedRange.blocks.First(); first != 0 {
+		t.Fatalf("Invalid index tail before pruning (expected #0, got #%d)", first)
+	}
+	head := ts.chain.CurrentBlock()
+	view := NewChainView(ts.chain, head.Number.Uint64(), head.Hash())
+	for _, cutoff := range []uint64{300, 600, 900} {
+		ts.fm.SetTarget(view, cutoff, 0)
+		ts.fm.WaitIdle()
+		if first := ts.fm.indexedRange.blocks.First(); first < cutoff {
+			t.Fatalf("Blocks below history cutoff #%d still indexed (tail #%d)", cutoff, first)
+		}
+		if last := ts.fm.indexedRange.blocks.Last(); last != 1000 {
+			t.Fatalf("Invalid index head (expected #1000, got #%d)", last)
+		}
+	}
+}
+
 func TestIndexerMatcherView(t *testing.T) {
 	testIndexerMatcherView(t, false)
 }
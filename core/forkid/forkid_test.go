@@ -435,3 +435,37 @@ func TestTimeBasedForkInGenesis(t *testing.T) {
 		}
 	}
 }
+
+// Tests that the Lux PostQuantum fork timestamp is included in the fork ID, so
+// nodes disagreeing on the post-quantum precompile set do not peer.
+func TestPostQuantumForkID(t *testing.T) {
+	var (
+		genesis  = types.NewBlockWithHeader(&types.Header{Time: 0})
+		checksum = crc32.ChecksumIEEE(genesis.Hash().Bytes())
+		fork     = uint64(1700000000)
+		config   = *params.MergedTestChainConfig
+	)
+	config.OsakaTime = nil
+	config.PostQuantumTime = &fork
+
+	tests := []struct {
+		time uint64
+		want ID
+	}{
+		// Before the fork, the next fork is the post-quantum one
+		{fork - 1, ID{Hash: checksumToBytes(checksum), Next: fork}},
+
+		// After the fork, its timestamp is part of the hash
+		{fork, ID{Hash: checksumToBytes(checksumUpdate(checksum, fork)), Next: 0}},
+	}
+	for i, tt := range tests {
+		if have := NewID(&config, genesis, 0, tt.time); have != tt.want {
+			t.Errorf("test %d: fork ID mismatch: have %x, want %x", i, have, tt.want)
+		}
+	}
+	// Dropping the fork from the config must change the fork ID
+	config.PostQuantumTime = nil
+	if have := NewID(&config, genesis, 0, fork); have == tests[1].want {
+		t.Errorf("fork ID unchanged without post-quantum fork: %x", have)
+	}
+}
@@ -258,8 +258,9 @@ func (e *GenesisMismatchError) Error() string {
 
 // ChainOverrides contains the changes to chain config.
 type ChainOverrides struct {
-	OverrideOsaka  *uint64
-	OverrideVerkle *uint64
+	OverrideOsaka       *uint64
+	OverrideVerkle      *uint64
+	OverridePostQuantum *uint64
 }
 
 // apply applies the chain overrides on the supplied chain config.
@@ -273,6 +274,9 @@ func (o *ChainOverrides) apply(cfg *params.ChainConfig) error {
 	if o.OverrideVerkle != nil {
 		cfg.VerkleTime = o.OverrideVerkle
 	}
+	if o.OverridePostQuantum != nil {
+		cfg.PostQuantumTime = o.OverridePostQuantum
+	}
 	return cfg.CheckConfigForkOrder()
 }
 
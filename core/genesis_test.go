@@ -252,6 +252,49 @@ func TestReadWriteGenesisAlloc(t *testing.T) {
 	}
 }
 
+// Tests that the Lux PostQuantum fork timestamp survives a round trip through
+// the genesis JSON, the database and the chain overrides.
+func TestGenesisPostQuantumJSON(t *testing.T) {
+	config := *params.MergedTestChainConfig
+	config.PostQuantumTime = new(uint64)
+	*config.PostQuantumTime = 1000
+
+	blob, err := json.Marshal(&Genesis{Config: &config, BaseFee: big.NewInt(params.InitialBaseFee), Difficulty: big.NewInt(0), Alloc: types.GenesisAlloc{}})
+	if err != nil {
+		t.Fatalf("failed to marshal genesis: %v", err)
+	}
+	if !bytes.Contains(blob, []byte(`"postQuantumTime":1000`)) {
+		t.Fatalf("post-quantum fork missing from genesis JSON: %s", blob)
+	}
+	var genesis Genesis
+	if err := json.Unmarshal(blob, &genesis); err != nil {
+		t.Fatalf("failed to unmarshal genesis: %v", err)
+	}
+	if genesis.Config.PostQuantumTime == nil || *genesis.Config.PostQuantumTime != 1000 {
+		t.Fatalf("post-quantum fork mismatch: have %v, want 1000", genesis.Config.PostQuantumTime)
+	}
+	db := rawdb.NewMemoryDatabase()
+	stored, hash, _, err := SetupGenesisBlock(db, triedb.NewDatabase(db, triedb.HashDefaults), &genesis)
+	if err != nil {
+		t.Fatalf("failed to setup genesis: %v", err)
+	}
+	if stored.PostQuantumTime == nil || *stored.PostQuantumTime != 1000 {
+		t.Fatalf("stored post-quantum fork mismatch: have %v, want 1000", stored.PostQuantumTime)
+	}
+	if reload := rawdb.ReadChainConfig(db, hash); reload.PostQuantumTime == nil || *reload.PostQuantumTime != 1000 {
+		t.Fatalf("reloaded post-quantum fork mismatch: have %v, want 1000", reload.PostQuantumTime)
+	}
+	// Overriding the fork before it activated must be accepted
+	override := uint64(2000)
+	stored, _, _, err = SetupGenesisBlockWithOverride(db, triedb.NewDatabase(db, triedb.HashDefaults), &genesis, &ChainOverrides{OverridePostQuantum: &override})
+	if err != nil {
+		t.Fatalf("failed to setup genesis with override: %v", err)
+	}
+	if stored.PostQuantumTime == nil || *stored.PostQuantumTime != override {
+		t.Fatalf("overridden post-quantum fork mismatch: have %v, want %d", stored.PostQuantumTime, override)
+	}
+}
+
 func newDbConfig(scheme string) *triedb.Config {
 	if scheme == rawdb.HashScheme {
 		return triedb.HashDefaults
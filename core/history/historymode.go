@@ -32,10 +32,18 @@ const (
 
 	// KeepPostMerge sets the history pruning point to the merge activation block.
 	KeepPostMerge
+
+	// KeepRecent keeps a rolling window of recent blocks, continuously pruning
+	// the older history as the chain advances.
+	KeepRecent
 )
 
+// DefaultWindow is the default number of recent blocks whose history is kept
+// in the KeepRecent mode, about three months of 12 second blocks.
+const DefaultWindow = 648_000
+
 func (m HistoryMode) IsValid() bool {
-	return m <= KeepPostMerge
+	return m <= KeepRecent
 }
 
 func (m HistoryMode) String() string {
@@ -44,6 +52,8 @@ func (m HistoryMode) String() string {
 		return "all"
 	case KeepPostMerge:
 		return "postmerge"
+	case KeepRecent:
+		return "recent"
 	default:
 		return fmt.Sprintf("invalid HistoryMode(%d)", m)
 	}
@@ -64,8 +74,10 @@ func (m *HistoryMode) UnmarshalText(text []byte) error {
 		*m = KeepAll
 	case "postmerge":
 		*m = KeepPostMerge
+	case "recent":
+		*m = KeepRecent
 	default:
-		return fmt.Errorf(`unknown sync mode %q, want "all" or "postmerge"`, text)
+		return fmt.Errorf(`unknown sync mode %q, want "all", "postmerge" or "recent"`, text)
 	}
 	return nil
 }
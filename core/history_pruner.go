@@ -0,0 +1,129 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"github.com/luxfi/geth/core/history"
+	"github.com/luxfi/geth/core/rawdb"
+	"github.com/luxfi/geth/ethdb"
+	"github.com/luxfi/geth/log"
+)
+
+// historyPruneStep is the minimum number of blocks the history is pruned by at
+// once, avoiding a freezer tail truncation for every new block.
+const historyPruneStep = 128
+
+// historyPruner is the module responsible for continuously pruning the chain
+// history in the history.KeepRecent mode, retaining the bodies and receipts of
+// the latest N blocks only.
+type historyPruner struct {
+	// window is the number of blocks from head whose history is retained,
+	// i.e. the history of the blocks [HEAD-N+1, HEAD] is kept.
+	window uint64
+
+	chain  *BlockChain
+	db     ethdb.Database
+	term   chan chan struct{}
+	closed chan struct{}
+}
+
+// newHistoryPruner initializes the rolling history pruner.
+func newHistoryPruner(window uint64, chain *BlockChain) *historyPruner {
+	pruner := &historyPruner{
+		window: window,
+		chain:  chain,
+		db:     chain.db,
+		term:   make(chan chan struct{}),
+		closed: make(chan struct{}),
+	}
+	go pruner.loop()
+
+	log.Info("Initialized history pruner", "window", window)
+	return pruner
+}
+
+// prune truncates the history below the retention window of the given head.
+// Only frozen blocks are pruned, and never the blocks whose transactions are
+// still indexed, so that the tx indexer is able to unindex them later.
+func (pruner *historyPruner) prune(head uint64) {
+	if head+1 <= pruner.window {
+		return
+	}
+	target := head + 1 - pruner.window
+
+	frozen, err := pruner.db.Ancients()
+	if err != nil {
+		return // no freezer, nothing to prune
+	}
+	target = min(target, frozen)
+	if tail := rawdb.ReadTxIndexTail(pruner.db); tail != nil {
+		target = min(target, *tail)
+	}
+	current, err := pruner.db.Tail()
+	if err != nil || target < current+historyPruneStep {
+		return
+	}
+	if _, err := pruner.db.TruncateTail(target); err != nil {
+		log.Error("Failed to prune chain history", "target", target, "err", err)
+		return
+	}
+	pruner.chain.historyPrunePoint.Store(&history.PrunePoint{
+		BlockNumber: target,
+		BlockHash:   rawdb.ReadCanonicalHash(pruner.db, target),
+	})
+	log.Debug("Pruned chain history", "earliest", target, "pruned", target-current)
+}
+
+// loop is the scheduler of the pruner, pruning the chain history in the
+// background whenever a new head is received.
+func (pruner *historyPruner) loop() {
+	defer close(pruner.closed)
+
+	var (
+		done   chan struct{} // Non-nil if background routine is active
+		headCh = make(chan ChainHeadEvent)
+		sub    = pruner.chain.SubscribeChainHeadEvent(headCh)
+	)
+	defer sub.Unsubscribe()
+
+	run := func(head uint64) {
+		ch := make(chan struct{})
+		go func() {
+			defer close(ch)
+			pruner.prune(head)
+		}()
+		done = ch
+	}
+	if head := pruner.chain.CurrentBlock(); head != nil {
+		run(head.Number.Uint64())
+	}
+	for {
+		select {
+		case h := <-headCh:
+			if done == nil {
+				run(h.Header.Number.Uint64())
+			}
+
+		case <-done:
+			done = nil
+
+		case ch := <-pruner.term:
+			if done != nil {
+				<-done
+			}
+			close(ch)
+			return
+		}
+	}
+}
+
+// close shuts down the pruner. Safe to be called for multiple times.
+func (pruner *historyPruner) close() {
+	ch := make(chan struct{})
+	select {
+	case pruner.term <- ch:
+		<-ch
+	case <-pruner.closed:
+	}
+}
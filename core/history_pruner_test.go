@@ -0,0 +1,87 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/luxfi/geth/consensus/beacon"
+	"github.com/luxfi/geth/consensus/ethash"
+	"github.com/luxfi/geth/core/history"
+	"github.com/luxfi/geth/core/rawdb"
+	"github.com/luxfi/geth/core/types"
+	"github.com/luxfi/geth/params"
+)
+
+// Tests that the rolling history mode truncates the chain history below the
+// configured window, and that the prune point survives a restart.
+func TestHistoryPrunerKeepRecent(t *testing.T) {
+	const (
+		chainLength = 600
+		window      = 200
+	)
+	var (
+		gspec = &Genesis{
+			Config:  params.TestChainConfig,
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		engine = beacon.New(ethash.NewFaker())
+	)
+	_, blocks, receipts := GenerateChainWithGenesis(gspec, engine, chainLength, nil)
+
+	db, _ := rawdb.Open(rawdb.NewMemoryDatabase(), rawdb.OpenOptions{})
+	defer db.Close()
+
+	options := DefaultConfig().WithStateScheme(rawdb.PathScheme)
+	options.ChainHistoryMode = history.KeepRecent
+	options.ChainHistoryWindow = window
+
+	chain, err := NewBlockChain(db, gspec, engine, options)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	if n, err := chain.InsertReceiptChain(blocks, types.EncodeBlockReceiptLists(receipts), chainLength+1); err != nil {
+		t.Fatalf("failed to insert receipt %d: %v", n, err)
+	}
+	chain.historyPruner.prune(chainLength)
+
+	earliest := uint64(chainLength + 1 - window)
+	if tail, _ := db.Tail(); tail != earliest {
+		t.Fatalf("unexpected freezer tail: have %d, want %d", tail, earliest)
+	}
+	if cutoff, hash := chain.HistoryPruningCutoff(); cutoff != earliest || hash != blocks[earliest-1].Hash() {
+		t.Fatalf("unexpected cutoff: have %d (%x), want %d", cutoff, hash, earliest)
+	}
+	for _, block := range blocks {
+		num, hash := block.NumberU64(), block.Hash()
+		if chain.GetHeaderByNumber(num) == nil {
+			t.Fatalf("block #%d: missing header", num)
+		}
+		if have := chain.GetBody(hash) != nil; have != (num >= earliest) {
+			t.Fatalf("block #%d: body available %t, cutoff %d", num, have, earliest)
+		}
+	}
+	// Pruning again within the step is a no-op
+	chain.historyPruner.prune(chainLength + historyPruneStep - 1)
+	if tail, _ := db.Tail(); tail != earliest {
+		t.Fatalf("unexpected freezer tail: have %d, want %d", tail, earliest)
+	}
+	chain.Stop()
+
+	// Restart with an unlimited tx index, which is clamped to the window
+	options.TxLookupLimit = 0
+	chain, err = NewBlockChain(db, gspec, engine, options)
+	if err != nil {
+		t.Fatalf("failed to reopen chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if cutoff, _ := chain.HistoryPruningCutoff(); cutoff != earliest {
+		t.Fatalf("unexpected cutoff after restart: have %d, want %d", cutoff, earliest)
+	}
+	if chain.txIndexer.limit != window {
+		t.Fatalf("unexpected tx index limit: have %d, want %d", chain.txIndexer.limit, window)
+	}
+}
@@ -17,6 +17,7 @@
 package rawdb
 
 import (
+	"crypto/cipher"
 	"path/filepath"
 
 	"github.com/luxfi/geth/ethdb"
@@ -50,8 +51,9 @@ var chainFreezerTableConfigs = map[string]freezerTableConfig{
 
 // freezerTableConfig contains the settings for a freezer table.
 type freezerTableConfig struct {
-	noSnappy bool // disables item compression
-	prunable bool // true for tables that can be pruned by TruncateTail
+	noSnappy bool        // disables item compression
+	prunable bool        // true for tables that can be pruned by TruncateTail
+	cipher   cipher.AEAD // encrypts the items at rest if set
 }
 
 const (
@@ -90,8 +92,8 @@ var freezers = []string{ChainFreezerName, MerkleStateFreezerName, VerkleStateFre
 //   - if the empty directory is given, initializes the pure in-memory
 //     state freezer (e.g. dev mode).
 //   - if non-empty directory is given, initializes the regular file-based
-//     state freezer.
-func NewStateFreezer(ancientDir string, verkle bool, readOnly bool) (ethdb.ResettableAncientStore, error) {
+//     state freezer, encrypting its items at rest if aead is set.
+func NewStateFreezer(ancientDir string, verkle bool, readOnly bool, aead cipher.AEAD) (ethdb.ResettableAncientStore, error) {
 	if ancientDir == "" {
 		return NewMemoryFreezer(readOnly, stateFreezerTableConfigs), nil
 	}
@@ -101,5 +103,5 @@ func NewStateFreezer(ancientDir string, verkle bool, readOnly bool) (ethdb.Reset
 	} else {
 		name = filepath.Join(ancientDir, MerkleStateFreezerName)
 	}
-	return newResettableFreezer(name, "eth/db/state", readOnly, stateHistoryTableSize, stateFreezerTableConfigs)
+	return newResettableFreezer(name, "eth/db/state", readOnly, stateHistoryTableSize, encryptedTables(stateFreezerTableConfigs, aead))
 }
@@ -91,9 +91,9 @@ func inspectFreezers(db ethdb.Database) ([]freezerInfo, error) {
 		case MerkleStateFreezerName, VerkleStateFreezerName:
 			datadir, err := db.AncientDatadir()
 			if err != nil {
-				return nil, err
+				continue // the state freezer is only accessible locally
 			}
-			f, err := NewStateFreezer(datadir, freezer == VerkleStateFreezerName, true)
+			f, err := NewStateFreezer(datadir, freezer == VerkleStateFreezerName, true, nil)
 			if err != nil {
 				continue // might be possible the state freezer is not existent
 			}
@@ -0,0 +1,318 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/luxfi/geth/common"
+	"github.com/luxfi/geth/ethdb"
+	"github.com/luxfi/geth/log"
+)
+
+const (
+	// BackupManifestName is the name of the file describing a backup.
+	BackupManifestName = "BACKUP.json"
+
+	// BackupKeyValueDir is the directory of the key-value store checkpoint
+	// inside a backup.
+	BackupKeyValueDir = "chaindata"
+
+	// BackupAncientDir is the directory of the freezer copy inside a backup.
+	BackupAncientDir = "ancient"
+
+	// backupVersion is the current version of the backup layout.
+	backupVersion = 1
+
+	// freezerCopyRetries is the number of times copying a freezer table is
+	// attempted while its tail is being truncated.
+	freezerCopyRetries = 3
+)
+
+// BackupManifest describes a database backup created by Backup.
+type BackupManifest struct {
+	Version    uint        `json:"version"`
+	Engine     string      `json:"engine"`     // Engine of the key-value store checkpoint
+	Time       uint64      `json:"time"`       // Unix time at which the backup was started
+	Genesis    common.Hash `json:"genesis"`    // Genesis block hash
+	HeadHash   common.Hash `json:"headHash"`   // Head block hash when the checkpoint was taken
+	HeadNumber uint64      `json:"headNumber"` // Head block number when the checkpoint was taken
+	Ancients   uint64      `json:"ancients"`   // Minimum number of items in the chain freezer copy
+	HasAncient bool        `json:"hasAncient"` // Whether the backup contains a freezer copy
+}
+
+// ReadBackupManifest reads the manifest of the backup in dir.
+func ReadBackupManifest(dir string) (*BackupManifest, error) {
+	blob, err := os.ReadFile(filepath.Join(dir, BackupManifestName))
+	if err != nil {
+		return nil, err
+	}
+	var manifest BackupManifest
+	if err := json.Unmarshal(blob, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid backup manifest: %v", err)
+	}
+	if manifest.Version != backupVersion {
+		return nil, fmt.Errorf("unsupported backup version %d", manifest.Version)
+	}
+	return &manifest, nil
+}
+
+// Backup creates a crash-consistent backup of db in dir, which must not exist
+// yet: a checkpoint of the key-value store in dir/chaindata and a copy of the
+// freezer in dir/ancient. The database may be written to during the backup.
+//
+// The freezer is flushed before the checkpoint is taken and copied afterwards,
+// so every chain segment missing from the checkpoint because it was moved to
+// the freezer is part of the freezer copy. The copy may hold a few more items
+// than the checkpoint expects, which is the same state as after a crash in the
+// middle of freezing and is handled when the backup is opened.
+func Backup(db ethdb.Database, dir string) (*BackupManifest, error) {
+	cp, ok := db.(ethdb.Checkpointer)
+	if !ok {
+		return nil, errors.New("database does not support checkpoints")
+	}
+	if common.FileExist(dir) {
+		return nil, fmt.Errorf("backup directory %s already exists", dir)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	ancient, err := db.AncientDatadir()
+	hasAncient := err == nil && ancient != ""
+	manifest := &BackupManifest{
+		Version:    backupVersion,
+		Time:       uint64(time.Now().Unix()),
+		Genesis:    ReadCanonicalHash(db, 0),
+		HasAncient: hasAncient,
+	}
+	if hasAncient {
+		if err := db.SyncAncient(); err != nil {
+			return nil, err
+		}
+		if manifest.Ancients, err = db.Ancients(); err != nil {
+			return nil, err
+		}
+	}
+	start := time.Now()
+	manifest.HeadHash = ReadHeadBlockHash(db)
+	if number, ok := ReadHeaderNumber(db, manifest.HeadHash); ok {
+		manifest.HeadNumber = number
+	}
+	kvdir := filepath.Join(dir, BackupKeyValueDir)
+	if err := cp.Checkpoint(kvdir); err != nil {
+		return nil, fmt.Errorf("failed to checkpoint key-value store: %v", err)
+	}
+	manifest.Engine = PreexistingDatabase(kvdir)
+	log.Info("Checkpointed key-value store", "engine", manifest.Engine, "elapsed", common.PrettyDuration(time.Since(start)))
+
+	if hasAncient {
+		start = time.Now()
+		if err := copyFreezerDir(ancient, filepath.Join(dir, BackupAncientDir)); err != nil {
+			return nil, fmt.Errorf("failed to copy freezer: %v", err)
+		}
+		log.Info("Copied freezer", "items", manifest.Ancients, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	blob, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, BackupManifestName), blob, 0644); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// copyFreezerDir copies all freezers below src into dst while they may be
+// written to. Freezer tables are copied with copyFreezerTable, any other file
+// is copied as is. The freezer lock files are skipped.
+func copyFreezerDir(src, dst string) error {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return err
+	}
+	// Collect the tables first, their files are copied together
+	tables := make(map[string]bool)
+	for _, entry := range entries {
+		name := entry.Name()
+		if ext := filepath.Ext(name); ext == ".ridx" || ext == ".cidx" {
+			tables[strings.TrimSuffix(name, ext)] = ext == ".cidx"
+		}
+	}
+	for table, compressed := range tables {
+		if err := copyFreezerTable(src, dst, table, compressed); err != nil {
+			return fmt.Errorf("table %s: %v", table, err)
+		}
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			if err := copyFreezerDir(filepath.Join(src, name), filepath.Join(dst, name)); err != nil {
+				return err
+			}
+			continue
+		}
+		if name == "FLOCK" {
+			continue
+		}
+		if _, ok := tables[freezerTableOf(name)]; ok {
+			continue
+		}
+		if err := copyFile(filepath.Join(src, name), filepath.Join(dst, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// freezerTableOf returns the name of the freezer table the given file belongs
+// to, or an empty string if it's not a freezer table file.
+func freezerTableOf(file string) string {
+	switch ext := filepath.Ext(file); ext {
+	case ".ridx", ".cidx", ".meta":
+		return strings.TrimSuffix(file, ext)
+	case ".rdat", ".cdat":
+		file = strings.TrimSuffix(file, ext)
+		return strings.TrimSuffix(file, filepath.Ext(file))
+	}
+	return ""
+}
+
+// copyFreezerTable copies the files of a single freezer table that may be
+// appended to or tail-truncated concurrently.
+//
+// The metadata is copied before the index and the index before the data files,
+// matching the reverse of the order in which the freezer persists them, so the
+// copied index never references data missing from the copy. Data files
+// deleted by a tail truncation during the copy cause the table to be copied
+// again.
+func copyFreezerTable(src, dst, name string, compressed bool) error {
+	idxExt, datExt := "ridx", "rdat"
+	if compressed {
+		idxExt, datExt = "cidx", "cdat"
+	}
+	var err error
+	for i := 0; i < freezerCopyRetries; i++ {
+		if err = copyFreezerTableOnce(src, dst, name, idxExt, datExt); !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		log.Debug("Freezer table truncated during copy, retrying", "table", name, "err", err)
+	}
+	return err
+}
+
+func copyFreezerTableOnce(src, dst, name, idxExt, datExt string) error {
+	// Drop the leftovers of a previous attempt
+	stale, err := filepath.Glob(filepath.Join(dst, name+".*"))
+	if err != nil {
+		return err
+	}
+	for _, file := range stale {
+		if err := os.Remove(file); err != nil {
+			return err
+		}
+	}
+	// Copy the metadata before reading the index and check it again afterwards,
+	// a change of the tail or a decreasing flush offset means the table was
+	// truncated in between.
+	var (
+		meta     = filepath.Join(src, fmt.Sprintf("%s.meta", name))
+		metaCopy = filepath.Join(dst, fmt.Sprintf("%s.meta", name))
+	)
+	if err := copyFile(meta, metaCopy); err != nil {
+		return err
+	}
+	before, err := readFreezerTableMeta(metaCopy)
+	if err != nil {
+		return err
+	}
+	index, err := os.ReadFile(filepath.Join(src, fmt.Sprintf("%s.%s", name, idxExt)))
+	if err != nil {
+		return err
+	}
+	after, err := readFreezerTableMeta(meta)
+	if err != nil {
+		return err
+	}
+	if before.virtualTail != after.virtualTail || before.flushOffset > after.flushOffset {
+		return fmt.Errorf("table truncated during copy: %w", os.ErrNotExist)
+	}
+	// Only keep the index items flushed according to the copied metadata, the
+	// others are not guaranteed to be backed by data.
+	index = index[:len(index)-len(index)%indexEntrySize]
+	if before.version >= freezerTableV2 && int64(len(index)) > max(before.flushOffset, indexEntrySize) {
+		index = index[:max(before.flushOffset, indexEntrySize)]
+	}
+	if err := os.WriteFile(filepath.Join(dst, fmt.Sprintf("%s.%s", name, idxExt)), index, 0644); err != nil {
+		return err
+	}
+	if len(index) == 0 {
+		return nil
+	}
+	// The first index entry holds the number of the tail data file, the last
+	// one the number of the head data file.
+	first := binary.BigEndian.Uint16(index[:2])
+	last := binary.BigEndian.Uint16(index[len(index)-indexEntrySize:])
+	for num := first; num <= last; num++ {
+		data := fmt.Sprintf("%s.%04d.%s", name, num, datExt)
+		if err := copyFile(filepath.Join(src, data), filepath.Join(dst, data)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readFreezerTableMeta decodes the metadata file of a freezer table without
+// modifying it.
+func readFreezerTableMeta(path string) (*freezerTableMeta, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if m := decodeV3(file); m != nil {
+		return m, nil
+	}
+	if m := decodeV2(file); m != nil {
+		return m, nil
+	}
+	if m := decodeV1(file); m != nil {
+		return m, nil
+	}
+	return nil, fmt.Errorf("failed to decode metadata %s", path)
+}
+
+// copyFile copies the current content of the file src into dst.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,97 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/luxfi/geth/core/types"
+	"github.com/luxfi/geth/ethdb/pebble"
+)
+
+func TestBackup(t *testing.T) {
+	dir := t.TempDir()
+	kvdb, err := pebble.New(filepath.Join(dir, "chaindata"), 16, 16, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := Open(kvdb, OpenOptions{Ancient: filepath.Join(dir, "ancient")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// Freeze the first half of the chain, keep the rest in the key-value store
+	blocks := makeTestBlocks(100, 1)
+	receipts := makeTestReceipts(100, 1)
+	if _, err := WriteAncientBlocks(db, blocks[:60], types.EncodeBlockReceiptLists(receipts[:60])); err != nil {
+		t.Fatal(err)
+	}
+	for _, block := range blocks[60:] {
+		WriteBlock(db, block)
+		WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+	}
+	head := blocks[len(blocks)-1]
+	WriteHeadBlockHash(db, head.Hash())
+
+	backup := filepath.Join(t.TempDir(), "backup")
+	manifest, err := Backup(db, backup)
+	if err != nil {
+		t.Fatalf("backup failed: %v", err)
+	}
+	if manifest.Engine != DBPebble || manifest.Genesis != blocks[0].Hash() || manifest.HeadHash != head.Hash() || manifest.HeadNumber != head.NumberU64() || manifest.Ancients != 60 {
+		t.Fatalf("unexpected manifest: %+v", manifest)
+	}
+	if _, err := Backup(db, backup); err == nil {
+		t.Fatal("backup into existing directory succeeded")
+	}
+	if loaded, err := ReadBackupManifest(backup); err != nil || *loaded != *manifest {
+		t.Fatalf("manifest mismatch: have %+v, want %+v (err %v)", loaded, manifest, err)
+	}
+	if _, err := os.Stat(filepath.Join(backup, BackupAncientDir, ChainFreezerName, "FLOCK")); !os.IsNotExist(err) {
+		t.Fatalf("freezer lock copied: %v", err)
+	}
+	// Writes after the backup must not show up in it
+	WriteHeadBlockHash(db, blocks[80].Hash())
+
+	kvcopy, err := pebble.New(filepath.Join(backup, BackupKeyValueDir), 16, 16, "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := Open(kvcopy, OpenOptions{Ancient: filepath.Join(backup, BackupAncientDir), ReadOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer restored.Close()
+
+	if ancients, err := restored.Ancients(); err != nil || ancients != 60 {
+		t.Fatalf("wrong number of ancients: %d (err %v)", ancients, err)
+	}
+	if hash := ReadHeadBlockHash(restored); hash != head.Hash() {
+		t.Fatalf("wrong head block: have %x, want %x", hash, head.Hash())
+	}
+	for _, block := range blocks {
+		if have := ReadBlock(restored, block.Hash(), block.NumberU64()); have == nil || have.Hash() != block.Hash() {
+			t.Fatalf("block %d missing from backup", block.NumberU64())
+		}
+	}
+}
+
+func TestFreezerTableOf(t *testing.T) {
+	tests := map[string]string{
+		"headers.cidx":      "headers",
+		"headers.0002.cdat": "headers",
+		"bodies.meta":       "bodies",
+		"hashes.0000.rdat":  "hashes",
+		"FLOCK":             "",
+		"history.json":      "",
+	}
+	for file, want := range tests {
+		if have := freezerTableOf(file); have != want {
+			t.Errorf("%s: have %q, want %q", file, have, want)
+		}
+	}
+}
@@ -17,6 +17,7 @@
 package rawdb
 
 import (
+	"crypto/cipher"
 	"errors"
 	"fmt"
 	"sync"
@@ -60,7 +61,9 @@ type chainFreezer struct {
 //     state freezer (e.g. dev mode).
 //   - if non-empty directory is given, initializes the regular file-based
 //     state freezer.
-func newChainFreezer(datadir string, eraDir string, namespace string, readonly bool) (*chainFreezer, error) {
+//
+// If aead is set, the items of the file-based freezer are encrypted at rest.
+func newChainFreezer(datadir string, eraDir string, namespace string, readonly bool, aead cipher.AEAD) (*chainFreezer, error) {
 	if datadir == "" {
 		return &chainFreezer{
 			ancients: NewMemoryFreezer(readonly, chainFreezerTableConfigs),
@@ -68,7 +71,7 @@ func newChainFreezer(datadir string, eraDir string, namespace string, readonly b
 			trigger:  make(chan chan struct{}),
 		}, nil
 	}
-	freezer, err := NewFreezer(datadir, namespace, readonly, freezerTableSize, chainFreezerTableConfigs)
+	freezer, err := NewFreezer(datadir, namespace, readonly, freezerTableSize, encryptedTables(chainFreezerTableConfigs, aead))
 	if err != nil {
 		return nil, err
 	}
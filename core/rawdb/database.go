@@ -17,6 +17,7 @@
 package rawdb
 
 import (
+	"crypto/cipher"
 	"bytes"
 	"errors"
 	"fmt"
@@ -44,6 +45,7 @@ type freezerdb struct {
 
 	readOnly    bool
 	ancientRoot string
+	cipher      cipher.AEAD
 }
 
 // AncientDatadir returns the path of root ancient directory.
@@ -51,6 +53,11 @@ func (frdb *freezerdb) AncientDatadir() (string, error) {
 	return frdb.ancientRoot, nil
 }
 
+// AncientCipher returns the cipher the freezers are encrypted with, if any.
+func (frdb *freezerdb) AncientCipher() cipher.AEAD {
+	return frdb.cipher
+}
+
 // Close implements io.Closer, closing both the fast key-value store as well as
 // the slow ancient tables.
 func (frdb *freezerdb) Close() error {
@@ -67,6 +74,18 @@ func (frdb *freezerdb) Close() error {
 	return nil
 }
 
+// Checkpoint implements ethdb.Checkpointer, creating a consistent copy of the
+// fast key-value store in dir. The ancient tables are not included.
+func (frdb *freezerdb) Checkpoint(dir string) error {
+	return checkpoint(frdb.KeyValueStore, dir)
+}
+
+// NewSnapshot implements ethdb.Snapshotter, creating a snapshot of the fast
+// key-value store. The ancient tables are not included.
+func (frdb *freezerdb) NewSnapshot() (ethdb.Snapshot, error) {
+	return newSnapshot(frdb.KeyValueStore)
+}
+
 // Freeze is a helper method used for external testing to trigger and block until
 // a freeze cycle completes, without having to sleep for a minute to trigger the
 // automatic background run.
@@ -152,6 +171,34 @@ func (db *nofreezedb) AncientDatadir() (string, error) {
 	return "", errNotSupported
 }
 
+// Checkpoint implements ethdb.Checkpointer, creating a consistent copy of the
+// key-value store in dir.
+func (db *nofreezedb) Checkpoint(dir string) error {
+	return checkpoint(db.KeyValueStore, dir)
+}
+
+// NewSnapshot implements ethdb.Snapshotter, creating a snapshot of the
+// key-value store.
+func (db *nofreezedb) NewSnapshot() (ethdb.Snapshot, error) {
+	return newSnapshot(db.KeyValueStore)
+}
+
+// checkpoint creates a checkpoint of the given key-value store if supported.
+func checkpoint(db ethdb.KeyValueStore, dir string) error {
+	if cp, ok := db.(ethdb.Checkpointer); ok {
+		return cp.Checkpoint(dir)
+	}
+	return errNotSupported
+}
+
+// newSnapshot creates a snapshot of the given key-value store if supported.
+func newSnapshot(db ethdb.KeyValueStore) (ethdb.Snapshot, error) {
+	if snapshotter, ok := db.(ethdb.Snapshotter); ok {
+		return snapshotter.NewSnapshot()
+	}
+	return nil, errNotSupported
+}
+
 // NewDatabase creates a high level database on top of a given key-value data
 // store without a freezer moving immutable chain segments into cold storage.
 func NewDatabase(db ethdb.KeyValueStore) ethdb.Database {
@@ -208,9 +255,10 @@ func NewDatabaseWithFreezer(db ethdb.KeyValueStore, ancient string, namespace st
 
 // OpenOptions specifies options for opening the database.
 type OpenOptions struct {
-	Ancient          string // ancients directory
-	Era              string // era files directory
-	MetricsNamespace string // prefix added to freezer metric names
+	Ancient          string      // ancients directory
+	Era              string      // era files directory
+	MetricsNamespace string      // prefix added to freezer metric names
+	Cipher           cipher.AEAD // encrypts the freezer items at rest if set
 	ReadOnly         bool
 }
 
@@ -223,7 +271,7 @@ func Open(db ethdb.KeyValueStore, opts OpenOptions) (ethdb.Database, error) {
 	if chainFreezerDir != "" {
 		chainFreezerDir = resolveChainFreezerDir(chainFreezerDir)
 	}
-	frdb, err := newChainFreezer(chainFreezerDir, opts.Era, opts.MetricsNamespace, opts.ReadOnly)
+	frdb, err := newChainFreezer(chainFreezerDir, opts.Era, opts.MetricsNamespace, opts.ReadOnly, opts.Cipher)
 	if err != nil {
 		printChainMetadata(db)
 		return nil, err
@@ -321,6 +369,7 @@ func Open(db ethdb.KeyValueStore, opts OpenOptions) (ethdb.Database, error) {
 	}
 	return &freezerdb{
 		ancientRoot:   opts.Ancient,
+		cipher:        opts.Cipher,
 		KeyValueStore: db,
 		chainFreezer:  frdb,
 	}, nil
@@ -335,12 +384,18 @@ func NewMemoryDatabase() ethdb.Database {
 const (
 	DBPebble  = "pebble"
 	DBLeveldb = "leveldb"
+	DBBadger  = "badger"
 )
 
 // PreexistingDatabase checks the given data directory whether a database is already
 // instantiated at that location, and if so, returns the type of database (or the
 // empty string).
 func PreexistingDatabase(path string) string {
+	// Check for BadgerDB, which keeps its encryption key registry next to the
+	// manifest even if encryption is disabled
+	if _, err := os.Stat(filepath.Join(path, "KEYREGISTRY")); err == nil {
+		return DBBadger
+	}
 	// Check for LevelDB/PebbleDB
 	if _, err := os.Stat(filepath.Join(path, "CURRENT")); err != nil {
 		return "" // No pre-existing db
@@ -92,7 +92,7 @@ func (batch *freezerBatch) commit() (item uint64, writeSize int64, err error) {
 type freezerTableBatch struct {
 	t *freezerTable
 
-	sb          *snappyBuffer
+	compressor  itemCompressor
 	encBuffer   writeBuffer
 	dataBuffer  []byte
 	indexBuffer []byte
@@ -103,8 +103,11 @@ type freezerTableBatch struct {
 // newBatch creates a new batch for the freezer table.
 func (t *freezerTable) newBatch() *freezerTableBatch {
 	batch := &freezerTableBatch{t: t}
-	if !t.config.noSnappy {
-		batch.sb = new(snappyBuffer)
+	switch {
+	case t.zstd != nil:
+		batch.compressor = &zstdBuffer{codec: t.zstd}
+	case !t.config.noSnappy:
+		batch.compressor = new(snappyBuffer)
 	}
 	batch.reset()
 	return batch
@@ -132,10 +135,10 @@ func (batch *freezerTableBatch) Append(item uint64, data interface{}) error {
 		return err
 	}
 	encItem := batch.encBuffer.data
-	if batch.sb != nil {
-		encItem = batch.sb.compress(encItem)
+	if batch.compressor != nil {
+		encItem = batch.compressor.compress(encItem)
 	}
-	return batch.appendItem(encItem)
+	return batch.appendEncrypted(item, encItem)
 }
 
 // AppendRaw injects a binary blob at the end of the freezer table. The item number is a
@@ -147,10 +150,22 @@ func (batch *freezerTableBatch) AppendRaw(item uint64, blob []byte) error {
 	}
 
 	encItem := blob
-	if batch.sb != nil {
-		encItem = batch.sb.compress(blob)
+	if batch.compressor != nil {
+		encItem = batch.compressor.compress(blob)
 	}
-	return batch.appendItem(encItem)
+	return batch.appendEncrypted(item, encItem)
+}
+
+// appendEncrypted encrypts the item data if the table is encrypted, and adds
+// it at the end of the table.
+func (batch *freezerTableBatch) appendEncrypted(item uint64, data []byte) error {
+	if batch.t.config.cipher != nil {
+		var err error
+		if data, err = sealFreezerItem(batch.t.config.cipher, batch.t.name, item, data); err != nil {
+			return err
+		}
+	}
+	return batch.appendItem(data)
 }
 
 func (batch *freezerTableBatch) appendItem(data []byte) error {
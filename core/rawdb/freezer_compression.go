@@ -0,0 +1,168 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// freezerCodec identifies the compression codec of a freezer table.
+type freezerCodec uint8
+
+const (
+	// freezerCodecDefault is snappy for compressed tables, or no compression
+	// for tables configured with noSnappy.
+	freezerCodecDefault freezerCodec = iota
+
+	// freezerCodecZstd is zstd, optionally with a trained dictionary.
+	freezerCodecZstd
+)
+
+func (c freezerCodec) String() string {
+	switch c {
+	case freezerCodecDefault:
+		return "snappy"
+	case freezerCodecZstd:
+		return "zstd"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(c))
+	}
+}
+
+// freezerZstdLevel is the zstd compression level of freezer items.
+const freezerZstdLevel = zstd.SpeedBetterCompression
+
+// zstdCodec compresses and decompresses the items of a zstd table. Both the
+// encoder and decoder are safe for concurrent use. As only whole items are
+// processed, they hold no resources besides memory and are never closed, which
+// would race with readers of a table being closed.
+type zstdCodec struct {
+	dictID uint32
+	enc    *zstd.Encoder
+	dec    *zstd.Decoder
+}
+
+// newZstdCodec creates a zstd codec using the given dictionary, which may be
+// nil.
+func newZstdCodec(dict []byte) (*zstdCodec, error) {
+	var (
+		dictID uint32
+		eopts  = []zstd.EOption{zstd.WithEncoderLevel(freezerZstdLevel), zstd.WithEncoderConcurrency(1)}
+		dopts  = []zstd.DOption{zstd.WithDecoderConcurrency(0)}
+	)
+	if dict != nil {
+		info, err := zstd.InspectDictionary(dict)
+		if err != nil {
+			return nil, fmt.Errorf("invalid zstd dictionary: %v", err)
+		}
+		dictID = info.ID()
+		eopts = append(eopts, zstd.WithEncoderDict(dict))
+		dopts = append(dopts, zstd.WithDecoderDicts(dict))
+	}
+	enc, err := zstd.NewWriter(nil, eopts...)
+	if err != nil {
+		return nil, err
+	}
+	dec, err := zstd.NewReader(nil, dopts...)
+	if err != nil {
+		return nil, err
+	}
+	return &zstdCodec{dictID: dictID, enc: enc, dec: dec}, nil
+}
+
+// compress appends the compressed data to dst.
+func (c *zstdCodec) compress(dst, data []byte) []byte {
+	return c.enc.EncodeAll(data, dst)
+}
+
+// decompress decompresses a single item.
+func (c *zstdCodec) decompress(data []byte) ([]byte, error) {
+	return c.dec.DecodeAll(data, nil)
+}
+
+// decodedLen returns the decompressed size of an item, or its compressed size
+// if it is not recorded in the frame header.
+func (c *zstdCodec) decodedLen(data []byte) int {
+	var header zstd.Header
+	if err := header.Decode(data); err != nil || !header.HasFCS {
+		return len(data)
+	}
+	return int(header.FrameContentSize)
+}
+
+// freezerDictFile returns the path of the zstd dictionary of a table.
+func freezerDictFile(path, name string) string {
+	return filepath.Join(path, fmt.Sprintf("%s.zdict", name))
+}
+
+// loadZstdCodec loads the codec of a zstd table, checking that its dictionary
+// matches the one recorded in the metadata.
+func loadZstdCodec(path, name string, dictID uint32) (*zstdCodec, error) {
+	var dict []byte
+	if dictID != 0 {
+		var err error
+		if dict, err = os.ReadFile(freezerDictFile(path, name)); err != nil {
+			return nil, fmt.Errorf("failed to load zstd dictionary: %v", err)
+		}
+	}
+	codec, err := newZstdCodec(dict)
+	if err != nil {
+		return nil, err
+	}
+	if codec.dictID != dictID {
+		return nil, fmt.Errorf("zstd dictionary mismatch: have %d, want %d", codec.dictID, dictID)
+	}
+	return codec, nil
+}
+
+// itemCompressor compresses the items appended to a table into a reusable
+// buffer.
+type itemCompressor interface {
+	compress(data []byte) []byte
+}
+
+// zstdBuffer compresses items with zstd into a reusable buffer.
+type zstdBuffer struct {
+	codec *zstdCodec
+	dst   []byte
+}
+
+// compress zstd-compresses the data.
+func (z *zstdBuffer) compress(data []byte) []byte {
+	z.dst = z.codec.compress(z.dst[:0], data)
+	return z.dst
+}
+
+var errUncompressedTable = errors.New("table is not compressed")
+
+// decodedLen returns the decompressed size of an item of the table.
+func (t *freezerTable) decodedLen(item []byte) int {
+	switch {
+	case t.zstd != nil:
+		return t.zstd.decodedLen(item)
+	case !t.config.noSnappy:
+		n, _ := snappy.DecodedLen(item)
+		return n
+	default:
+		return len(item)
+	}
+}
+
+// decompress decompresses an item of the table.
+func (t *freezerTable) decompress(item []byte) ([]byte, error) {
+	switch {
+	case t.zstd != nil:
+		return t.zstd.decompress(item)
+	case !t.config.noSnappy:
+		return snappy.Decode(nil, item)
+	default:
+		return item, nil
+	}
+}
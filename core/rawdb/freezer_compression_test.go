@@ -0,0 +1,152 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/luxfi/geth/ethdb"
+)
+
+// testCompressibleItem returns an item resembling a receipt, which compresses
+// far better with a dictionary than on its own.
+func testCompressibleItem(kind string, i uint64) []byte {
+	return []byte(fmt.Sprintf(`{"table":%q,"status":"0x1","cumulativeGasUsed":"%#x","logs":[{"address":"0x00000000000000000000000000000000deadbeef",`+
+		`"topics":["0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"],"data":"%#064x","logIndex":"%#x"}]}`, kind, i*21000, i, i%7))
+}
+
+func testRecompress(t *testing.T, aead cipher.AEAD) {
+	var (
+		ancient = t.TempDir()
+		dir     = filepath.Join(ancient, ChainFreezerName)
+		tables  = encryptedTables(chainFreezerTableConfigs, aead)
+		items   = uint64(2000)
+		tail    = uint64(100)
+	)
+	open := func() *Freezer {
+		f, err := NewFreezer(dir, "", false, freezerTableSize, tables)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+	appendItems := func(f *Freezer, from, to uint64) {
+		_, err := f.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+			for i := from; i < to; i++ {
+				for kind := range tables {
+					if err := op.AppendRaw(kind, i, testCompressibleItem(kind, i)); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Only prunable tables are truncated
+	first := func(kind string) uint64 {
+		if tables[kind].prunable {
+			return tail
+		}
+		return 0
+	}
+	check := func(f *Freezer, to uint64) {
+		for kind := range tables {
+			for i := first(kind); i < to; i++ {
+				blob, err := f.Ancient(kind, i)
+				if err != nil {
+					t.Fatalf("table %s item %d: %v", kind, i, err)
+				}
+				if want := testCompressibleItem(kind, i); !bytes.Equal(blob, want) {
+					t.Fatalf("table %s item %d: have %q, want %q", kind, i, blob, want)
+				}
+			}
+			if _, err := f.Ancient(kind, tail-1); err == nil && first(kind) > 0 {
+				t.Fatalf("table %s: deleted item retrievable", kind)
+			}
+		}
+	}
+	f := open()
+	appendItems(f, 0, items)
+	if _, err := f.TruncateTail(tail); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	estimates, err := RecompressFreezer(ancient, ChainFreezerName, nil, aead, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err := RecompressFreezer(ancient, ChainFreezerName, nil, aead, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 3 || len(estimates) != len(stats) {
+		t.Fatalf("wrong number of recompressed tables: %d, %d estimated", len(stats), len(estimates))
+	}
+	for i, stat := range stats {
+		if stat.Codec != "snappy" || stat.Items != items-first(stat.Table) || stat.NewSize >= stat.Size {
+			t.Errorf("unexpected stats: %+v", stat)
+		}
+		if estimates[i].Table != stat.Table || estimates[i].Size != stat.Size {
+			t.Errorf("dry run stats differ: %+v, %+v", estimates[i], stat)
+		}
+	}
+	f = open()
+	check(f, items)
+
+	// New items are appended with zstd as well
+	appendItems(f, items, items+100)
+	check(f, items+100)
+	for _, table := range []string{ChainFreezerBodiesTable, ChainFreezerReceiptTable, ChainFreezerHeaderTable} {
+		if codec := f.tables[table].metadata.codec; codec != freezerCodecZstd {
+			t.Errorf("table %s has codec %v", table, codec)
+		}
+	}
+	if codec := f.tables[ChainFreezerHashTable].metadata.codec; codec != freezerCodecDefault {
+		t.Errorf("uncompressed table has codec %v", codec)
+	}
+	f.Close()
+
+	// Recompressing again retrains the dictionary on all items
+	stats, err = RecompressFreezer(ancient, ChainFreezerName, []string{ChainFreezerReceiptTable}, aead, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 1 || stats[0].Codec != "zstd" || stats[0].Items != items+100-tail {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	f = open()
+	check(f, items+100)
+	f.Close()
+
+	if _, err := RecompressFreezer(ancient, ChainFreezerName, []string{ChainFreezerHashTable}, aead, false); err == nil {
+		t.Fatal("uncompressed table recompressed")
+	}
+}
+
+func TestFreezerRecompress(t *testing.T) {
+	t.Run("plain", func(t *testing.T) { testRecompress(t, nil) })
+	t.Run("encrypted", func(t *testing.T) { testRecompress(t, newTestFreezerCipher(t, 1)) })
+}
+
+func TestReadWriteFreezerTableMetaV3(t *testing.T) {
+	dir := t.TempDir()
+	if err := createZstdTable(dir, "test", 100, 42); err != nil {
+		t.Fatal(err)
+	}
+	meta, err := readFreezerTableMeta(filepath.Join(dir, "test.meta"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.version != freezerTableV3 || meta.virtualTail != 100 || meta.flushOffset != indexEntrySize || meta.codec != freezerCodecZstd || meta.dictID != 42 {
+		t.Fatalf("unexpected metadata: %+v", meta)
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+
+	"github.com/gofrs/flock"
+	"github.com/luxfi/geth/ethdb"
+	"github.com/luxfi/geth/log"
+)
+
+var errFreezerDecrypt = errors.New("failed to decrypt freezer item")
+
+// encryptedTables returns a copy of the table configs with item encryption
+// enabled, or the configs themselves if aead is nil.
+func encryptedTables(tables map[string]freezerTableConfig, aead cipher.AEAD) map[string]freezerTableConfig {
+	if aead == nil {
+		return tables
+	}
+	tables = maps.Clone(tables)
+	for name, config := range tables {
+		config.cipher = aead
+		tables[name] = config
+	}
+	return tables
+}
+
+// freezerItemAD returns the associated data binding an encrypted item to its
+// position, so items can't be swapped within or across tables.
+func freezerItemAD(table string, item uint64) []byte {
+	return binary.BigEndian.AppendUint64([]byte(table), item)
+}
+
+// sealFreezerItem encrypts the (compressed) item data with a random nonce,
+// which is prepended to the result. The encrypted size only depends on the
+// size of the data.
+func sealFreezerItem(aead cipher.AEAD, table string, item uint64, data []byte) ([]byte, error) {
+	out := make([]byte, aead.NonceSize(), aead.NonceSize()+len(data)+aead.Overhead())
+	if _, err := rand.Read(out); err != nil {
+		return nil, err
+	}
+	return aead.Seal(out, out, data, freezerItemAD(table, item)), nil
+}
+
+// openFreezerItem decrypts an item encrypted by sealFreezerItem.
+func openFreezerItem(aead cipher.AEAD, table string, item uint64, data []byte) ([]byte, error) {
+	if len(data) < aead.NonceSize()+aead.Overhead() {
+		return nil, fmt.Errorf("%w %d of table %s", errFreezerDecrypt, item, table)
+	}
+	out, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], freezerItemAD(table, item))
+	if err != nil {
+		return nil, fmt.Errorf("%w %d of table %s", errFreezerDecrypt, item, table)
+	}
+	return out, nil
+}
+
+// AncientCipher returns the cipher the freezers of db are encrypted with, or
+// nil if they are stored in plain.
+func AncientCipher(db ethdb.Database) cipher.AEAD {
+	if c, ok := db.(interface{ AncientCipher() cipher.AEAD }); ok {
+		return c.AncientCipher()
+	}
+	return nil
+}
+
+// RekeyFreezers re-encrypts the items of all freezers in the given root
+// ancient directory from oldCipher to newCipher. The freezers must not be
+// open. As encrypted items keep their size, the data files are rewritten one
+// by one without touching the indexes. Items already encrypted with newCipher
+// are left as they are, so an interrupted run can simply be repeated.
+func RekeyFreezers(ancient string, oldCipher, newCipher cipher.AEAD) error {
+	freezers := []struct {
+		dir    string
+		tables map[string]freezerTableConfig
+	}{
+		{resolveChainFreezerDir(ancient), chainFreezerTableConfigs},
+		{filepath.Join(ancient, MerkleStateFreezerName), stateFreezerTableConfigs},
+		{filepath.Join(ancient, VerkleStateFreezerName), stateFreezerTableConfigs},
+	}
+	for _, freezer := range freezers {
+		if _, err := os.Stat(freezer.dir); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		lock := flock.New(filepath.Join(freezer.dir, "FLOCK"))
+		if locked, err := lock.TryLock(); err != nil {
+			return err
+		} else if !locked {
+			return fmt.Errorf("freezer %s is in use", freezer.dir)
+		}
+		for name, config := range freezer.tables {
+			if err := rekeyFreezerTable(freezer.dir, name, config, oldCipher, newCipher); err != nil {
+				lock.Unlock()
+				return fmt.Errorf("freezer %s, table %s: %v", freezer.dir, name, err)
+			}
+		}
+		lock.Unlock()
+	}
+	return nil
+}
+
+// rekeyFreezerTable re-encrypts the items of a single table.
+func rekeyFreezerTable(dir, name string, config freezerTableConfig, oldCipher, newCipher cipher.AEAD) error {
+	idxExt, datExt := "cidx", "cdat"
+	if config.noSnappy {
+		idxExt, datExt = "ridx", "rdat"
+	}
+	index, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf("%s.%s", name, idxExt)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	entries := make([]indexEntry, len(index)/indexEntrySize)
+	for i := range entries {
+		entries[i].unmarshalBinary(index[i*indexEntrySize:])
+	}
+	if len(entries) < 2 {
+		return nil
+	}
+	// The first entry holds the number of deleted items, the first remaining
+	// item starts at the beginning of its data file.
+	var (
+		first = uint64(entries[0].offset)
+		items []uint64 // item numbers in the current data file
+		ends  []uint32 // end offsets of the items in the current data file
+	)
+	entries[0] = indexEntry{filenum: entries[1].filenum}
+
+	flush := func(filenum uint32) error {
+		if len(items) == 0 {
+			return nil
+		}
+		path := filepath.Join(dir, fmt.Sprintf("%s.%04d.%s", name, filenum, datExt))
+		if err := rekeyFreezerFile(path, name, items, ends, oldCipher, newCipher); err != nil {
+			return err
+		}
+		items, ends = items[:0], ends[:0]
+		return nil
+	}
+	for i := 1; i < len(entries); i++ {
+		if entries[i].filenum != entries[i-1].filenum {
+			if err := flush(entries[i-1].filenum); err != nil {
+				return err
+			}
+		}
+		items = append(items, first+uint64(i-1))
+		ends = append(ends, entries[i].offset)
+	}
+	return flush(entries[len(entries)-1].filenum)
+}
+
+// rekeyFreezerFile rewrites a single data file with the given items, which
+// are stored back to back from the start of the file. Any data after the last
+// item is retained as is.
+func rekeyFreezerFile(path, table string, items []uint64, ends []uint32, oldCipher, newCipher cipher.AEAD) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(path + ".rekey")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(dst.Name())
+	defer dst.Close()
+
+	var (
+		start   uint32
+		rotated int
+	)
+	for i, item := range items {
+		data := make([]byte, ends[i]-start)
+		if _, err := io.ReadFull(src, data); err != nil {
+			return err
+		}
+		start = ends[i]
+
+		if _, err := openFreezerItem(newCipher, table, item, data); err != nil {
+			plain, err := openFreezerItem(oldCipher, table, item, data)
+			if err != nil {
+				return err
+			}
+			if data, err = sealFreezerItem(newCipher, table, item, plain); err != nil {
+				return err
+			}
+			rotated++
+		}
+		if _, err := dst.Write(data); err != nil {
+			return err
+		}
+	}
+	if rotated == 0 {
+		return nil
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		return err
+	}
+	if err := dst.Sync(); err != nil {
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	log.Debug("Re-encrypted freezer data file", "file", path, "items", rotated)
+	return os.Rename(dst.Name(), path)
+}
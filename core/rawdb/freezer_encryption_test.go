@@ -0,0 +1,118 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/luxfi/geth/ethdb"
+)
+
+func newTestFreezerCipher(t *testing.T, seed byte) cipher.AEAD {
+	block, err := aes.NewCipher(bytes.Repeat([]byte{seed}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return aead
+}
+
+func testFreezerItem(kind string, i uint64) []byte {
+	return []byte(fmt.Sprintf("plain %s item %d", kind, i))
+}
+
+// Tests that encrypted freezer items round-trip, are not stored in plain and
+// can be re-encrypted with a new key.
+func TestFreezerEncryption(t *testing.T) {
+	var (
+		ancient   = t.TempDir()
+		dir       = filepath.Join(ancient, ChainFreezerName)
+		oldCipher = newTestFreezerCipher(t, 1)
+		newCipher = newTestFreezerCipher(t, 2)
+		items     = uint64(100)
+		tail      = uint64(10)
+	)
+	open := func(aead cipher.AEAD) *Freezer {
+		f, err := NewFreezer(dir, "", false, 512, encryptedTables(chainFreezerTableConfigs, aead))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+	check := func(f *Freezer) error {
+		for kind := range chainFreezerTableConfigs {
+			for i := tail; i < items; i++ {
+				blob, err := f.Ancient(kind, i)
+				if err != nil {
+					return err
+				}
+				if want := testFreezerItem(kind, i); !bytes.Equal(blob, want) {
+					return fmt.Errorf("table %s item %d: have %q, want %q", kind, i, blob, want)
+				}
+			}
+		}
+		return nil
+	}
+	f := open(oldCipher)
+	_, err := f.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		for i := uint64(0); i < items; i++ {
+			for kind := range chainFreezerTableConfigs {
+				if err := op.AppendRaw(kind, i, testFreezerItem(kind, i)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.TruncateTail(tail); err != nil {
+		t.Fatal(err)
+	}
+	if err := check(f); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		blob, err := os.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Contains(blob, []byte("plain")) {
+			t.Fatalf("plain data in %s", file.Name())
+		}
+	}
+	// Rekey twice, the second run must not change anything
+	for i := 0; i < 2; i++ {
+		if err := RekeyFreezers(ancient, oldCipher, newCipher); err != nil {
+			t.Fatalf("rekey %d failed: %v", i, err)
+		}
+	}
+	f = open(newCipher)
+	if err := check(f); err != nil {
+		t.Fatalf("failed to read with new key: %v", err)
+	}
+	f.Close()
+
+	f = open(oldCipher)
+	if err := check(f); err == nil {
+		t.Fatal("items still readable with old key")
+	}
+	f.Close()
+}
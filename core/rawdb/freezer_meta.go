@@ -29,7 +29,8 @@ import (
 const (
 	freezerTableV1 = 1              // Initial version of metadata struct
 	freezerTableV2 = 2              // Add field: 'flushOffset'
-	freezerVersion = freezerTableV2 // The current used version
+	freezerTableV3 = 3              // Add fields: 'codec', 'dictID'
+	freezerVersion = freezerTableV2 // The version used for tables with the default codec
 )
 
 // freezerTableMeta is a collection of additional properties that describe the
@@ -65,6 +66,13 @@ type freezerTableMeta struct {
 	// The offset could be moved forward by applying sync operation, or be moved
 	// backward in cases of head/tail truncation, etc.
 	flushOffset int64
+
+	// codec is the compression codec of the table items, and dictID the ID of
+	// the zstd dictionary they are compressed with, if any. Tables using the
+	// default codec keep the v2 format, so that they remain readable by older
+	// versions.
+	codec  freezerCodec
+	dictID uint32
 }
 
 // decodeV1 attempts to decode the metadata structure in v1 format. If fails or
@@ -123,6 +131,45 @@ func decodeV2(file *os.File) *freezerTableMeta {
 	}
 }
 
+// decodeV3 attempts to decode the metadata structure in v3 format. If fails or
+// the result is incompatible, nil is returned.
+func decodeV3(file *os.File) *freezerTableMeta {
+	_, err := file.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil
+	}
+	type obj struct {
+		Version uint16
+		Tail    uint64
+		Offset  uint64
+		Codec   uint8
+		DictID  uint32
+	}
+	var o obj
+	if err := rlp.Decode(file, &o); err != nil {
+		return nil
+	}
+	if o.Version != freezerTableV3 {
+		return nil
+	}
+	if o.Offset > math.MaxInt64 {
+		log.Error("Invalid flushOffset %d in freezer metadata", o.Offset, "file", file.Name())
+		return nil
+	}
+	if freezerCodec(o.Codec) > freezerCodecZstd {
+		log.Error("Unknown codec in freezer metadata", "codec", o.Codec, "file", file.Name())
+		return nil
+	}
+	return &freezerTableMeta{
+		file:        file,
+		version:     freezerTableV3,
+		virtualTail: o.Tail,
+		flushOffset: int64(o.Offset),
+		codec:       freezerCodec(o.Codec),
+		dictID:      o.DictID,
+	}
+}
+
 // newMetadata initializes the metadata object, either by loading it from the file
 // or by constructing a new one from scratch.
 func newMetadata(file *os.File) (*freezerTableMeta, error) {
@@ -142,6 +189,9 @@ func newMetadata(file *os.File) (*freezerTableMeta, error) {
 		}
 		return m, nil
 	}
+	if m := decodeV3(file); m != nil {
+		return m, nil
+	}
 	if m := decodeV2(file); m != nil {
 		return m, nil
 	}
@@ -170,16 +220,34 @@ func (m *freezerTableMeta) write(sync bool) error {
 		Tail    uint64
 		Offset  uint64
 	}
-	var o obj
-	o.Version = freezerVersion // forcibly use the current version
-	o.Tail = m.virtualTail
-	o.Offset = uint64(m.flushOffset)
-
+	type objV3 struct {
+		Version uint16
+		Tail    uint64
+		Offset  uint64
+		Codec   uint8
+		DictID  uint32
+	}
+	var o any
+	if m.codec == freezerCodecDefault {
+		o = &obj{
+			Version: freezerVersion, // forcibly use the current version
+			Tail:    m.virtualTail,
+			Offset:  uint64(m.flushOffset),
+		}
+	} else {
+		o = &objV3{
+			Version: freezerTableV3,
+			Tail:    m.virtualTail,
+			Offset:  uint64(m.flushOffset),
+			Codec:   uint8(m.codec),
+			DictID:  m.dictID,
+		}
+	}
 	_, err := m.file.Seek(0, io.SeekStart)
 	if err != nil {
 		return err
 	}
-	if err := rlp.Encode(m.file, &o); err != nil {
+	if err := rlp.Encode(m.file, o); err != nil {
 		return err
 	}
 	if !sync {
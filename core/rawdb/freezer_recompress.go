@@ -0,0 +1,408 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"crypto/cipher"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/klauspost/compress/dict"
+	"github.com/luxfi/geth/common"
+	"github.com/luxfi/geth/log"
+)
+
+const (
+	// freezerDictSize is the maximum size of a trained zstd dictionary.
+	freezerDictSize = 112 * 1024
+
+	// freezerDictSamples is the maximum number of items sampled evenly from a
+	// table to train its dictionary, and freezerDictSampleBytes the maximum
+	// total size of the samples.
+	freezerDictSamples     = 8192
+	freezerDictSampleBytes = 64 * 1024 * 1024
+
+	// recompressMarker is the file marking a completely written staging table,
+	// which only has to be moved into place.
+	recompressMarker = "RECOMPRESSED"
+
+	// recompressReadBytes is the amount of data read from the original table
+	// at once.
+	recompressReadBytes = 16 * 1024 * 1024
+)
+
+// FreezerCompressionStats reports the effect of recompressing a freezer table.
+type FreezerCompressionStats struct {
+	Freezer string             // Name of the freezer
+	Table   string             // Name of the table
+	Codec   string             // Codec of the table before recompression
+	Items   uint64             // Number of items in the table
+	Dict    int                // Size of the trained dictionary, 0 if none
+	Size    common.StorageSize // Size of the table before recompression
+	NewSize common.StorageSize // Size of the recompressed table, estimated in a dry run
+}
+
+// Saved returns the fraction of the original size saved by recompression.
+func (s FreezerCompressionStats) Saved() float64 {
+	if s.Size == 0 {
+		return 0
+	}
+	return 1 - float64(s.NewSize)/float64(s.Size)
+}
+
+// freezerTables resolves the directory and table configs of the named freezer
+// in the given root ancient directory.
+func freezerTables(ancient string, freezer string) (string, map[string]freezerTableConfig, error) {
+	switch freezer {
+	case ChainFreezerName:
+		return resolveChainFreezerDir(ancient), chainFreezerTableConfigs, nil
+	case MerkleStateFreezerName, VerkleStateFreezerName:
+		return filepath.Join(ancient, freezer), stateFreezerTableConfigs, nil
+	default:
+		return "", nil, fmt.Errorf("unknown freezer, supported ones: %v", freezers)
+	}
+}
+
+// RecompressFreezer recompresses the given tables of the named freezer with
+// zstd, using a dictionary trained on the table items. If no tables are given,
+// all compressed tables are recompressed. The freezer must not be open, aead
+// is the cipher the freezer is encrypted with, if any.
+//
+// Each table is rewritten into a staging directory next to it and moved into
+// place once complete. If the process is interrupted while moving the files,
+// running it again finishes the move; the table refuses to open until then.
+// In a dry run, nothing is written and the recompressed sizes are estimated.
+func RecompressFreezer(ancient string, freezer string, tables []string, aead cipher.AEAD, dryRun bool) ([]FreezerCompressionStats, error) {
+	dir, configs, err := freezerTables(ancient, freezer)
+	if err != nil {
+		return nil, err
+	}
+	if len(tables) == 0 {
+		for name, config := range configs {
+			if !config.noSnappy {
+				tables = append(tables, name)
+			}
+		}
+		slices.Sort(tables)
+	}
+	for _, name := range tables {
+		config, ok := configs[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown table %s in freezer %s", name, freezer)
+		}
+		if config.noSnappy {
+			return nil, fmt.Errorf("table %s: %w", name, errUncompressedTable)
+		}
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return nil, err
+	}
+	lock := flock.New(filepath.Join(dir, "FLOCK"))
+	if locked, err := lock.TryLock(); err != nil {
+		return nil, err
+	} else if !locked {
+		return nil, fmt.Errorf("freezer %s is in use", dir)
+	}
+	defer lock.Unlock()
+
+	var stats []FreezerCompressionStats
+	for _, name := range tables {
+		config := configs[name]
+		config.cipher = aead
+		stat, err := recompressFreezerTable(dir, name, config, dryRun)
+		if err != nil {
+			return nil, fmt.Errorf("freezer %s, table %s: %v", freezer, name, err)
+		}
+		stat.Freezer = freezer
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
+
+// recompressStagingDir returns the staging directory of a table.
+func recompressStagingDir(dir, name string) string {
+	return filepath.Join(dir, name+".recompress")
+}
+
+// checkRecompression returns an error if the table has an interrupted
+// recompression that still has to be finished.
+func checkRecompression(dir, name string) error {
+	if _, err := os.Stat(filepath.Join(recompressStagingDir(dir, name), recompressMarker)); err == nil {
+		return fmt.Errorf("freezer table %s has an unfinished recompression, run 'geth db recompress' to complete it", name)
+	}
+	return nil
+}
+
+// recompressFreezerTable recompresses a single table.
+func recompressFreezerTable(dir, name string, config freezerTableConfig, dryRun bool) (FreezerCompressionStats, error) {
+	stat := FreezerCompressionStats{Table: name}
+
+	staging := recompressStagingDir(dir, name)
+	if _, err := os.Stat(filepath.Join(staging, recompressMarker)); err == nil {
+		if dryRun {
+			return stat, fmt.Errorf("unfinished recompression, run without dry run to complete it")
+		}
+		log.Info("Finishing interrupted freezer table recompression", "table", name)
+		if err := swapRecompressedTable(dir, staging, name); err != nil {
+			return stat, err
+		}
+	}
+	if err := os.RemoveAll(staging); err != nil {
+		return stat, err
+	}
+	src, err := newFreezerTable(dir, name, config, dryRun)
+	if err != nil {
+		return stat, err
+	}
+	defer src.Close()
+
+	var (
+		tail  = src.itemHidden.Load()
+		head  = src.items.Load()
+		start = time.Now()
+	)
+	stat.Codec = src.metadata.codec.String()
+	stat.Items = head - tail
+	size, err := src.size()
+	if err != nil {
+		return stat, err
+	}
+	stat.Size = common.StorageSize(size)
+
+	dictionary, err := trainFreezerDict(src, tail, head)
+	if err != nil {
+		return stat, err
+	}
+	stat.Dict = len(dictionary)
+	codec, err := newZstdCodec(dictionary)
+	if err != nil {
+		return stat, err
+	}
+	if dryRun {
+		// Estimate the size from the compressed items, their index entries and
+		// the encryption overhead.
+		var buf []byte
+		newSize := uint64(len(dictionary)) + (stat.Items+1)*indexEntrySize
+		err := iterateFreezerTable(src, tail, head, func(item uint64, data []byte) error {
+			buf = codec.compress(buf[:0], data)
+			newSize += uint64(len(buf))
+			if config.cipher != nil {
+				newSize += uint64(config.cipher.NonceSize() + config.cipher.Overhead())
+			}
+			return nil
+		})
+		stat.NewSize = common.StorageSize(newSize)
+		return stat, err
+	}
+	// Prepare an empty zstd table in the staging directory, starting at the
+	// tail of the original one.
+	if err := os.Mkdir(staging, 0755); err != nil {
+		return stat, err
+	}
+	if dictionary != nil {
+		if err := os.WriteFile(freezerDictFile(staging, name), dictionary, 0644); err != nil {
+			return stat, err
+		}
+	}
+	if err := createZstdTable(staging, name, tail, codec.dictID); err != nil {
+		return stat, err
+	}
+	dst, err := newFreezerTable(staging, name, config, false)
+	if err != nil {
+		return stat, err
+	}
+	batch := dst.newBatch()
+	err = iterateFreezerTable(src, tail, head, func(item uint64, data []byte) error {
+		return batch.AppendRaw(item, data)
+	})
+	if err == nil {
+		err = batch.commit()
+	}
+	if err == nil {
+		err = dst.Sync()
+	}
+	if err == nil {
+		var newSize uint64
+		newSize, err = dst.size()
+		stat.NewSize = common.StorageSize(newSize + uint64(len(dictionary)))
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return stat, err
+	}
+	src.Close()
+
+	if err := os.WriteFile(filepath.Join(staging, recompressMarker), nil, 0644); err != nil {
+		return stat, err
+	}
+	if err := syncDir(staging); err != nil {
+		return stat, err
+	}
+	if err := swapRecompressedTable(dir, staging, name); err != nil {
+		return stat, err
+	}
+	log.Info("Recompressed freezer table", "table", name, "items", stat.Items, "size", stat.Size, "new", stat.NewSize,
+		"elapsed", common.PrettyDuration(time.Since(start)))
+	return stat, nil
+}
+
+// iterateFreezerTable calls fn with every item of the table in [tail, head).
+func iterateFreezerTable(t *freezerTable, tail, head uint64, fn func(item uint64, data []byte) error) error {
+	logged := time.Now()
+	for next := tail; next < head; {
+		items, err := t.RetrieveItems(next, head-next, recompressReadBytes)
+		if err != nil {
+			return err
+		}
+		for _, data := range items {
+			if err := fn(next, data); err != nil {
+				return err
+			}
+			next++
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Recompressing freezer table", "table", t.name, "item", next, "head", head)
+			logged = time.Now()
+		}
+	}
+	return nil
+}
+
+// trainFreezerDict trains a zstd dictionary on items sampled evenly from the
+// table. Nil is returned if the table holds too little data to train one.
+func trainFreezerDict(t *freezerTable, tail, head uint64) ([]byte, error) {
+	count := head - tail
+	if count == 0 {
+		return nil, nil
+	}
+	step := max(count/freezerDictSamples, 1)
+
+	var (
+		samples [][]byte
+		size    int
+	)
+	for item := tail; item < head && size < freezerDictSampleBytes; item += step {
+		data, err := t.Retrieve(item)
+		if err != nil {
+			return nil, err
+		}
+		samples = append(samples, data)
+		size += len(data)
+	}
+	dictionary, err := dict.BuildZstdDict(samples, dict.Options{
+		MaxDictSize: freezerDictSize,
+		HashBytes:   6,
+		ZstdLevel:   freezerZstdLevel,
+	})
+	if err != nil {
+		log.Warn("Failed to train zstd dictionary, compressing without", "table", t.name, "samples", len(samples), "err", err)
+		return nil, nil
+	}
+	return dictionary, nil
+}
+
+// createZstdTable creates the index and metadata of an empty zstd table whose
+// first item is tail.
+func createZstdTable(dir, name string, tail uint64, dictID uint32) error {
+	first := indexEntry{offset: uint32(tail)}
+	if err := os.WriteFile(filepath.Join(dir, name+".cidx"), first.append(nil), 0644); err != nil {
+		return err
+	}
+	file, err := os.Create(filepath.Join(dir, name+".meta"))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	meta := &freezerTableMeta{
+		file:        file,
+		version:     freezerTableV3,
+		virtualTail: tail,
+		flushOffset: indexEntrySize,
+		codec:       freezerCodecZstd,
+		dictID:      dictID,
+	}
+	return meta.write(true)
+}
+
+// swapRecompressedTable moves the files of the recompressed table from the
+// staging directory into place. The data files are moved first and the index
+// last, then data files beyond the new head and any stale dictionary are
+// removed. It can be repeated after an interruption.
+func swapRecompressedTable(dir, staging, name string) error {
+	entries, err := os.ReadDir(staging)
+	if err != nil {
+		return err
+	}
+	rank := func(file string) int {
+		switch filepath.Ext(file) {
+		case ".cdat":
+			return 0
+		case ".zdict":
+			return 1
+		case ".meta":
+			return 2
+		default:
+			return 3
+		}
+	}
+	var files []string
+	for _, entry := range entries {
+		if entry.Name() != recompressMarker {
+			files = append(files, entry.Name())
+		}
+	}
+	slices.SortStableFunc(files, func(a, b string) int { return rank(a) - rank(b) })
+
+	for _, file := range files {
+		if err := os.Rename(filepath.Join(staging, file), filepath.Join(dir, file)); err != nil {
+			return err
+		}
+	}
+	if err := syncDir(dir); err != nil {
+		return err
+	}
+	// Remove the leftovers of the original table
+	index, err := os.ReadFile(filepath.Join(dir, name+".cidx"))
+	if err != nil {
+		return err
+	}
+	var last indexEntry
+	last.unmarshalBinary(index[len(index)-indexEntrySize:])
+
+	if entries, err = os.ReadDir(dir); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		file := entry.Name()
+		if !strings.HasPrefix(file, name+".") || !strings.HasSuffix(file, ".cdat") {
+			continue
+		}
+		var num uint32
+		if _, err := fmt.Sscanf(file, name+".%04d.cdat", &num); err != nil || num <= last.filenum {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, file)); err != nil {
+			return err
+		}
+	}
+	meta, err := readFreezerTableMeta(filepath.Join(dir, name+".meta"))
+	if err != nil {
+		return err
+	}
+	if meta.dictID == 0 {
+		if err := os.Remove(freezerDictFile(dir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return os.RemoveAll(staging)
+}
@@ -32,7 +32,6 @@ import (
 	"github.com/luxfi/geth/common"
 	"github.com/luxfi/geth/log"
 	"github.com/luxfi/geth/metrics"
-	"github.com/golang/snappy"
 )
 
 var (
@@ -113,6 +112,7 @@ type freezerTable struct {
 	tailId uint32              // number of the earliest file
 
 	metadata    *freezerTableMeta // metadata of the table
+	zstd        *zstdCodec        // codec of zstd compressed tables, nil otherwise
 	uncommitted uint64            // Count of items written without flushing to file
 	lastSync    time.Time         // Timestamp when the last sync was performed
 
@@ -138,6 +138,9 @@ func newTable(path string, name string, readMeter, writeMeter *metrics.Meter, si
 	if err := os.MkdirAll(path, 0755); err != nil {
 		return nil, err
 	}
+	if err := checkRecompression(path, name); err != nil {
+		return nil, err
+	}
 	var idxName string
 	if config.noSnappy {
 		idxName = fmt.Sprintf("%s.ridx", name) // raw index file
@@ -175,10 +178,20 @@ func newTable(path string, name string, readMeter, writeMeter *metrics.Meter, si
 	if err != nil {
 		return nil, err
 	}
+	var codec *zstdCodec
+	if metadata.codec == freezerCodecZstd {
+		if config.noSnappy {
+			return nil, fmt.Errorf("freezer table %s: %w", name, errUncompressedTable)
+		}
+		if codec, err = loadZstdCodec(path, name, metadata.dictID); err != nil {
+			return nil, fmt.Errorf("freezer table %s: %v", name, err)
+		}
+	}
 	// Create the table and repair any past inconsistency
 	tab := &freezerTable{
 		index:       index,
 		metadata:    metadata,
+		zstd:        codec,
 		lastSync:    time.Now(),
 		files:       make(map[uint32]*os.File),
 		readMeter:   readMeter,
@@ -983,26 +996,24 @@ func (t *freezerTable) RetrieveItems(start, count, maxBytes uint64) ([][]byte, e
 		offset     int // offset for reading
 		outputSize int // size of uncompressed data
 	)
-	// Now slice up the data and decompress.
+	// Now slice up the data, decrypt and decompress.
 	for i, diskSize := range sizes {
 		item := diskData[offset : offset+diskSize]
 		offset += diskSize
-		decompressedSize := diskSize
-		if !t.config.noSnappy {
-			decompressedSize, _ = snappy.DecodedLen(item)
+		if t.config.cipher != nil {
+			if item, err = openFreezerItem(t.config.cipher, t.name, start+uint64(i), item); err != nil {
+				return nil, err
+			}
 		}
+		decompressedSize := t.decodedLen(item)
 		if i > 0 && maxBytes != 0 && uint64(outputSize+decompressedSize) > maxBytes {
 			break
 		}
-		if !t.config.noSnappy {
-			data, err := snappy.Decode(nil, item)
-			if err != nil {
-				return nil, err
-			}
-			output = append(output, data)
-		} else {
-			output = append(output, item)
+		data, err := t.decompress(item)
+		if err != nil {
+			return nil, err
 		}
+		output = append(output, data)
 		outputSize += decompressedSize
 	}
 	return output, nil
@@ -0,0 +1,128 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+)
+
+// FreezerTableReport describes the structural integrity of a freezer table.
+type FreezerTableReport struct {
+	Name  string `json:"name"`            // Name of the table
+	Tail  uint64 `json:"tail"`            // Number of the first accessible item
+	Items uint64 `json:"items"`           // Number of items, including the deleted ones
+	Size  uint64 `json:"size"`            // Total size of the data and index files
+	Error string `json:"error,omitempty"` // Reason the table is damaged, if it is
+}
+
+// VerifyFreezerTables opens every table of the named freezer read-only and
+// checks that its index and data files agree with each other. The content of
+// the items is not checked. The freezer must not be open.
+func VerifyFreezerTables(ancient string, freezer string, aead cipher.AEAD) ([]FreezerTableReport, error) {
+	dir, configs, err := freezerTables(ancient, freezer)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return nil, err
+	}
+	var names []string
+	for name := range configs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	reports := make([]FreezerTableReport, 0, len(names))
+	for _, name := range names {
+		config := configs[name]
+		config.cipher = aead
+
+		report := FreezerTableReport{Name: name}
+		if err := verifyFreezerTable(dir, name, config, &report); err != nil {
+			report.Error = err.Error()
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// verifyFreezerTable opens a single table read-only, which validates the index
+// and the head data file, and checks the remaining data files.
+func verifyFreezerTable(dir, name string, config freezerTableConfig, report *FreezerTableReport) error {
+	t, err := newFreezerTable(dir, name, config, true)
+	if err != nil {
+		return err
+	}
+	defer t.Close()
+
+	report.Tail, report.Items = t.itemHidden.Load(), t.items.Load()
+	if report.Size, err = t.size(); err != nil {
+		return err
+	}
+	return verifyTableFiles(t)
+}
+
+// verifyTableFiles checks that every data file referenced by the index ends
+// exactly where the last item stored in it does.
+func verifyTableFiles(t *freezerTable) error {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	stat, err := t.index.Stat()
+	if err != nil {
+		return err
+	}
+	var (
+		reader = bufio.NewReader(io.NewSectionReader(t.index, 0, stat.Size()))
+		buf    = make([]byte, indexEntrySize)
+		ends   = make(map[uint32]uint32)
+		entry  indexEntry
+	)
+	// The first entry marks the tail, all the others the end of an item
+	for offset := int64(0); offset < stat.Size(); offset += indexEntrySize {
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return err
+		}
+		if offset == 0 {
+			continue
+		}
+		entry.unmarshalBinary(buf)
+		ends[entry.filenum] = entry.offset
+	}
+	for filenum, end := range ends {
+		if filenum < t.tailId || filenum > t.headId {
+			return fmt.Errorf("index references data file %d outside of [%d, %d]", filenum, t.tailId, t.headId)
+		}
+		f := t.files[filenum]
+		if f == nil {
+			return fmt.Errorf("data file %d is missing", filenum)
+		}
+		stat, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		if stat.Size() != int64(end) {
+			return fmt.Errorf("data file %d has size %d, indexed %d", filenum, stat.Size(), end)
+		}
+	}
+	return nil
+}
+
+// OpenChainFreezer opens the chain freezer in the given root ancient directory
+// on its own, without the key-value store. In writable mode the tables are
+// repaired and truncated to a common length while opening.
+func OpenChainFreezer(ancient string, aead cipher.AEAD, readonly bool) (*Freezer, error) {
+	return NewFreezer(resolveChainFreezerDir(ancient), "", readonly, freezerTableSize, encryptedTables(chainFreezerTableConfigs, aead))
+}
+
+// ChainEraDir returns the directory the era files of the chain freezer in the
+// given root ancient directory are stored in.
+func ChainEraDir(ancient string, era string) string {
+	return resolveChainEraDir(resolveChainFreezerDir(ancient), era)
+}
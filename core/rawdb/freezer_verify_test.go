@@ -0,0 +1,50 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/luxfi/geth/metrics"
+)
+
+// Tests that a data file which doesn't match the index is detected, even if it
+// is not the head file validated when opening the table.
+func TestVerifyTableFiles(t *testing.T) {
+	var (
+		dir        = t.TempDir()
+		rm, wm, sg = metrics.NewMeter(), metrics.NewMeter(), metrics.NewGauge()
+		config     = freezerTableConfig{noSnappy: true}
+	)
+	// Three items per data file, three data files
+	f, err := newTable(dir, "test", rm, wm, sg, 50, config, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeChunks(t, f, 9, 15)
+	f.Close()
+
+	f, err = newFreezerTable(dir, "test", config, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyTableFiles(f); err != nil {
+		t.Fatalf("intact table reported as damaged: %v", err)
+	}
+	f.Close()
+
+	if err := os.Truncate(filepath.Join(dir, "test.0000.rdat"), 20); err != nil {
+		t.Fatal(err)
+	}
+	f, err = newFreezerTable(dir, "test", config, true)
+	if err != nil {
+		t.Fatalf("failed to open table: %v", err)
+	}
+	defer f.Close()
+	if err := verifyTableFiles(f); err == nil {
+		t.Fatal("truncated data file not detected")
+	}
+}
@@ -17,6 +17,8 @@
 package rawdb
 
 import (
+	"crypto/cipher"
+
 	"github.com/luxfi/geth/ethdb"
 )
 
@@ -112,6 +114,11 @@ func (t *table) AncientDatadir() (string, error) {
 	return t.db.AncientDatadir()
 }
 
+// AncientCipher returns the ancient cipher of the underlying database.
+func (t *table) AncientCipher() cipher.AEAD {
+	return AncientCipher(t.db)
+}
+
 // Put inserts the given value into the database at a prefixed version of the
 // provided key.
 func (t *table) Put(key []byte, value []byte) error {
@@ -17,14 +17,14 @@
 package state
 
 import (
-	"errors"
-
+	"github.com/luxfi/crypto"
 	"github.com/luxfi/geth/common"
 	"github.com/luxfi/geth/common/lru"
 	"github.com/luxfi/geth/core/state/snapshot"
 	"github.com/luxfi/geth/core/types"
 	"github.com/luxfi/geth/ethdb"
 	"github.com/luxfi/geth/rlp"
+	"github.com/luxfi/geth/trie"
 	"github.com/luxfi/geth/trie/utils"
 	"github.com/luxfi/geth/triedb"
 	"github.com/luxfi/geth/triedb/pathdb"
@@ -128,15 +128,34 @@ func (db *HistoricDB) Reader(stateRoot common.Hash) (Reader, error) {
 	return newReader(newCachingCodeReader(db.disk, db.codeCache, db.codeSizeCache), newHistoricReader(hr)), nil
 }
 
-// OpenTrie opens the main account trie. It's not supported by historic database.
+// OpenTrie opens the main account trie. The trie nodes of historic states are
+// rebuilt from the state histories, which is only supported within the proof
+// history configured in the path database. The trie remains readable while new
+// states are committed, as long as the state stays within the proof history.
 func (db *HistoricDB) OpenTrie(root common.Hash) (Trie, error) {
-	return nil, errors.New("not implemented")
+	nodes, err := db.triedb.HistoricNodes(root)
+	if err != nil {
+		return nil, err
+	}
+	tr, err := trie.NewStateTrie(trie.StateTrieID(root), nodes)
+	if err != nil {
+		return nil, err
+	}
+	return tr, nil
 }
 
-// OpenStorageTrie opens the storage trie of an account. It's not supported by
-// historic database.
-func (db *HistoricDB) OpenStorageTrie(stateRoot common.Hash, address common.Address, root common.Hash, trie Trie) (Trie, error) {
-	return nil, errors.New("not implemented")
+// OpenStorageTrie opens the storage trie of an account. The trie nodes are
+// rebuilt from the state histories like the ones of the main account trie.
+func (db *HistoricDB) OpenStorageTrie(stateRoot common.Hash, address common.Address, root common.Hash, self Trie) (Trie, error) {
+	nodes, err := db.triedb.HistoricNodes(stateRoot)
+	if err != nil {
+		return nil, err
+	}
+	tr, err := trie.NewStateTrie(trie.StorageTrieID(stateRoot, common.BytesToHash(crypto.Keccak256(address.Bytes())), root), nodes)
+	if err != nil {
+		return nil, err
+	}
+	return tr, nil
 }
 
 // PointCache returns the cache holding points used in verkle tree key computation
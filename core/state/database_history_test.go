@@ -0,0 +1,99 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/luxfi/geth/common"
+	"github.com/luxfi/geth/core/rawdb"
+	"github.com/luxfi/geth/core/tracing"
+	"github.com/luxfi/geth/core/types"
+	"github.com/luxfi/geth/triedb"
+	"github.com/luxfi/geth/triedb/pathdb"
+)
+
+// Tests that the tries of a historic state opened before new states are
+// committed remain readable once the disk layer moved on.
+func TestHistoricTriesAcrossCommits(t *testing.T) {
+	disk, err := rawdb.Open(rawdb.NewMemoryDatabase(), rawdb.OpenOptions{Ancient: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer disk.Close()
+
+	tdb := triedb.NewDatabase(disk, &triedb.Config{PathDB: &pathdb.Config{
+		ProofHistory: 256,
+		NoAsyncFlush: true,
+	}})
+	defer tdb.Close()
+
+	var (
+		sdb   = NewDatabase(tdb, nil)
+		addr  = common.HexToAddress("0xdeadbeef")
+		roots = []common.Hash{types.EmptyRootHash}
+	)
+	commit := func(blocks int) {
+		for i := 0; i < blocks; i++ {
+			number := uint64(len(roots))
+			state, err := New(roots[len(roots)-1], sdb)
+			if err != nil {
+				t.Fatal(err)
+			}
+			state.SetBalance(addr, uint256.NewInt(number), tracing.BalanceChangeUnspecified)
+			state.SetState(addr, common.BigToHash(common.Big1), common.BytesToHash([]byte{byte(number)}))
+			state.SetState(addr, common.BytesToHash([]byte{byte(number)}), common.BytesToHash([]byte{byte(number)}))
+
+			root, err := state.Commit(number, false, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			roots = append(roots, root)
+		}
+	}
+	// Commit enough states for the first ones to be flattened into the disk layer
+	commit(140)
+
+	var (
+		number = uint64(5)
+		root   = roots[number]
+		hdb    = NewHistoricDatabase(disk, tdb)
+	)
+	tr, err := hdb.OpenTrie(root)
+	if err != nil {
+		t.Fatalf("failed to open historic trie: %v", err)
+	}
+	// Replace the disk layer the trie nodes were rebuilt from
+	commit(10)
+
+	account, err := tr.GetAccount(addr)
+	if err != nil {
+		t.Fatalf("failed to read historic account: %v", err)
+	}
+	if account == nil || account.Balance.Uint64() != number {
+		t.Fatalf("wrong historic account: %v", account)
+	}
+	st, err := hdb.OpenStorageTrie(root, addr, account.Root, tr)
+	if err != nil {
+		t.Fatalf("failed to open historic storage trie: %v", err)
+	}
+	commit(10)
+
+	for slot, want := range map[common.Hash]byte{
+		common.BigToHash(common.Big1):    byte(number),
+		common.BytesToHash([]byte{4}):    4,
+		common.BytesToHash([]byte{5}):    5,
+		common.BytesToHash([]byte{6}):    0,
+		common.BytesToHash([]byte{0x80}): 0,
+	} {
+		blob, err := st.GetStorage(addr, slot.Bytes())
+		if err != nil {
+			t.Fatalf("failed to read historic slot %x: %v", slot, err)
+		}
+		if common.BytesToHash(blob) != common.BytesToHash([]byte{want}) {
+			t.Fatalf("historic slot %x mismatch: have %x, want %x", slot, blob, want)
+		}
+	}
+}
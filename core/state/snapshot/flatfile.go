@@ -0,0 +1,473 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"time"
+
+	"github.com/luxfi/crypto"
+	"github.com/luxfi/geth/common"
+	"github.com/luxfi/geth/core/rawdb"
+	"github.com/luxfi/geth/core/types"
+	"github.com/luxfi/geth/ethdb"
+	"github.com/luxfi/geth/log"
+	"github.com/luxfi/geth/rlp"
+	"github.com/luxfi/geth/trie"
+)
+
+// The flat state file holds the accounts, storage slots and contract codes of
+// a state, in the order of the account and slot hashes. It's laid out as
+//
+//	magic | header chunk | entry chunk ... | footer chunk
+//
+// where every chunk is framed as
+//
+//	kind (1 byte) | payload size (4 bytes) | payload CRC32-C (4 bytes) | payload
+//
+// The header payload is the RLP encoded FlatHeader along with the format version,
+// the entry payloads are RLP lists of entries, the footer payload is the RLP
+// encoded FlatStats of the entries for the completeness check. The codes are
+// stored before the first account referencing them and the storage slots right
+// after the account owning them.
+const (
+	// FlatFileVersion is the version of the flat state file format.
+	FlatFileVersion = 1
+
+	flatChunkHeader = 0
+	flatChunkEntry  = 1
+	flatChunkFooter = 2
+
+	flatEntryAccount = 0
+	flatEntryStorage = 1
+	flatEntryCode    = 2
+
+	flatChunkSize  = 4 * 1024 * 1024 // Payload size to cut the entry chunks at
+	flatChunkLimit = 4 * flatChunkSize
+)
+
+var (
+	flatFileMagic = []byte("LUXFLAT\x00")
+	flatCRCTable  = crc32.MakeTable(crc32.Castagnoli)
+)
+
+// FlatHeader describes the state held by a flat state file.
+type FlatHeader struct {
+	Root   common.Hash // Root of the state
+	Number uint64      // Number of the block the state belongs to
+	Hash   common.Hash // Hash of the block the state belongs to
+}
+
+// flatHeader is the versioned header stored in the flat state file.
+type flatHeader struct {
+	Version uint64
+	FlatHeader
+}
+
+// FlatStats counts the entries of a flat state file.
+type FlatStats struct {
+	Accounts uint64
+	Slots    uint64
+	Codes    uint64
+}
+
+// flatEntry is a single state element in the flat state file.
+type flatEntry struct {
+	Kind  uint8
+	Hash  common.Hash
+	Value []byte
+}
+
+// flatWriter cuts the flat state entries into checksummed chunks.
+type flatWriter struct {
+	w       io.Writer
+	entries []flatEntry
+	size    int
+}
+
+func (fw *flatWriter) writeChunk(kind byte, payload []byte) error {
+	var frame [9]byte
+	frame[0] = kind
+	binary.BigEndian.PutUint32(frame[1:5], uint32(len(payload)))
+	binary.BigEndian.PutUint32(frame[5:9], crc32.Checksum(payload, flatCRCTable))
+	if _, err := fw.w.Write(frame[:]); err != nil {
+		return err
+	}
+	_, err := fw.w.Write(payload)
+	return err
+}
+
+func (fw *flatWriter) add(kind uint8, hash common.Hash, value []byte) error {
+	fw.entries = append(fw.entries, flatEntry{Kind: kind, Hash: hash, Value: common.CopyBytes(value)})
+	fw.size += common.HashLength + len(value)
+	if fw.size < flatChunkSize {
+		return nil
+	}
+	return fw.flush()
+}
+
+func (fw *flatWriter) flush() error {
+	if len(fw.entries) == 0 {
+		return nil
+	}
+	payload, err := rlp.EncodeToBytes(fw.entries)
+	if err != nil {
+		return err
+	}
+	fw.entries, fw.size = fw.entries[:0], 0
+	return fw.writeChunk(flatChunkEntry, payload)
+}
+
+// ExportFlat writes the state iterated by the given account iterator, along with
+// the storage slots and contract codes, into the writer in the flat state file
+// format. The storage function opens the storage iterator of an account and the
+// codes are read from the given database.
+func ExportFlat(w io.Writer, header FlatHeader, accounts AccountIterator, storage func(account common.Hash) (StorageIterator, error), codes ethdb.KeyValueReader) (*FlatStats, error) {
+	var (
+		fw     = &flatWriter{w: w}
+		stats  = new(FlatStats)
+		seen   = make(map[common.Hash]struct{})
+		start  = time.Now()
+		logged = time.Now()
+	)
+	if _, err := w.Write(flatFileMagic); err != nil {
+		return nil, err
+	}
+	blob, err := rlp.EncodeToBytes(&flatHeader{Version: FlatFileVersion, FlatHeader: header})
+	if err != nil {
+		return nil, err
+	}
+	if err := fw.writeChunk(flatChunkHeader, blob); err != nil {
+		return nil, err
+	}
+	for accounts.Next() {
+		account, err := types.FullAccount(accounts.Account())
+		if err != nil {
+			return nil, err
+		}
+		codeHash := common.BytesToHash(account.CodeHash)
+		if codeHash != types.EmptyCodeHash {
+			if _, ok := seen[codeHash]; !ok {
+				code := rawdb.ReadCode(codes, codeHash)
+				if len(code) == 0 {
+					return nil, fmt.Errorf("missing code %x of account %x", codeHash, accounts.Hash())
+				}
+				if err := fw.add(flatEntryCode, codeHash, code); err != nil {
+					return nil, err
+				}
+				seen[codeHash] = struct{}{}
+				stats.Codes++
+			}
+		}
+		if err := fw.add(flatEntryAccount, accounts.Hash(), accounts.Account()); err != nil {
+			return nil, err
+		}
+		stats.Accounts++
+
+		if account.Root != types.EmptyRootHash {
+			it, err := storage(accounts.Hash())
+			if err != nil {
+				return nil, err
+			}
+			for it.Next() {
+				if err := fw.add(flatEntryStorage, it.Hash(), it.Slot()); err != nil {
+					it.Release()
+					return nil, err
+				}
+				stats.Slots++
+			}
+			it.Release()
+			if err := it.Error(); err != nil {
+				return nil, err
+			}
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Exporting flat state", "at", accounts.Hash(), "accounts", stats.Accounts, "slots", stats.Slots,
+				"codes", stats.Codes, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := accounts.Error(); err != nil {
+		return nil, err
+	}
+	if err := fw.flush(); err != nil {
+		return nil, err
+	}
+	blob, err = rlp.EncodeToBytes(stats)
+	if err != nil {
+		return nil, err
+	}
+	if err := fw.writeChunk(flatChunkFooter, blob); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// readFlatChunk reads and verifies the next chunk of the flat state file.
+func readFlatChunk(r io.Reader) (byte, []byte, error) {
+	var frame [9]byte
+	if _, err := io.ReadFull(r, frame[:]); err != nil {
+		if errors.Is(err, io.EOF) {
+			return 0, nil, io.ErrUnexpectedEOF
+		}
+		return 0, nil, err
+	}
+	size := binary.BigEndian.Uint32(frame[1:5])
+	if size > flatChunkLimit {
+		return 0, nil, fmt.Errorf("oversized chunk, size %d", size)
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	if crc32.Checksum(payload, flatCRCTable) != binary.BigEndian.Uint32(frame[5:9]) {
+		return 0, nil, errors.New("chunk checksum mismatch")
+	}
+	return frame[0], payload, nil
+}
+
+// flatImporter rebuilds the tries from the entries of a flat state file, along
+// with the flat state and the contract codes.
+type flatImporter struct {
+	batch  ethdb.Batch
+	scheme string
+	stats  FlatStats
+	codes  map[common.Hash]struct{}
+
+	accountTrie *trie.StackTrie
+	account     *types.StateAccount // Account whose storage slots are being imported
+	accountHash common.Hash
+	storageTrie *trie.StackTrie
+	slotHash    common.Hash
+}
+
+func (fi *flatImporter) write() error {
+	if fi.batch.ValueSize() < ethdb.IdealBatchSize {
+		return nil
+	}
+	if err := fi.batch.Write(); err != nil {
+		return err
+	}
+	fi.batch.Reset()
+	return nil
+}
+
+// finishAccount verifies the storage root of the account being imported, once
+// all of its storage slots are imported.
+func (fi *flatImporter) finishAccount() error {
+	if fi.account == nil {
+		return nil
+	}
+	root := types.EmptyRootHash
+	if fi.storageTrie != nil {
+		root = fi.storageTrie.Hash()
+	}
+	if root != fi.account.Root {
+		return fmt.Errorf("storage root mismatch of account %x, want %x, got %x", fi.accountHash, fi.account.Root, root)
+	}
+	fi.account, fi.storageTrie = nil, nil
+	return nil
+}
+
+func (fi *flatImporter) add(entry flatEntry) error {
+	switch entry.Kind {
+	case flatEntryCode:
+		if common.BytesToHash(crypto.Keccak256(entry.Value)) != entry.Hash {
+			return fmt.Errorf("code hash mismatch %x", entry.Hash)
+		}
+		rawdb.WriteCode(fi.batch, entry.Hash, entry.Value)
+		fi.codes[entry.Hash] = struct{}{}
+		fi.stats.Codes++
+
+	case flatEntryAccount:
+		if err := fi.finishAccount(); err != nil {
+			return err
+		}
+		if fi.stats.Accounts > 0 && bytes.Compare(entry.Hash[:], fi.accountHash[:]) <= 0 {
+			return fmt.Errorf("account %x is out of order", entry.Hash)
+		}
+		account, err := types.FullAccount(entry.Value)
+		if err != nil {
+			return err
+		}
+		if codeHash := common.BytesToHash(account.CodeHash); codeHash != types.EmptyCodeHash {
+			if _, ok := fi.codes[codeHash]; !ok {
+				return fmt.Errorf("missing code %x of account %x", codeHash, entry.Hash)
+			}
+		}
+		full, err := types.FullAccountRLP(entry.Value)
+		if err != nil {
+			return err
+		}
+		if err := fi.accountTrie.Update(entry.Hash[:], full); err != nil {
+			return err
+		}
+		rawdb.WriteAccountSnapshot(fi.batch, entry.Hash, entry.Value)
+		fi.account, fi.accountHash = account, entry.Hash
+		fi.stats.Accounts++
+
+	case flatEntryStorage:
+		if fi.account == nil {
+			return fmt.Errorf("storage slot %x without account", entry.Hash)
+		}
+		if fi.storageTrie == nil {
+			owner := fi.accountHash
+			fi.storageTrie = trie.NewStackTrie(func(path []byte, hash common.Hash, blob []byte) {
+				rawdb.WriteTrieNode(fi.batch, owner, path, hash, blob, fi.scheme)
+			})
+		} else if bytes.Compare(entry.Hash[:], fi.slotHash[:]) <= 0 {
+			return fmt.Errorf("storage slot %x of account %x is out of order", entry.Hash, fi.accountHash)
+		}
+		if err := fi.storageTrie.Update(entry.Hash[:], entry.Value); err != nil {
+			return err
+		}
+		rawdb.WriteStorageSnapshot(fi.batch, fi.accountHash, entry.Hash, entry.Value)
+		fi.slotHash = entry.Hash
+		fi.stats.Slots++
+
+	default:
+		return fmt.Errorf("unknown entry kind %d", entry.Kind)
+	}
+	return fi.write()
+}
+
+// ImportFlat loads a flat state file from the reader into the database. The
+// tries of the state are rebuilt with the given state scheme and verified
+// against the state root in the file, the flat state is marked as complete.
+//
+// Any flat state already in the database is wiped, while the trie nodes of
+// other states are left untouched. The database content is undefined if the
+// import fails.
+func ImportFlat(r io.Reader, db ethdb.KeyValueStore, scheme string) (*FlatHeader, *FlatStats, error) {
+	r = bufio.NewReader(r)
+
+	magic := make([]byte, len(flatFileMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return nil, nil, err
+	}
+	if !bytes.Equal(magic, flatFileMagic) {
+		return nil, nil, errors.New("not a flat state file")
+	}
+	kind, payload, err := readFlatChunk(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if kind != flatChunkHeader {
+		return nil, nil, fmt.Errorf("unexpected chunk kind %d, want header", kind)
+	}
+	var header flatHeader
+	if err := rlp.DecodeBytes(payload, &header); err != nil {
+		return nil, nil, err
+	}
+	if header.Version != FlatFileVersion {
+		return nil, nil, fmt.Errorf("unsupported flat state file version %d", header.Version)
+	}
+	if err := wipeFlatState(db); err != nil {
+		return nil, nil, err
+	}
+	var (
+		start = time.Now()
+		fi    = &flatImporter{
+			batch:  db.NewBatch(),
+			scheme: scheme,
+			codes:  make(map[common.Hash]struct{}),
+		}
+		logged = time.Now()
+	)
+	fi.accountTrie = trie.NewStackTrie(func(path []byte, hash common.Hash, blob []byte) {
+		rawdb.WriteTrieNode(fi.batch, common.Hash{}, path, hash, blob, scheme)
+	})
+	for {
+		kind, payload, err := readFlatChunk(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		if kind == flatChunkFooter {
+			var stats FlatStats
+			if err := rlp.DecodeBytes(payload, &stats); err != nil {
+				return nil, nil, err
+			}
+			if stats != fi.stats {
+				return nil, nil, fmt.Errorf("entry count mismatch, want %+v, got %+v", stats, fi.stats)
+			}
+			break
+		}
+		if kind != flatChunkEntry {
+			return nil, nil, fmt.Errorf("unexpected chunk kind %d", kind)
+		}
+		var entries []flatEntry
+		if err := rlp.DecodeBytes(payload, &entries); err != nil {
+			return nil, nil, err
+		}
+		for _, entry := range entries {
+			if err := fi.add(entry); err != nil {
+				return nil, nil, err
+			}
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Importing flat state", "at", fi.accountHash, "accounts", fi.stats.Accounts, "slots", fi.stats.Slots,
+				"codes", fi.stats.Codes, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if _, err := r.Read(make([]byte, 1)); err != io.EOF {
+		return nil, nil, errors.New("trailing data after footer")
+	}
+	if err := fi.finishAccount(); err != nil {
+		return nil, nil, err
+	}
+	if root := fi.accountTrie.Hash(); root != header.Root {
+		return nil, nil, fmt.Errorf("state root mismatch, want %x, got %x", header.Root, root)
+	}
+	// Mark the flat state as complete, sparing the regeneration
+	rawdb.WriteSnapshotRoot(fi.batch, header.Root)
+	journalProgress(fi.batch, nil, &generatorStats{accounts: fi.stats.Accounts, slots: fi.stats.Slots})
+	if err := fi.batch.Write(); err != nil {
+		return nil, nil, err
+	}
+	log.Info("Imported flat state", "root", header.Root, "accounts", fi.stats.Accounts, "slots", fi.stats.Slots,
+		"codes", fi.stats.Codes, "elapsed", common.PrettyDuration(time.Since(start)))
+	return &header.FlatHeader, &fi.stats, nil
+}
+
+// wipeFlatState deletes the flat accounts and storage slots in the database.
+func wipeFlatState(db ethdb.KeyValueStore) error {
+	batch := db.NewBatch()
+	for _, prefix := range []struct {
+		prefix []byte
+		keylen int
+	}{
+		{rawdb.SnapshotAccountPrefix, len(rawdb.SnapshotAccountPrefix) + common.HashLength},
+		{rawdb.SnapshotStoragePrefix, len(rawdb.SnapshotStoragePrefix) + 2*common.HashLength},
+	} {
+		it := db.NewIterator(prefix.prefix, nil)
+		for it.Next() {
+			if len(it.Key()) != prefix.keylen {
+				continue
+			}
+			if err := batch.Delete(it.Key()); err != nil {
+				it.Release()
+				return err
+			}
+			if batch.ValueSize() >= ethdb.IdealBatchSize {
+				if err := batch.Write(); err != nil {
+					it.Release()
+					return err
+				}
+				batch.Reset()
+			}
+		}
+		it.Release()
+		if err := it.Error(); err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
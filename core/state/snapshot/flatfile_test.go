@@ -0,0 +1,156 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snapshot
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/holiman/uint256"
+	"github.com/luxfi/geth/common"
+	"github.com/luxfi/geth/core/rawdb"
+	"github.com/luxfi/geth/core/types"
+	"github.com/luxfi/geth/rlp"
+	"github.com/luxfi/geth/trie"
+	"github.com/luxfi/geth/triedb"
+	"github.com/luxfi/geth/triedb/hashdb"
+	"github.com/luxfi/geth/triedb/pathdb"
+)
+
+// exportTestFlat builds a small state with storage and code, and exports it in
+// the flat state file format.
+func exportTestFlat(t *testing.T, scheme string, header FlatHeader) (common.Hash, []byte) {
+	var (
+		helper = newHelper(scheme)
+		code   = []byte{0x60, 0x00, 0x60, 0x00, 0xf3}
+	)
+	rawdb.WriteCode(helper.diskdb, hashData(code), code)
+
+	stRoot := helper.makeStorageTrie("", []string{"key-1", "key-2", "key-3"}, []string{"val-1", "val-2", "val-3"}, false)
+	helper.addAccount("acc-1", &types.StateAccount{Balance: uint256.NewInt(1), Root: stRoot, CodeHash: hashData(code).Bytes()})
+	helper.addAccount("acc-2", &types.StateAccount{Balance: uint256.NewInt(2), Root: types.EmptyRootHash, CodeHash: types.EmptyCodeHash.Bytes()})
+	helper.addAccount("acc-3", &types.StateAccount{Balance: uint256.NewInt(3), Root: stRoot, CodeHash: hashData(code).Bytes()})
+	helper.makeStorageTrie("acc-1", []string{"key-1", "key-2", "key-3"}, []string{"val-1", "val-2", "val-3"}, true)
+	helper.makeStorageTrie("acc-3", []string{"key-1", "key-2", "key-3"}, []string{"val-1", "val-2", "val-3"}, true)
+	helper.addSnapStorage("acc-1", []string{"key-1", "key-2", "key-3"}, []string{"val-1", "val-2", "val-3"})
+	helper.addSnapStorage("acc-3", []string{"key-1", "key-2", "key-3"}, []string{"val-1", "val-2", "val-3"})
+
+	root, snap := helper.CommitAndGenerate()
+	select {
+	case <-snap.genPending:
+	case <-time.After(3 * time.Second):
+		t.Fatal("snapshot generation failed")
+	}
+	if header.Root == (common.Hash{}) {
+		header.Root = root
+	}
+	var buf bytes.Buffer
+	stats, err := ExportFlat(&buf, header, snap.AccountIterator(common.Hash{}), func(account common.Hash) (StorageIterator, error) {
+		return snap.StorageIterator(account, common.Hash{}), nil
+	}, helper.diskdb)
+	if err != nil {
+		t.Fatalf("failed to export flat state: %v", err)
+	}
+	if want := (FlatStats{Accounts: 3, Slots: 6, Codes: 1}); *stats != want {
+		t.Fatalf("export stats mismatch, want %+v, got %+v", want, *stats)
+	}
+	return root, buf.Bytes()
+}
+
+func TestFlatFile(t *testing.T) {
+	testFlatFile(t, rawdb.HashScheme)
+	testFlatFile(t, rawdb.PathScheme)
+}
+
+func testFlatFile(t *testing.T, scheme string) {
+	root, blob := exportTestFlat(t, scheme, FlatHeader{Number: 1, Hash: common.Hash{0x01}})
+
+	// Stale flat state in the target database is expected to be wiped
+	db := rawdb.NewMemoryDatabase()
+	rawdb.WriteAccountSnapshot(db, common.Hash{0xff}, []byte{0x01})
+
+	header, stats, err := ImportFlat(bytes.NewReader(blob), db, scheme)
+	if err != nil {
+		t.Fatalf("failed to import flat state: %v", err)
+	}
+	if header.Root != root || header.Number != 1 || header.Hash != (common.Hash{0x01}) {
+		t.Fatalf("header mismatch: %+v", header)
+	}
+	if want := (FlatStats{Accounts: 3, Slots: 6, Codes: 1}); *stats != want {
+		t.Fatalf("import stats mismatch, want %+v, got %+v", want, *stats)
+	}
+	if rawdb.ReadAccountSnapshot(db, common.Hash{0xff}) != nil {
+		t.Fatal("stale flat state is not wiped")
+	}
+	if rawdb.ReadSnapshotRoot(db) != root {
+		t.Fatal("flat state is not marked with the root")
+	}
+	// Ensure the whole state is rebuilt
+	config := &triedb.Config{HashDB: &hashdb.Config{}}
+	if scheme == rawdb.PathScheme {
+		config = &triedb.Config{PathDB: &pathdb.Config{SnapshotNoBuild: true}}
+	}
+	tdb := triedb.NewDatabase(db, config)
+	defer tdb.Close()
+
+	tr, err := trie.NewStateTrie(trie.StateTrieID(root), tdb)
+	if err != nil {
+		t.Fatalf("failed to open imported state: %v", err)
+	}
+	it, err := tr.NodeIterator(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var accounts int
+	for it.Next(true) {
+		if !it.Leaf() {
+			continue
+		}
+		accounts++
+		var account types.StateAccount
+		if err := rlp.DecodeBytes(it.LeafBlob(), &account); err != nil {
+			t.Fatal(err)
+		}
+		st, err := trie.NewStateTrie(trie.StorageTrieID(root, common.BytesToHash(it.LeafKey()), account.Root), tdb)
+		if err != nil {
+			t.Fatalf("failed to open imported storage: %v", err)
+		}
+		sit, err := st.NodeIterator(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for sit.Next(true) {
+		}
+		if sit.Error() != nil {
+			t.Fatalf("failed to iterate imported storage: %v", sit.Error())
+		}
+		if codeHash := common.BytesToHash(account.CodeHash); codeHash != types.EmptyCodeHash && len(rawdb.ReadCode(db, codeHash)) == 0 {
+			t.Fatalf("missing imported code %x", codeHash)
+		}
+	}
+	if it.Error() != nil || accounts != 3 {
+		t.Fatalf("failed to iterate imported state, accounts %d: %v", accounts, it.Error())
+	}
+}
+
+func TestFlatFileCorruption(t *testing.T) {
+	_, blob := exportTestFlat(t, rawdb.HashScheme, FlatHeader{})
+
+	// Flipped bits are caught by the chunk checksums
+	corrupt := bytes.Clone(blob)
+	corrupt[len(corrupt)/2] ^= 0x01
+	if _, _, err := ImportFlat(bytes.NewReader(corrupt), rawdb.NewMemoryDatabase(), rawdb.HashScheme); err == nil {
+		t.Fatal("expected error for corrupted file")
+	}
+	// Truncated files are rejected
+	if _, _, err := ImportFlat(bytes.NewReader(blob[:len(blob)-1]), rawdb.NewMemoryDatabase(), rawdb.HashScheme); err == nil {
+		t.Fatal("expected error for truncated file")
+	}
+	// The rebuilt state must match the root in the header
+	_, blob = exportTestFlat(t, rawdb.HashScheme, FlatHeader{Root: common.Hash{0x01}})
+	if _, _, err := ImportFlat(bytes.NewReader(blob), rawdb.NewMemoryDatabase(), rawdb.HashScheme); err == nil {
+		t.Fatal("expected error for root mismatch")
+	}
+}
@@ -116,6 +116,23 @@ func IntrinsicGas(data []byte, accessList types.AccessList, authList []types.Set
 	return gas, nil
 }
 
+// PQSignatureGas computes the intrinsic gas charged for the public key and
+// signature of a post-quantum transaction, on top of IntrinsicGas. Neither
+// compresses, so every byte is priced like non-zero calldata.
+func PQSignatureGas(size uint64) (uint64, error) {
+	if size > math.MaxUint64/params.TxPQSignatureByteGas {
+		return 0, ErrGasUintOverflow
+	}
+	return size * params.TxPQSignatureByteGas, nil
+}
+
+// pqSignatureSize returns the combined size of the post-quantum public key and
+// signature carried by tx, zero for all other transaction types.
+func pqSignatureSize(tx *types.Transaction) uint64 {
+	_, pub, sig := tx.PQSignature()
+	return uint64(len(pub) + len(sig))
+}
+
 // FloorDataGas computes the minimum gas required for a transaction based on its data tokens (EIP-7623).
 func FloorDataGas(data []byte) (uint64, error) {
 	var (
@@ -156,6 +173,7 @@ type Message struct {
 	BlobGasFeeCap         *big.Int
 	BlobHashes            []common.Hash
 	SetCodeAuthorizations []types.SetCodeAuthorization
+	PQSignatureSize       uint64 // Size of the public key and signature of a post-quantum transaction
 
 	// When SkipNonceChecks is true, the message nonce is not checked against the
 	// account nonce in state.
@@ -181,6 +199,7 @@ func TransactionToMessage(tx *types.Transaction, s types.Signer, baseFee *big.In
 		Data:                  tx.Data(),
 		AccessList:            tx.AccessList(),
 		SetCodeAuthorizations: tx.SetCodeAuthorizations(),
+		PQSignatureSize:       pqSignatureSize(tx),
 		SkipNonceChecks:       false,
 		SkipFromEOACheck:      false,
 		BlobHashes:            tx.BlobHashes(),
@@ -443,6 +462,16 @@ func (st *stateTransition) execute() (*ExecutionResult, error) {
 	if err != nil {
 		return nil, err
 	}
+	if msg.PQSignatureSize > 0 {
+		pqGas, err := PQSignatureGas(msg.PQSignatureSize)
+		if err != nil {
+			return nil, err
+		}
+		if gas > math.MaxUint64-pqGas {
+			return nil, ErrGasUintOverflow
+		}
+		gas += pqGas
+	}
 	if st.gasRemaining < gas {
 		return nil, fmt.Errorf("%w: have %d, want %d", ErrIntrinsicGas, st.gasRemaining, gas)
 	}
@@ -37,10 +37,12 @@ import (
 	"github.com/luxfi/geth/core/types"
 	"github.com/luxfi/geth/core/vm"
 	"github.com/luxfi/crypto"
+	"github.com/luxfi/geth/crypto/pqcrypto"
 	"github.com/luxfi/geth/event"
 	"github.com/luxfi/geth/params"
 	"github.com/luxfi/geth/trie"
 	"github.com/holiman/uint256"
+	"github.com/cloudflare/circl/sign/mldsa/mldsa44"
 )
 
 var (
@@ -2612,6 +2614,72 @@ func TestSetCodeTransactionsReorg(t *testing.T) {
 	}
 }
 
+// pqTransaction creates a dynamic fee transaction signed with an ML-DSA-44 key
+// derived from seed, returning it along with the sender address.
+func pqTransaction(t *testing.T, signer types.Signer, nonce uint64, gaslimit uint64, seed byte) (*types.Transaction, common.Address) {
+	pub, priv := mldsa44.NewKeyFromSeed(&[mldsa44.SeedSize]byte{seed})
+	tx := types.NewTx(&types.PQTx{
+		ChainID:   uint256.MustFromBig(signer.ChainID()),
+		Nonce:     nonce,
+		GasTipCap: uint256.NewInt(1),
+		GasFeeCap: uint256.NewInt(10),
+		Gas:       gaslimit,
+		To:        &common.Address{},
+		Value:     uint256.NewInt(100),
+		Algorithm: uint8(pqcrypto.AlgoMLDSA44),
+	})
+	h := signer.Hash(tx)
+	sig := make([]byte, mldsa44.SignatureSize)
+	if err := mldsa44.SignTo(priv, h[:], nil, false, sig); err != nil {
+		t.Fatal(err)
+	}
+	pubBytes, _ := pub.MarshalBinary()
+	tx, err := tx.WithPQSignature(signer, pubBytes, sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx, common.Address(crypto.Keccak256(pubBytes)[12:])
+}
+
+// Tests that post-quantum signed transactions are only accepted once the
+// PostQuantum fork is active, and that they pay for their signature.
+func TestPostQuantumTransactions(t *testing.T) {
+	t.Parallel()
+
+	config := *params.MergedTestChainConfig
+	config.PostQuantumTime = new(uint64)
+
+	pool, _ := setupPoolWithConfig(&config)
+	defer pool.Close()
+
+	tx, from := pqTransaction(t, pool.signer, 0, 100000, 1)
+	testAddBalance(pool, from, big.NewInt(params.Ether))
+
+	// The public key and signature are charged on top of the base intrinsic gas
+	pqGas, _ := core.PQSignatureGas(uint64(mldsa44.PublicKeySize + mldsa44.SignatureSize))
+	short, _ := pqTransaction(t, pool.signer, 0, params.TxGas+pqGas-1, 1)
+	if err := pool.addRemoteSync(short); !errors.Is(err, core.ErrIntrinsicGas) {
+		t.Fatalf("error mismatch: want %v, have %v", core.ErrIntrinsicGas, err)
+	}
+	if err := pool.addRemoteSync(tx); err != nil {
+		t.Fatalf("failed to add post-quantum transaction: %v", err)
+	}
+	if pending, queued := pool.Stats(); pending != 1 || queued != 0 {
+		t.Fatalf("pool stats mismatch: pending %d, queued %d", pending, queued)
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	// Pools not yet on the PostQuantum fork must reject the type
+	prague, _ := setupPoolWithConfig(params.MergedTestChainConfig)
+	defer prague.Close()
+
+	testAddBalance(prague, from, big.NewInt(params.Ether))
+	if err := prague.addRemoteSync(tx); !errors.Is(err, core.ErrTxTypeNotSupported) {
+		t.Fatalf("error mismatch: want %v, have %v", core.ErrTxTypeNotSupported, err)
+	}
+}
+
 // Benchmarks the speed of validating the contents of the pending queue of the
 // transaction pool.
 func BenchmarkPendingDemotion100(b *testing.B)   { benchmarkPendingDemotion(b, 100) }
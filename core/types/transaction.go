@@ -51,6 +51,11 @@ const (
 	DynamicFeeTxType = 0x02
 	BlobTxType       = 0x03
 	SetCodeTxType    = 0x04
+
+	// PQTxType is the Lux post-quantum signed transaction type, enabled by the
+	// PostQuantum fork. It is chosen above the Ethereum types in use so that it
+	// still fits the txpool type bitmaps.
+	PQTxType = 0x07
 )
 
 // Transaction is an Ethereum transaction.
@@ -213,6 +218,8 @@ func (tx *Transaction) decodeTyped(b []byte) (TxData, error) {
 		inner = new(BlobTx)
 	case SetCodeTxType:
 		inner = new(SetCodeTx)
+	case PQTxType:
+		inner = new(PQTx)
 	default:
 		return nil, ErrTxTypeNotSupported
 	}
@@ -609,6 +616,33 @@ func (tx *Transaction) WithSignature(signer Signer, sig []byte) (*Transaction, e
 	return &Transaction{inner: cpy, time: tx.time}, nil
 }
 
+// WithPQSignature returns a new transaction with the given post-quantum public
+// key and signature, which must be over signer.Hash(tx). It is the PQTx
+// counterpart of WithSignature.
+func (tx *Transaction) WithPQSignature(signer Signer, pub, sig []byte) (*Transaction, error) {
+	if tx.Type() != PQTxType {
+		return nil, ErrTxTypeNotSupported
+	}
+	if chainID := tx.inner.chainID(); chainID.Sign() != 0 && chainID.Cmp(signer.ChainID()) != 0 {
+		return nil, fmt.Errorf("%w: have %d want %d", ErrInvalidChainId, chainID, signer.ChainID())
+	}
+	cpy := tx.inner.copy().(*PQTx)
+	cpy.ChainID = uint256.MustFromBig(signer.ChainID())
+	cpy.PublicKey = common.CopyBytes(pub)
+	cpy.Signature = common.CopyBytes(sig)
+	return &Transaction{inner: cpy, time: tx.time}, nil
+}
+
+// PQSignature returns the post-quantum algorithm, public key and signature of a
+// PQ transaction, or zero values for any other type.
+func (tx *Transaction) PQSignature() (algo uint8, pub []byte, sig []byte) {
+	pqtx, ok := tx.inner.(*PQTx)
+	if !ok {
+		return 0, nil, nil
+	}
+	return pqtx.Algorithm, pqtx.PublicKey, pqtx.Signature
+}
+
 // Transactions implements DerivableList for transactions.
 type Transactions []*Transaction
 
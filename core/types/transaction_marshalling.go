@@ -49,6 +49,11 @@ type txJSON struct {
 	S                    *hexutil.Big           `json:"s"`
 	YParity              *hexutil.Uint64        `json:"yParity,omitempty"`
 
+	// Post-quantum signature encoding:
+	PQAlgorithm *hexutil.Uint64 `json:"pqAlgorithm,omitempty"`
+	PQPublicKey *hexutil.Bytes  `json:"pqPublicKey,omitempty"`
+	PQSignature *hexutil.Bytes  `json:"pqSignature,omitempty"`
+
 	// Blob transaction sidecar encoding:
 	Blobs       []kzg4844.Blob       `json:"blobs,omitempty"`
 	Commitments []kzg4844.Commitment `json:"commitments,omitempty"`
@@ -170,6 +175,21 @@ func (tx *Transaction) MarshalJSON() ([]byte, error) {
 		enc.S = (*hexutil.Big)(itx.S.ToBig())
 		yparity := itx.V.Uint64()
 		enc.YParity = (*hexutil.Uint64)(&yparity)
+
+	case *PQTx:
+		enc.ChainID = (*hexutil.Big)(itx.ChainID.ToBig())
+		enc.Nonce = (*hexutil.Uint64)(&itx.Nonce)
+		enc.To = tx.To()
+		enc.Gas = (*hexutil.Uint64)(&itx.Gas)
+		enc.MaxFeePerGas = (*hexutil.Big)(itx.GasFeeCap.ToBig())
+		enc.MaxPriorityFeePerGas = (*hexutil.Big)(itx.GasTipCap.ToBig())
+		enc.Value = (*hexutil.Big)(itx.Value.ToBig())
+		enc.Input = (*hexutil.Bytes)(&itx.Data)
+		enc.AccessList = &itx.AccessList
+		algo := uint64(itx.Algorithm)
+		enc.PQAlgorithm = (*hexutil.Uint64)(&algo)
+		enc.PQPublicKey = (*hexutil.Bytes)(&itx.PublicKey)
+		enc.PQSignature = (*hexutil.Bytes)(&itx.Signature)
 	}
 	return json.Marshal(&enc)
 }
@@ -507,6 +527,72 @@ func (tx *Transaction) UnmarshalJSON(input []byte) error {
 			}
 		}
 
+	case PQTxType:
+		var itx PQTx
+		inner = &itx
+		if dec.ChainID == nil {
+			return errors.New("missing required field 'chainId' in transaction")
+		}
+		var overflow bool
+		itx.ChainID, overflow = uint256.FromBig(dec.ChainID.ToInt())
+		if overflow {
+			return errors.New("'chainId' value overflows uint256")
+		}
+		if dec.Nonce == nil {
+			return errors.New("missing required field 'nonce' in transaction")
+		}
+		itx.Nonce = uint64(*dec.Nonce)
+		if dec.To != nil {
+			itx.To = dec.To
+		}
+		if dec.Gas == nil {
+			return errors.New("missing required field 'gas' for txdata")
+		}
+		itx.Gas = uint64(*dec.Gas)
+		if dec.MaxPriorityFeePerGas == nil {
+			return errors.New("missing required field 'maxPriorityFeePerGas' for txdata")
+		}
+		itx.GasTipCap, overflow = uint256.FromBig((*big.Int)(dec.MaxPriorityFeePerGas))
+		if overflow {
+			return errors.New("'maxPriorityFeePerGas' value overflows uint256")
+		}
+		if dec.MaxFeePerGas == nil {
+			return errors.New("missing required field 'maxFeePerGas' for txdata")
+		}
+		itx.GasFeeCap, overflow = uint256.FromBig((*big.Int)(dec.MaxFeePerGas))
+		if overflow {
+			return errors.New("'maxFeePerGas' value overflows uint256")
+		}
+		if dec.Value == nil {
+			return errors.New("missing required field 'value' in transaction")
+		}
+		itx.Value, overflow = uint256.FromBig((*big.Int)(dec.Value))
+		if overflow {
+			return errors.New("'value' value overflows uint256")
+		}
+		if dec.Input == nil {
+			return errors.New("missing required field 'input' in transaction")
+		}
+		itx.Data = *dec.Input
+		if dec.AccessList != nil {
+			itx.AccessList = *dec.AccessList
+		}
+		if dec.PQAlgorithm == nil {
+			return errors.New("missing required field 'pqAlgorithm' in transaction")
+		}
+		if *dec.PQAlgorithm > 0xff {
+			return errors.New("'pqAlgorithm' value overflows uint8")
+		}
+		itx.Algorithm = uint8(*dec.PQAlgorithm)
+		if dec.PQPublicKey == nil {
+			return errors.New("missing required field 'pqPublicKey' in transaction")
+		}
+		itx.PublicKey = *dec.PQPublicKey
+		if dec.PQSignature == nil {
+			return errors.New("missing required field 'pqSignature' in transaction")
+		}
+		itx.Signature = *dec.PQSignature
+
 	default:
 		return ErrTxTypeNotSupported
 	}
@@ -42,6 +42,8 @@ type sigCache struct {
 func MakeSigner(config *params.ChainConfig, blockNumber *big.Int, blockTime uint64) Signer {
 	var signer Signer
 	switch {
+	case config.IsPostQuantum(blockNumber, blockTime):
+		signer = NewPostQuantumSigner(config.ChainID)
 	case config.IsPrague(blockNumber, blockTime):
 		signer = NewPragueSigner(config.ChainID)
 	case config.IsCancun(blockNumber, blockTime):
@@ -71,6 +73,8 @@ func LatestSigner(config *params.ChainConfig) Signer {
 	var signer Signer
 	if config.ChainID != nil {
 		switch {
+		case config.PostQuantumTime != nil:
+			signer = NewPostQuantumSigner(config.ChainID)
 		case config.PragueTime != nil:
 			signer = NewPragueSigner(config.ChainID)
 		case config.CancunTime != nil:
@@ -100,7 +104,7 @@ func LatestSigner(config *params.ChainConfig) Signer {
 func LatestSignerForChainID(chainID *big.Int) Signer {
 	var signer Signer
 	if chainID != nil {
-		signer = NewPragueSigner(chainID)
+		signer = NewPostQuantumSigner(chainID)
 	} else {
 		signer = HomesteadSigner{}
 	}
@@ -251,6 +255,9 @@ func (s *modernSigner) Sender(tx *Transaction) (common.Address, error) {
 	if tx.ChainId().Cmp(s.chainID) != 0 {
 		return common.Address{}, fmt.Errorf("%w: have %d want %d", ErrInvalidChainId, tx.ChainId(), s.chainID)
 	}
+	if tt == PQTxType {
+		return tx.inner.(*PQTx).sender(s.Hash(tx))
+	}
 	// 'modern' txs are defined to use 0 and 1 as their recovery
 	// id, add 27 to become equivalent to unprotected Homestead signatures.
 	V, R, S := tx.RawSignatureValues()
@@ -266,6 +273,9 @@ func (s *modernSigner) SignatureValues(tx *Transaction, sig []byte) (R, S, V *bi
 	if tt == LegacyTxType {
 		return s.legacy.SignatureValues(tx, sig)
 	}
+	if tt == PQTxType {
+		return nil, nil, nil, errPQSignatureRequired
+	}
 	// Check that chain ID of tx matches the signer. We also accept ID zero here,
 	// because it indicates that the chain ID was not specified in the tx.
 	if tx.inner.chainID().Sign() != 0 && tx.inner.chainID().Cmp(s.chainID) != 0 {
@@ -287,6 +297,20 @@ func NewPragueSigner(chainId *big.Int) Signer {
 	return newModernSigner(chainId, forks.Prague)
 }
 
+// NewPostQuantumSigner returns a signer that accepts
+// - Lux post-quantum signed transactions
+// - EIP-7702 set code transactions
+// - EIP-4844 blob transactions
+// - EIP-1559 dynamic fee transactions
+// - EIP-2930 access list transactions,
+// - EIP-155 replay protected transactions, and
+// - legacy Homestead transactions.
+func NewPostQuantumSigner(chainId *big.Int) Signer {
+	s := newModernSigner(chainId, forks.Prague).(*modernSigner)
+	s.txtypes[PQTxType] = struct{}{}
+	return s
+}
+
 // NewCancunSigner returns a signer that accepts
 // - EIP-4844 blob transactions
 // - EIP-1559 dynamic fee transactions
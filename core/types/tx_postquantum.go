@@ -0,0 +1,164 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// Post-quantum signed transaction type
+
+package types
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+
+	"github.com/holiman/uint256"
+	"github.com/luxfi/geth/common"
+	"github.com/luxfi/geth/crypto/pqcrypto"
+	"github.com/luxfi/geth/rlp"
+)
+
+var (
+	ErrPQAlgorithmNotSupported = errors.New("post-quantum signature algorithm not supported")
+	errPQSignatureRequired     = errors.New("post-quantum transactions must be signed with WithPQSignature")
+)
+
+// PQTx is a dynamic fee transaction authenticated with a post-quantum
+// signature instead of secp256k1. The sender is derived from the embedded
+// public key with pqcrypto.PubkeyToAddress: the last 20 bytes of its Keccak256
+// hash.
+//
+// The signature covers the signing hash of every field except PublicKey and
+// Signature. The public key is bound by the signature verifying under it.
+type PQTx struct {
+	ChainID    *uint256.Int
+	Nonce      uint64
+	GasTipCap  *uint256.Int // a.k.a. maxPriorityFeePerGas
+	GasFeeCap  *uint256.Int // a.k.a. maxFeePerGas
+	Gas        uint64
+	To         *common.Address `rlp:"nil"` // nil means contract creation
+	Value      *uint256.Int
+	Data       []byte
+	AccessList AccessList
+
+	// Signature values
+	Algorithm uint8 // pqcrypto.Algorithm of the key
+	PublicKey []byte
+	Signature []byte
+}
+
+// pqAlgorithms are the signature algorithms accepted in PQ transactions.
+var pqAlgorithms = map[pqcrypto.Algorithm]bool{
+	pqcrypto.AlgoMLDSA44:    true,
+	pqcrypto.AlgoMLDSA65:    true,
+	pqcrypto.AlgoMLDSA87:    true,
+	pqcrypto.AlgoSLHDSA128s: true,
+	pqcrypto.AlgoSLHDSA192s: true,
+	pqcrypto.AlgoSLHDSA256s: true,
+}
+
+// IsPQAlgorithmSupported reports whether algo is accepted in PQ transactions.
+func IsPQAlgorithmSupported(algo pqcrypto.Algorithm) bool {
+	return pqAlgorithms[algo]
+}
+
+// copy creates a deep copy of the transaction data and initializes all fields.
+func (tx *PQTx) copy() TxData {
+	cpy := &PQTx{
+		Nonce:     tx.Nonce,
+		To:        copyAddressPtr(tx.To),
+		Data:      common.CopyBytes(tx.Data),
+		Gas:       tx.Gas,
+		Algorithm: tx.Algorithm,
+		PublicKey: common.CopyBytes(tx.PublicKey),
+		Signature: common.CopyBytes(tx.Signature),
+		// These are copied below.
+		AccessList: make(AccessList, len(tx.AccessList)),
+		Value:      new(uint256.Int),
+		ChainID:    new(uint256.Int),
+		GasTipCap:  new(uint256.Int),
+		GasFeeCap:  new(uint256.Int),
+	}
+	copy(cpy.AccessList, tx.AccessList)
+	if tx.Value != nil {
+		cpy.Value.Set(tx.Value)
+	}
+	if tx.ChainID != nil {
+		cpy.ChainID.Set(tx.ChainID)
+	}
+	if tx.GasTipCap != nil {
+		cpy.GasTipCap.Set(tx.GasTipCap)
+	}
+	if tx.GasFeeCap != nil {
+		cpy.GasFeeCap.Set(tx.GasFeeCap)
+	}
+	return cpy
+}
+
+// accessors for innerTx.
+func (tx *PQTx) txType() byte           { return PQTxType }
+func (tx *PQTx) chainID() *big.Int      { return tx.ChainID.ToBig() }
+func (tx *PQTx) accessList() AccessList { return tx.AccessList }
+func (tx *PQTx) data() []byte           { return tx.Data }
+func (tx *PQTx) gas() uint64            { return tx.Gas }
+func (tx *PQTx) gasFeeCap() *big.Int    { return tx.GasFeeCap.ToBig() }
+func (tx *PQTx) gasTipCap() *big.Int    { return tx.GasTipCap.ToBig() }
+func (tx *PQTx) gasPrice() *big.Int     { return tx.GasFeeCap.ToBig() }
+func (tx *PQTx) value() *big.Int        { return tx.Value.ToBig() }
+func (tx *PQTx) nonce() uint64          { return tx.Nonce }
+func (tx *PQTx) to() *common.Address    { return tx.To }
+
+func (tx *PQTx) effectiveGasPrice(dst *big.Int, baseFee *big.Int) *big.Int {
+	if baseFee == nil {
+		return dst.Set(tx.GasFeeCap.ToBig())
+	}
+	tip := dst.Sub(tx.GasFeeCap.ToBig(), baseFee)
+	if tip.Cmp(tx.GasTipCap.ToBig()) > 0 {
+		tip.Set(tx.GasTipCap.ToBig())
+	}
+	return tip.Add(tip, baseFee)
+}
+
+// rawSignatureValues returns zero values, PQ transactions carry no secp256k1
+// signature.
+func (tx *PQTx) rawSignatureValues() (v, r, s *big.Int) {
+	return new(big.Int), new(big.Int), new(big.Int)
+}
+
+func (tx *PQTx) setSignatureValues(chainID, v, r, s *big.Int) {
+	tx.ChainID = uint256.MustFromBig(chainID)
+}
+
+func (tx *PQTx) encode(b *bytes.Buffer) error {
+	return rlp.Encode(b, tx)
+}
+
+func (tx *PQTx) decode(input []byte) error {
+	return rlp.DecodeBytes(input, tx)
+}
+
+func (tx *PQTx) sigHash(chainID *big.Int) common.Hash {
+	return prefixedRlpHash(
+		PQTxType,
+		[]any{
+			chainID,
+			tx.Nonce,
+			tx.GasTipCap,
+			tx.GasFeeCap,
+			tx.Gas,
+			tx.To,
+			tx.Value,
+			tx.Data,
+			tx.AccessList,
+			tx.Algorithm,
+		})
+}
+
+// sender verifies the post-quantum signature over sighash and returns the
+// address of the embedded public key.
+func (tx *PQTx) sender(sighash common.Hash) (common.Address, error) {
+	algo := pqcrypto.Algorithm(tx.Algorithm)
+	if !pqAlgorithms[algo] {
+		return common.Address{}, ErrPQAlgorithmNotSupported
+	}
+	if !pqcrypto.Verify(algo, tx.PublicKey, sighash[:], tx.Signature) {
+		return common.Address{}, ErrInvalidSig
+	}
+	return pqcrypto.PubkeyToAddress(algo, tx.PublicKey)
+}
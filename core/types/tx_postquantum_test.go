@@ -0,0 +1,212 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// Post-quantum signed transaction type tests
+
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/cloudflare/circl/sign/mldsa/mldsa44"
+	"github.com/holiman/uint256"
+	"github.com/luxfi/crypto"
+	"github.com/luxfi/geth/common"
+	"github.com/luxfi/geth/crypto/pqcrypto"
+	"github.com/luxfi/geth/crypto/slhdsa"
+	"github.com/luxfi/geth/rlp"
+)
+
+// signPQTestTx creates a PQ transaction signed with a deterministic ML-DSA-44
+// key, returning it along with the encoded public key.
+func signPQTestTx(t *testing.T, signer Signer, to *common.Address) (*Transaction, []byte) {
+	t.Helper()
+
+	pub, priv := mldsa44.NewKeyFromSeed(&[mldsa44.SeedSize]byte{1})
+	tx := NewTx(&PQTx{
+		ChainID:   uint256.MustFromBig(signer.ChainID()),
+		Nonce:     1,
+		GasTipCap: uint256.NewInt(1),
+		GasFeeCap: uint256.NewInt(10),
+		Gas:       100000,
+		To:        to,
+		Value:     uint256.NewInt(5),
+		Data:      []byte{0xca, 0xfe},
+		AccessList: AccessList{{
+			Address:     common.Address{0x01},
+			StorageKeys: []common.Hash{{0x02}},
+		}},
+		Algorithm: uint8(pqcrypto.AlgoMLDSA44),
+	})
+	h := signer.Hash(tx)
+	sig := make([]byte, mldsa44.SignatureSize)
+	if err := mldsa44.SignTo(priv, h[:], nil, false, sig); err != nil {
+		t.Fatal(err)
+	}
+	pubBytes, _ := pub.MarshalBinary()
+	signed, err := tx.WithPQSignature(signer, pubBytes, sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed, pubBytes
+}
+
+func TestPQTxSender(t *testing.T) {
+	signer := NewPostQuantumSigner(big.NewInt(1))
+	to := common.HexToAddress("0x00000000000000000000000000000000deadbeef")
+	tx, pub := signPQTestTx(t, signer, &to)
+
+	from, err := Sender(signer, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := common.BytesToAddress(crypto.Keccak256(pub)[12:]); from != want {
+		t.Fatalf("sender mismatch: have %x, want %x", from, want)
+	}
+	// Any change to the signed fields must invalidate the signature
+	cpy := tx.inner.copy().(*PQTx)
+	cpy.Nonce++
+	if _, err := signer.Sender(NewTx(cpy)); !errors.Is(err, ErrInvalidSig) {
+		t.Errorf("modified nonce: have %v, want %v", err, ErrInvalidSig)
+	}
+	cpy = tx.inner.copy().(*PQTx)
+	cpy.Signature[0] ^= 1
+	if _, err := signer.Sender(NewTx(cpy)); !errors.Is(err, ErrInvalidSig) {
+		t.Errorf("modified signature: have %v, want %v", err, ErrInvalidSig)
+	}
+	cpy = tx.inner.copy().(*PQTx)
+	cpy.Algorithm = uint8(pqcrypto.AlgoMLDSA65)
+	if _, err := signer.Sender(NewTx(cpy)); !errors.Is(err, ErrInvalidSig) {
+		t.Errorf("modified algorithm: have %v, want %v", err, ErrInvalidSig)
+	}
+	cpy = tx.inner.copy().(*PQTx)
+	cpy.Algorithm = uint8(pqcrypto.AlgoMLKEM768)
+	if _, err := signer.Sender(NewTx(cpy)); !errors.Is(err, ErrPQAlgorithmNotSupported) {
+		t.Errorf("KEM algorithm: have %v, want %v", err, ErrPQAlgorithmNotSupported)
+	}
+	// Other chains and signers without the PostQuantum fork must reject it
+	if _, err := NewPostQuantumSigner(big.NewInt(2)).Sender(tx); !errors.Is(err, ErrInvalidChainId) {
+		t.Errorf("wrong chain: have %v, want %v", err, ErrInvalidChainId)
+	}
+	if _, err := NewPragueSigner(big.NewInt(1)).Sender(tx); !errors.Is(err, ErrTxTypeNotSupported) {
+		t.Errorf("prague signer: have %v, want %v", err, ErrTxTypeNotSupported)
+	}
+	// ECDSA signatures cannot be attached to a PQ transaction
+	if _, err := tx.WithSignature(signer, make([]byte, 65)); err == nil {
+		t.Errorf("secp256k1 signature accepted for PQ transaction")
+	}
+}
+
+func TestPQTxSLHDSASender(t *testing.T) {
+	signer := NewPostQuantumSigner(big.NewInt(1))
+	tx := NewTx(&PQTx{
+		ChainID:   uint256.NewInt(1),
+		GasTipCap: uint256.NewInt(1),
+		GasFeeCap: uint256.NewInt(1),
+		Gas:       21000,
+		Value:     uint256.NewInt(0),
+		Algorithm: uint8(pqcrypto.AlgoSLHDSA128s),
+	})
+	pub, priv, err := slhdsa.SHAKE128s.NewKeyFromSeed(make([]byte, slhdsa.SHAKE128s.SeedSize()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := signer.Hash(tx)
+	sig, err := slhdsa.SHAKE128s.Sign(priv, h[:], nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err = tx.WithPQSignature(signer, pub, sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	from, err := Sender(signer, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := common.BytesToAddress(crypto.Keccak256(pub)[12:]); from != want {
+		t.Fatalf("sender mismatch: have %x, want %x", from, want)
+	}
+}
+
+func TestPQTxCoding(t *testing.T) {
+	signer := NewPostQuantumSigner(big.NewInt(1))
+	to := common.Address{0xaa}
+	for _, recipient := range []*common.Address{&to, nil} {
+		tx, _ := signPQTestTx(t, signer, recipient)
+
+		// Binary (RLP) round trip
+		enc, err := tx.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if enc[0] != PQTxType {
+			t.Fatalf("wrong type prefix: %x", enc[0])
+		}
+		var dec Transaction
+		if err := dec.UnmarshalBinary(enc); err != nil {
+			t.Fatal(err)
+		}
+		if err := assertEqual(tx, &dec); err != nil {
+			t.Fatal(err)
+		}
+		if tx.Size() != uint64(len(enc)) {
+			t.Errorf("size mismatch: have %d, want %d", tx.Size(), len(enc))
+		}
+		// Network (RLP string) round trip
+		blob, err := rlp.EncodeToBytes(tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var dec2 Transaction
+		if err := rlp.DecodeBytes(blob, &dec2); err != nil {
+			t.Fatal(err)
+		}
+		if err := assertEqual(tx, &dec2); err != nil {
+			t.Fatal(err)
+		}
+
+		// JSON round trip
+		js, err := json.Marshal(tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var dec3 Transaction
+		if err := json.Unmarshal(js, &dec3); err != nil {
+			t.Fatal(err)
+		}
+		if err := assertEqual(tx, &dec3); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Sender(signer, &dec3); err != nil {
+			t.Fatalf("decoded transaction has invalid signature: %v", err)
+		}
+	}
+}
+
+func TestPQTxReceiptCoding(t *testing.T) {
+	receipt := &Receipt{Type: PQTxType, Status: ReceiptStatusSuccessful, CumulativeGasUsed: 1, Logs: []*Log{}}
+	enc, err := receipt.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dec Receipt
+	if err := dec.UnmarshalBinary(enc); err != nil {
+		t.Fatal(err)
+	}
+	if dec.Type != PQTxType || !bytes.Equal(dec.Bloom[:], receipt.Bloom[:]) {
+		t.Fatalf("receipt mismatch: have type %d", dec.Type)
+	}
+}
+
+func TestPQSignerSelection(t *testing.T) {
+	pq, prague := NewPostQuantumSigner(big.NewInt(1)), NewPragueSigner(big.NewInt(1))
+	if pq.Equal(prague) || prague.Equal(pq) {
+		t.Fatalf("post-quantum signer equal to prague signer")
+	}
+	if !LatestSignerForChainID(big.NewInt(1)).Equal(pq) {
+		t.Errorf("latest signer does not accept PQ transactions")
+	}
+}
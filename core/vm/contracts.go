@@ -180,6 +180,9 @@ var (
 	PrecompiledAddressesIstanbul  []common.Address
 	PrecompiledAddressesByzantium []common.Address
 	PrecompiledAddressesHomestead []common.Address
+
+	PrecompiledAddressesLux      []common.Address
+	PrecompiledAddressesLuxOsaka []common.Address
 )
 
 func init() {
@@ -204,12 +207,22 @@ func init() {
 	for k := range PrecompiledContractsOsaka {
 		PrecompiledAddressesOsaka = append(PrecompiledAddressesOsaka, k)
 	}
+	for k := range PrecompiledContractsLux {
+		PrecompiledAddressesLux = append(PrecompiledAddressesLux, k)
+	}
+	for k := range PrecompiledContractsLuxOsaka {
+		PrecompiledAddressesLuxOsaka = append(PrecompiledAddressesLuxOsaka, k)
+	}
 }
 
 func activePrecompiledContracts(rules params.Rules) PrecompiledContracts {
 	switch {
 	case rules.IsVerkle:
 		return PrecompiledContractsVerkle
+	case rules.IsPostQuantum && rules.IsOsaka:
+		return PrecompiledContractsLuxOsaka
+	case rules.IsPostQuantum:
+		return PrecompiledContractsLux
 	case rules.IsOsaka:
 		return PrecompiledContractsOsaka
 	case rules.IsPrague:
@@ -235,6 +248,10 @@ func ActivePrecompiledContracts(rules params.Rules) PrecompiledContracts {
 // ActivePrecompiles returns the precompile addresses enabled with the current configuration.
 func ActivePrecompiles(rules params.Rules) []common.Address {
 	switch {
+	case rules.IsPostQuantum && rules.IsOsaka:
+		return PrecompiledAddressesLuxOsaka
+	case rules.IsPostQuantum:
+		return PrecompiledAddressesLux
 	case rules.IsOsaka:
 		return PrecompiledAddressesOsaka
 	case rules.IsPrague:
@@ -5,30 +5,34 @@ package vm
 
 import (
 	"errors"
+	"maps"
 
 	"github.com/luxfi/geth/common"
-	"github.com/luxfi/crypto/mlkem"
-	"github.com/luxfi/crypto/mldsa"
-	"github.com/luxfi/crypto/slhdsa"
+	"github.com/luxfi/geth/crypto/pqcrypto"
 )
 
 // Post-quantum precompile addresses following NIST standards
+//
+// The ML-DSA sign and ML-KEM decapsulation addresses are reserved but will never
+// be assigned a contract: both operations take a private key as input, and any
+// key passed in calldata is published in the chain history the moment the
+// transaction is included.
 var (
 	// ML-DSA (Module Lattice Digital Signature Algorithm - Dilithium)
-	mldsaVerify44Address  = common.BytesToAddress([]byte{0x01, 0x10})
-	mldsaVerify65Address  = common.BytesToAddress([]byte{0x01, 0x11})
-	mldsaVerify87Address  = common.BytesToAddress([]byte{0x01, 0x12})
-	mldsaSign44Address    = common.BytesToAddress([]byte{0x01, 0x13})
-	mldsaSign65Address    = common.BytesToAddress([]byte{0x01, 0x14})
-	mldsaSign87Address    = common.BytesToAddress([]byte{0x01, 0x15})
+	mldsaVerify44Address = common.BytesToAddress([]byte{0x01, 0x10})
+	mldsaVerify65Address = common.BytesToAddress([]byte{0x01, 0x11})
+	mldsaVerify87Address = common.BytesToAddress([]byte{0x01, 0x12})
+	mldsaSign44Address   = common.BytesToAddress([]byte{0x01, 0x13}) // reserved, never assigned
+	mldsaSign65Address   = common.BytesToAddress([]byte{0x01, 0x14}) // reserved, never assigned
+	mldsaSign87Address   = common.BytesToAddress([]byte{0x01, 0x15}) // reserved, never assigned
 
 	// ML-KEM (Module Lattice Key Encapsulation Mechanism - Kyber)
 	mlkemEncap512Address  = common.BytesToAddress([]byte{0x01, 0x20})
-	mlkemDecap512Address  = common.BytesToAddress([]byte{0x01, 0x21})
+	mlkemDecap512Address  = common.BytesToAddress([]byte{0x01, 0x21}) // reserved, never assigned
 	mlkemEncap768Address  = common.BytesToAddress([]byte{0x01, 0x22})
-	mlkemDecap768Address  = common.BytesToAddress([]byte{0x01, 0x23})
+	mlkemDecap768Address  = common.BytesToAddress([]byte{0x01, 0x23}) // reserved, never assigned
 	mlkemEncap1024Address = common.BytesToAddress([]byte{0x01, 0x24})
-	mlkemDecap1024Address = common.BytesToAddress([]byte{0x01, 0x25})
+	mlkemDecap1024Address = common.BytesToAddress([]byte{0x01, 0x25}) // reserved, never assigned
 
 	// SLH-DSA (Stateless Hash-based Digital Signature Algorithm - SPHINCS+)
 	slhdsaVerify128sAddress = common.BytesToAddress([]byte{0x01, 0x30})
@@ -39,215 +43,151 @@ var (
 	slhdsaVerify256fAddress = common.BytesToAddress([]byte{0x01, 0x35})
 )
 
-// Gas costs based on benchmarks (in gas units)
+// Gas costs, calibrated with the precompile benchmarks in this package so that
+// no operation is cheaper per unit of execution time than ecrecover. ML-DSA and
+// ML-KEM are priced well above that, SLH-DSA with a margin, as its verification
+// time varies with the signature and across hardware. The fast SLH-DSA variants
+// trade signature size for many more hash calls during verification.
 const (
 	// ML-DSA gas costs (verification only for on-chain)
-	mldsaVerify44Gas = 120000  // ~1.1 μs
-	mldsaVerify65Gas = 150000  // ~1.4 μs
-	mldsaVerify87Gas = 200000  // ~2.0 μs
-	
-	// ML-KEM gas costs
-	mlkemEncap512Gas  = 140000  // ~1.3 μs
-	mlkemDecap512Gas  = 80000   // ~0.7 μs
-	mlkemEncap768Gas  = 190000  // ~1.8 μs
-	mlkemDecap768Gas  = 150000  // ~1.4 μs
-	mlkemEncap1024Gas = 240000  // ~2.3 μs
-	mlkemDecap1024Gas = 150000  // ~1.4 μs
-	
-	// SLH-DSA gas costs (larger due to signature size)
-	slhdsaVerify128sGas = 200000  // ~2 μs
-	slhdsaVerify128fGas = 150000  // ~1.5 μs
-	slhdsaVerify192sGas = 300000  // ~3 μs
-	slhdsaVerify192fGas = 250000  // ~2.5 μs
-	slhdsaVerify256sGas = 400000  // ~4 μs
-	slhdsaVerify256fGas = 350000  // ~3.5 μs
+	mldsaVerify44Gas = 120000
+	mldsaVerify65Gas = 150000
+	mldsaVerify87Gas = 200000
+
+	// ML-KEM gas costs (encapsulation only for on-chain)
+	mlkemEncap512Gas  = 140000
+	mlkemEncap768Gas  = 190000
+	mlkemEncap1024Gas = 240000
+
+	// SLH-DSA gas costs
+	slhdsaVerify128sGas = 150000
+	slhdsaVerify128fGas = 620000
+	slhdsaVerify192sGas = 280000
+	slhdsaVerify192fGas = 920000
+	slhdsaVerify256sGas = 340000
+	slhdsaVerify256fGas = 570000
+
+	// pqVerifyPerWordGas is charged per 32 byte word of the signed message. Both
+	// ML-DSA and SLH-DSA absorb the message into SHAKE256 exactly once, so it is
+	// priced like the KECCAK256 opcode.
+	pqVerifyPerWordGas = 6
 )
 
-// mldsaVerify44 implements ML-DSA-44 signature verification
-type mldsaVerify44 struct{}
+var (
+	errPQVerifyInvalidInputLength = errors.New("invalid input length for post-quantum verify")
+	errMLKEMInvalidInputLength    = errors.New("invalid input length for ML-KEM encapsulate")
+	errMLKEMInvalidPublicKey      = errors.New("invalid ML-KEM encapsulation key")
+)
 
-func (c *mldsaVerify44) RequiredGas(input []byte) uint64 {
-	return mldsaVerify44Gas
+// pqVerify implements signature verification for one of the ML-DSA (FIPS 204)
+// or SLH-DSA (FIPS 205) parameter sets, with an empty context string.
+//
+// Input format: [pubkey || message || signature], where the public key and
+// signature sizes are fixed by the parameter set and the message takes up the
+// remaining bytes. The output is a 32 byte word, 1 if the signature is valid and
+// 0 otherwise. Inputs too short to hold a key and a signature are rejected.
+//
+// The gas cost is a fixed base plus a per-word charge on the message length.
+type pqVerify struct {
+	algo pqcrypto.Algorithm
+	gas  uint64
 }
 
-func (c *mldsaVerify44) Run(input []byte) ([]byte, error) {
-	// Input format: [pubkey(1312) || message(variable) || signature(2420)]
-	if len(input) < mldsa.MLDSA44PublicKeySize + 1 + mldsa.MLDSA44SignatureSize {
-		return nil, errors.New("invalid input length for ML-DSA-44 verify")
-	}
-	
-	pubKeyBytes := input[:mldsa.MLDSA44PublicKeySize]
-	sigStart := len(input) - mldsa.MLDSA44SignatureSize
-	signature := input[sigStart:]
-	message := input[mldsa.MLDSA44PublicKeySize:sigStart]
-	
-	pubKey, err := mldsa.PublicKeyFromBytes(pubKeyBytes, mldsa.MLDSA44)
-	if err != nil {
-		return common.LeftPadBytes([]byte{0}, 32), nil // Invalid public key
+func (c *pqVerify) RequiredGas(input []byte) uint64 {
+	var msgLen uint64
+	if size := len(input) - pqcrypto.PublicKeySize(c.algo) - pqcrypto.SignatureSize(c.algo); size > 0 {
+		msgLen = uint64(size)
 	}
-	
-	valid := pubKey.Verify(message, signature, nil)
-	if valid {
-		return common.LeftPadBytes([]byte{1}, 32), nil
-	}
-	return common.LeftPadBytes([]byte{0}, 32), nil
+	return c.gas + toWordSize(msgLen)*pqVerifyPerWordGas
 }
 
-// mldsaVerify65 implements ML-DSA-65 signature verification
-type mldsaVerify65 struct{}
-
-func (c *mldsaVerify65) RequiredGas(input []byte) uint64 {
-	return mldsaVerify65Gas
-}
-
-func (c *mldsaVerify65) Run(input []byte) ([]byte, error) {
-	// Input format: [pubkey(1952) || message(variable) || signature(3293)]
-	if len(input) < mldsa.MLDSA65PublicKeySize + 1 + mldsa.MLDSA65SignatureSize {
-		return nil, errors.New("invalid input length for ML-DSA-65 verify")
-	}
-	
-	pubKeyBytes := input[:mldsa.MLDSA65PublicKeySize]
-	sigStart := len(input) - mldsa.MLDSA65SignatureSize
-	signature := input[sigStart:]
-	message := input[mldsa.MLDSA65PublicKeySize:sigStart]
-	
-	pubKey, err := mldsa.PublicKeyFromBytes(pubKeyBytes, mldsa.MLDSA65)
-	if err != nil {
+func (c *pqVerify) Run(input []byte) ([]byte, error) {
+	pubSize, sigSize := pqcrypto.PublicKeySize(c.algo), pqcrypto.SignatureSize(c.algo)
+	if len(input) < pubSize+sigSize {
+		return nil, errPQVerifyInvalidInputLength
+	}
+	var (
+		sigStart  = len(input) - sigSize
+		pubKey    = input[:pubSize]
+		message   = input[pubSize:sigStart]
+		signature = input[sigStart:]
+	)
+	if !pqcrypto.Verify(c.algo, pubKey, message, signature) {
 		return common.LeftPadBytes([]byte{0}, 32), nil
 	}
-	
-	valid := pubKey.Verify(message, signature, nil)
-	if valid {
-		return common.LeftPadBytes([]byte{1}, 32), nil
-	}
-	return common.LeftPadBytes([]byte{0}, 32), nil
+	return common.LeftPadBytes([]byte{1}, 32), nil
 }
 
-// mldsaVerify87 implements ML-DSA-87 signature verification
-type mldsaVerify87 struct{}
-
-func (c *mldsaVerify87) RequiredGas(input []byte) uint64 {
-	return mldsaVerify87Gas
-}
-
-func (c *mldsaVerify87) Run(input []byte) ([]byte, error) {
-	// Input format: [pubkey(2592) || message(variable) || signature(4595)]
-	if len(input) < mldsa.MLDSA87PublicKeySize + 1 + mldsa.MLDSA87SignatureSize {
-		return nil, errors.New("invalid input length for ML-DSA-87 verify")
-	}
-	
-	pubKeyBytes := input[:mldsa.MLDSA87PublicKeySize]
-	sigStart := len(input) - mldsa.MLDSA87SignatureSize
-	signature := input[sigStart:]
-	message := input[mldsa.MLDSA87PublicKeySize:sigStart]
-	
-	pubKey, err := mldsa.PublicKeyFromBytes(pubKeyBytes, mldsa.MLDSA87)
-	if err != nil {
-		return common.LeftPadBytes([]byte{0}, 32), nil
-	}
-	
-	valid := pubKey.Verify(message, signature, nil)
-	if valid {
-		return common.LeftPadBytes([]byte{1}, 32), nil
-	}
-	return common.LeftPadBytes([]byte{0}, 32), nil
+// mlkemEncap implements FIPS 203 ML-KEM encapsulation with caller supplied
+// randomness, for one of the ML-KEM parameter sets.
+//
+// Input format: [encapsulation key || m], where m is the 32 byte randomness of
+// ML-KEM.Encaps_internal. The output is [ciphertext || shared secret]. Since the
+// randomness is part of the calldata the shared secret is public too: the
+// contract lets on-chain code check that a ciphertext is a well-formed
+// encapsulation, it does not establish a secret. Inputs of any other length
+// and encapsulation keys failing the FIPS 203 modulus check are rejected.
+type mlkemEncap struct {
+	algo pqcrypto.Algorithm
+	gas  uint64
 }
 
-// mlkemEncap768 implements ML-KEM-768 encapsulation
-type mlkemEncap768 struct{}
-
-func (c *mlkemEncap768) RequiredGas(input []byte) uint64 {
-	return mlkemEncap768Gas
+func (c *mlkemEncap) RequiredGas(input []byte) uint64 {
+	return c.gas
 }
 
-func (c *mlkemEncap768) Run(input []byte) ([]byte, error) {
-	// Input: public key (1184 bytes)
-	if len(input) != mlkem.MLKEM768PublicKeySize {
-		return nil, errors.New("invalid public key size for ML-KEM-768")
+func (c *mlkemEncap) Run(input []byte) ([]byte, error) {
+	keySize := pqcrypto.PublicKeySize(c.algo)
+	if len(input) != keySize+pqcrypto.EncapsulationSeedSize(c.algo) {
+		return nil, errMLKEMInvalidInputLength
 	}
-	
-	pubKey, err := mlkem.PublicKeyFromBytes(input, mlkem.MLKEM768)
-	if err != nil {
+	ct, ss, err := pqcrypto.EncapsulateDeterministically(c.algo, input[:keySize], input[keySize:])
+	if errors.Is(err, pqcrypto.ErrInvalidPublicKey) {
+		return nil, errMLKEMInvalidPublicKey
+	} else if err != nil {
 		return nil, err
 	}
-	
-	// Note: In production, we'd need a secure random source
-	// For deterministic testing, we could use block hash as seed
-	result, err := pubKey.Encapsulate(nil) // This will need proper randomness
-	if err != nil {
-		return nil, err
-	}
-	
-	// Return: ciphertext || shared_secret
-	output := make([]byte, mlkem.MLKEM768CiphertextSize+32)
-	copy(output, result.Ciphertext)
-	copy(output[mlkem.MLKEM768CiphertextSize:], result.SharedSecret)
-	
-	return output, nil
+	return append(ct, ss...), nil
 }
 
-// slhdsaVerify128f implements SLH-DSA-128f signature verification
-type slhdsaVerify128f struct{}
+// GetPostQuantumPrecompiles returns all post-quantum precompiles activated by
+// the Lux PostQuantum fork: the ML-DSA and SLH-DSA verifiers and ML-KEM
+// encapsulation with explicit randomness.
+func GetPostQuantumPrecompiles() PrecompiledContracts {
+	return PrecompiledContracts{
+		mldsaVerify44Address: &pqVerify{algo: pqcrypto.AlgoMLDSA44, gas: mldsaVerify44Gas},
+		mldsaVerify65Address: &pqVerify{algo: pqcrypto.AlgoMLDSA65, gas: mldsaVerify65Gas},
+		mldsaVerify87Address: &pqVerify{algo: pqcrypto.AlgoMLDSA87, gas: mldsaVerify87Gas},
 
-func (c *slhdsaVerify128f) RequiredGas(input []byte) uint64 {
-	return slhdsaVerify128fGas
-}
+		mlkemEncap512Address:  &mlkemEncap{algo: pqcrypto.AlgoMLKEM512, gas: mlkemEncap512Gas},
+		mlkemEncap768Address:  &mlkemEncap{algo: pqcrypto.AlgoMLKEM768, gas: mlkemEncap768Gas},
+		mlkemEncap1024Address: &mlkemEncap{algo: pqcrypto.AlgoMLKEM1024, gas: mlkemEncap1024Gas},
 
-func (c *slhdsaVerify128f) Run(input []byte) ([]byte, error) {
-	// Input format: [pubkey(32) || message(variable) || signature(17088)]
-	if len(input) < slhdsa.SLHDSA128fPublicKeySize + 1 + slhdsa.SLHDSA128fSignatureSize {
-		return nil, errors.New("invalid input length for SLH-DSA-128f verify")
+		slhdsaVerify128sAddress: &pqVerify{algo: pqcrypto.AlgoSLHDSA128s, gas: slhdsaVerify128sGas},
+		slhdsaVerify128fAddress: &pqVerify{algo: pqcrypto.AlgoSLHDSA128f, gas: slhdsaVerify128fGas},
+		slhdsaVerify192sAddress: &pqVerify{algo: pqcrypto.AlgoSLHDSA192s, gas: slhdsaVerify192sGas},
+		slhdsaVerify192fAddress: &pqVerify{algo: pqcrypto.AlgoSLHDSA192f, gas: slhdsaVerify192fGas},
+		slhdsaVerify256sAddress: &pqVerify{algo: pqcrypto.AlgoSLHDSA256s, gas: slhdsaVerify256sGas},
+		slhdsaVerify256fAddress: &pqVerify{algo: pqcrypto.AlgoSLHDSA256f, gas: slhdsaVerify256fGas},
 	}
-	
-	pubKeyBytes := input[:slhdsa.SLHDSA128fPublicKeySize]
-	sigStart := len(input) - slhdsa.SLHDSA128fSignatureSize
-	signature := input[sigStart:]
-	message := input[slhdsa.SLHDSA128fPublicKeySize:sigStart]
-	
-	pubKey, err := slhdsa.PublicKeyFromBytes(pubKeyBytes, slhdsa.SLHDSA128f)
-	if err != nil {
-		return common.LeftPadBytes([]byte{0}, 32), nil
-	}
-	
-	valid := pubKey.Verify(message, signature, nil)
-	if valid {
-		return common.LeftPadBytes([]byte{1}, 32), nil
-	}
-	return common.LeftPadBytes([]byte{0}, 32), nil
 }
 
-// GetPostQuantumPrecompiles returns all post-quantum precompiles
-func GetPostQuantumPrecompiles() PrecompiledContracts {
-	return PrecompiledContracts{
-		// ML-DSA (Dilithium)
-		mldsaVerify44Address: &mldsaVerify44{},
-		mldsaVerify65Address: &mldsaVerify65{},
-		mldsaVerify87Address: &mldsaVerify87{},
-		
-		// ML-KEM (Kyber) - only encapsulation for now
-		mlkemEncap768Address: &mlkemEncap768{},
-		
-		// SLH-DSA (SPHINCS+) - only 128f for now
-		slhdsaVerify128fAddress: &slhdsaVerify128f{},
-	}
-}
+// PrecompiledContractsLux contains the Prague set of pre-compiled contracts
+// extended with the post-quantum contracts, activated by the Lux PostQuantum fork.
+var PrecompiledContractsLux = withPostQuantum(PrecompiledContractsPrague)
 
-// PrecompiledContractsLux includes all standard contracts plus post-quantum
-var PrecompiledContractsLux = func() PrecompiledContracts {
-	contracts := make(PrecompiledContracts)
-	
-	// Copy all Prague contracts
-	for addr, contract := range PrecompiledContractsPrague {
-		contracts[addr] = contract
-	}
-	
-	// Add post-quantum contracts
-	for addr, contract := range GetPostQuantumPrecompiles() {
-		contracts[addr] = contract
-	}
-	
+// PrecompiledContractsLuxOsaka contains the Osaka set of pre-compiled contracts
+// extended with the post-quantum contracts, used if both Osaka and the Lux
+// PostQuantum fork are active.
+var PrecompiledContractsLuxOsaka = withPostQuantum(PrecompiledContractsOsaka)
+
+// withPostQuantum returns a new contract set containing base plus all the
+// post-quantum precompiles.
+func withPostQuantum(base PrecompiledContracts) PrecompiledContracts {
+	contracts := maps.Clone(base)
+	maps.Copy(contracts, GetPostQuantumPrecompiles())
 	return contracts
-}()
+}
 
 // PostQuantumAddresses returns all post-quantum precompile addresses
 func PostQuantumAddresses() []common.Address {
@@ -255,7 +195,14 @@ func PostQuantumAddresses() []common.Address {
 		mldsaVerify44Address,
 		mldsaVerify65Address,
 		mldsaVerify87Address,
+		mlkemEncap512Address,
 		mlkemEncap768Address,
+		mlkemEncap1024Address,
+		slhdsaVerify128sAddress,
 		slhdsaVerify128fAddress,
+		slhdsaVerify192sAddress,
+		slhdsaVerify192fAddress,
+		slhdsaVerify256sAddress,
+		slhdsaVerify256fAddress,
 	}
-}
\ No newline at end of file
+}
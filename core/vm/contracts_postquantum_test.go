@@ -0,0 +1,233 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// Post-quantum cryptography precompiled contract tests
+
+package vm
+
+import (
+	"bytes"
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/luxfi/geth/common"
+	"github.com/luxfi/geth/core/state"
+	"github.com/luxfi/geth/core/types"
+	"github.com/luxfi/geth/crypto/pqcrypto"
+	"github.com/luxfi/geth/params"
+)
+
+func TestPrecompiledMLDSAVerify44(t *testing.T)    { testJson("mldsaVerify44", "0110", t) }
+func TestPrecompiledMLDSAVerify65(t *testing.T)    { testJson("mldsaVerify65", "0111", t) }
+func TestPrecompiledMLDSAVerify87(t *testing.T)    { testJson("mldsaVerify87", "0112", t) }
+func TestPrecompiledMLKEMEncap512(t *testing.T)    { testJson("mlkemEncap512", "0120", t) }
+func TestPrecompiledMLKEMEncap768(t *testing.T)    { testJson("mlkemEncap768", "0122", t) }
+func TestPrecompiledMLKEMEncap1024(t *testing.T)   { testJson("mlkemEncap1024", "0124", t) }
+func TestPrecompiledSLHDSAVerify128s(t *testing.T) { testJson("slhdsaVerify128s", "0130", t) }
+func TestPrecompiledSLHDSAVerify128f(t *testing.T) { testJson("slhdsaVerify128f", "0131", t) }
+func TestPrecompiledSLHDSAVerify192s(t *testing.T) { testJson("slhdsaVerify192s", "0132", t) }
+func TestPrecompiledSLHDSAVerify192f(t *testing.T) { testJson("slhdsaVerify192f", "0133", t) }
+func TestPrecompiledSLHDSAVerify256s(t *testing.T) { testJson("slhdsaVerify256s", "0134", t) }
+func TestPrecompiledSLHDSAVerify256f(t *testing.T) { testJson("slhdsaVerify256f", "0135", t) }
+
+func TestPrecompiledMLKEMEncap512Fail(t *testing.T)    { testJsonFail("mlkemEncap512", "0120", t) }
+func TestPrecompiledMLKEMEncap768Fail(t *testing.T)    { testJsonFail("mlkemEncap768", "0122", t) }
+func TestPrecompiledMLKEMEncap1024Fail(t *testing.T)   { testJsonFail("mlkemEncap1024", "0124", t) }
+func TestPrecompiledSLHDSAVerify128sFail(t *testing.T) { testJsonFail("slhdsaVerify128s", "0130", t) }
+func TestPrecompiledSLHDSAVerify128fFail(t *testing.T) { testJsonFail("slhdsaVerify128f", "0131", t) }
+func TestPrecompiledSLHDSAVerify192sFail(t *testing.T) { testJsonFail("slhdsaVerify192s", "0132", t) }
+func TestPrecompiledSLHDSAVerify192fFail(t *testing.T) { testJsonFail("slhdsaVerify192f", "0133", t) }
+func TestPrecompiledSLHDSAVerify256sFail(t *testing.T) { testJsonFail("slhdsaVerify256s", "0134", t) }
+func TestPrecompiledSLHDSAVerify256fFail(t *testing.T) { testJsonFail("slhdsaVerify256f", "0135", t) }
+
+func BenchmarkPrecompiledMLDSAVerify44(b *testing.B)    { benchJson("mldsaVerify44", "0110", b) }
+func BenchmarkPrecompiledMLDSAVerify65(b *testing.B)    { benchJson("mldsaVerify65", "0111", b) }
+func BenchmarkPrecompiledMLDSAVerify87(b *testing.B)    { benchJson("mldsaVerify87", "0112", b) }
+func BenchmarkPrecompiledMLKEMEncap512(b *testing.B)    { benchJson("mlkemEncap512", "0120", b) }
+func BenchmarkPrecompiledMLKEMEncap768(b *testing.B)    { benchJson("mlkemEncap768", "0122", b) }
+func BenchmarkPrecompiledMLKEMEncap1024(b *testing.B)   { benchJson("mlkemEncap1024", "0124", b) }
+func BenchmarkPrecompiledSLHDSAVerify128s(b *testing.B) { benchJson("slhdsaVerify128s", "0130", b) }
+func BenchmarkPrecompiledSLHDSAVerify128f(b *testing.B) { benchJson("slhdsaVerify128f", "0131", b) }
+func BenchmarkPrecompiledSLHDSAVerify192s(b *testing.B) { benchJson("slhdsaVerify192s", "0132", b) }
+func BenchmarkPrecompiledSLHDSAVerify192f(b *testing.B) { benchJson("slhdsaVerify192f", "0133", b) }
+func BenchmarkPrecompiledSLHDSAVerify256s(b *testing.B) { benchJson("slhdsaVerify256s", "0134", b) }
+func BenchmarkPrecompiledSLHDSAVerify256f(b *testing.B) { benchJson("slhdsaVerify256f", "0135", b) }
+
+// Tests that the private key operations are never assigned a contract.
+func TestPostQuantumReservedAddresses(t *testing.T) {
+	reserved := []common.Address{
+		mldsaSign44Address, mldsaSign65Address, mldsaSign87Address,
+		mlkemDecap512Address, mlkemDecap768Address, mlkemDecap1024Address,
+	}
+	for _, addr := range reserved {
+		if _, ok := PrecompiledContractsLuxOsaka[addr]; ok {
+			t.Errorf("reserved address %x has a contract", addr)
+		}
+		if slices.Contains(PostQuantumAddresses(), addr) {
+			t.Errorf("reserved address %x listed as active", addr)
+		}
+	}
+}
+
+// Tests that the verifiers charge per word of message on top of the base cost.
+func TestPostQuantumVerifyGas(t *testing.T) {
+	p := GetPostQuantumPrecompiles()[mldsaVerify44Address].(*pqVerify)
+	fixed := pqcrypto.PublicKeySize(p.algo) + pqcrypto.SignatureSize(p.algo)
+	for _, tt := range []struct {
+		size int
+		want uint64
+	}{
+		{0, mldsaVerify44Gas},
+		{fixed, mldsaVerify44Gas},
+		{fixed + 1, mldsaVerify44Gas + pqVerifyPerWordGas},
+		{fixed + 32, mldsaVerify44Gas + pqVerifyPerWordGas},
+		{fixed + 33, mldsaVerify44Gas + 2*pqVerifyPerWordGas},
+		{fixed + 1024, mldsaVerify44Gas + 32*pqVerifyPerWordGas},
+	} {
+		if have := p.RequiredGas(make([]byte, tt.size)); have != tt.want {
+			t.Errorf("input size %d: gas mismatch: have %d, want %d", tt.size, have, tt.want)
+		}
+	}
+}
+
+// postQuantumTestConfig returns a chain config with the Lux PostQuantum fork
+// scheduled at the given timestamp, optionally on top of Osaka.
+func postQuantumTestConfig(fork uint64, osaka bool) *params.ChainConfig {
+	config := *params.MergedTestChainConfig
+	config.OsakaTime = nil
+	if osaka {
+		config.OsakaTime = new(uint64)
+	}
+	config.PostQuantumTime = &fork
+	return &config
+}
+
+// Tests that the post-quantum precompiles are only selected once the Lux
+// PostQuantum fork is active, on top of both the Prague and Osaka sets.
+func TestActivePrecompilesPostQuantum(t *testing.T) {
+	for i, tt := range []struct {
+		osaka bool
+		time  uint64
+		want  bool
+	}{
+		{osaka: false, time: 99, want: false},
+		{osaka: false, time: 100, want: true},
+		{osaka: true, time: 99, want: false},
+		{osaka: true, time: 100, want: true},
+	} {
+		rules := postQuantumTestConfig(100, tt.osaka).Rules(big.NewInt(0), true, tt.time)
+
+		// The address list must match the contracts one-to-one
+		active := ActivePrecompiles(rules)
+		contracts := ActivePrecompiledContracts(rules)
+		if len(active) != len(contracts) {
+			t.Errorf("test %d: address/contract count mismatch: %d != %d", i, len(active), len(contracts))
+		}
+		for _, addr := range active {
+			if _, ok := contracts[addr]; !ok {
+				t.Errorf("test %d: active address %x has no contract", i, addr)
+			}
+		}
+		// The fork must not drop or replace any of the Ethereum precompiles
+		base := PrecompiledContractsPrague
+		if tt.osaka {
+			base = PrecompiledContractsOsaka
+		}
+		for addr, want := range base {
+			if have, ok := contracts[addr]; !ok || have != want {
+				t.Errorf("test %d: base precompile %x missing or replaced", i, addr)
+			}
+		}
+		for _, addr := range PostQuantumAddresses() {
+			if have := slices.Contains(active, addr); have != tt.want {
+				t.Errorf("test %d: address %x active mismatch: have %v, want %v", i, addr, have, tt.want)
+			}
+			if _, have := contracts[addr]; have != tt.want {
+				t.Errorf("test %d: contract %x active mismatch: have %v, want %v", i, addr, have, tt.want)
+			}
+		}
+		if want := len(base); !tt.want && len(contracts) != want {
+			t.Errorf("test %d: contract count mismatch: have %d, want %d", i, len(contracts), want)
+		}
+		if want := len(base) + len(PostQuantumAddresses()); tt.want && len(contracts) != want {
+			t.Errorf("test %d: contract count mismatch: have %d, want %d", i, len(contracts), want)
+		}
+	}
+}
+
+// Tests that every contract in the post-quantum fork set can be executed with
+// arbitrary input without crashing, and that its output is deterministic.
+func TestPostQuantumPrecompilesRun(t *testing.T) {
+	inputs := [][]byte{
+		nil,
+		make([]byte, 1),
+		make([]byte, 1184),
+		make([]byte, 800+32),  // ML-KEM-512 key and randomness
+		make([]byte, 1184+32), // ML-KEM-768 key and randomness
+		make([]byte, 1568+32), // ML-KEM-1024 key and randomness
+		make([]byte, 8192),
+		bytes.Repeat([]byte{0xff}, 8192),
+		make([]byte, 64+49856+32), // fits the largest SLH-DSA key and signature
+	}
+	for addr, p := range GetPostQuantumPrecompiles() {
+		if _, ok := PrecompiledContractsLuxOsaka[addr]; !ok {
+			t.Errorf("precompile %x missing from the fork set", addr)
+		}
+		for _, input := range inputs {
+			gas := p.RequiredGas(input)
+			out1, _, err1 := RunPrecompiledContract(p, input, gas, nil)
+			out2, _, err2 := RunPrecompiledContract(p, input, gas, nil)
+			if !bytes.Equal(out1, out2) || (err1 == nil) != (err2 == nil) {
+				t.Errorf("precompile %x: non-deterministic output for input length %d", addr, len(input))
+			}
+		}
+	}
+}
+
+// Tests that calling a post-quantum precompile through the EVM only executes
+// the contract after the fork, and is a plain call to an empty account before.
+func TestPostQuantumForkBoundary(t *testing.T) {
+	tests, err := loadJson("mldsaVerify44")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var valid precompiledTest
+	for _, test := range tests {
+		if test.Name == "valid-short" {
+			valid = test
+		}
+	}
+	input := common.Hex2Bytes(valid.Input)
+	config := postQuantumTestConfig(100, true)
+
+	for _, tt := range []struct {
+		time uint64
+		want []byte
+	}{
+		{time: 99, want: nil},
+		{time: 100, want: common.LeftPadBytes([]byte{1}, 32)},
+	} {
+		statedb, _ := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+		vmctx := BlockContext{
+			CanTransfer: func(StateDB, common.Address, *uint256.Int) bool { return true },
+			Transfer:    func(StateDB, common.Address, common.Address, *uint256.Int) {},
+			BlockNumber: big.NewInt(1),
+			Time:        tt.time,
+			Random:      &common.Hash{},
+		}
+		evm := NewEVM(vmctx, statedb, config, Config{})
+
+		ret, leftOver, err := evm.Call(common.Address{}, mldsaVerify44Address, input, 1_000_000, new(uint256.Int))
+		if err != nil {
+			t.Fatalf("time %d: call failed: %v", tt.time, err)
+		}
+		if !bytes.Equal(ret, tt.want) {
+			t.Errorf("time %d: output mismatch: have %x, want %x", tt.time, ret, tt.want)
+		}
+		if tt.want != nil {
+			if used := 1_000_000 - leftOver; used != valid.Gas {
+				t.Errorf("time %d: gas used mismatch: have %d, want %d", tt.time, used, valid.Gas)
+			}
+		}
+	}
+}
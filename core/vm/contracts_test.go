@@ -68,6 +68,19 @@ var allPrecompiles = map[common.Address]PrecompiledContract{
 	common.BytesToAddress([]byte{0x0f, 0x10}): &bls12381MapG2{},
 
 	common.BytesToAddress([]byte{0x0b}): &p256Verify{},
+
+	common.BytesToAddress([]byte{0x01, 0x10}): GetPostQuantumPrecompiles()[mldsaVerify44Address],
+	common.BytesToAddress([]byte{0x01, 0x11}): GetPostQuantumPrecompiles()[mldsaVerify65Address],
+	common.BytesToAddress([]byte{0x01, 0x12}): GetPostQuantumPrecompiles()[mldsaVerify87Address],
+	common.BytesToAddress([]byte{0x01, 0x20}): GetPostQuantumPrecompiles()[mlkemEncap512Address],
+	common.BytesToAddress([]byte{0x01, 0x22}): GetPostQuantumPrecompiles()[mlkemEncap768Address],
+	common.BytesToAddress([]byte{0x01, 0x24}): GetPostQuantumPrecompiles()[mlkemEncap1024Address],
+	common.BytesToAddress([]byte{0x01, 0x30}): GetPostQuantumPrecompiles()[slhdsaVerify128sAddress],
+	common.BytesToAddress([]byte{0x01, 0x31}): GetPostQuantumPrecompiles()[slhdsaVerify128fAddress],
+	common.BytesToAddress([]byte{0x01, 0x32}): GetPostQuantumPrecompiles()[slhdsaVerify192sAddress],
+	common.BytesToAddress([]byte{0x01, 0x33}): GetPostQuantumPrecompiles()[slhdsaVerify192fAddress],
+	common.BytesToAddress([]byte{0x01, 0x34}): GetPostQuantumPrecompiles()[slhdsaVerify256sAddress],
+	common.BytesToAddress([]byte{0x01, 0x35}): GetPostQuantumPrecompiles()[slhdsaVerify256fAddress],
 }
 
 // EIP-152 test vectors
@@ -0,0 +1,219 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// Post-Quantum Cryptography Integration for Geth
+
+package pqcrypto
+
+import (
+	"crypto/ecdsa"
+	"errors"
+
+	"github.com/cloudflare/circl/kem/mlkem/mlkem768"
+	"github.com/cloudflare/circl/sign/mldsa/mldsa65"
+	"github.com/luxfi/crypto"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	// secp256k1 sizes used by the classical and hybrid algorithms.
+	secp256k1PrivateKeySize          = 32
+	secp256k1PublicKeySize           = 65 // uncompressed
+	secp256k1CompressedPublicKeySize = 33
+	secp256k1SignatureSize           = 65 // [R || S || V]
+
+	// hybridSignatureDomain prefixes every message signed by a hybrid key.
+	hybridSignatureDomain = "LUX-HYBRID-SECP256K1-MLDSA65"
+
+	// hybridKEMLabel is mixed into every hybrid shared secret.
+	hybridKEMLabel = "LUX-HYBRID-SECP256K1-MLKEM768"
+)
+
+var errInvalidHybridSize = errors.New("invalid hybrid encoding size")
+
+// classicalScheme signs keccak256(msg) with secp256k1. Public keys are encoded
+// uncompressed; signatures are in the [R || S || V] format where V is 0 or 1.
+var classicalScheme = &signatureScheme{
+	publicKeySize:  secp256k1PublicKeySize,
+	privateKeySize: secp256k1PrivateKeySize,
+	signatureSize:  secp256k1SignatureSize,
+	seedSize:       secp256k1PrivateKeySize,
+	newKey: func(seed []byte) ([]byte, any, error) {
+		key, err := crypto.ToECDSA(seed)
+		if err != nil {
+			return nil, nil, err
+		}
+		return crypto.FromECDSAPub(&key.PublicKey), key, nil
+	},
+	sign: func(priv any, msg []byte) ([]byte, error) {
+		return crypto.Sign(crypto.Keccak256(msg), priv.(*ecdsa.PrivateKey))
+	},
+	verify: func(pub, msg, sig []byte) bool {
+		return verifySecp256k1(pub, crypto.Keccak256(msg), sig)
+	},
+}
+
+// verifySecp256k1 checks a [R || S || V] signature over hash, rejecting
+// malleable signatures and recovery IDs other than 0 and 1.
+func verifySecp256k1(pub, hash, sig []byte) bool {
+	if len(sig) != secp256k1SignatureSize || sig[64] > 1 {
+		return false
+	}
+	return crypto.VerifySignature(pub, hash, sig[:64])
+}
+
+// hybridKey is the expanded private key of the hybrid algorithms.
+type hybridKey struct {
+	classical *ecdsa.PrivateKey
+	pq        any
+}
+
+// hybridSignatureScheme pairs secp256k1 with ML-DSA-65, see the package
+// documentation for the encoding.
+var hybridSignatureScheme = func() *signatureScheme {
+	pq := mldsaScheme(mldsa65.Scheme())
+	return &signatureScheme{
+		publicKeySize:  secp256k1CompressedPublicKeySize + pq.publicKeySize,
+		privateKeySize: secp256k1PrivateKeySize + pq.privateKeySize,
+		signatureSize:  secp256k1SignatureSize + pq.signatureSize,
+		seedSize:       secp256k1PrivateKeySize + pq.seedSize,
+		newKey: func(seed []byte) ([]byte, any, error) {
+			classical, err := crypto.ToECDSA(seed[:secp256k1PrivateKeySize])
+			if err != nil {
+				return nil, nil, err
+			}
+			pqPub, pqPriv, err := pq.newKey(seed[secp256k1PrivateKeySize:])
+			if err != nil {
+				return nil, nil, err
+			}
+			pub := append(crypto.CompressPubkey(&classical.PublicKey), pqPub...)
+			return pub, &hybridKey{classical, pqPriv}, nil
+		},
+		sign: func(priv any, msg []byte) ([]byte, error) {
+			key := priv.(*hybridKey)
+			m := hybridMessage(msg)
+			classicalSig, err := crypto.Sign(crypto.Keccak256(m), key.classical)
+			if err != nil {
+				return nil, err
+			}
+			pqSig, err := pq.sign(key.pq, m)
+			if err != nil {
+				return nil, err
+			}
+			return append(classicalSig, pqSig...), nil
+		},
+		verify: func(pub, msg, sig []byte) bool {
+			classicalPub, pqPub, err := SplitHybridPublicKey(pub)
+			if err != nil {
+				return false
+			}
+			classicalSig, pqSig, err := SplitHybridSignature(sig)
+			if err != nil {
+				return false
+			}
+			m := hybridMessage(msg)
+			return verifySecp256k1(classicalPub, crypto.Keccak256(m), classicalSig) && pq.verify(pqPub, m, pqSig)
+		},
+	}
+}()
+
+// hybridMessage returns the message representative signed by both halves of a
+// hybrid signature.
+func hybridMessage(msg []byte) []byte {
+	return append([]byte(hybridSignatureDomain), msg...)
+}
+
+// SplitHybridPublicKey splits an AlgoHybridSecp256k1MLDSA public key into its
+// compressed secp256k1 and ML-DSA-65 halves.
+func SplitHybridPublicKey(pub []byte) (classical, pq []byte, err error) {
+	if len(pub) != secp256k1CompressedPublicKeySize+mldsa65.PublicKeySize {
+		return nil, nil, errInvalidHybridSize
+	}
+	return pub[:secp256k1CompressedPublicKeySize], pub[secp256k1CompressedPublicKeySize:], nil
+}
+
+// SplitHybridSignature splits an AlgoHybridSecp256k1MLDSA signature into its
+// secp256k1 and ML-DSA-65 halves. Neither half is a valid signature of the
+// original message on its own.
+func SplitHybridSignature(sig []byte) (classical, pq []byte, err error) {
+	if len(sig) != secp256k1SignatureSize+mldsa65.SignatureSize {
+		return nil, nil, errInvalidHybridSize
+	}
+	return sig[:secp256k1SignatureSize], sig[secp256k1SignatureSize:], nil
+}
+
+// hybridKEMScheme pairs a secp256k1 Diffie-Hellman exchange with ML-KEM-768,
+// see the package documentation for the encoding.
+var hybridKEMScheme = func() *kemScheme {
+	pq := mlkemScheme(mlkem768.Scheme())
+	return &kemScheme{
+		publicKeySize:  secp256k1CompressedPublicKeySize + pq.publicKeySize,
+		privateKeySize: secp256k1PrivateKeySize + pq.privateKeySize,
+		ciphertextSize: secp256k1CompressedPublicKeySize + pq.ciphertextSize,
+		seedSize:       secp256k1PrivateKeySize + pq.seedSize,
+		encapSeedSize:  secp256k1PrivateKeySize + pq.encapSeedSize,
+		newKey: func(seed []byte) ([]byte, any, error) {
+			classical, err := crypto.ToECDSA(seed[:secp256k1PrivateKeySize])
+			if err != nil {
+				return nil, nil, err
+			}
+			pqPub, pqPriv, err := pq.newKey(seed[secp256k1PrivateKeySize:])
+			if err != nil {
+				return nil, nil, err
+			}
+			pub := append(crypto.CompressPubkey(&classical.PublicKey), pqPub...)
+			return pub, &hybridKey{classical, pqPriv}, nil
+		},
+		encapsulate: func(pub, seed []byte) ([]byte, []byte, error) {
+			classicalPub, err := crypto.DecompressPubkey(pub[:secp256k1CompressedPublicKeySize])
+			if err != nil {
+				return nil, nil, ErrInvalidPublicKey
+			}
+			ephemeral, err := crypto.ToECDSA(seed[:secp256k1PrivateKeySize])
+			if err != nil {
+				return nil, nil, err
+			}
+			pqCt, pqSS, err := pq.encapsulate(pub[secp256k1CompressedPublicKeySize:], seed[secp256k1PrivateKeySize:])
+			if err != nil {
+				return nil, nil, err
+			}
+			ct := append(crypto.CompressPubkey(&ephemeral.PublicKey), pqCt...)
+			ss := hybridSharedSecret(pqSS, ecdh(ephemeral, classicalPub), ct[:secp256k1CompressedPublicKeySize], pub[:secp256k1CompressedPublicKeySize])
+			return ct, ss, nil
+		},
+		decapsulate: func(priv any, ct []byte) ([]byte, error) {
+			key := priv.(*hybridKey)
+			if len(ct) != secp256k1CompressedPublicKeySize+pq.ciphertextSize {
+				return nil, errInvalidHybridSize
+			}
+			ephemeral, err := crypto.DecompressPubkey(ct[:secp256k1CompressedPublicKeySize])
+			if err != nil {
+				return nil, err
+			}
+			pqSS, err := pq.decapsulate(key.pq, ct[secp256k1CompressedPublicKeySize:])
+			if err != nil {
+				return nil, err
+			}
+			pub := crypto.CompressPubkey(&key.classical.PublicKey)
+			return hybridSharedSecret(pqSS, ecdh(key.classical, ephemeral), ct[:secp256k1CompressedPublicKeySize], pub), nil
+		},
+	}
+}()
+
+// ecdh returns the x coordinate of the secp256k1 Diffie-Hellman point.
+func ecdh(priv *ecdsa.PrivateKey, pub *ecdsa.PublicKey) []byte {
+	x, _ := crypto.S256().ScalarMult(pub.X, pub.Y, priv.D.Bytes())
+	return crypto.PaddedBigBytes(x, 32)
+}
+
+// hybridSharedSecret combines the two shared secrets, binding the classical
+// exchange to its public values:
+//
+//	SHA3-256(ss_mlkem || ss_ecdh || ephemeral key || recipient key || label)
+func hybridSharedSecret(pqSS, classicalSS, ephemeral, recipient []byte) []byte {
+	h := sha3.New256()
+	h.Write(pqSS)
+	h.Write(classicalSS)
+	h.Write(ephemeral)
+	h.Write(recipient)
+	h.Write([]byte(hybridKEMLabel))
+	return h.Sum(nil)
+}
@@ -1,19 +1,55 @@
 // Copyright (C) 2025, Lux Industries Inc. All rights reserved.
 // Post-Quantum Cryptography Integration for Geth
 
+// Package pqcrypto implements the post-quantum and hybrid algorithms shared by
+// the precompiles, post-quantum transactions, the keystore and the RPC layer.
+//
+// Signatures use the pure variants of ML-DSA (FIPS 204) and SLH-DSA (FIPS 205)
+// with an empty context string; signing is deterministic. Key encapsulation uses
+// ML-KEM (FIPS 203). Keys are encoded as specified by the standards and private
+// keys are serialized as the seed they are derived from.
+//
+// The address of a key is the last 20 bytes of the Keccak256 hash of its encoded
+// public key. AlgoClassical keys are regular secp256k1 keys with the usual
+// Ethereum address, public key encoding and [R || S || V] signatures over the
+// Keccak256 hash of the message.
+//
+// # Hybrid signatures
+//
+// AlgoHybridSecp256k1MLDSA pairs a secp256k1 key with an ML-DSA-65 key:
+//
+//	public key = compressed secp256k1 key (33 bytes) || ML-DSA-65 key (1952 bytes)
+//	signature  = secp256k1 signature (65 bytes) || ML-DSA-65 signature (3309 bytes)
+//	seed       = secp256k1 private key (32 bytes) || ML-DSA-65 seed (32 bytes)
+//
+// Both halves sign M' = "LUX-HYBRID-SECP256K1-MLDSA65" || msg, the secp256k1 half
+// through its Keccak256 hash. A hybrid signature is only valid if both halves
+// are, so it stays secure as long as either algorithm is. The domain prefix
+// prevents a half from being stripped off and passed off as a standalone
+// signature of msg, and since the address commits to both keys neither half can
+// be swapped out either.
+//
+// # Hybrid key encapsulation
+//
+// AlgoHybridSecp256k1MLKEM combines an ephemeral-static secp256k1 Diffie-Hellman
+// exchange with ML-KEM-768:
+//
+//	public key = compressed secp256k1 key (33 bytes) || ML-KEM-768 key (1184 bytes)
+//	ciphertext = compressed ephemeral key (33 bytes) || ML-KEM-768 ciphertext (1088 bytes)
+//	secret     = SHA3-256(ss_mlkem || ss_ecdh || ephemeral key || recipient key || label)
+//
+// where ss_ecdh is the x coordinate of the shared point and the label is
+// "LUX-HYBRID-SECP256K1-MLKEM768".
 package pqcrypto
 
 import (
-	"crypto/ecdsa"
 	"crypto/rand"
 	"errors"
 	"fmt"
+	"io"
 
-	"github.com/luxfi/geth/common"
 	"github.com/luxfi/crypto"
-	"github.com/luxfi/crypto/mldsa"
-	"github.com/luxfi/crypto/mlkem"
-	"github.com/luxfi/crypto/slhdsa"
+	"github.com/luxfi/geth/common"
 )
 
 // Algorithm types
@@ -32,228 +68,246 @@ const (
 	AlgoSLHDSA256s
 	AlgoHybridSecp256k1MLDSA
 	AlgoHybridSecp256k1MLKEM
+	AlgoSLHDSA128f
+	AlgoSLHDSA192f
+	AlgoSLHDSA256f
 )
 
-// PQSigner represents a post-quantum capable signer
-type PQSigner struct {
-	algo       Algorithm
-	classical  *ecdsa.PrivateKey  // For hybrid modes
-	mldsaPriv  *mldsa.PrivateKey
-	mlkemPriv  *mlkem.PrivateKey
-	slhdsaPriv *slhdsa.PrivateKey
-}
+var (
+	ErrUnsupportedAlgorithm = errors.New("unsupported algorithm")
+	ErrInvalidPublicKey     = errors.New("invalid public key")
+	ErrInvalidSignature     = errors.New("invalid signature")
+	ErrInvalidSeed          = errors.New("invalid seed length")
+	ErrRecoveryNotSupported = errors.New("public key recovery not supported")
+)
 
-// NewPQSigner creates a new post-quantum signer
-func NewPQSigner(algo Algorithm) (*PQSigner, error) {
-	signer := &PQSigner{algo: algo}
-	
-	switch algo {
+// String returns the string representation of the algorithm
+func (a Algorithm) String() string {
+	switch a {
 	case AlgoClassical:
-		key, err := crypto.GenerateKey()
-		if err != nil {
-			return nil, err
-		}
-		signer.classical = key
-		
+		return "Classical"
 	case AlgoMLDSA44:
-		priv, err := mldsa.GenerateKey(rand.Reader, mldsa.MLDSA44)
-		if err != nil {
-			return nil, err
-		}
-		signer.mldsaPriv = priv
-		
+		return "ML-DSA-44"
 	case AlgoMLDSA65:
-		priv, err := mldsa.GenerateKey(rand.Reader, mldsa.MLDSA65)
-		if err != nil {
-			return nil, err
-		}
-		signer.mldsaPriv = priv
-		
+		return "ML-DSA-65"
 	case AlgoMLDSA87:
-		priv, err := mldsa.GenerateKey(rand.Reader, mldsa.MLDSA87)
-		if err != nil {
-			return nil, err
-		}
-		signer.mldsaPriv = priv
-		
+		return "ML-DSA-87"
 	case AlgoMLKEM512:
-		priv, err := mlkem.GenerateKeyPair(rand.Reader, mlkem.MLKEM512)
-		if err != nil {
-			return nil, err
-		}
-		signer.mlkemPriv = priv
-		
+		return "ML-KEM-512"
 	case AlgoMLKEM768:
-		priv, err := mlkem.GenerateKeyPair(rand.Reader, mlkem.MLKEM768)
-		if err != nil {
-			return nil, err
-		}
-		signer.mlkemPriv = priv
-		
+		return "ML-KEM-768"
 	case AlgoMLKEM1024:
-		priv, err := mlkem.GenerateKeyPair(rand.Reader, mlkem.MLKEM1024)
-		if err != nil {
-			return nil, err
-		}
-		signer.mlkemPriv = priv
-		
+		return "ML-KEM-1024"
 	case AlgoSLHDSA128s:
-		priv, err := slhdsa.GenerateKey(rand.Reader, slhdsa.SLHDSA128s)
-		if err != nil {
-			return nil, err
-		}
-		signer.slhdsaPriv = priv
-		
+		return "SLH-DSA-128s"
 	case AlgoSLHDSA192s:
-		priv, err := slhdsa.GenerateKey(rand.Reader, slhdsa.SLHDSA192s)
-		if err != nil {
-			return nil, err
-		}
-		signer.slhdsaPriv = priv
-		
+		return "SLH-DSA-192s"
 	case AlgoSLHDSA256s:
-		priv, err := slhdsa.GenerateKey(rand.Reader, slhdsa.SLHDSA256s)
-		if err != nil {
-			return nil, err
-		}
-		signer.slhdsaPriv = priv
-		
+		return "SLH-DSA-256s"
+	case AlgoSLHDSA128f:
+		return "SLH-DSA-128f"
+	case AlgoSLHDSA192f:
+		return "SLH-DSA-192f"
+	case AlgoSLHDSA256f:
+		return "SLH-DSA-256f"
 	case AlgoHybridSecp256k1MLDSA:
-		// Generate both classical and PQ keys
-		classical, err := crypto.GenerateKey()
-		if err != nil {
-			return nil, err
-		}
-		signer.classical = classical
-		
-		priv, err := mldsa.GenerateKey(rand.Reader, mldsa.MLDSA44)
-		if err != nil {
-			return nil, err
-		}
-		signer.mldsaPriv = priv
-		
+		return "Hybrid-Secp256k1-MLDSA"
 	case AlgoHybridSecp256k1MLKEM:
-		// Generate both classical and KEM keys
-		classical, err := crypto.GenerateKey()
-		if err != nil {
-			return nil, err
+		return "Hybrid-Secp256k1-MLKEM"
+	default:
+		return "Unknown"
+	}
+}
+
+// Verify reports whether sig is a valid signature of msg by the encoded public
+// key pub. It returns false for keys and signatures of the wrong size and for
+// algorithms that are not signature algorithms.
+func Verify(algo Algorithm, pub, msg, sig []byte) bool {
+	s, ok := signatureSchemes[algo]
+	if !ok || len(pub) != s.publicKeySize || len(sig) != s.signatureSize {
+		return false
+	}
+	return s.verify(pub, msg, sig)
+}
+
+// PubkeyToAddress returns the address of an encoded public key.
+func PubkeyToAddress(algo Algorithm, pub []byte) (common.Address, error) {
+	if size := PublicKeySize(algo); size == 0 {
+		return common.Address{}, ErrUnsupportedAlgorithm
+	} else if len(pub) != size {
+		return common.Address{}, ErrInvalidPublicKey
+	}
+	if algo == AlgoClassical {
+		// Same as crypto.PubkeyToAddress, without the need to parse the point
+		return common.BytesToAddress(crypto.Keccak256(pub[1:])[12:]), nil
+	}
+	return common.BytesToAddress(crypto.Keccak256(pub)[12:]), nil
+}
+
+// RecoverPublicKey returns the public key that created sig over msg. Only
+// AlgoClassical supports recovery: the post-quantum schemes cannot recover a key
+// from a signature, so their public keys always have to be transmitted along
+// with it. Use RecoverAddress to handle both cases uniformly.
+func RecoverPublicKey(algo Algorithm, msg, sig []byte) ([]byte, error) {
+	if algo != AlgoClassical {
+		if !algo.IsSignature() {
+			return nil, ErrUnsupportedAlgorithm
 		}
-		signer.classical = classical
-		
-		priv, err := mlkem.GenerateKeyPair(rand.Reader, mlkem.MLKEM512)
-		if err != nil {
-			return nil, err
+		return nil, ErrRecoveryNotSupported
+	}
+	if len(sig) != secp256k1SignatureSize || sig[64] > 1 {
+		return nil, ErrInvalidSignature
+	}
+	pub, err := crypto.Ecrecover(crypto.Keccak256(msg), sig)
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	return pub, nil
+}
+
+// RecoverAddress returns the address of the key that created sig over msg. The
+// public key may be omitted for AlgoClassical, in which case it is recovered from
+// the signature.
+func RecoverAddress(algo Algorithm, pub, msg, sig []byte) (common.Address, error) {
+	if !algo.IsSignature() {
+		return common.Address{}, ErrUnsupportedAlgorithm
+	}
+	if pub == nil {
+		var err error
+		if pub, err = RecoverPublicKey(algo, msg, sig); err != nil {
+			return common.Address{}, err
 		}
-		signer.mlkemPriv = priv
-		
-	default:
-		return nil, fmt.Errorf("unsupported algorithm: %d", algo)
 	}
-	
-	return signer, nil
+	if !Verify(algo, pub, msg, sig) {
+		return common.Address{}, ErrInvalidSignature
+	}
+	return PubkeyToAddress(algo, pub)
+}
+
+// Encapsulate generates a shared secret and its encapsulation to the encoded
+// public key pub, drawing the randomness from r.
+func Encapsulate(algo Algorithm, pub []byte, r io.Reader) (ct, ss []byte, err error) {
+	seed := make([]byte, EncapsulationSeedSize(algo))
+	if _, err := io.ReadFull(r, seed); err != nil {
+		return nil, nil, err
+	}
+	return EncapsulateDeterministically(algo, pub, seed)
+}
+
+// EncapsulateDeterministically is like Encapsulate, but takes the randomness as
+// an explicit seed of EncapsulationSeedSize bytes.
+func EncapsulateDeterministically(algo Algorithm, pub, seed []byte) (ct, ss []byte, err error) {
+	s, ok := kemSchemes[algo]
+	if !ok {
+		return nil, nil, ErrUnsupportedAlgorithm
+	}
+	if len(pub) != s.publicKeySize {
+		return nil, nil, ErrInvalidPublicKey
+	}
+	if len(seed) != s.encapSeedSize {
+		return nil, nil, ErrInvalidSeed
+	}
+	return s.encapsulate(pub, seed)
+}
+
+// PQSigner represents a post-quantum capable signer
+type PQSigner struct {
+	algo Algorithm
+	seed []byte
+	pub  []byte
+	priv any
+}
+
+// NewPQSigner creates a new post-quantum signer
+func NewPQSigner(algo Algorithm) (*PQSigner, error) {
+	size := SeedSize(algo)
+	if size == 0 {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedAlgorithm, algo)
+	}
+	seed := make([]byte, size)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, err
+	}
+	return NewPQSignerFromSeed(algo, seed)
+}
+
+// NewPQSignerFromSeed deterministically derives a signer from a seed of
+// SeedSize(algo) bytes.
+func NewPQSignerFromSeed(algo Algorithm, seed []byte) (*PQSigner, error) {
+	var newKey func([]byte) ([]byte, any, error)
+	if s, ok := signatureSchemes[algo]; ok {
+		newKey = s.newKey
+	} else if s, ok := kemSchemes[algo]; ok {
+		newKey = s.newKey
+	} else {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedAlgorithm, algo)
+	}
+	if len(seed) != SeedSize(algo) {
+		return nil, ErrInvalidSeed
+	}
+	pub, priv, err := newKey(seed)
+	if err != nil {
+		return nil, err
+	}
+	return &PQSigner{algo: algo, seed: common.CopyBytes(seed), pub: pub, priv: priv}, nil
+}
+
+// UnmarshalPQSigner decodes a signer serialized by MarshalBinary.
+func UnmarshalPQSigner(data []byte) (*PQSigner, error) {
+	if len(data) == 0 {
+		return nil, ErrInvalidSeed
+	}
+	return NewPQSignerFromSeed(Algorithm(data[0]), data[1:])
+}
+
+// MarshalBinary serializes the signer as its algorithm byte followed by the
+// seed of its private key.
+func (s *PQSigner) MarshalBinary() ([]byte, error) {
+	return append([]byte{byte(s.algo)}, s.seed...), nil
+}
+
+// Algorithm returns the algorithm of the signer.
+func (s *PQSigner) Algorithm() Algorithm {
+	return s.algo
+}
+
+// PublicKey returns the encoded public key of the signer.
+func (s *PQSigner) PublicKey() []byte {
+	return common.CopyBytes(s.pub)
 }
 
 // Sign signs a message using the appropriate algorithm
 func (s *PQSigner) Sign(message []byte) ([]byte, error) {
-	switch s.algo {
-	case AlgoClassical:
-		hash := crypto.Keccak256Hash(message)
-		return crypto.Sign(hash.Bytes(), s.classical)
-		
-	case AlgoMLDSA44, AlgoMLDSA65, AlgoMLDSA87:
-		return s.mldsaPriv.Sign(rand.Reader, message, nil)
-		
-	case AlgoSLHDSA128s, AlgoSLHDSA192s, AlgoSLHDSA256s:
-		return s.slhdsaPriv.Sign(rand.Reader, message, nil)
-		
-	case AlgoHybridSecp256k1MLDSA:
-		// Sign with both algorithms
-		hash := crypto.Keccak256Hash(message)
-		classicalSig, err := crypto.Sign(hash.Bytes(), s.classical)
-		if err != nil {
-			return nil, err
-		}
-		
-		pqSig, err := s.mldsaPriv.Sign(rand.Reader, message, nil)
-		if err != nil {
-			return nil, err
-		}
-		
-		// Concatenate signatures
-		return append(classicalSig, pqSig...), nil
-		
-	default:
+	scheme, ok := signatureSchemes[s.algo]
+	if !ok {
 		return nil, errors.New("signing not supported for this algorithm")
 	}
+	return scheme.sign(s.priv, message)
 }
 
 // Address returns the Ethereum address for this signer
 func (s *PQSigner) Address() common.Address {
-	switch s.algo {
-	case AlgoClassical, AlgoHybridSecp256k1MLDSA, AlgoHybridSecp256k1MLKEM:
-		addr := crypto.PubkeyToAddress(s.classical.PublicKey)
-		return common.BytesToAddress(addr[:])
-		
-	case AlgoMLDSA44, AlgoMLDSA65, AlgoMLDSA87:
-		// Use first 20 bytes of public key hash
-		pubBytes := s.mldsaPriv.PublicKey.Bytes()
-		hash := crypto.Keccak256(pubBytes)
-		return common.BytesToAddress(hash[12:])
-		
-	case AlgoMLKEM512, AlgoMLKEM768, AlgoMLKEM1024:
-		// Use first 20 bytes of public key hash
-		pubBytes := s.mlkemPriv.PublicKey.Bytes()
-		hash := crypto.Keccak256(pubBytes)
-		return common.BytesToAddress(hash[12:])
-		
-	case AlgoSLHDSA128s, AlgoSLHDSA192s, AlgoSLHDSA256s:
-		// Use first 20 bytes of public key hash
-		pubBytes := s.slhdsaPriv.PublicKey.Bytes()
-		hash := crypto.Keccak256(pubBytes)
-		return common.BytesToAddress(hash[12:])
-		
-	default:
-		return common.Address{}
-	}
+	addr, _ := PubkeyToAddress(s.algo, s.pub)
+	return addr
 }
 
-// Encapsulate performs key encapsulation (for KEM algorithms)
+// Encapsulate generates a shared secret encapsulated to pubKey, which must be a
+// key of the same algorithm as the signer.
 func (s *PQSigner) Encapsulate(pubKey []byte) ([]byte, []byte, error) {
-	if s.mlkemPriv == nil {
+	if !s.algo.IsKEM() {
 		return nil, nil, errors.New("encapsulation requires ML-KEM key")
 	}
-	
-	// Parse public key based on algorithm
-	var mode mlkem.Mode
-	switch s.algo {
-	case AlgoMLKEM512, AlgoHybridSecp256k1MLKEM:
-		mode = mlkem.MLKEM512
-	case AlgoMLKEM768:
-		mode = mlkem.MLKEM768
-	case AlgoMLKEM1024:
-		mode = mlkem.MLKEM1024
-	default:
-		return nil, nil, errors.New("not a KEM algorithm")
-	}
-	
-	pub, err := mlkem.PublicKeyFromBytes(pubKey, mode)
-	if err != nil {
-		return nil, nil, err
-	}
-	
-	result, err := pub.Encapsulate(rand.Reader)
-	if err != nil {
-		return nil, nil, err
-	}
-	
-	return result.Ciphertext, result.SharedSecret, nil
+	return Encapsulate(s.algo, pubKey, rand.Reader)
 }
 
 // Decapsulate performs key decapsulation
 func (s *PQSigner) Decapsulate(ciphertext []byte) ([]byte, error) {
-	if s.mlkemPriv == nil {
+	scheme, ok := kemSchemes[s.algo]
+	if !ok {
 		return nil, errors.New("decapsulation requires ML-KEM key")
 	}
-	
-	return s.mlkemPriv.Decapsulate(ciphertext)
-}
\ No newline at end of file
+	if len(ciphertext) != scheme.ciphertextSize {
+		return nil, errors.New("invalid ciphertext length")
+	}
+	return scheme.decapsulate(s.priv, ciphertext)
+}
@@ -6,6 +6,7 @@ package pqcrypto
 import (
 	"testing"
 
+	"github.com/luxfi/crypto"
 	"github.com/luxfi/geth/common"
 	"github.com/stretchr/testify/require"
 )
@@ -54,12 +55,7 @@ func TestPQSigner(t *testing.T) {
 				require.NoError(err)
 
 				// Get public key
-				var pubKey []byte
-				if algo == AlgoHybridSecp256k1MLKEM {
-					pubKey = signer2.mlkemPriv.PublicKey.Bytes()
-				} else {
-					pubKey = signer2.mlkemPriv.PublicKey.Bytes()
-				}
+				pubKey := signer2.PublicKey()
 
 				// Encapsulate
 				ciphertext, sharedSecret1, err := signer.Encapsulate(pubKey)
@@ -114,13 +110,204 @@ func TestAddressGeneration(t *testing.T) {
 
 		addr := signer.Address().Hex()
 		require.NotEmpty(addr)
-		
+
 		// Check uniqueness
 		require.False(addresses[addr], "Duplicate address generated")
 		addresses[addr] = true
 	}
 }
 
+func TestVerify(t *testing.T) {
+	// The slow SLH-DSA small variants are covered by TestPQSigner
+	algorithms := []Algorithm{
+		AlgoClassical,
+		AlgoMLDSA44,
+		AlgoMLDSA65,
+		AlgoMLDSA87,
+		AlgoSLHDSA128f,
+		AlgoHybridSecp256k1MLDSA,
+	}
+	message := []byte("Test message for PQ verification")
+
+	for _, algo := range algorithms {
+		t.Run(algo.String(), func(t *testing.T) {
+			require := require.New(t)
+
+			signer, err := NewPQSigner(algo)
+			require.NoError(err)
+			pub := signer.PublicKey()
+			require.Len(pub, PublicKeySize(algo))
+
+			sig, err := signer.Sign(message)
+			require.NoError(err)
+			require.Len(sig, SignatureSize(algo))
+			require.True(Verify(algo, pub, message, sig))
+
+			// Signing is deterministic
+			sig2, err := signer.Sign(message)
+			require.NoError(err)
+			require.Equal(sig, sig2)
+
+			// Tampering with any input must invalidate the signature
+			require.False(Verify(algo, pub, []byte("Other message"), sig))
+			for _, i := range []int{0, len(sig) / 2, len(sig) - 2} {
+				bad := common.CopyBytes(sig)
+				bad[i] ^= 1
+				require.False(Verify(algo, pub, message, bad), "flipped signature byte %d", i)
+			}
+			require.False(Verify(algo, pub, message, sig[:len(sig)-1]))
+			require.False(Verify(algo, pub[:len(pub)-1], message, sig))
+
+			other, err := NewPQSigner(algo)
+			require.NoError(err)
+			require.False(Verify(algo, other.PublicKey(), message, sig))
+
+			// Address derivation is shared with verification
+			addr, err := RecoverAddress(algo, pub, message, sig)
+			require.NoError(err)
+			require.Equal(signer.Address(), addr)
+		})
+	}
+	require.False(t, Verify(AlgoMLKEM768, make([]byte, PublicKeySize(AlgoMLKEM768)), message, nil))
+	require.False(t, Verify(Algorithm(0xff), nil, message, nil))
+}
+
+func TestHybridSignatureHalves(t *testing.T) {
+	require := require.New(t)
+
+	signer, err := NewPQSigner(AlgoHybridSecp256k1MLDSA)
+	require.NoError(err)
+	other, err := NewPQSigner(AlgoHybridSecp256k1MLDSA)
+	require.NoError(err)
+
+	message := []byte("Hybrid signature test message")
+	sig, err := signer.Sign(message)
+	require.NoError(err)
+	otherSig, err := other.Sign(message)
+	require.NoError(err)
+
+	pub := signer.PublicKey()
+	require.Len(pub, 33+1952)
+	require.Len(sig, 65+3309)
+
+	// Replacing either half with a valid half of another key must fail
+	classical, pq, err := SplitHybridSignature(sig)
+	require.NoError(err)
+	otherClassical, otherPQ, err := SplitHybridSignature(otherSig)
+	require.NoError(err)
+	require.False(Verify(AlgoHybridSecp256k1MLDSA, pub, message, append(common.CopyBytes(otherClassical), pq...)))
+	require.False(Verify(AlgoHybridSecp256k1MLDSA, pub, message, append(common.CopyBytes(classical), otherPQ...)))
+
+	// Neither half is a valid standalone signature of the message
+	classicalPub, pqPub, err := SplitHybridPublicKey(pub)
+	require.NoError(err)
+	key, err := crypto.DecompressPubkey(classicalPub)
+	require.NoError(err)
+	require.False(Verify(AlgoClassical, crypto.FromECDSAPub(key), message, classical))
+	require.False(Verify(AlgoMLDSA65, pqPub, message, pq))
+
+	// The address commits to both keys
+	require.NotEqual(crypto.PubkeyToAddress(*key), signer.Address())
+}
+
+func TestRecoverPublicKey(t *testing.T) {
+	require := require.New(t)
+	message := []byte("Recovery test message")
+
+	signer, err := NewPQSigner(AlgoClassical)
+	require.NoError(err)
+	sig, err := signer.Sign(message)
+	require.NoError(err)
+
+	pub, err := RecoverPublicKey(AlgoClassical, message, sig)
+	require.NoError(err)
+	require.Equal(signer.PublicKey(), pub)
+	addr, err := RecoverAddress(AlgoClassical, nil, message, sig)
+	require.NoError(err)
+	require.Equal(signer.Address(), addr)
+
+	// Post-quantum keys must be provided with the signature
+	pq, err := NewPQSigner(AlgoMLDSA44)
+	require.NoError(err)
+	sig, err = pq.Sign(message)
+	require.NoError(err)
+	_, err = RecoverPublicKey(AlgoMLDSA44, message, sig)
+	require.ErrorIs(err, ErrRecoveryNotSupported)
+	_, err = RecoverAddress(AlgoMLDSA44, nil, message, sig)
+	require.ErrorIs(err, ErrRecoveryNotSupported)
+	_, err = RecoverAddress(AlgoMLKEM512, pq.PublicKey(), message, sig)
+	require.ErrorIs(err, ErrUnsupportedAlgorithm)
+}
+
+func TestSignerSerialization(t *testing.T) {
+	algorithms := []Algorithm{
+		AlgoClassical,
+		AlgoMLDSA65,
+		AlgoMLKEM768,
+		AlgoSLHDSA128f,
+		AlgoHybridSecp256k1MLDSA,
+		AlgoHybridSecp256k1MLKEM,
+	}
+	for _, algo := range algorithms {
+		t.Run(algo.String(), func(t *testing.T) {
+			require := require.New(t)
+
+			signer, err := NewPQSigner(algo)
+			require.NoError(err)
+			enc, err := signer.MarshalBinary()
+			require.NoError(err)
+			require.Len(enc, 1+SeedSize(algo))
+
+			dec, err := UnmarshalPQSigner(enc)
+			require.NoError(err)
+			require.Equal(algo, dec.Algorithm())
+			require.Equal(signer.PublicKey(), dec.PublicKey())
+			require.Equal(signer.Address(), dec.Address())
+
+			_, err = UnmarshalPQSigner(enc[:len(enc)-1])
+			require.ErrorIs(err, ErrInvalidSeed)
+		})
+	}
+	_, err := UnmarshalPQSigner([]byte{0xff})
+	require.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+}
+
+func TestEncapsulateDeterministically(t *testing.T) {
+	for _, algo := range []Algorithm{AlgoMLKEM512, AlgoMLKEM768, AlgoMLKEM1024, AlgoHybridSecp256k1MLKEM} {
+		t.Run(algo.String(), func(t *testing.T) {
+			require := require.New(t)
+
+			recipient, err := NewPQSigner(algo)
+			require.NoError(err)
+			seed := make([]byte, EncapsulationSeedSize(algo))
+			seed[0] = 1
+
+			ct, ss, err := EncapsulateDeterministically(algo, recipient.PublicKey(), seed)
+			require.NoError(err)
+			require.Len(ct, CiphertextSize(algo))
+			ct2, ss2, err := EncapsulateDeterministically(algo, recipient.PublicKey(), seed)
+			require.NoError(err)
+			require.Equal(ct, ct2)
+			require.Equal(ss, ss2)
+
+			dec, err := recipient.Decapsulate(ct)
+			require.NoError(err)
+			require.Equal(ss, dec)
+
+			// ML-KEM decapsulation rejects implicitly, yielding an unrelated secret
+			ct[len(ct)-1] ^= 1
+			dec, err = recipient.Decapsulate(ct)
+			require.NoError(err)
+			require.NotEqual(ss, dec)
+
+			_, _, err = EncapsulateDeterministically(algo, recipient.PublicKey(), seed[1:])
+			require.ErrorIs(err, ErrInvalidSeed)
+			_, _, err = EncapsulateDeterministically(algo, recipient.PublicKey()[1:], seed)
+			require.ErrorIs(err, ErrInvalidPublicKey)
+		})
+	}
+}
+
 func BenchmarkPQSigning(b *testing.B) {
 	algorithms := []Algorithm{
 		AlgoClassical,
@@ -152,8 +339,8 @@ func BenchmarkKEM(b *testing.B) {
 		b.Run(algo.String()+"_Encapsulate", func(b *testing.B) {
 			signer1, _ := NewPQSigner(algo)
 			signer2, _ := NewPQSigner(algo)
-			pubKey := signer2.mlkemPriv.PublicKey.Bytes()
-			
+			pubKey := signer2.PublicKey()
+
 			b.ResetTimer()
 			for i := 0; i < b.N; i++ {
 				_, _, _ = signer1.Encapsulate(pubKey)
@@ -161,35 +348,3 @@ func BenchmarkKEM(b *testing.B) {
 		})
 	}
 }
-
-// String returns the string representation of the algorithm
-func (a Algorithm) String() string {
-	switch a {
-	case AlgoClassical:
-		return "Classical"
-	case AlgoMLDSA44:
-		return "ML-DSA-44"
-	case AlgoMLDSA65:
-		return "ML-DSA-65"
-	case AlgoMLDSA87:
-		return "ML-DSA-87"
-	case AlgoMLKEM512:
-		return "ML-KEM-512"
-	case AlgoMLKEM768:
-		return "ML-KEM-768"
-	case AlgoMLKEM1024:
-		return "ML-KEM-1024"
-	case AlgoSLHDSA128s:
-		return "SLH-DSA-128s"
-	case AlgoSLHDSA192s:
-		return "SLH-DSA-192s"
-	case AlgoSLHDSA256s:
-		return "SLH-DSA-256s"
-	case AlgoHybridSecp256k1MLDSA:
-		return "Hybrid-Secp256k1-MLDSA"
-	case AlgoHybridSecp256k1MLKEM:
-		return "Hybrid-Secp256k1-MLKEM"
-	default:
-		return "Unknown"
-	}
-}
\ No newline at end of file
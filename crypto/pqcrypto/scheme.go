@@ -0,0 +1,207 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// Post-Quantum Cryptography Integration for Geth
+
+package pqcrypto
+
+import (
+	"github.com/cloudflare/circl/kem"
+	"github.com/cloudflare/circl/kem/mlkem/mlkem1024"
+	"github.com/cloudflare/circl/kem/mlkem/mlkem512"
+	"github.com/cloudflare/circl/kem/mlkem/mlkem768"
+	"github.com/cloudflare/circl/sign"
+	"github.com/cloudflare/circl/sign/mldsa/mldsa44"
+	"github.com/cloudflare/circl/sign/mldsa/mldsa65"
+	"github.com/cloudflare/circl/sign/mldsa/mldsa87"
+	"github.com/luxfi/geth/crypto/slhdsa"
+)
+
+// signatureScheme implements one of the signature algorithms on encoded keys.
+type signatureScheme struct {
+	publicKeySize  int
+	privateKeySize int // size of the standard encoding of the expanded private key
+	signatureSize  int
+	seedSize       int
+
+	newKey func(seed []byte) (pub []byte, priv any, err error)
+	sign   func(priv any, msg []byte) ([]byte, error)
+	verify func(pub, msg, sig []byte) bool
+}
+
+// kemScheme implements one of the key encapsulation algorithms on encoded keys.
+type kemScheme struct {
+	publicKeySize  int
+	privateKeySize int
+	ciphertextSize int
+	seedSize       int
+	encapSeedSize  int // randomness consumed by a single encapsulation
+
+	newKey      func(seed []byte) (pub []byte, priv any, err error)
+	encapsulate func(pub, seed []byte) (ct, ss []byte, err error)
+	decapsulate func(priv any, ct []byte) ([]byte, error)
+}
+
+func mldsaScheme(s sign.Scheme) *signatureScheme {
+	return &signatureScheme{
+		publicKeySize:  s.PublicKeySize(),
+		privateKeySize: s.PrivateKeySize(),
+		signatureSize:  s.SignatureSize(),
+		seedSize:       s.SeedSize(),
+		newKey: func(seed []byte) ([]byte, any, error) {
+			pub, priv := s.DeriveKey(seed)
+			enc, err := pub.MarshalBinary()
+			return enc, priv, err
+		},
+		sign: func(priv any, msg []byte) ([]byte, error) {
+			return s.Sign(priv.(sign.PrivateKey), msg, nil), nil
+		},
+		verify: func(pub, msg, sig []byte) bool {
+			key, err := s.UnmarshalBinaryPublicKey(pub)
+			if err != nil {
+				return false
+			}
+			return s.Verify(key, msg, sig, nil)
+		},
+	}
+}
+
+func slhdsaScheme(p *slhdsa.Params) *signatureScheme {
+	return &signatureScheme{
+		publicKeySize:  p.PublicKeySize(),
+		privateKeySize: p.PrivateKeySize(),
+		signatureSize:  p.SignatureSize(),
+		seedSize:       p.SeedSize(),
+		newKey: func(seed []byte) ([]byte, any, error) {
+			return p.NewKeyFromSeed(seed)
+		},
+		sign: func(priv any, msg []byte) ([]byte, error) {
+			return p.Sign(priv.([]byte), msg, nil, nil)
+		},
+		verify: func(pub, msg, sig []byte) bool {
+			return p.Verify(pub, msg, sig, nil)
+		},
+	}
+}
+
+func mlkemScheme(s kem.Scheme) *kemScheme {
+	return &kemScheme{
+		publicKeySize:  s.PublicKeySize(),
+		privateKeySize: s.PrivateKeySize(),
+		ciphertextSize: s.CiphertextSize(),
+		seedSize:       s.SeedSize(),
+		encapSeedSize:  s.EncapsulationSeedSize(),
+		newKey: func(seed []byte) ([]byte, any, error) {
+			pub, priv := s.DeriveKeyPair(seed)
+			enc, err := pub.MarshalBinary()
+			return enc, priv, err
+		},
+		encapsulate: func(pub, seed []byte) ([]byte, []byte, error) {
+			key, err := s.UnmarshalBinaryPublicKey(pub)
+			if err != nil {
+				return nil, nil, ErrInvalidPublicKey
+			}
+			return s.EncapsulateDeterministically(key, seed)
+		},
+		decapsulate: func(priv any, ct []byte) ([]byte, error) {
+			return s.Decapsulate(priv.(kem.PrivateKey), ct)
+		},
+	}
+}
+
+var (
+	signatureSchemes = map[Algorithm]*signatureScheme{
+		AlgoClassical:            classicalScheme,
+		AlgoMLDSA44:              mldsaScheme(mldsa44.Scheme()),
+		AlgoMLDSA65:              mldsaScheme(mldsa65.Scheme()),
+		AlgoMLDSA87:              mldsaScheme(mldsa87.Scheme()),
+		AlgoSLHDSA128s:           slhdsaScheme(slhdsa.SHAKE128s),
+		AlgoSLHDSA128f:           slhdsaScheme(slhdsa.SHAKE128f),
+		AlgoSLHDSA192s:           slhdsaScheme(slhdsa.SHAKE192s),
+		AlgoSLHDSA192f:           slhdsaScheme(slhdsa.SHAKE192f),
+		AlgoSLHDSA256s:           slhdsaScheme(slhdsa.SHAKE256s),
+		AlgoSLHDSA256f:           slhdsaScheme(slhdsa.SHAKE256f),
+		AlgoHybridSecp256k1MLDSA: hybridSignatureScheme,
+	}
+	kemSchemes = map[Algorithm]*kemScheme{
+		AlgoMLKEM512:             mlkemScheme(mlkem512.Scheme()),
+		AlgoMLKEM768:             mlkemScheme(mlkem768.Scheme()),
+		AlgoMLKEM1024:            mlkemScheme(mlkem1024.Scheme()),
+		AlgoHybridSecp256k1MLKEM: hybridKEMScheme,
+	}
+)
+
+// IsSignature reports whether the algorithm is a supported signature algorithm.
+func (a Algorithm) IsSignature() bool {
+	_, ok := signatureSchemes[a]
+	return ok
+}
+
+// IsKEM reports whether the algorithm is a supported key encapsulation mechanism.
+func (a Algorithm) IsKEM() bool {
+	_, ok := kemSchemes[a]
+	return ok
+}
+
+// PublicKeySize returns the size of an encoded public key, or 0 if the
+// algorithm is not supported.
+func PublicKeySize(algo Algorithm) int {
+	if s, ok := signatureSchemes[algo]; ok {
+		return s.publicKeySize
+	}
+	if s, ok := kemSchemes[algo]; ok {
+		return s.publicKeySize
+	}
+	return 0
+}
+
+// PrivateKeySize returns the size of the standard encoding of an expanded
+// private key, or 0 if the algorithm is not supported. Keys are serialized as
+// seeds instead, see SeedSize.
+func PrivateKeySize(algo Algorithm) int {
+	if s, ok := signatureSchemes[algo]; ok {
+		return s.privateKeySize
+	}
+	if s, ok := kemSchemes[algo]; ok {
+		return s.privateKeySize
+	}
+	return 0
+}
+
+// SeedSize returns the size of the seed a private key is derived from, or 0 if
+// the algorithm is not supported.
+func SeedSize(algo Algorithm) int {
+	if s, ok := signatureSchemes[algo]; ok {
+		return s.seedSize
+	}
+	if s, ok := kemSchemes[algo]; ok {
+		return s.seedSize
+	}
+	return 0
+}
+
+// SignatureSize returns the size of a signature, or 0 if the algorithm is not
+// a supported signature algorithm.
+func SignatureSize(algo Algorithm) int {
+	if s, ok := signatureSchemes[algo]; ok {
+		return s.signatureSize
+	}
+	return 0
+}
+
+// CiphertextSize returns the size of an encapsulation, or 0 if the algorithm is
+// not a supported key encapsulation mechanism.
+func CiphertextSize(algo Algorithm) int {
+	if s, ok := kemSchemes[algo]; ok {
+		return s.ciphertextSize
+	}
+	return 0
+}
+
+// EncapsulationSeedSize returns the amount of randomness consumed by an
+// encapsulation, or 0 if the algorithm is not a supported key encapsulation
+// mechanism.
+func EncapsulationSeedSize(algo Algorithm) int {
+	if s, ok := kemSchemes[algo]; ok {
+		return s.encapSeedSize
+	}
+	return 0
+}
@@ -0,0 +1,137 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// SLH-DSA (FIPS 205) stateless hash-based signatures
+
+package slhdsa
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/luxfi/geth/common"
+)
+
+// The known-answer vectors in testdata are the SHAKE parameter sets of the NIST
+// ACVP sample vector sets for FIPS 205 (ACVP-Server v1.1.0.38, gen-val/json-files
+// SLH-DSA-keyGen-FIPS205 and SLH-DSA-sigVer-FIPS205), with the prompts merged
+// with the expected results. Only the external, pure signature interface is
+// kept for sigVer, as it's the only one exposed.
+
+// acvpHex is a byte string hex encoded without the 0x prefix.
+type acvpHex []byte
+
+func (h *acvpHex) UnmarshalText(text []byte) error {
+	*h = common.FromHex(string(text))
+	return nil
+}
+
+type acvpKeyGen struct {
+	TestGroups []struct {
+		ParameterSet string `json:"parameterSet"`
+		Tests        []struct {
+			TcID   int     `json:"tcId"`
+			SkSeed acvpHex `json:"skSeed"`
+			SkPrf  acvpHex `json:"skPrf"`
+			PkSeed acvpHex `json:"pkSeed"`
+			Sk     acvpHex `json:"sk"`
+			Pk     acvpHex `json:"pk"`
+		} `json:"tests"`
+	} `json:"testGroups"`
+}
+
+type acvpSigVer struct {
+	TestGroups []struct {
+		ParameterSet string `json:"parameterSet"`
+		Tests        []struct {
+			TcID       int     `json:"tcId"`
+			Pk         acvpHex `json:"pk"`
+			Message    acvpHex `json:"message"`
+			Context    acvpHex `json:"context"`
+			Signature  acvpHex `json:"signature"`
+			TestPassed bool    `json:"testPassed"`
+		} `json:"tests"`
+	} `json:"testGroups"`
+}
+
+// readACVP decodes a gzipped ACVP vector set.
+func readACVP(t *testing.T, path string, v any) {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.NewDecoder(r).Decode(v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// paramsByName returns the parameter set of the given ACVP name.
+func paramsByName(t *testing.T, name string) *Params {
+	for _, p := range allParams {
+		if p.Name == name {
+			return p
+		}
+	}
+	t.Fatalf("unknown parameter set %s", name)
+	return nil
+}
+
+func TestACVPKeyGen(t *testing.T) {
+	var vectors acvpKeyGen
+	readACVP(t, "testdata/keyGen.json.gz", &vectors)
+
+	sets := make(map[string]bool)
+	for _, group := range vectors.TestGroups {
+		p := paramsByName(t, group.ParameterSet)
+		sets[p.Name] = true
+
+		// The keys of the small variants take seconds to derive
+		if testing.Short() && strings.HasSuffix(p.Name, "s") {
+			continue
+		}
+		for _, tc := range group.Tests {
+			seed := bytes.Join([][]byte{tc.SkSeed, tc.SkPrf, tc.PkSeed}, nil)
+			pub, priv, err := p.NewKeyFromSeed(seed)
+			if err != nil {
+				t.Fatalf("%s tcId %d: %v", p.Name, tc.TcID, err)
+			}
+			if !bytes.Equal(pub, tc.Pk) {
+				t.Errorf("%s tcId %d: public key mismatch", p.Name, tc.TcID)
+			}
+			if !bytes.Equal(priv, tc.Sk) {
+				t.Errorf("%s tcId %d: private key mismatch", p.Name, tc.TcID)
+			}
+		}
+	}
+	if len(sets) != len(allParams) {
+		t.Fatalf("vectors cover %d parameter sets, want %d", len(sets), len(allParams))
+	}
+}
+
+func TestACVPSigVer(t *testing.T) {
+	var vectors acvpSigVer
+	readACVP(t, "testdata/sigVer.json.gz", &vectors)
+
+	sets := make(map[string]bool)
+	for _, group := range vectors.TestGroups {
+		p := paramsByName(t, group.ParameterSet)
+		sets[p.Name] = true
+
+		for _, tc := range group.Tests {
+			if have := p.Verify(tc.Pk, tc.Message, tc.Signature, tc.Context); have != tc.TestPassed {
+				t.Errorf("%s tcId %d: verification mismatch, have %v, want %v", p.Name, tc.TcID, have, tc.TestPassed)
+			}
+		}
+	}
+	if len(sets) != len(allParams) {
+		t.Fatalf("vectors cover %d parameter sets, want %d", len(sets), len(allParams))
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// SLH-DSA (FIPS 205) stateless hash-based signatures
+
+package slhdsa
+
+import "encoding/binary"
+
+// Address types from FIPS 205 section 4.2.
+const (
+	addrWotsHash = iota
+	addrWotsPK
+	addrTree
+	addrForsTree
+	addrForsRoots
+	addrWotsPRF
+	addrForsPRF
+)
+
+// address is the 32 byte ADRS structure used to domain separate every hash
+// call. The SHAKE parameter sets use it uncompressed:
+//
+//	layer (4) || tree (12) || type (4) || word1 (4) || word2 (4) || word3 (4)
+type address [32]byte
+
+func (a *address) setLayerAddress(layer int) {
+	binary.BigEndian.PutUint32(a[0:4], uint32(layer))
+}
+
+func (a *address) setTreeAddress(tree uint64) {
+	binary.BigEndian.PutUint32(a[4:8], 0)
+	binary.BigEndian.PutUint64(a[8:16], tree)
+}
+
+func (a *address) setTypeAndClear(typ uint32) {
+	binary.BigEndian.PutUint32(a[16:20], typ)
+	clear(a[20:])
+}
+
+func (a *address) setKeyPairAddress(i uint32) {
+	binary.BigEndian.PutUint32(a[20:24], i)
+}
+
+func (a *address) keyPairAddress() uint32 {
+	return binary.BigEndian.Uint32(a[20:24])
+}
+
+func (a *address) setChainAddress(i uint32) {
+	binary.BigEndian.PutUint32(a[24:28], i)
+}
+
+func (a *address) setTreeHeight(z uint32) {
+	binary.BigEndian.PutUint32(a[24:28], z)
+}
+
+func (a *address) setHashAddress(i uint32) {
+	binary.BigEndian.PutUint32(a[28:32], i)
+}
+
+func (a *address) setTreeIndex(i uint32) {
+	binary.BigEndian.PutUint32(a[28:32], i)
+}
+
+func (a *address) treeIndex() uint32 {
+	return binary.BigEndian.Uint32(a[28:32])
+}
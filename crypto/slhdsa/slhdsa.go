@@ -0,0 +1,554 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// SLH-DSA (FIPS 205) stateless hash-based signatures
+
+// Package slhdsa implements the SHAKE parameter sets of the Stateless Hash-Based
+// Digital Signature Algorithm standardized in FIPS 205.
+//
+// Only the pure (non pre-hashed) variant is provided. Signing is slow by design
+// of the scheme; verification is what the node needs on hot paths.
+package slhdsa
+
+import (
+	"crypto/sha3"
+	"crypto/subtle"
+	"errors"
+	"io"
+)
+
+// Params is one of the FIPS 205 parameter sets.
+type Params struct {
+	Name string
+
+	n  int // security parameter, hash output length in bytes
+	h  int // total hypertree height
+	d  int // hypertree layers
+	hp int // height of a single XMSS tree (h/d)
+	a  int // FORS tree height
+	k  int // number of FORS trees
+	m  int // message digest length in bytes
+
+	len int // number of WOTS+ chains
+}
+
+func newParams(name string, n, h, d, a, k, m int) *Params {
+	// With lg_w = 4, len1 = 2n and len2 = 3 for every standardized n.
+	return &Params{Name: name, n: n, h: h, d: d, hp: h / d, a: a, k: k, m: m, len: 2*n + 3}
+}
+
+// The SHAKE parameter sets from FIPS 205, table 2.
+var (
+	SHAKE128s = newParams("SLH-DSA-SHAKE-128s", 16, 63, 7, 12, 14, 30)
+	SHAKE128f = newParams("SLH-DSA-SHAKE-128f", 16, 66, 22, 6, 33, 34)
+	SHAKE192s = newParams("SLH-DSA-SHAKE-192s", 24, 63, 7, 14, 17, 39)
+	SHAKE192f = newParams("SLH-DSA-SHAKE-192f", 24, 66, 22, 8, 33, 42)
+	SHAKE256s = newParams("SLH-DSA-SHAKE-256s", 32, 64, 8, 14, 22, 47)
+	SHAKE256f = newParams("SLH-DSA-SHAKE-256f", 32, 68, 17, 9, 35, 49)
+)
+
+var (
+	errInvalidSeedLength       = errors.New("slhdsa: invalid seed length")
+	errInvalidPrivateKeyLength = errors.New("slhdsa: invalid private key length")
+	errContextTooLong          = errors.New("slhdsa: context longer than 255 bytes")
+)
+
+// PublicKeySize returns the size of an encoded public key (PK.seed || PK.root).
+func (p *Params) PublicKeySize() int { return 2 * p.n }
+
+// PrivateKeySize returns the size of an encoded private key
+// (SK.seed || SK.prf || PK.seed || PK.root).
+func (p *Params) PrivateKeySize() int { return 4 * p.n }
+
+// SeedSize returns the number of random bytes needed to derive a key pair.
+func (p *Params) SeedSize() int { return 3 * p.n }
+
+// SignatureSize returns the size of a signature.
+func (p *Params) SignatureSize() int {
+	return (1 + p.k*(1+p.a) + p.h + p.d*p.len) * p.n
+}
+
+// GenerateKey creates a new key pair using randomness from rand.
+func (p *Params) GenerateKey(rand io.Reader) (pub, priv []byte, err error) {
+	seed := make([]byte, p.SeedSize())
+	if _, err := io.ReadFull(rand, seed); err != nil {
+		return nil, nil, err
+	}
+	return p.NewKeyFromSeed(seed)
+}
+
+// NewKeyFromSeed deterministically derives a key pair from a 3n byte seed
+// holding SK.seed || SK.prf || PK.seed (FIPS 205 slh_keygen_internal).
+func (p *Params) NewKeyFromSeed(seed []byte) (pub, priv []byte, err error) {
+	if len(seed) != p.SeedSize() {
+		return nil, nil, errInvalidSeedLength
+	}
+	n := p.n
+	priv = make([]byte, 4*n)
+	copy(priv, seed)
+
+	c := p.newContext(priv[2*n:3*n], priv[:n])
+	var adrs address
+	adrs.setLayerAddress(p.d - 1)
+	c.xmssNode(priv[3*n:], 0, p.hp, &adrs)
+
+	return append([]byte{}, priv[2*n:]...), priv, nil
+}
+
+// PublicKey returns the public key embedded in an encoded private key.
+func (p *Params) PublicKey(priv []byte) ([]byte, error) {
+	if len(priv) != p.PrivateKeySize() {
+		return nil, errInvalidPrivateKeyLength
+	}
+	return append([]byte{}, priv[2*p.n:]...), nil
+}
+
+// Sign signs msg with the given context string (FIPS 205 slh_sign). If addrnd
+// is nil the deterministic variant is used, otherwise it must hold n random bytes.
+func (p *Params) Sign(priv, msg, ctx, addrnd []byte) ([]byte, error) {
+	if len(priv) != p.PrivateKeySize() {
+		return nil, errInvalidPrivateKeyLength
+	}
+	if len(ctx) > 255 {
+		return nil, errContextTooLong
+	}
+	if addrnd != nil && len(addrnd) != p.n {
+		return nil, errInvalidSeedLength
+	}
+	return p.signInternal(priv, encodeMessage(msg, ctx), addrnd), nil
+}
+
+// Verify reports whether sig is a valid signature of msg with the given context
+// string under the public key pub (FIPS 205 slh_verify).
+func (p *Params) Verify(pub, msg, sig, ctx []byte) bool {
+	if len(pub) != p.PublicKeySize() || len(sig) != p.SignatureSize() || len(ctx) > 255 {
+		return false
+	}
+	return p.verifyInternal(pub, encodeMessage(msg, ctx), sig)
+}
+
+// encodeMessage builds the pure-mode message M' = 0 || len(ctx) || ctx || M.
+func encodeMessage(msg, ctx []byte) [][]byte {
+	return [][]byte{{0, byte(len(ctx))}, ctx, msg}
+}
+
+// digest splits the H_msg output into the FORS message digest and the
+// hypertree tree and leaf indices.
+func (p *Params) digest(c *context, r, root []byte, msg [][]byte) (md []byte, tree uint64, leaf uint32) {
+	buf := make([]byte, p.m)
+	c.hmsg(buf, r, root, msg)
+
+	var (
+		mdLen   = (p.k*p.a + 7) / 8
+		treeLen = (p.h - p.hp + 7) / 8
+		leafLen = (p.hp + 7) / 8
+	)
+	md = buf[:mdLen]
+	tree = toInt(buf[mdLen:mdLen+treeLen]) & (1<<(p.h-p.hp) - 1)
+	leaf = uint32(toInt(buf[mdLen+treeLen:mdLen+treeLen+leafLen]) & (1<<p.hp - 1))
+	return md, tree, leaf
+}
+
+func (p *Params) signInternal(priv []byte, msg [][]byte, addrnd []byte) []byte {
+	var (
+		n      = p.n
+		skSeed = priv[:n]
+		skPrf  = priv[n : 2*n]
+		pkSeed = priv[2*n : 3*n]
+		pkRoot = priv[3*n:]
+	)
+	if addrnd == nil {
+		addrnd = pkSeed
+	}
+	c := p.newContext(pkSeed, skSeed)
+	sig := make([]byte, 0, p.SignatureSize())
+
+	r := make([]byte, n)
+	c.prfMsg(r, skPrf, addrnd, msg)
+	sig = append(sig, r...)
+
+	md, tree, leaf := p.digest(c, r, pkRoot, msg)
+
+	var adrs address
+	adrs.setTreeAddress(tree)
+	adrs.setTypeAndClear(addrForsTree)
+	adrs.setKeyPairAddress(leaf)
+	forsSig := c.forsSign(md, &adrs)
+	sig = append(sig, forsSig...)
+
+	pkFors := c.forsPkFromSig(forsSig, md, &adrs)
+	return append(sig, c.htSign(pkFors, tree, leaf)...)
+}
+
+func (p *Params) verifyInternal(pub []byte, msg [][]byte, sig []byte) bool {
+	var (
+		n      = p.n
+		pkSeed = pub[:n]
+		pkRoot = pub[n:]
+		r      = sig[:n]
+		fors   = sig[n : n+p.k*(1+p.a)*n]
+		ht     = sig[n+p.k*(1+p.a)*n:]
+	)
+	c := p.newContext(pkSeed, nil)
+	md, tree, leaf := p.digest(c, r, pkRoot, msg)
+
+	var adrs address
+	adrs.setTreeAddress(tree)
+	adrs.setTypeAndClear(addrForsTree)
+	adrs.setKeyPairAddress(leaf)
+	pkFors := c.forsPkFromSig(fors, md, &adrs)
+
+	return c.htVerify(pkFors, ht, tree, leaf, pkRoot)
+}
+
+// toInt interprets b as a big-endian integer.
+func toInt(b []byte) uint64 {
+	var x uint64
+	for _, v := range b {
+		x = x<<8 | uint64(v)
+	}
+	return x
+}
+
+// base2b splits x into len(out) integers of b bits each, most significant first.
+func base2b(out []uint32, x []byte, b int) {
+	var (
+		in    int
+		bits  int
+		total uint32
+	)
+	for i := range out {
+		for bits < b {
+			total = total<<8 | uint32(x[in])
+			in++
+			bits += 8
+		}
+		bits -= b
+		out[i] = (total >> bits) & (1<<b - 1)
+		total &= 1<<bits - 1
+	}
+}
+
+// context carries the per-key state and hash instance shared by the
+// components of a single signing or verification operation.
+type context struct {
+	p      *Params
+	pkSeed []byte
+	skSeed []byte
+	shake  *sha3.SHAKE
+}
+
+func (p *Params) newContext(pkSeed, skSeed []byte) *context {
+	return &context{p: p, pkSeed: pkSeed, skSeed: skSeed, shake: sha3.NewSHAKE256()}
+}
+
+// thash computes F, H and T_l: SHAKE256(PK.seed || ADRS || M, 8n).
+func (c *context) thash(out []byte, adrs *address, msg ...[]byte) {
+	c.shake.Reset()
+	c.shake.Write(c.pkSeed)
+	c.shake.Write(adrs[:])
+	for _, m := range msg {
+		c.shake.Write(m)
+	}
+	c.shake.Read(out[:c.p.n])
+}
+
+// prf computes PRF(PK.seed, SK.seed, ADRS) = SHAKE256(PK.seed || ADRS || SK.seed, 8n).
+func (c *context) prf(out []byte, adrs *address) {
+	c.thash(out, adrs, c.skSeed)
+}
+
+// prfMsg computes PRF_msg(SK.prf, opt_rand, M) = SHAKE256(SK.prf || opt_rand || M, 8n).
+func (c *context) prfMsg(out, skPrf, optRand []byte, msg [][]byte) {
+	c.shake.Reset()
+	c.shake.Write(skPrf)
+	c.shake.Write(optRand)
+	for _, m := range msg {
+		c.shake.Write(m)
+	}
+	c.shake.Read(out[:c.p.n])
+}
+
+// hmsg computes H_msg(R, PK.seed, PK.root, M) = SHAKE256(R || PK.seed || PK.root || M, 8m).
+func (c *context) hmsg(out, r, pkRoot []byte, msg [][]byte) {
+	c.shake.Reset()
+	c.shake.Write(r)
+	c.shake.Write(c.pkSeed)
+	c.shake.Write(pkRoot)
+	for _, m := range msg {
+		c.shake.Write(m)
+	}
+	c.shake.Read(out)
+}
+
+// chain applies s iterations of F to x in place, starting at index i.
+func (c *context) chain(x []byte, i, s uint32, adrs *address) {
+	for j := i; j < i+s; j++ {
+		adrs.setHashAddress(j)
+		c.thash(x, adrs, x)
+	}
+}
+
+// wotsDigits converts an n byte message into the len base-w digits signed by
+// WOTS+, including the checksum.
+func (c *context) wotsDigits(msg []byte) []uint32 {
+	var (
+		len1   = 2 * c.p.n
+		digits = make([]uint32, c.p.len)
+		csum   uint32
+	)
+	base2b(digits[:len1], msg, 4)
+	for _, d := range digits[:len1] {
+		csum += 15 - d
+	}
+	csum <<= 4
+	base2b(digits[len1:], []byte{byte(csum >> 8), byte(csum)}, 4)
+	return digits
+}
+
+// wotsPkGen computes the compressed WOTS+ public key (FIPS 205 algorithm 6).
+func (c *context) wotsPkGen(out []byte, adrs *address) {
+	n := c.p.n
+	sk := *adrs
+	sk.setTypeAndClear(addrWotsPRF)
+	sk.setKeyPairAddress(adrs.keyPairAddress())
+
+	tmp := make([]byte, c.p.len*n)
+	for i := 0; i < c.p.len; i++ {
+		sk.setChainAddress(uint32(i))
+		c.prf(tmp[i*n:], &sk)
+		adrs.setChainAddress(uint32(i))
+		c.chain(tmp[i*n:(i+1)*n], 0, 15, adrs)
+	}
+	c.wotsCompress(out, adrs, tmp)
+}
+
+func (c *context) wotsCompress(out []byte, adrs *address, tmp []byte) {
+	pk := *adrs
+	pk.setTypeAndClear(addrWotsPK)
+	pk.setKeyPairAddress(adrs.keyPairAddress())
+	c.thash(out, &pk, tmp)
+}
+
+// wotsSign creates a WOTS+ signature of an n byte message (FIPS 205 algorithm 7).
+func (c *context) wotsSign(msg []byte, adrs *address) []byte {
+	n := c.p.n
+	sk := *adrs
+	sk.setTypeAndClear(addrWotsPRF)
+	sk.setKeyPairAddress(adrs.keyPairAddress())
+
+	sig := make([]byte, c.p.len*n)
+	for i, d := range c.wotsDigits(msg) {
+		sk.setChainAddress(uint32(i))
+		c.prf(sig[i*n:], &sk)
+		adrs.setChainAddress(uint32(i))
+		c.chain(sig[i*n:(i+1)*n], 0, d, adrs)
+	}
+	return sig
+}
+
+// wotsPkFromSig computes a WOTS+ public key from a signature (FIPS 205 algorithm 8).
+func (c *context) wotsPkFromSig(out, sig, msg []byte, adrs *address) {
+	n := c.p.n
+	tmp := make([]byte, c.p.len*n)
+	copy(tmp, sig)
+	for i, d := range c.wotsDigits(msg) {
+		adrs.setChainAddress(uint32(i))
+		c.chain(tmp[i*n:(i+1)*n], d, 15-d, adrs)
+	}
+	c.wotsCompress(out, adrs, tmp)
+}
+
+// xmssNode computes the root of the XMSS subtree of height z at index i
+// (FIPS 205 algorithm 9).
+func (c *context) xmssNode(out []byte, i uint32, z int, adrs *address) {
+	n := c.p.n
+	if z == 0 {
+		adrs.setTypeAndClear(addrWotsHash)
+		adrs.setKeyPairAddress(i)
+		c.wotsPkGen(out, adrs)
+		return
+	}
+	children := make([]byte, 2*n)
+	c.xmssNode(children[:n], 2*i, z-1, adrs)
+	c.xmssNode(children[n:], 2*i+1, z-1, adrs)
+
+	adrs.setTypeAndClear(addrTree)
+	adrs.setTreeHeight(uint32(z))
+	adrs.setTreeIndex(i)
+	c.thash(out, adrs, children)
+}
+
+// xmssSign creates an XMSS signature of an n byte message (FIPS 205 algorithm 10).
+func (c *context) xmssSign(msg []byte, idx uint32, adrs *address) []byte {
+	n := c.p.n
+	auth := make([]byte, c.p.hp*n)
+	for j := 0; j < c.p.hp; j++ {
+		c.xmssNode(auth[j*n:], (idx>>j)^1, j, adrs)
+	}
+	adrs.setTypeAndClear(addrWotsHash)
+	adrs.setKeyPairAddress(idx)
+	return append(c.wotsSign(msg, adrs), auth...)
+}
+
+// xmssPkFromSig computes an XMSS root from a signature (FIPS 205 algorithm 11).
+func (c *context) xmssPkFromSig(out []byte, idx uint32, sig, msg []byte, adrs *address) {
+	n := c.p.n
+	adrs.setTypeAndClear(addrWotsHash)
+	adrs.setKeyPairAddress(idx)
+
+	var (
+		wots = sig[:c.p.len*n]
+		auth = sig[c.p.len*n:]
+		node = make([]byte, n)
+	)
+	c.wotsPkFromSig(node, wots, msg, adrs)
+
+	adrs.setTypeAndClear(addrTree)
+	adrs.setTreeIndex(idx)
+	for k := 0; k < c.p.hp; k++ {
+		adrs.setTreeHeight(uint32(k + 1))
+		sibling := auth[k*n : (k+1)*n]
+		if (idx>>k)&1 == 0 {
+			adrs.setTreeIndex(adrs.treeIndex() / 2)
+			c.thash(node, adrs, node, sibling)
+		} else {
+			adrs.setTreeIndex((adrs.treeIndex() - 1) / 2)
+			c.thash(node, adrs, sibling, node)
+		}
+	}
+	copy(out, node)
+}
+
+// htSign creates a hypertree signature (FIPS 205 algorithm 12).
+func (c *context) htSign(msg []byte, tree uint64, leaf uint32) []byte {
+	var (
+		n    = c.p.n
+		adrs address
+		root = make([]byte, n)
+	)
+	adrs.setTreeAddress(tree)
+	sig := c.xmssSign(msg, leaf, &adrs)
+	c.xmssPkFromSig(root, leaf, sig, msg, &adrs)
+
+	for j := 1; j < c.p.d; j++ {
+		leaf = uint32(tree & (1<<c.p.hp - 1))
+		tree >>= c.p.hp
+		adrs.setLayerAddress(j)
+		adrs.setTreeAddress(tree)
+		tmp := c.xmssSign(root, leaf, &adrs)
+		sig = append(sig, tmp...)
+		if j < c.p.d-1 {
+			c.xmssPkFromSig(root, leaf, tmp, root, &adrs)
+		}
+	}
+	return sig
+}
+
+// htVerify verifies a hypertree signature (FIPS 205 algorithm 13).
+func (c *context) htVerify(msg, sig []byte, tree uint64, leaf uint32, pkRoot []byte) bool {
+	var (
+		n      = c.p.n
+		adrs   address
+		node   = make([]byte, n)
+		xmssSz = (c.p.len + c.p.hp) * n
+	)
+	adrs.setTreeAddress(tree)
+	c.xmssPkFromSig(node, leaf, sig[:xmssSz], msg, &adrs)
+
+	for j := 1; j < c.p.d; j++ {
+		leaf = uint32(tree & (1<<c.p.hp - 1))
+		tree >>= c.p.hp
+		adrs.setLayerAddress(j)
+		adrs.setTreeAddress(tree)
+		c.xmssPkFromSig(node, leaf, sig[j*xmssSz:(j+1)*xmssSz], node, &adrs)
+	}
+	return subtle.ConstantTimeCompare(node, pkRoot) == 1
+}
+
+// forsSkGen derives a FORS private value (FIPS 205 algorithm 14).
+func (c *context) forsSkGen(out []byte, adrs *address, idx uint32) {
+	sk := *adrs
+	sk.setTypeAndClear(addrForsPRF)
+	sk.setKeyPairAddress(adrs.keyPairAddress())
+	sk.setTreeIndex(idx)
+	c.prf(out, &sk)
+}
+
+// forsNode computes the root of a FORS subtree of height z at index i
+// (FIPS 205 algorithm 15).
+func (c *context) forsNode(out []byte, i uint32, z int, adrs *address) {
+	n := c.p.n
+	if z == 0 {
+		sk := make([]byte, n)
+		c.forsSkGen(sk, adrs, i)
+		adrs.setTreeHeight(0)
+		adrs.setTreeIndex(i)
+		c.thash(out, adrs, sk)
+		return
+	}
+	children := make([]byte, 2*n)
+	c.forsNode(children[:n], 2*i, z-1, adrs)
+	c.forsNode(children[n:], 2*i+1, z-1, adrs)
+
+	adrs.setTreeHeight(uint32(z))
+	adrs.setTreeIndex(i)
+	c.thash(out, adrs, children)
+}
+
+// forsSign creates a FORS signature (FIPS 205 algorithm 16).
+func (c *context) forsSign(md []byte, adrs *address) []byte {
+	var (
+		n       = c.p.n
+		a       = c.p.a
+		indices = make([]uint32, c.p.k)
+		sig     = make([]byte, c.p.k*(1+a)*n)
+	)
+	base2b(indices, md, a)
+	for i, idx := range indices {
+		off := i * (1 + a) * n
+		c.forsSkGen(sig[off:], adrs, uint32(i)<<a+idx)
+		for j := 0; j < a; j++ {
+			s := (idx >> j) ^ 1
+			c.forsNode(sig[off+(1+j)*n:], uint32(i)<<(a-j)+s, j, adrs)
+		}
+	}
+	return sig
+}
+
+// forsPkFromSig computes a FORS public key from a signature (FIPS 205 algorithm 17).
+func (c *context) forsPkFromSig(sig, md []byte, adrs *address) []byte {
+	var (
+		n       = c.p.n
+		a       = c.p.a
+		indices = make([]uint32, c.p.k)
+		roots   = make([]byte, c.p.k*n)
+	)
+	base2b(indices, md, a)
+	for i, idx := range indices {
+		var (
+			off  = i * (1 + a) * n
+			node = roots[i*n : (i+1)*n]
+		)
+		adrs.setTreeHeight(0)
+		adrs.setTreeIndex(uint32(i)<<a + idx)
+		c.thash(node, adrs, sig[off:off+n])
+
+		for j := 0; j < a; j++ {
+			sibling := sig[off+(1+j)*n : off+(2+j)*n]
+			adrs.setTreeHeight(uint32(j + 1))
+			if (idx>>j)&1 == 0 {
+				adrs.setTreeIndex(adrs.treeIndex() / 2)
+				c.thash(node, adrs, node, sibling)
+			} else {
+				adrs.setTreeIndex((adrs.treeIndex() - 1) / 2)
+				c.thash(node, adrs, sibling, node)
+			}
+		}
+	}
+	pk := *adrs
+	pk.setTypeAndClear(addrForsRoots)
+	pk.setKeyPairAddress(adrs.keyPairAddress())
+
+	out := make([]byte, n)
+	c.thash(out, &pk, roots)
+	return out
+}
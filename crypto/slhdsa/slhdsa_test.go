@@ -0,0 +1,134 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// SLH-DSA (FIPS 205) stateless hash-based signatures
+
+package slhdsa
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+var allParams = []*Params{SHAKE128s, SHAKE128f, SHAKE192s, SHAKE192f, SHAKE256s, SHAKE256f}
+
+// Tests that the derived sizes match FIPS 205, table 2.
+func TestSizes(t *testing.T) {
+	for _, tt := range []struct {
+		p   *Params
+		pub int
+		sig int
+	}{
+		{SHAKE128s, 32, 7856},
+		{SHAKE128f, 32, 17088},
+		{SHAKE192s, 48, 16224},
+		{SHAKE192f, 48, 35664},
+		{SHAKE256s, 64, 29792},
+		{SHAKE256f, 64, 49856},
+	} {
+		if have := tt.p.PublicKeySize(); have != tt.pub {
+			t.Errorf("%s: public key size mismatch: have %d, want %d", tt.p.Name, have, tt.pub)
+		}
+		if have := tt.p.SignatureSize(); have != tt.sig {
+			t.Errorf("%s: signature size mismatch: have %d, want %d", tt.p.Name, have, tt.sig)
+		}
+		// The digest must be exactly consumed by the FORS and hypertree indices
+		want := (tt.p.k*tt.p.a+7)/8 + (tt.p.h-tt.p.hp+7)/8 + (tt.p.hp+7)/8
+		if tt.p.m != want {
+			t.Errorf("%s: digest length mismatch: have %d, want %d", tt.p.Name, tt.p.m, want)
+		}
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	params := allParams
+	if testing.Short() {
+		params = []*Params{SHAKE128f}
+	}
+	for _, p := range params {
+		t.Run(p.Name, func(t *testing.T) {
+			pub, priv, err := p.GenerateKey(rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if derived, _ := p.PublicKey(priv); !bytes.Equal(derived, pub) {
+				t.Fatalf("public key mismatch")
+			}
+			msg, ctx := []byte("lux post-quantum"), []byte("ctx")
+			addrnd := make([]byte, p.n)
+			rand.Read(addrnd)
+
+			sig, err := p.Sign(priv, msg, ctx, addrnd)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(sig) != p.SignatureSize() {
+				t.Fatalf("signature size mismatch: have %d, want %d", len(sig), p.SignatureSize())
+			}
+			if !p.Verify(pub, msg, sig, ctx) {
+				t.Fatalf("valid signature rejected")
+			}
+			if p.Verify(pub, []byte("lux post-quantun"), sig, ctx) {
+				t.Errorf("signature accepted for a different message")
+			}
+			if p.Verify(pub, msg, sig, nil) {
+				t.Errorf("signature accepted for a different context")
+			}
+			if p.Verify(pub, msg, sig[:len(sig)-1], ctx) {
+				t.Errorf("truncated signature accepted")
+			}
+			// Flipping a bit in any component must invalidate the signature
+			for _, pos := range []int{0, p.n, p.n + p.k*(1+p.a)*p.n, len(sig) - 1} {
+				bad := bytes.Clone(sig)
+				bad[pos] ^= 1
+				if p.Verify(pub, msg, bad, ctx) {
+					t.Errorf("signature with flipped byte %d accepted", pos)
+				}
+			}
+			badPub := bytes.Clone(pub)
+			badPub[len(badPub)-1] ^= 1
+			if p.Verify(badPub, msg, sig, ctx) {
+				t.Errorf("signature accepted under a different key")
+			}
+		})
+	}
+}
+
+// Tests that the deterministic variant signs reproducibly and that the
+// encoding stays stable for a fixed key.
+func TestDeterministicSign(t *testing.T) {
+	seed := make([]byte, SHAKE128f.SeedSize())
+	for i := range seed {
+		seed[i] = byte(i)
+	}
+	pub, priv, err := SHAKE128f.NewKeyFromSeed(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig1, _ := SHAKE128f.Sign(priv, []byte("msg"), nil, nil)
+	sig2, _ := SHAKE128f.Sign(priv, []byte("msg"), nil, nil)
+	if !bytes.Equal(sig1, sig2) {
+		t.Fatalf("deterministic signatures differ")
+	}
+	if !SHAKE128f.Verify(pub, []byte("msg"), sig1, nil) {
+		t.Fatalf("deterministic signature rejected")
+	}
+	if _, _, err := SHAKE128f.NewKeyFromSeed(seed[1:]); err == nil {
+		t.Errorf("short seed accepted")
+	}
+	if _, err := SHAKE128f.Sign(priv, nil, make([]byte, 256), nil); err == nil {
+		t.Errorf("oversized context accepted")
+	}
+}
+
+func BenchmarkVerify(b *testing.B) {
+	for _, p := range allParams {
+		b.Run(p.Name, func(b *testing.B) {
+			pub, priv, _ := p.GenerateKey(rand.Reader)
+			sig, _ := p.Sign(priv, []byte("msg"), nil, nil)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				p.Verify(pub, []byte("msg"), sig, nil)
+			}
+		})
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"strings"
 
 	"github.com/luxfi/geth/core"
+	"github.com/luxfi/geth/core/rawdb"
 	"github.com/luxfi/geth/core/types"
 	"github.com/luxfi/geth/rlp"
 )
@@ -141,3 +142,15 @@ func (api *AdminAPI) ImportChain(file string) (bool, error) {
 	}
 	return true, nil
 }
+
+// BackupDatabase creates a crash-consistent backup of the chain database in
+// the given directory while the node keeps running. State held in memory is
+// not part of the backup, a node started from it rewinds to the most recent
+// persisted state, same as after a crash.
+func (api *AdminAPI) BackupDatabase(dir string) (*rawdb.BackupManifest, error) {
+	if _, err := os.Stat(dir); err == nil {
+		// Same as for exports, don't allow writing into existing locations.
+		return nil, errors.New("location would overwrite an existing directory")
+	}
+	return rawdb.Backup(api.eth.ChainDb(), dir)
+}
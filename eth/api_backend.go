@@ -413,6 +413,7 @@ func (b *EthAPIBackend) SyncProgress(ctx context.Context) ethereum.SyncProgress
 	if err == nil {
 		prog.StateIndexRemaining = remain
 	}
+	prog.EarliestBlock, _ = b.eth.blockchain.HistoryPruningCutoff()
 	return prog
 }
 
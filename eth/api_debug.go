@@ -26,6 +26,7 @@ import (
 	"github.com/luxfi/geth/common/hexutil"
 	"github.com/luxfi/geth/core/rawdb"
 	"github.com/luxfi/geth/core/state"
+	"github.com/luxfi/geth/core/stateless"
 	"github.com/luxfi/geth/core/types"
 	"github.com/luxfi/crypto"
 	"github.com/luxfi/geth/internal/ethapi"
@@ -445,3 +446,386 @@ func (api *DebugAPI) GetTrieFlushInterval() (string, error) {
 	}
 	return api.eth.blockchain.GetTrieFlushInterval().String(), nil
 }
+
+// stateHistoryMaxResults is the maximum number of changes returned by a single
+// debug_accountHistory or debug_storageHistory call.
+const stateHistoryMaxResults = 1024
+
+// AccountHistoryValue is the content of an account at some point of its history.
+type AccountHistoryValue struct {
+	Nonce       hexutil.Uint64 `json:"nonce"`
+	Balance     *hexutil.U256  `json:"balance"`
+	CodeHash    common.Hash    `json:"codeHash"`
+	StorageRoot common.Hash    `json:"storageRoot"`
+}
+
+// AccountHistoryChange is a mutation of an account made by a block. The values
+// are null if the account didn't exist before or after the block.
+type AccountHistoryChange struct {
+	Block hexutil.Uint64       `json:"block"`
+	Old   *AccountHistoryValue `json:"old"`
+	New   *AccountHistoryValue `json:"new"`
+}
+
+// AccountHistoryResult is a page of the mutations of an account. Next is the
+// block to resume the query from, or null if the range is exhausted.
+type AccountHistoryResult struct {
+	Changes []AccountHistoryChange `json:"changes"`
+	Next    *hexutil.Uint64        `json:"next"`
+}
+
+// StorageHistoryChange is a mutation of a storage slot made by a block.
+type StorageHistoryChange struct {
+	Block hexutil.Uint64 `json:"block"`
+	Old   common.Hash    `json:"old"`
+	New   common.Hash    `json:"new"`
+}
+
+// StorageHistoryResult is a page of the mutations of a storage slot. Next is
+// the block to resume the query from, or null if the range is exhausted.
+type StorageHistoryResult struct {
+	Changes []StorageHistoryChange `json:"changes"`
+	Next    *hexutil.Uint64        `json:"next"`
+}
+
+// AccountHistory returns the blocks within [fromBlock, toBlock] which changed
+// the given account, along with its value before and after each of them.
+//
+// The changes are looked up in the state history index, which is only kept by
+// archive nodes using the path-based scheme, and cover the blocks whose state
+// histories are retained, excluding the most recent ones still in memory.
+func (api *DebugAPI) AccountHistory(ctx context.Context, address common.Address, fromBlock, toBlock rpc.BlockNumber) (*AccountHistoryResult, error) {
+	from, to, err := api.stateHistoryRange(ctx, fromBlock, toBlock)
+	if err != nil {
+		return nil, err
+	}
+	changes, more, err := api.eth.blockchain.TrieDB().AccountChanges(address, from, to, stateHistoryMaxResults)
+	if err != nil {
+		return nil, err
+	}
+	result := &AccountHistoryResult{Changes: make([]AccountHistoryChange, 0, len(changes))}
+	for _, change := range changes {
+		prev, err := decodeAccountHistoryValue(change.Prev)
+		if err != nil {
+			return nil, err
+		}
+		post, err := decodeAccountHistoryValue(change.Post)
+		if err != nil {
+			return nil, err
+		}
+		result.Changes = append(result.Changes, AccountHistoryChange{
+			Block: hexutil.Uint64(change.Block),
+			Old:   prev,
+			New:   post,
+		})
+	}
+	if more {
+		next := hexutil.Uint64(changes[len(changes)-1].Block + 1)
+		result.Next = &next
+	}
+	return result, nil
+}
+
+// StorageHistory returns the blocks within [fromBlock, toBlock] which changed
+// the given storage slot, along with its value before and after each of them.
+// The same availability constraints as for debug_accountHistory apply.
+func (api *DebugAPI) StorageHistory(ctx context.Context, address common.Address, slot common.Hash, fromBlock, toBlock rpc.BlockNumber) (*StorageHistoryResult, error) {
+	from, to, err := api.stateHistoryRange(ctx, fromBlock, toBlock)
+	if err != nil {
+		return nil, err
+	}
+	changes, more, err := api.eth.blockchain.TrieDB().StorageChanges(address, slot, from, to, stateHistoryMaxResults)
+	if err != nil {
+		return nil, err
+	}
+	result := &StorageHistoryResult{Changes: make([]StorageHistoryChange, 0, len(changes))}
+	for _, change := range changes {
+		prev, err := decodeStorageHistoryValue(change.Prev)
+		if err != nil {
+			return nil, err
+		}
+		post, err := decodeStorageHistoryValue(change.Post)
+		if err != nil {
+			return nil, err
+		}
+		result.Changes = append(result.Changes, StorageHistoryChange{
+			Block: hexutil.Uint64(change.Block),
+			Old:   prev,
+			New:   post,
+		})
+	}
+	if more {
+		next := hexutil.Uint64(changes[len(changes)-1].Block + 1)
+		result.Next = &next
+	}
+	return result, nil
+}
+
+// stateDiffMaxBlocks is the maximum number of blocks a single state diff query
+// may span, as the whole range is scanned for every page.
+const stateDiffMaxBlocks = 4096
+
+// StateDiffSlot is the net change of a storage slot. The key is the slot hash
+// instead of the raw slot key if Hashed is set, which happens for the slots
+// changed before Cancun unless the preimages are recorded.
+type StateDiffSlot struct {
+	Key    common.Hash `json:"key"`
+	Hashed bool        `json:"hashed,omitempty"`
+	Old    common.Hash `json:"old"`
+	New    common.Hash `json:"new"`
+}
+
+// StateDiffAccount is the net change of an account and its storage. The values
+// are null if the account didn't exist before or after the range.
+type StateDiffAccount struct {
+	Address common.Address       `json:"address"`
+	Old     *AccountHistoryValue `json:"old"`
+	New     *AccountHistoryValue `json:"new"`
+	Storage []StateDiffSlot      `json:"storage"`
+}
+
+// StateDiffResult is a page of the accounts changed within a block range. Next
+// is the account to resume the query from, or null if the diff is exhausted.
+type StateDiffResult struct {
+	Accounts []StateDiffAccount `json:"accounts"`
+	Next     *common.Address    `json:"next"`
+}
+
+// StateDiff returns the net state changes made by the blocks within [fromBlock,
+// toBlock]: the accounts and the storage slots whose values differ between the
+// state before fromBlock and the state after toBlock, along with both values.
+// The accounts are sorted by address and paged, the query is resumed by passing
+// the returned next address as start.
+//
+// The diff is computed from the state histories and the in-memory diff layers,
+// which are only kept by nodes using the path-based scheme. Reading the state
+// after toBlock from the state histories requires the archive mode index.
+func (api *DebugAPI) StateDiff(ctx context.Context, fromBlock, toBlock rpc.BlockNumber, start *common.Address) (*StateDiffResult, error) {
+	tdb := api.eth.blockchain.TrieDB()
+	if tdb.Scheme() != rawdb.PathScheme {
+		return nil, errors.New("state diff is only available with the path-based scheme")
+	}
+	resolve := func(number rpc.BlockNumber) (*types.Header, error) {
+		if number == rpc.PendingBlockNumber {
+			return nil, errors.New("pending block is not supported")
+		}
+		header, err := api.eth.APIBackend.HeaderByNumber(ctx, number)
+		if err != nil {
+			return nil, err
+		}
+		if header == nil {
+			return nil, fmt.Errorf("block #%d not found", number)
+		}
+		return header, nil
+	}
+	first, err := resolve(fromBlock)
+	if err != nil {
+		return nil, err
+	}
+	last, err := resolve(toBlock)
+	if err != nil {
+		return nil, err
+	}
+	if first.Number.Uint64() == 0 {
+		return nil, errors.New("genesis block has no state diff")
+	}
+	if first.Number.Uint64() > last.Number.Uint64() {
+		return nil, fmt.Errorf("fromBlock #%d is above toBlock #%d", first.Number, last.Number)
+	}
+	if n := last.Number.Uint64() - first.Number.Uint64() + 1; n > stateDiffMaxBlocks {
+		return nil, fmt.Errorf("block range %d exceeds the limit %d", n, stateDiffMaxBlocks)
+	}
+	parent := api.eth.blockchain.GetHeader(first.ParentHash, first.Number.Uint64()-1)
+	if parent == nil {
+		return nil, fmt.Errorf("parent of block #%d not found", first.Number)
+	}
+	if start == nil {
+		start = new(common.Address)
+	}
+	diffs, next, err := tdb.StateDiff(parent.Root, last.Root, *start, stateHistoryMaxResults)
+	if err != nil {
+		return nil, err
+	}
+	result := &StateDiffResult{
+		Accounts: make([]StateDiffAccount, 0, len(diffs)),
+		Next:     next,
+	}
+	for _, diff := range diffs {
+		prev, err := decodeAccountHistoryValue(diff.Prev)
+		if err != nil {
+			return nil, err
+		}
+		post, err := decodeAccountHistoryValue(diff.Post)
+		if err != nil {
+			return nil, err
+		}
+		account := StateDiffAccount{
+			Address: diff.Address,
+			Old:     prev,
+			New:     post,
+			Storage: make([]StateDiffSlot, 0, len(diff.Storage)),
+		}
+		for _, slot := range diff.Storage {
+			prev, err := decodeStorageHistoryValue(slot.Prev)
+			if err != nil {
+				return nil, err
+			}
+			post, err := decodeStorageHistoryValue(slot.Post)
+			if err != nil {
+				return nil, err
+			}
+			account.Storage = append(account.Storage, StateDiffSlot{
+				Key:    slot.Key,
+				Hashed: slot.Hashed,
+				Old:    prev,
+				New:    post,
+			})
+		}
+		result.Accounts = append(result.Accounts, account)
+	}
+	return result, nil
+}
+
+// executionWitnessReexec is the maximum number of blocks re-executed to regenerate
+// the parent state of a block whose witness is requested, with the hash-based scheme.
+const executionWitnessReexec = 128
+
+// ExecutionWitness re-executes the given block on top of its parent state and
+// returns the execution witness collected meanwhile: the ancestor headers, the
+// contract codes and the trie nodes needed to execute the block statelessly.
+//
+// The parent state must be available, either live, regenerated from a nearby
+// state with the hash-based scheme, or rebuilt from the state histories within
+// the proof history with the path-based scheme.
+func (api *DebugAPI) ExecutionWitness(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*stateless.ExtWitness, error) {
+	witness, err := api.executionWitness(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	return witness.ToExtWitness(), nil
+}
+
+// ExecutionWitnessRLP is analogous to ExecutionWitness, only it returns the
+// witness in the compact RLP encoding accepted by the stateless execution.
+func (api *DebugAPI) ExecutionWitnessRLP(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	witness, err := api.executionWitness(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	return rlp.EncodeToBytes(witness)
+}
+
+// executionWitness re-executes the given block and collects its witness.
+func (api *DebugAPI) executionWitness(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*stateless.Witness, error) {
+	if number, ok := blockNrOrHash.Number(); ok && number == rpc.PendingBlockNumber {
+		return nil, errors.New("pending block is not supported")
+	}
+	block, err := api.eth.APIBackend.BlockByNumberOrHash(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, errors.New("block not found")
+	}
+	if block.NumberU64() == 0 {
+		return nil, errors.New("genesis block is not executable")
+	}
+	chain := api.eth.blockchain
+	parent := chain.GetBlock(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, fmt.Errorf("parent of block #%d not found", block.NumberU64())
+	}
+	statedb, release, err := api.eth.stateAtBlock(ctx, parent, executionWitnessReexec, nil, true, false)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	witness, err := stateless.NewWitness(block.Header(), chain)
+	if err != nil {
+		return nil, err
+	}
+	statedb.StartPrefetcher("debug", witness)
+	defer statedb.StopPrefetcher()
+
+	res, err := chain.Processor().Process(block, statedb, chain.Config())
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute block #%d: %v", block.NumberU64(), err)
+	}
+	// Validating the post state computes the state root, which gathers the trie
+	// nodes of the mutated elements into the witness.
+	if err := chain.Validator().ValidateState(block, statedb, res.Receipts, res.GasUsed); err != nil {
+		return nil, fmt.Errorf("failed to validate block #%d: %v", block.NumberU64(), err)
+	}
+	return witness, nil
+}
+
+// stateHistoryRange resolves the block range of a state history query, making
+// sure the state history index is available and complete.
+func (api *DebugAPI) stateHistoryRange(ctx context.Context, fromBlock, toBlock rpc.BlockNumber) (uint64, uint64, error) {
+	tdb := api.eth.blockchain.TrieDB()
+	if tdb.Scheme() != rawdb.PathScheme {
+		return 0, 0, errors.New("state history is only available with the path-based scheme")
+	}
+	remain, err := tdb.IndexProgress()
+	if err != nil {
+		return 0, 0, err
+	}
+	if remain != 0 {
+		return 0, 0, fmt.Errorf("state history is being indexed, %d histories remaining", remain)
+	}
+	resolve := func(number rpc.BlockNumber) (uint64, error) {
+		if number == rpc.PendingBlockNumber {
+			return 0, errors.New("pending block is not supported")
+		}
+		header, err := api.eth.APIBackend.HeaderByNumber(ctx, number)
+		if err != nil {
+			return 0, err
+		}
+		if header == nil {
+			return 0, fmt.Errorf("block #%d not found", number)
+		}
+		return header.Number.Uint64(), nil
+	}
+	from, err := resolve(fromBlock)
+	if err != nil {
+		return 0, 0, err
+	}
+	to, err := resolve(toBlock)
+	if err != nil {
+		return 0, 0, err
+	}
+	if from > to {
+		return 0, 0, fmt.Errorf("fromBlock #%d is above toBlock #%d", from, to)
+	}
+	return from, to, nil
+}
+
+// decodeAccountHistoryValue decodes an account recorded in the state history.
+func decodeAccountHistoryValue(blob []byte) (*AccountHistoryValue, error) {
+	if len(blob) == 0 {
+		return nil, nil
+	}
+	account, err := types.FullAccount(blob)
+	if err != nil {
+		return nil, err
+	}
+	return &AccountHistoryValue{
+		Nonce:       hexutil.Uint64(account.Nonce),
+		Balance:     (*hexutil.U256)(account.Balance),
+		CodeHash:    common.BytesToHash(account.CodeHash),
+		StorageRoot: account.Root,
+	}, nil
+}
+
+// decodeStorageHistoryValue decodes a storage slot recorded in the state history.
+func decodeStorageHistoryValue(blob []byte) (common.Hash, error) {
+	if len(blob) == 0 {
+		return common.Hash{}, nil
+	}
+	_, content, _, err := rlp.Split(blob)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(content), nil
+}
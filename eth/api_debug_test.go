@@ -18,6 +18,7 @@ package eth
 
 import (
 	"bytes"
+	"context"
 	"crypto/ecdsa"
 	"fmt"
 	"math/big"
@@ -29,14 +30,21 @@ import (
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/luxfi/geth/common"
+	"github.com/luxfi/geth/consensus"
 	"github.com/luxfi/geth/consensus/ethash"
 	"github.com/luxfi/geth/core"
 	"github.com/luxfi/geth/core/rawdb"
 	"github.com/luxfi/geth/core/state"
+	"github.com/luxfi/geth/core/stateless"
 	"github.com/luxfi/geth/core/tracing"
 	"github.com/luxfi/geth/core/types"
+	"github.com/luxfi/geth/core/vm"
+	"github.com/luxfi/geth/internal/ethapi"
 	"github.com/luxfi/crypto"
 	"github.com/luxfi/geth/params"
+	"github.com/luxfi/geth/rlp"
+	"github.com/luxfi/geth/rpc"
+	"github.com/luxfi/geth/trie"
 	"github.com/luxfi/geth/triedb"
 	"github.com/holiman/uint256"
 	"github.com/stretchr/testify/assert"
@@ -335,3 +343,384 @@ func TestGetModifiedAccounts(t *testing.T) {
 		}
 	})
 }
+
+// newPathArchiveBackend creates a path-based archive chain from the given blocks,
+// keeping its state histories in a temporary freezer. The chain is stopped
+// when the test is done.
+func newPathArchiveBackend(t *testing.T, genesis *core.Genesis, engine consensus.Engine, blocks []*types.Block, proofHistory uint64) *Ethereum {
+	options := &core.BlockChainConfig{
+		TrieCleanLimit: 256,
+		TrieDirtyLimit: 256,
+		TrieTimeLimit:  5 * time.Minute,
+		StateScheme:    rawdb.PathScheme,
+		ArchiveMode:    true,
+		ProofHistory:   proofHistory,
+	}
+	db, err := rawdb.Open(rawdb.NewMemoryDatabase(), rawdb.OpenOptions{Ancient: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	chain, err := core.NewBlockChain(db, genesis, engine, options)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	t.Cleanup(chain.Stop)
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	eth := &Ethereum{blockchain: chain}
+	eth.APIBackend = &EthAPIBackend{eth: eth}
+	return eth
+}
+
+func TestStateHistory(t *testing.T) {
+	t.Parallel()
+
+	var (
+		accounts = newAccounts(2)
+		contract = common.HexToAddress("0xc0de")
+		genesis  = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: types.GenesisAlloc{
+				accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+				// PUSH1 0 CALLDATALOAD PUSH1 0 SSTORE
+				contract: {Balance: common.Big0, Code: common.FromHex("0x6000356000556000")},
+			},
+		}
+		signer   = types.LatestSigner(genesis.Config)
+		engine   = ethash.NewFaker()
+		transfer = map[int]bool{3: true, 7: true, 15: true}
+		store    = map[int]bool{5: true, 12: true}
+	)
+	// Generate enough blocks to flush the first ones to the disk layer, their
+	// state histories are written and indexed then.
+	_, blocks, _ := core.GenerateChainWithGenesis(genesis, engine, 160, func(i int, b *core.BlockGen) {
+		var tx *types.Transaction
+		switch {
+		case transfer[i+1]:
+			tx = types.NewTransaction(b.TxNonce(accounts[0].addr), accounts[1].addr, big.NewInt(1000), params.TxGas, b.BaseFee(), nil)
+		case store[i+1]:
+			tx = types.NewTransaction(b.TxNonce(accounts[0].addr), contract, common.Big0, 100000, b.BaseFee(), common.BigToHash(big.NewInt(int64(i+1))).Bytes())
+		default:
+			return
+		}
+		tx, _ = types.SignTx(tx, signer, accounts[0].key)
+		b.AddTx(tx)
+	})
+	eth := newPathArchiveBackend(t, genesis, engine, blocks, 0)
+	api := NewDebugAPI(eth)
+
+	// Wait for the state histories to be indexed
+	var accountHistory *AccountHistoryResult
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		var err error
+		accountHistory, err = api.AccountHistory(context.Background(), accounts[1].addr, 0, rpc.LatestBlockNumber)
+		if err == nil {
+			break
+		}
+		if time.Since(start) > 10*time.Second {
+			t.Fatalf("failed to retrieve account history: %v", err)
+		}
+	}
+	if len(accountHistory.Changes) != len(transfer) || accountHistory.Next != nil {
+		t.Fatalf("unexpected account history: %s", dumper.Sdump(accountHistory))
+	}
+	for i, block := range []uint64{3, 7, 15} {
+		change := accountHistory.Changes[i]
+		if uint64(change.Block) != block {
+			t.Fatalf("change %d: block mismatch, want %d, got %d", i, block, change.Block)
+		}
+		if balance := (*uint256.Int)(change.New.Balance).Uint64(); balance != 1000*uint64(i+1) {
+			t.Fatalf("change %d: new balance mismatch, want %d, got %d", i, 1000*(i+1), balance)
+		}
+		if i == 0 {
+			if change.Old != nil {
+				t.Fatalf("change %d: unexpected old value %v", i, change.Old)
+			}
+		} else if (*uint256.Int)(change.Old.Balance).Uint64() != 1000*uint64(i) {
+			t.Fatalf("change %d: old balance mismatch", i)
+		}
+	}
+	// Check the storage slot history, restricted to a range
+	storageHistory, err := api.StorageHistory(context.Background(), contract, common.Hash{}, 6, 20)
+	if err != nil {
+		t.Fatalf("failed to retrieve storage history: %v", err)
+	}
+	want := []StorageHistoryChange{{Block: 12, Old: common.BigToHash(big.NewInt(5)), New: common.BigToHash(big.NewInt(12))}}
+	if !reflect.DeepEqual(storageHistory.Changes, want) || storageHistory.Next != nil {
+		t.Fatalf("unexpected storage history: %s", dumper.Sdump(storageHistory))
+	}
+	if _, err := api.AccountHistory(context.Background(), accounts[1].addr, 20, 10); err == nil {
+		t.Fatal("expected error for inverted range")
+	}
+}
+
+func TestHistoricProof(t *testing.T) {
+	t.Parallel()
+
+	var (
+		accounts = newAccounts(2)
+		genesis  = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  types.GenesisAlloc{accounts[0].addr: {Balance: big.NewInt(params.Ether)}},
+		}
+		signer = types.LatestSigner(genesis.Config)
+		engine = ethash.NewFaker()
+	)
+	_, blocks, _ := core.GenerateChainWithGenesis(genesis, engine, 160, func(i int, b *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(accounts[0].addr), accounts[1].addr, big.NewInt(1000), params.TxGas, b.BaseFee(), nil), signer, accounts[0].key)
+		b.AddTx(tx)
+	})
+	// The disk layer is at block 32, allow proving 16 blocks below it
+	var (
+		eth = newPathArchiveBackend(t, genesis, engine, blocks, 16)
+		api = ethapi.NewBlockChainAPI(eth.APIBackend)
+	)
+	for _, number := range []int64{20, 25, 32} {
+		var (
+			result *ethapi.AccountResult
+			err    error
+		)
+		// Historic states are only accessible once indexed
+		for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+			result, err = api.GetProof(context.Background(), accounts[1].addr, nil, rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(number)))
+			if err == nil {
+				break
+			}
+			if time.Since(start) > 10*time.Second {
+				t.Fatalf("block %d: failed to prove account: %v", number, err)
+			}
+		}
+		if want := big.NewInt(1000 * number); result.Balance.ToInt().Cmp(want) != 0 {
+			t.Fatalf("block %d: balance mismatch, want %v, got %v", number, want, result.Balance)
+		}
+		proof := rawdb.NewMemoryDatabase()
+		for _, node := range result.AccountProof {
+			blob := common.FromHex(node)
+			proof.Put(crypto.Keccak256(blob), blob)
+		}
+		root := blocks[number-1].Root()
+		blob, err := trie.VerifyProof(root, crypto.Keccak256(accounts[1].addr.Bytes()), proof)
+		if err != nil {
+			t.Fatalf("block %d: invalid proof: %v", number, err)
+		}
+		var account types.StateAccount
+		if err := rlp.DecodeBytes(blob, &account); err != nil {
+			t.Fatalf("block %d: invalid account: %v", number, err)
+		}
+		if account.Balance.ToBig().Cmp(result.Balance.ToInt()) != 0 {
+			t.Fatalf("block %d: proven balance mismatch", number)
+		}
+	}
+	if _, err := api.GetProof(context.Background(), accounts[1].addr, nil, rpc.BlockNumberOrHashWithNumber(10)); err == nil {
+		t.Fatal("expected error beyond the proof history limit")
+	}
+}
+
+func TestStateDiff(t *testing.T) {
+	t.Parallel()
+
+	var (
+		accounts = newAccounts(2)
+		contract = common.HexToAddress("0xc0de")
+		genesis  = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: types.GenesisAlloc{
+				accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+				// PUSH1 0 CALLDATALOAD PUSH1 0 SSTORE
+				contract: {Balance: common.Big0, Code: common.FromHex("0x6000356000556000")},
+			},
+		}
+		signer   = types.LatestSigner(genesis.Config)
+		engine   = ethash.NewFaker()
+		transfer = map[int]bool{3: true, 7: true, 155: true}
+		store    = map[int]bool{5: true, 12: true, 150: true}
+	)
+	// The disk layer is at block 32, the later blocks are kept in diff layers
+	_, blocks, _ := core.GenerateChainWithGenesis(genesis, engine, 160, func(i int, b *core.BlockGen) {
+		var tx *types.Transaction
+		switch {
+		case transfer[i+1]:
+			tx = types.NewTransaction(b.TxNonce(accounts[0].addr), accounts[1].addr, big.NewInt(1000), params.TxGas, b.BaseFee(), nil)
+		case store[i+1]:
+			tx = types.NewTransaction(b.TxNonce(accounts[0].addr), contract, common.Big0, 100000, b.BaseFee(), common.BigToHash(big.NewInt(int64(i+1))).Bytes())
+		default:
+			return
+		}
+		tx, _ = types.SignTx(tx, signer, accounts[0].key)
+		b.AddTx(tx)
+	})
+	eth := newPathArchiveBackend(t, genesis, engine, blocks, 0)
+	api := NewDebugAPI(eth)
+
+	find := func(result *StateDiffResult, address common.Address) *StateDiffAccount {
+		for i := range result.Accounts {
+			if result.Accounts[i].Address == address {
+				return &result.Accounts[i]
+			}
+		}
+		return nil
+	}
+	for _, tt := range []struct {
+		from, to   rpc.BlockNumber
+		oldBalance uint64 // balance of the recipient before the range
+		newBalance uint64 // balance of the recipient after the range
+		oldSlot    int64  // contract slot before the range, -1 if not changed
+		newSlot    int64  // contract slot after the range
+	}{
+		{from: 3, to: 12, oldBalance: 0, newBalance: 2000, oldSlot: 0, newSlot: 12},
+		{from: 6, to: 151, oldBalance: 1000, newBalance: 2000, oldSlot: 5, newSlot: 150},
+		{from: 151, to: rpc.LatestBlockNumber, oldBalance: 2000, newBalance: 3000, oldSlot: -1},
+	} {
+		// Historic target states are only accessible once indexed
+		var (
+			result *StateDiffResult
+			err    error
+		)
+		for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+			result, err = api.StateDiff(context.Background(), tt.from, tt.to, nil)
+			if err == nil {
+				break
+			}
+			if time.Since(start) > 10*time.Second {
+				t.Fatalf("range [%d, %d]: failed to retrieve state diff: %v", tt.from, tt.to, err)
+			}
+		}
+		if result.Next != nil {
+			t.Fatalf("range [%d, %d]: unexpected next page %v", tt.from, tt.to, result.Next)
+		}
+		sender, recipient := find(result, accounts[0].addr), find(result, accounts[1].addr)
+		if sender == nil || recipient == nil {
+			t.Fatalf("range [%d, %d]: missing accounts: %s", tt.from, tt.to, dumper.Sdump(result))
+		}
+		if uint64(recipient.New.Nonce) != 0 || (*uint256.Int)(recipient.New.Balance).Uint64() != tt.newBalance {
+			t.Fatalf("range [%d, %d]: new recipient mismatch: %s", tt.from, tt.to, dumper.Sdump(recipient))
+		}
+		if tt.oldBalance == 0 {
+			if recipient.Old != nil {
+				t.Fatalf("range [%d, %d]: unexpected old recipient: %s", tt.from, tt.to, dumper.Sdump(recipient))
+			}
+		} else if (*uint256.Int)(recipient.Old.Balance).Uint64() != tt.oldBalance {
+			t.Fatalf("range [%d, %d]: old recipient mismatch: %s", tt.from, tt.to, dumper.Sdump(recipient))
+		}
+		if sender.New.Nonce <= sender.Old.Nonce {
+			t.Fatalf("range [%d, %d]: sender nonce not increased: %s", tt.from, tt.to, dumper.Sdump(sender))
+		}
+		diff := find(result, contract)
+		if tt.oldSlot < 0 {
+			if diff != nil {
+				t.Fatalf("range [%d, %d]: unexpected contract change: %s", tt.from, tt.to, dumper.Sdump(diff))
+			}
+			continue
+		}
+		// The blocks predate Cancun, so only the slot hashes are recorded
+		want := []StateDiffSlot{{
+			Key:    common.BytesToHash(crypto.Keccak256(common.Hash{}.Bytes())),
+			Hashed: true,
+			Old:    common.BigToHash(big.NewInt(tt.oldSlot)),
+			New:    common.BigToHash(big.NewInt(tt.newSlot)),
+		}}
+		if diff == nil || !reflect.DeepEqual(diff.Storage, want) || diff.Old.CodeHash != diff.New.CodeHash {
+			t.Fatalf("range [%d, %d]: contract change mismatch: %s", tt.from, tt.to, dumper.Sdump(diff))
+		}
+	}
+	// Ensure the diff can be resumed from an account
+	result, err := api.StateDiff(context.Background(), 3, 12, &contract)
+	if err != nil {
+		t.Fatalf("failed to retrieve state diff: %v", err)
+	}
+	for _, account := range result.Accounts {
+		if account.Address.Cmp(contract) < 0 {
+			t.Fatalf("unexpected account %x before the start", account.Address)
+		}
+	}
+	if find(result, contract) == nil {
+		t.Fatal("missing the start account")
+	}
+	if _, err := api.StateDiff(context.Background(), 12, 3, nil); err == nil {
+		t.Fatal("expected error for inverted range")
+	}
+}
+
+func TestExecutionWitness(t *testing.T) {
+	t.Parallel()
+
+	var (
+		accounts = newAccounts(2)
+		contract = common.HexToAddress("0xc0de")
+		genesis  = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: types.GenesisAlloc{
+				accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+				// PUSH1 0 CALLDATALOAD PUSH1 0 SSTORE
+				contract: {Balance: common.Big0, Code: common.FromHex("0x6000356000556000")},
+			},
+		}
+		signer = types.LatestSigner(genesis.Config)
+		engine = ethash.NewFaker()
+	)
+	_, blocks, _ := core.GenerateChainWithGenesis(genesis, engine, 160, func(i int, b *core.BlockGen) {
+		tx := types.NewTransaction(b.TxNonce(accounts[0].addr), accounts[1].addr, big.NewInt(1000), params.TxGas, b.BaseFee(), nil)
+		if i%2 == 0 {
+			tx = types.NewTransaction(b.TxNonce(accounts[0].addr), contract, common.Big0, 100000, b.BaseFee(), common.BigToHash(big.NewInt(int64(i+1))).Bytes())
+		}
+		tx, _ = types.SignTx(tx, signer, accounts[0].key)
+		b.AddTx(tx)
+	})
+	// The disk layer is at block 32, allow rebuilding 16 states below it
+	var (
+		eth = newPathArchiveBackend(t, genesis, engine, blocks, 16)
+		api = NewDebugAPI(eth)
+	)
+	for _, number := range []int64{25, 33, 149, 150, 160} {
+		var (
+			witness *stateless.ExtWitness
+			err     error
+		)
+		// Historic states are only accessible once indexed
+		for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+			witness, err = api.ExecutionWitness(context.Background(), rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(number)))
+			if err == nil {
+				break
+			}
+			if time.Since(start) > 10*time.Second {
+				t.Fatalf("block %d: failed to retrieve witness: %v", number, err)
+			}
+		}
+		if len(witness.Headers) == 0 || witness.Headers[0].Hash() != blocks[number-1].ParentHash() {
+			t.Fatalf("block %d: parent header mismatch", number)
+		}
+		// The odd blocks call the contract, the even ones transfer only
+		if number%2 == 1 && (len(witness.Codes) != 1 || !bytes.Equal(witness.Codes[0], genesis.Alloc[contract].Code)) {
+			t.Fatalf("block %d: codes mismatch: %x", number, witness.Codes)
+		}
+		// The RLP encoded witness must be sufficient to execute the block
+		enc, err := api.ExecutionWitnessRLP(context.Background(), rpc.BlockNumberOrHashWithHash(blocks[number-1].Hash(), true))
+		if err != nil {
+			t.Fatalf("block %d: failed to retrieve RLP witness: %v", number, err)
+		}
+		decoded := new(stateless.Witness)
+		if err := rlp.DecodeBytes(enc, decoded); err != nil {
+			t.Fatalf("block %d: failed to decode witness: %v", number, err)
+		}
+		if len(decoded.State) != len(witness.State) {
+			t.Fatalf("block %d: state nodes mismatch, want %d, got %d", number, len(witness.State), len(decoded.State))
+		}
+		block := blocks[number-1]
+		stateRoot, receiptRoot, err := core.ExecuteStateless(genesis.Config, vm.Config{}, block, decoded)
+		if err != nil {
+			t.Fatalf("block %d: stateless execution failed: %v", number, err)
+		}
+		if stateRoot != block.Root() || receiptRoot != block.ReceiptHash() {
+			t.Fatalf("block %d: stateless roots mismatch, want %x %x, got %x %x", number, block.Root(), block.ReceiptHash(), stateRoot, receiptRoot)
+		}
+	}
+	if _, err := api.ExecutionWitness(context.Background(), rpc.BlockNumberOrHashWithNumber(10)); err == nil {
+		t.Fatal("expected error beyond the proof history limit")
+	}
+	if _, err := api.ExecutionWitness(context.Background(), rpc.BlockNumberOrHashWithNumber(0)); err == nil {
+		t.Fatal("expected error for the genesis block")
+	}
+}
@@ -20,6 +20,7 @@ package eth
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"math/big"
@@ -33,6 +34,7 @@ import (
 	"github.com/luxfi/geth/consensus"
 	"github.com/luxfi/geth/core"
 	"github.com/luxfi/geth/core/filtermaps"
+	"github.com/luxfi/geth/core/history"
 	"github.com/luxfi/geth/core/rawdb"
 	"github.com/luxfi/geth/core/state/pruner"
 	"github.com/luxfi/geth/core/txpool"
@@ -48,6 +50,7 @@ import (
 	"github.com/luxfi/geth/eth/protocols/snap"
 	"github.com/luxfi/geth/eth/tracers"
 	"github.com/luxfi/geth/ethdb"
+	"github.com/luxfi/geth/ethdb/netdb"
 	"github.com/luxfi/geth/event"
 	"github.com/luxfi/geth/internal/ethapi"
 	"github.com/luxfi/geth/internal/shutdowncheck"
@@ -136,6 +139,9 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if !config.HistoryMode.IsValid() {
 		return nil, fmt.Errorf("invalid history mode %d", config.HistoryMode)
 	}
+	if config.HistoryMode == history.KeepRecent && config.HistoryWindow == 0 {
+		return nil, errors.New("history window must be non-zero in the recent history mode")
+	}
 	if config.Miner.GasPrice == nil || config.Miner.GasPrice.Sign() <= 0 {
 		log.Warn("Sanitizing invalid miner gas price", "provided", config.Miner.GasPrice, "updated", ethconfig.Defaults.Miner.GasPrice)
 		config.Miner.GasPrice = new(big.Int).Set(ethconfig.Defaults.Miner.GasPrice)
@@ -222,17 +228,19 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	}
 	var (
 		options = &core.BlockChainConfig{
-			TrieCleanLimit:   config.TrieCleanCache,
-			NoPrefetch:       config.NoPrefetch,
-			TrieDirtyLimit:   config.TrieDirtyCache,
-			ArchiveMode:      config.NoPruning,
-			TrieTimeLimit:    config.TrieTimeout,
-			SnapshotLimit:    config.SnapshotCache,
-			Preimages:        config.Preimages,
-			StateHistory:     config.StateHistory,
-			StateScheme:      scheme,
-			ChainHistoryMode: config.HistoryMode,
-			TxLookupLimit:    int64(min(config.TransactionHistory, math.MaxInt64)),
+			TrieCleanLimit:     config.TrieCleanCache,
+			NoPrefetch:         config.NoPrefetch,
+			TrieDirtyLimit:     config.TrieDirtyCache,
+			ArchiveMode:        config.NoPruning,
+			TrieTimeLimit:      config.TrieTimeout,
+			SnapshotLimit:      config.SnapshotCache,
+			Preimages:          config.Preimages,
+			StateHistory:       config.StateHistory,
+			ProofHistory:       config.ProofHistory,
+			StateScheme:        scheme,
+			ChainHistoryMode:   config.HistoryMode,
+			ChainHistoryWindow: config.HistoryWindow,
+			TxLookupLimit:      int64(min(config.TransactionHistory, math.MaxInt64)),
 			VmConfig: vm.Config{
 				EnablePreimageRecording: config.EnablePreimageRecording,
 			},
@@ -262,6 +270,9 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if config.OverrideVerkle != nil {
 		overrides.OverrideVerkle = config.OverrideVerkle
 	}
+	if config.OverridePostQuantum != nil {
+		overrides.OverridePostQuantum = config.OverridePostQuantum
+	}
 	options.Overrides = &overrides
 
 	eth.blockchain, err = core.NewBlockChain(chainDb, config.Genesis, eth.engine, options)
@@ -271,7 +282,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 
 	// Initialize filtermaps log index.
 	fmConfig := filtermaps.Config{
-		History:        config.LogHistory,
+		History:        logHistory(config),
 		Disabled:       config.LogNoHistory,
 		ExportFileName: config.LogExportCheckpoints,
 		HashScheme:     scheme == rawdb.HashScheme,
@@ -351,6 +362,13 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	stack.RegisterProtocols(eth.Protocols())
 	stack.RegisterLifecycle(eth)
 
+	// Serve the chain database to local processes if requested. Lifecycles are
+	// stopped in reverse order, so the server is stopped before the database is
+	// closed.
+	if config.DatabaseServe != "" {
+		stack.RegisterLifecycle(netdb.NewService(chainDb, config.DatabaseServe))
+	}
+
 	// Successful startup; push a marker and check previous unclean shutdowns.
 	eth.shutdownTracker.MarkStartup()
 
@@ -374,6 +392,19 @@ func makeExtraData(extra []byte) []byte {
 	return extra
 }
 
+// logHistory returns the number of recent blocks covered by the log index. In
+// the recent history mode the index is limited to the retained receipts, the
+// maps of pruned blocks are unindexed as the history cutoff advances.
+func logHistory(config *ethconfig.Config) uint64 {
+	if config.HistoryMode != history.KeepRecent {
+		return config.LogHistory
+	}
+	if config.LogHistory == 0 || config.LogHistory > config.HistoryWindow {
+		return config.HistoryWindow
+	}
+	return config.LogHistory
+}
+
 // APIs return the collection of RPC services the ethereum package offers.
 // NOTE, some of these services probably need to be moved to somewhere else.
 func (s *Ethereum) APIs() []rpc.API {
@@ -0,0 +1,35 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package eth
+
+import (
+	"testing"
+
+	"github.com/luxfi/geth/core/history"
+	"github.com/luxfi/geth/eth/ethconfig"
+)
+
+// Tests that the log index doesn't cover more blocks than the chain history
+// retained in the recent history mode.
+func TestLogHistory(t *testing.T) {
+	tests := []struct {
+		mode    history.HistoryMode
+		logs    uint64
+		window  uint64
+		expHist uint64
+	}{
+		{history.KeepAll, 2350000, 1000, 2350000},
+		{history.KeepAll, 0, 1000, 0},
+		{history.KeepPostMerge, 2350000, 1000, 2350000},
+		{history.KeepRecent, 2350000, 1000, 1000},
+		{history.KeepRecent, 0, 1000, 1000},
+		{history.KeepRecent, 500, 1000, 500},
+	}
+	for i, test := range tests {
+		config := &ethconfig.Config{HistoryMode: test.mode, LogHistory: test.logs, HistoryWindow: test.window}
+		if have := logHistory(config); have != test.expHist {
+			t.Errorf("test %d: wrong log history %d, want %d", i, have, test.expHist)
+		}
+	}
+}
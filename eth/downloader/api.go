@@ -85,6 +85,7 @@ func (api *DownloaderAPI) eventLoop() {
 			if err == nil {
 				prog.StateIndexRemaining = remain
 			}
+			prog.EarliestBlock, _ = api.chain.HistoryPruningCutoff()
 			return prog
 		}
 	)
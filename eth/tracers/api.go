@@ -1121,6 +1121,10 @@ func overrideConfig(original *params.ChainConfig, override *params.ChainConfig)
 		copy.VerkleTime = timestamp
 		canon = false
 	}
+	if timestamp := override.PostQuantumTime; timestamp != nil {
+		copy.PostQuantumTime = timestamp
+		canon = false
+	}
 
 	return copy, canon
 }
@@ -1,221 +1,941 @@
 // Copyright (C) 2025, Lux Industries, Inc. All rights reserved.
 // See the file LICENSE for licensing terms.
 
+// Package badgerdb implements the key-value database layer based on BadgerDB.
 package badgerdb
 
 import (
-	"io"
-
-	"github.com/luxfi/geth/ethdb"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/dgraph-io/badger/v4/options"
 	"github.com/luxfi/geth/common"
+	"github.com/luxfi/geth/ethdb"
+	"github.com/luxfi/geth/log"
+	"github.com/luxfi/geth/metrics"
 )
 
-// Database is a badgerdb implementation of ethdb.Database
-type Database struct {
-	db interface{}
-}
+const (
+	// minCache is the minimum amount of memory in megabytes to allocate to badger
+	// read and write caching.
+	minCache = 16
 
-// NewDatabase creates a new badgerdb database
-func NewDatabase(path string) (ethdb.Database, error) {
-	return &Database{}, nil
-}
+	// minMemTableSize is the minimum size of a badger memory table. Badger caps
+	// transactions at 15% of a memory table, which has to hold a value of the
+	// maximum size kept in the LSM tree.
+	minMemTableSize = 8 << 20
 
-// New creates a new badgerdb database with options
-func New(path string, cache int, handles int, namespace string, readonly bool) (ethdb.Database, error) {
-	return &Database{}, nil
-}
+	// memTableLimit is the number of memory tables badger keeps in memory,
+	// including the active one.
+	memTableLimit = 4
 
-// Has checks if key exists
-func (d *Database) Has(key []byte) (bool, error) {
-	return false, nil
-}
+	// deleteRangeChunk is the number of keys collected by a range deletion
+	// before they are deleted.
+	deleteRangeChunk = 10000
 
-// Get retrieves value for key
-func (d *Database) Get(key []byte) ([]byte, error) {
-	return nil, nil
-}
+	// gcDiscardRatio is the fraction of stale data that makes a value log
+	// file eligible for rewriting during compaction.
+	gcDiscardRatio = 0.5
 
-// Put stores value for key
-func (d *Database) Put(key []byte, value []byte) error {
-	return nil
-}
+	// metricsGatheringInterval specifies the interval to retrieve badger database
+	// level and cache stats to report to the user.
+	metricsGatheringInterval = 3 * time.Second
+)
 
-// Delete removes key
-func (d *Database) Delete(key []byte) error {
-	return nil
-}
+// errClosed is returned when operating on a closed database.
+var errClosed = badger.ErrDBClosed
 
-// NewBatch creates a new batch
+// versionKey holds the version of the last complete write. It is hidden from
+// iteration.
+var versionKey = []byte("\x00badgerdb-version")
+
+// Database is a persistent key-value store based on the BadgerDB storage engine.
+// Apart from basic data storage functionality it also supports batch writes,
+// snapshots and iterating over the keyspace in binary-alphabetical order.
+type Database struct {
+	fn string     // filename for reporting
+	db *badger.DB // Underlying badger storage engine
+
+	diskSizeGauge       *metrics.Gauge   // Gauge for tracking the size of the LSM tree and value log
+	lsmSizeGauge        *metrics.Gauge   // Gauge for tracking the size of the LSM tree
+	vlogSizeGauge       *metrics.Gauge   // Gauge for tracking the size of the value log
+	blockCacheHitGauge  *metrics.Gauge   // Gauge for tracking the number of total hit in the block cache
+	blockCacheMissGauge *metrics.Gauge   // Gauge for tracking the number of total miss in the block cache
+	levelsGauge         []*metrics.Gauge // Gauge for tracking the number of tables in levels
+
+	// Badger runs in managed mode: every write is committed at a new version,
+	// which is only published to readers once all its transactions succeeded.
+	// Writes too large for a single transaction are thus still atomic.
+	writeLock sync.Mutex     // Serializes writes
+	latest    uint64         // Highest version written, protected by writeLock
+	lock      sync.Mutex     // Protects version and readers
+	version   uint64         // Version of the last complete write
+	readers   map[uint64]int // Versions read by open transactions
+
+	quitLock sync.RWMutex    // Mutex protecting the quit channel and the closed flag
+	quitChan chan chan error // Quit channel to stop the metrics collection before closing the database
+	closed   bool            // keep track of whether we're Closed
+
+	log log.Logger // Contextual logger tracking the database path
+}
+
+// badgerLogger forwards badger's internal logs to the database logger. Badger
+// is chatty, so its informational messages are demoted to debug level.
+type badgerLogger struct {
+	log log.Logger
+}
+
+func (l badgerLogger) Errorf(format string, args ...interface{}) {
+	l.log.Error(strings.TrimSpace(fmt.Sprintf(format, args...)))
+}
+
+func (l badgerLogger) Warningf(format string, args ...interface{}) {
+	l.log.Warn(strings.TrimSpace(fmt.Sprintf(format, args...)))
+}
+
+func (l badgerLogger) Infof(format string, args ...interface{}) {
+	l.log.Debug(strings.TrimSpace(fmt.Sprintf(format, args...)))
+}
+
+func (l badgerLogger) Debugf(format string, args ...interface{}) {
+	l.log.Trace(strings.TrimSpace(fmt.Sprintf(format, args...)))
+}
+
+// New returns a wrapped badger DB object. The namespace is the prefix that the
+// metrics reporting should use for surfacing internal stats.
+//
+// Badger memory-maps its tables instead of keeping a bounded set of files
+// open, so the handles allowance is not used.
+func New(file string, cache int, handles int, namespace string, readonly bool) (*Database, error) {
+	// Ensure we have some minimal caching guarantees
+	if cache < minCache {
+		cache = minCache
+	}
+	logger := log.New("database", file)
+	logger.Info("Allocated cache", "cache", common.StorageSize(cache*1024*1024))
+
+	// Half of the allowance goes to the block cache, a quarter to the index
+	// and bloom filter cache and the rest to the memory tables.
+	memTableSize := int64(cache) * 1024 * 1024 / 4 / memTableLimit
+	if memTableSize < minMemTableSize {
+		memTableSize = minMemTableSize
+	}
+	opt := badger.DefaultOptions(file).
+		WithBlockCacheSize(int64(cache) * 1024 * 1024 / 2).
+		WithIndexCacheSize(int64(cache) * 1024 * 1024 / 4).
+		WithMemTableSize(memTableSize).
+		WithNumMemtables(memTableLimit).
+		WithNumCompactors(max(2, runtime.NumCPU()/2)).
+		WithCompression(options.Snappy).
+		WithReadOnly(readonly).
+		// Writes are serialized by the database, conflict detection would only
+		// slow down transactions.
+		WithDetectConflicts(false).
+		WithLogger(badgerLogger{logger})
+
+	innerDB, err := badger.OpenManaged(opt)
+	if err != nil {
+		return nil, err
+	}
+	db, err := newDatabase(innerDB, logger)
+	if err != nil {
+		innerDB.Close()
+		return nil, err
+	}
+	db.fn = file
+	db.quitChan = make(chan chan error)
+	db.diskSizeGauge = metrics.GetOrRegisterGauge(namespace+"disk/size", nil)
+	db.lsmSizeGauge = metrics.GetOrRegisterGauge(namespace+"disk/lsm", nil)
+	db.vlogSizeGauge = metrics.GetOrRegisterGauge(namespace+"disk/vlog", nil)
+	db.blockCacheHitGauge = metrics.GetOrRegisterGauge(namespace+"cache/block/hit", nil)
+	db.blockCacheMissGauge = metrics.GetOrRegisterGauge(namespace+"cache/block/miss", nil)
+
+	// Start up the metrics gathering and return
+	go db.meter(metricsGatheringInterval, namespace)
+	return db, nil
+}
+
+// newDatabase wraps a badger database opened in managed mode and rolls back
+// the remains of a write interrupted by a crash.
+func newDatabase(innerDB *badger.DB, logger log.Logger) (*Database, error) {
+	d := &Database{
+		db:      innerDB,
+		log:     logger,
+		latest:  innerDB.MaxVersion(),
+		readers: make(map[uint64]int),
+	}
+	// Databases without a version marker were written outside of managed mode,
+	// all their writes are complete.
+	d.version = d.latest
+	txn := innerDB.NewTransactionAt(math.MaxUint64, false)
+	enc, err := get(txn, versionKey)
+	txn.Discard()
+	switch {
+	case errors.Is(err, badger.ErrKeyNotFound):
+	case err != nil:
+		return nil, err
+	case len(enc) != 8:
+		return nil, fmt.Errorf("invalid version marker %x", enc)
+	default:
+		d.version = binary.BigEndian.Uint64(enc)
+	}
+	if d.latest > d.version && !innerDB.Opts().ReadOnly {
+		d.log.Warn("Rolling back incomplete write", "version", d.version, "latest", d.latest)
+		d.writeLock.Lock()
+		defer d.writeLock.Unlock()
+		if err := d.rollback(); err != nil {
+			return nil, err
+		}
+	}
+	innerDB.SetDiscardTs(d.version)
+	return d, nil
+}
+
+// beginRead opens a read-only transaction at the last complete version. It
+// must be ended by endRead.
+func (d *Database) beginRead() *badger.Txn {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.readers[d.version]++
+	return d.db.NewTransactionAt(d.version, false)
+}
+
+// endRead discards a transaction opened by beginRead.
+func (d *Database) endRead(txn *badger.Txn) {
+	txn.Discard()
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	version := txn.ReadTs()
+	if d.readers[version]--; d.readers[version] == 0 {
+		delete(d.readers, version)
+	}
+}
+
+// publish makes a complete write visible to readers, and allows badger to
+// discard the versions no open transaction reads anymore.
+func (d *Database) publish(version uint64) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.version = version
+	discard := version
+	for v := range d.readers {
+		discard = min(discard, v)
+	}
+	d.db.SetDiscardTs(discard)
+}
+
+// rollback reverts the keys written by versions above the last complete one,
+// restoring their value at that version. It must be called with writeLock held.
+func (d *Database) rollback() error {
+	read := d.db.NewTransactionAt(d.version, false)
+	defer read.Discard()
+	scan := d.db.NewTransactionAt(math.MaxUint64, false)
+	defer scan.Discard()
+
+	// Collect the keys first, as the iterator only covers the versions written
+	// before the rollback.
+	var keys [][]byte
+	it := scan.NewIterator(badger.IteratorOptions{AllVersions: true, SinceTs: d.version})
+	for it.Rewind(); it.Valid(); it.Next() {
+		key := it.Item().Key()
+		if bytes.Equal(key, versionKey) || (len(keys) > 0 && bytes.Equal(key, keys[len(keys)-1])) {
+			continue
+		}
+		keys = append(keys, it.Item().KeyCopy(nil))
+	}
+	it.Close()
+
+	w := d.newWriter()
+	defer w.discard()
+	for _, key := range keys {
+		val, err := get(read, key)
+		switch {
+		case errors.Is(err, badger.ErrKeyNotFound):
+			err = w.delete(key)
+		case err == nil:
+			err = w.set(key, val)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return w.commit()
+}
+
+// Close stops the metrics collection, flushes any pending data to disk and closes
+// all io accesses to the underlying key-value store.
+func (d *Database) Close() error {
+	d.quitLock.Lock()
+	defer d.quitLock.Unlock()
+	// Allow double closing, simplifies things
+	if d.closed {
+		return nil
+	}
+	d.closed = true
+	if d.quitChan != nil {
+		errc := make(chan error)
+		d.quitChan <- errc
+		if err := <-errc; err != nil {
+			d.log.Error("Metrics collection failed", "err", err)
+		}
+		d.quitChan = nil
+	}
+	return d.db.Close()
+}
+
+// Has retrieves if a key is present in the key-value store.
+func (d *Database) Has(key []byte) (bool, error) {
+	d.quitLock.RLock()
+	defer d.quitLock.RUnlock()
+	if d.closed {
+		return false, errClosed
+	}
+	txn := d.beginRead()
+	defer d.endRead(txn)
+	_, err := txn.Get(key)
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Get retrieves the given key if it's present in the key-value store.
+func (d *Database) Get(key []byte) ([]byte, error) {
+	d.quitLock.RLock()
+	defer d.quitLock.RUnlock()
+	if d.closed {
+		return nil, errClosed
+	}
+	txn := d.beginRead()
+	defer d.endRead(txn)
+	return get(txn, key)
+}
+
+// get retrieves a copy of the value of key from the transaction.
+func get(txn *badger.Txn, key []byte) ([]byte, error) {
+	item, err := txn.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	var ret []byte
+	err = item.Value(func(val []byte) error {
+		ret = common.CopyBytes(val)
+		return nil
+	})
+	return ret, err
+}
+
+// Put inserts the given value into the key-value store.
+func (d *Database) Put(key []byte, value []byte) error {
+	d.quitLock.RLock()
+	defer d.quitLock.RUnlock()
+	if d.closed {
+		return errClosed
+	}
+	d.writeLock.Lock()
+	defer d.writeLock.Unlock()
+	w := d.newWriter()
+	defer w.discard()
+	if err := w.set(common.CopyBytes(key), common.CopyBytes(value)); err != nil {
+		return err
+	}
+	return w.commit()
+}
+
+// Delete removes the key from the key-value store.
+func (d *Database) Delete(key []byte) error {
+	d.quitLock.RLock()
+	defer d.quitLock.RUnlock()
+	if d.closed {
+		return errClosed
+	}
+	d.writeLock.Lock()
+	defer d.writeLock.Unlock()
+	w := d.newWriter()
+	defer w.discard()
+	if err := w.delete(common.CopyBytes(key)); err != nil {
+		return err
+	}
+	return w.commit()
+}
+
+// DeleteRange deletes all of the keys (and values) in the range [start,end)
+// (inclusive on start, exclusive on end).
+//
+// Badger has no range tombstones, so the keys are deleted one by one. The range
+// is still deleted atomically.
+func (d *Database) DeleteRange(start, end []byte) error {
+	d.quitLock.RLock()
+	defer d.quitLock.RUnlock()
+	if d.closed {
+		return errClosed
+	}
+	d.writeLock.Lock()
+	defer d.writeLock.Unlock()
+	w := d.newWriter()
+	defer w.discard()
+	if err := w.deleteRange(start, end); err != nil {
+		return err
+	}
+	return w.commit()
+}
+
+// NewBatch creates a write-only key-value store that buffers changes to its host
+// database until a final write is called.
 func (d *Database) NewBatch() ethdb.Batch {
-	return &batch{}
+	return &batch{db: d}
 }
 
-// NewBatchWithSize creates a new batch with size hint
+// NewBatchWithSize creates a write-only database batch with pre-allocated buffer.
 func (d *Database) NewBatchWithSize(size int) ethdb.Batch {
-	return &batch{}
-}
-
-// NewIterator creates a new iterator
-func (d *Database) NewIterator(prefix []byte, start []byte) ethdb.Iterator {
-	return &iterator{}
+	return &batch{db: d}
 }
 
-// Stat returns database statistics
+// Stat returns the level and cache statistics of badger in a text format.
 func (d *Database) Stat() (string, error) {
-	return "", nil
-}
-
-// Compact compacts the database
+	d.quitLock.RLock()
+	defer d.quitLock.RUnlock()
+	if d.closed {
+		return "", errClosed
+	}
+	lsm, vlog := d.db.Size()
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "LSM size: %v, value log size: %v\n", common.StorageSize(lsm), common.StorageSize(vlog))
+	buf.WriteString(d.db.LevelsToString())
+	if m := d.db.BlockCacheMetrics(); m != nil {
+		fmt.Fprintf(&buf, "Block cache: %d hits, %d misses\n", m.Hits(), m.Misses())
+	}
+	if m := d.db.IndexCacheMetrics(); m != nil {
+		fmt.Fprintf(&buf, "Index cache: %d hits, %d misses\n", m.Hits(), m.Misses())
+	}
+	return buf.String(), nil
+}
+
+// Compact flattens the LSM tree into a single level and rewrites the value log
+// files with enough stale data to be worth it.
+//
+// Badger cannot compact a key range, so the whole data store is compacted
+// regardless of start and limit.
 func (d *Database) Compact(start []byte, limit []byte) error {
-	return nil
-}
-
-// NewSnapshot creates a snapshot  
-type Snapshot interface {
-	Has(key []byte) (bool, error)
-	Get(key []byte) ([]byte, error)
-	Release()
-}
-
-// NewSnapshot creates a snapshot
-func (d *Database) NewSnapshot() (Snapshot, error) {
-	return &snapshot{}, nil
-}
-
-// Close closes the database
-func (d *Database) Close() error {
-	return nil
-}
-
-// DeleteRange deletes all keys in the given range
-func (d *Database) DeleteRange(start, end []byte) error {
-	return nil
-}
-
-// AncientDatadir returns ancient datadir path
-func (d *Database) AncientDatadir() (string, error) {
-	return "", nil
-}
-
-// batch implements ethdb.Batch
-type batch struct{}
-
-func (b *batch) Put(key []byte, value []byte) error { return nil }
-func (b *batch) Delete(key []byte) error { return nil }
-func (b *batch) DeleteRange(start, end []byte) error { return nil }
-func (b *batch) ValueSize() int { return 0 }
-func (b *batch) Write() error { return nil }
-func (b *batch) Reset() {}
-func (b *batch) Replay(w ethdb.KeyValueWriter) error { return nil }
-
-// iterator implements ethdb.Iterator
-type iterator struct{}
-
-func (i *iterator) Next() bool { return false }
-func (i *iterator) Error() error { return nil }
-func (i *iterator) Key() []byte { return nil }
-func (i *iterator) Value() []byte { return nil }
-func (i *iterator) Release() {}
-
-// snapshot implements Snapshot
-type snapshot struct{}
-
-func (s *snapshot) Has(key []byte) (bool, error) { return false, nil }
-func (s *snapshot) Get(key []byte) ([]byte, error) { return nil, nil }
-func (s *snapshot) Release() {}
-
-// Implement AncientStore interface methods
-func (d *Database) HasAncient(kind string, number uint64) (bool, error) {
-	return false, nil
-}
-
-func (d *Database) Ancient(kind string, number uint64) ([]byte, error) {
-	return nil, nil
-}
-
-func (d *Database) AncientRange(kind string, start, count, maxBytes uint64) ([][]byte, error) {
-	return nil, nil
-}
-
-func (d *Database) Ancients() (uint64, error) {
-	return 0, nil
-}
-
-func (d *Database) Tail() (uint64, error) {
-	return 0, nil
-}
-
-func (d *Database) AncientSize(kind string) (uint64, error) {
-	return 0, nil
-}
-
-func (d *Database) ModifyAncients(fn func(ethdb.AncientWriteOp) error) (int64, error) {
-	return 0, nil
-}
-
-func (d *Database) TruncateHead(n uint64) (uint64, error) {
-	return 0, nil
-}
-
-func (d *Database) TruncateTail(n uint64) (uint64, error) {
-	return 0, nil
-}
-
-func (d *Database) Sync() error {
-	return nil
-}
-
-func (d *Database) SyncAncient() error {
-	return nil
-}
-
+	d.quitLock.RLock()
+	defer d.quitLock.RUnlock()
+	if d.closed {
+		return errClosed
+	}
+	if err := d.db.Flatten(runtime.NumCPU()); err != nil {
+		return err
+	}
+	for {
+		err := d.db.RunValueLogGC(gcDiscardRatio)
+		switch {
+		case err == nil:
+			continue
+		case errors.Is(err, badger.ErrNoRewrite), errors.Is(err, badger.ErrGCInMemoryMode):
+			return nil
+		default:
+			return err
+		}
+	}
+}
+
+// Path returns the path to the database directory.
+func (d *Database) Path() string {
+	return d.fn
+}
+
+// SyncKeyValue flushes all pending writes in the write-ahead-log to disk,
+// ensuring data durability up to that point.
+//
+// Writes are not synced individually, matching the other backends. Badger's
+// Sync fsyncs both the write-ahead-log of the active memory table and the
+// value log, older memory tables are synced when they are flushed.
 func (d *Database) SyncKeyValue() error {
+	d.quitLock.RLock()
+	defer d.quitLock.RUnlock()
+	if d.closed {
+		return errClosed
+	}
+	d.writeLock.Lock()
+	defer d.writeLock.Unlock()
+	return d.db.Sync()
+}
+
+// Checkpoint creates a consistent copy of the database in dir, which must not
+// exist yet, by copying the content of a snapshot into a fresh database.
+func (d *Database) Checkpoint(dir string) error {
+	if common.FileExist(dir) {
+		return fmt.Errorf("checkpoint directory %s already exists", dir)
+	}
+	snap, err := d.newSnapshot()
+	if err != nil {
+		return err
+	}
+	defer snap.Release()
+
+	// The checkpoint only needs to be written once, keep the default caches.
+	cp, err := badger.Open(badger.DefaultOptions(dir).
+		WithCompression(options.Snappy).
+		WithDetectConflicts(false).
+		WithLogger(d.db.Opts().Logger))
+	if err != nil {
+		return err
+	}
+	if err := copySnapshot(snap, cp); err != nil {
+		cp.Close()
+		return err
+	}
+	if err := cp.Sync(); err != nil {
+		cp.Close()
+		return err
+	}
+	return cp.Close()
+}
+
+// copySnapshot writes the content of a snapshot into dst.
+func copySnapshot(snap *Snapshot, dst *badger.DB) error {
+	wb := dst.NewWriteBatch()
+	it := snap.NewIterator(nil, nil)
+	defer it.Release()
+
+	for it.Next() {
+		if err := wb.Set(common.CopyBytes(it.Key()), it.Value()); err != nil {
+			wb.Cancel()
+			return err
+		}
+	}
+	if err := it.Error(); err != nil {
+		wb.Cancel()
+		return err
+	}
+	return wb.Flush()
+}
+
+// meter periodically retrieves internal badger counters and reports them to
+// the metrics subsystem.
+func (d *Database) meter(refresh time.Duration, namespace string) {
+	var errc chan error
+	timer := time.NewTimer(refresh)
+	defer timer.Stop()
+
+	// Iterate ad infinitum and collect the stats
+	for errc == nil {
+		lsm, vlog := d.db.Size()
+		d.diskSizeGauge.Update(lsm + vlog)
+		d.lsmSizeGauge.Update(lsm)
+		d.vlogSizeGauge.Update(vlog)
+		if m := d.db.BlockCacheMetrics(); m != nil {
+			d.blockCacheHitGauge.Update(int64(m.Hits()))
+			d.blockCacheMissGauge.Update(int64(m.Misses()))
+		}
+		for i, level := range d.db.Levels() {
+			// Append metrics for additional layers
+			if i >= len(d.levelsGauge) {
+				d.levelsGauge = append(d.levelsGauge, metrics.GetOrRegisterGauge(namespace+fmt.Sprintf("tables/level%v", i), nil))
+			}
+			d.levelsGauge[i].Update(int64(level.NumTables))
+		}
+
+		// Sleep a bit, then repeat the stats collection
+		select {
+		case errc = <-d.quitChan:
+			// Quit requesting, stop hammering the database
+		case <-timer.C:
+			timer.Reset(refresh)
+			// Timeout, gather a new set of stats
+		}
+	}
+	errc <- nil
+}
+
+// writer applies a sequence of writes as a single version. The writes are
+// committed in as few transactions as possible, the pending transaction is
+// committed whenever it grows too big. Readers only see the writes once all
+// transactions are committed, and a crash in between is rolled back when the
+// database is opened again.
+//
+// A writer must be used with writeLock held.
+type writer struct {
+	d       *Database
+	version uint64
+	txn     *badger.Txn
+	done    bool // whether the writes were committed or rolled back
+	flushed bool // whether a transaction was committed before the last one
+}
+
+func (d *Database) newWriter() *writer {
+	d.latest++
+	return &writer{d: d, version: d.latest, txn: d.db.NewTransactionAt(d.latest, true)}
+}
+
+// apply runs op on the pending transaction, retrying it in a new one if the
+// pending transaction is full.
+func (w *writer) apply(op func(txn *badger.Txn) error) error {
+	err := op(w.txn)
+	if !errors.Is(err, badger.ErrTxnTooBig) {
+		return err
+	}
+	if err := w.flush(); err != nil {
+		return err
+	}
+	return op(w.txn)
+}
+
+func (w *writer) set(key, value []byte) error {
+	return w.apply(func(txn *badger.Txn) error { return txn.Set(key, value) })
+}
+
+func (w *writer) delete(key []byte) error {
+	return w.apply(func(txn *badger.Txn) error { return txn.Delete(key) })
+}
+
+// deleteRange deletes the keys in [start, end). A nil end is treated as a key
+// after all keys in the data store.
+func (w *writer) deleteRange(start, end []byte) error {
+	if end != nil && bytes.Compare(start, end) >= 0 {
+		return nil
+	}
+	for {
+		// Collect a chunk of keys first, the transaction cannot be committed
+		// while an iterator is open. The transaction reads at the version being
+		// written, so the keys deleted in earlier transactions are skipped.
+		keys := make([][]byte, 0, deleteRangeChunk)
+		it := w.txn.NewIterator(badger.IteratorOptions{})
+		for it.Seek(start); it.Valid() && len(keys) < deleteRangeChunk; it.Next() {
+			key := it.Item().KeyCopy(nil)
+			if end != nil && bytes.Compare(key, end) >= 0 {
+				break
+			}
+			if !bytes.Equal(key, versionKey) {
+				keys = append(keys, key)
+			}
+		}
+		it.Close()
+
+		for _, key := range keys {
+			if err := w.delete(key); err != nil {
+				return err
+			}
+		}
+		if len(keys) < deleteRangeChunk {
+			return nil
+		}
+		start = append(keys[len(keys)-1], 0)
+	}
+}
+
+// flush commits the pending transaction and opens a new one at the same
+// version.
+func (w *writer) flush() error {
+	err := w.txn.CommitAt(w.version, nil)
+	w.txn = w.d.db.NewTransactionAt(w.version, true)
+	w.flushed = true
+	return err
+}
+
+// commit commits the pending transaction along with the version marker, and
+// publishes the writes to readers.
+func (w *writer) commit() error {
+	var enc [8]byte
+	binary.BigEndian.PutUint64(enc[:], w.version)
+	if err := w.apply(func(txn *badger.Txn) error { return txn.Set(versionKey, enc[:]) }); err != nil {
+		return err
+	}
+	if err := w.txn.CommitAt(w.version, nil); err != nil {
+		return err
+	}
+	w.done = true
+	w.d.publish(w.version)
 	return nil
 }
 
-func (d *Database) MigrateTable(string, func([]byte) ([]byte, error)) error {
+// discard discards the pending transaction. If transactions were committed
+// already, their writes are rolled back.
+func (w *writer) discard() {
+	w.txn.Discard()
+	if w.done || !w.flushed {
+		return
+	}
+	w.done = true
+	if err := w.d.rollback(); err != nil {
+		w.d.log.Error("Failed to roll back incomplete write", "version", w.version, "err", err)
+	}
+}
+
+// keyvalue is a single write queued up in a batch.
+type keyvalue struct {
+	key    []byte
+	value  []byte
+	delete bool
+
+	ranged    bool // whether the entry deletes the range [rangeFrom, rangeTo)
+	rangeFrom []byte
+	rangeTo   []byte
+}
+
+// batch is a write-only batch that commits changes to its host database
+// when Write is called. A batch cannot be used concurrently.
+//
+// A batch is written atomically, even if it doesn't fit into a single badger
+// transaction.
+type batch struct {
+	db     *Database
+	writes []keyvalue
+	size   int
+}
+
+// Put inserts the given value into the batch for later committing.
+func (b *batch) Put(key, value []byte) error {
+	b.writes = append(b.writes, keyvalue{key: common.CopyBytes(key), value: common.CopyBytes(value)})
+	b.size += len(key) + len(value)
 	return nil
 }
 
-func (d *Database) AncientOffSet() uint64 {
-	return 0
-}
-
-func (d *Database) ReadAncients(fn func(ethdb.AncientReaderOp) error) (err error) {
+// Delete inserts the key removal into the batch for later committing.
+func (b *batch) Delete(key []byte) error {
+	b.writes = append(b.writes, keyvalue{key: common.CopyBytes(key), delete: true})
+	b.size += len(key)
 	return nil
 }
 
-func (d *Database) ItemAmountInAncient() (uint64, error) {
-	return 0, nil
-}
-
-func (d *Database) AncientBlob(kind string, number uint64, maxBytes uint64) ([]byte, error) {
-	return nil, nil
-}
-
-// Implement the io.Closer interface
-func (d *Database) CloseAncient() error {
-	return nil
-}
-
-// Implement additional AncientStore methods that might be needed
-func (d *Database) AppendAncient(number uint64, hash, header, body, receipt, td []byte) error {
+// DeleteRange removes all keys in the range [start, end) from the batch for
+// later committing, inclusive on start, exclusive on end.
+func (b *batch) DeleteRange(start, end []byte) error {
+	b.writes = append(b.writes, keyvalue{
+		ranged:    true,
+		rangeFrom: bytes.Clone(start),
+		rangeTo:   bytes.Clone(end),
+		delete:    true,
+	})
+	b.size += len(start) + len(end)
 	return nil
 }
 
-func (d *Database) TruncateAncients(n uint64) error {
+// ValueSize retrieves the amount of data queued up for writing.
+func (b *batch) ValueSize() int {
+	return b.size
+}
+
+// Write flushes any accumulated data to disk.
+func (b *batch) Write() error {
+	b.db.quitLock.RLock()
+	defer b.db.quitLock.RUnlock()
+	if b.db.closed {
+		return errClosed
+	}
+	if len(b.writes) == 0 {
+		return nil
+	}
+	b.db.writeLock.Lock()
+	defer b.db.writeLock.Unlock()
+	w := b.db.newWriter()
+	defer w.discard()
+	for _, entry := range b.writes {
+		var err error
+		switch {
+		case !entry.delete:
+			err = w.set(entry.key, entry.value)
+		case entry.ranged:
+			err = w.deleteRange(entry.rangeFrom, entry.rangeTo)
+		default:
+			err = w.delete(entry.key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return w.commit()
+}
+
+// Reset resets the batch for reuse.
+func (b *batch) Reset() {
+	b.writes = b.writes[:0]
+	b.size = 0
+}
+
+// Replay replays the batch contents.
+func (b *batch) Replay(w ethdb.KeyValueWriter) error {
+	for _, entry := range b.writes {
+		if !entry.delete {
+			if err := w.Put(entry.key, entry.value); err != nil {
+				return err
+			}
+			continue
+		}
+		if !entry.ranged {
+			if err := w.Delete(entry.key); err != nil {
+				return err
+			}
+			continue
+		}
+		rangeDeleter, ok := w.(ethdb.KeyValueRangeDeleter)
+		if !ok {
+			return errors.New("ethdb.KeyValueWriter does not implement DeleteRange")
+		}
+		if err := rangeDeleter.DeleteRange(entry.rangeFrom, entry.rangeTo); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func (d *Database) AncientBlobReader(kind string, number uint64) (io.ReadSeeker, error) {
-	return nil, nil
-}
-
-// Implement AncientWriter interface methods
-type AncientWriter interface {
-	AppendAncient(number uint64, hash, header, body, receipts, td []byte) error
+// Snapshot is a read-only view of the database at the time it was taken.
+type Snapshot struct {
+	db       *Database
+	txn      *badger.Txn
+	released bool
+}
+
+// NewSnapshot creates a database snapshot based on the current state. The
+// snapshot must be released after use.
+func (d *Database) NewSnapshot() (ethdb.Snapshot, error) {
+	snap, err := d.newSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// newSnapshot creates a database snapshot based on the current state.
+func (d *Database) newSnapshot() (*Snapshot, error) {
+	d.quitLock.RLock()
+	defer d.quitLock.RUnlock()
+	if d.closed {
+		return nil, errClosed
+	}
+	return &Snapshot{db: d, txn: d.beginRead()}, nil
+}
+
+// Has retrieves if a key is present in the snapshot.
+func (s *Snapshot) Has(key []byte) (bool, error) {
+	_, err := s.txn.Get(key)
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Get retrieves the given key if it's present in the snapshot.
+func (s *Snapshot) Get(key []byte) ([]byte, error) {
+	return get(s.txn, key)
+}
+
+// NewIterator creates a binary-alphabetical iterator over a subset of the
+// snapshot content with a particular key prefix, starting at a particular
+// initial key (or after, if it does not exist). The iterator must be released
+// before the snapshot.
+func (s *Snapshot) NewIterator(prefix []byte, start []byte) ethdb.Iterator {
+	return newIterator(s.txn, prefix, start, nil)
+}
+
+// Release releases associated resources. Release should always succeed and can
+// be called multiple times without causing error.
+func (s *Snapshot) Release() {
+	if !s.released {
+		s.db.endRead(s.txn)
+		s.released = true
+	}
+}
+
+// badgerIterator is a wrapper of underlying iterator in storage engine.
+// The purpose of this structure is to implement the missing APIs.
+//
+// The badger iterator is not thread-safe.
+type badgerIterator struct {
+	txn      *badger.Txn
+	iter     *badger.Iterator
+	release  func() // ends the transaction along with the iterator, if owned
+	moved    bool
+	released bool
+
+	value []byte
+	err   error
+}
+
+// NewIterator creates a binary-alphabetical iterator over a subset
+// of database content with a particular key prefix, starting at a particular
+// initial key (or after, if it does not exist).
+func (d *Database) NewIterator(prefix []byte, start []byte) ethdb.Iterator {
+	txn := d.beginRead()
+	return newIterator(txn, prefix, start, func() { d.endRead(txn) })
+}
+
+func newIterator(txn *badger.Txn, prefix []byte, start []byte, release func()) *badgerIterator {
+	opts := badger.DefaultIteratorOptions
+	opts.Prefix = common.CopyBytes(prefix)
+
+	iter := txn.NewIterator(opts)
+	iter.Seek(append(common.CopyBytes(prefix), start...))
+	return &badgerIterator{txn: txn, iter: iter, release: release, moved: true}
+}
+
+// Next moves the iterator to the next key/value pair. It returns whether the
+// iterator is exhausted.
+func (iter *badgerIterator) Next() bool {
+	if iter.released || iter.err != nil {
+		return false
+	}
+	if iter.moved {
+		iter.moved = false
+	} else if iter.iter.Valid() {
+		iter.iter.Next()
+	}
+	// Skip the version marker of the database.
+	if iter.iter.Valid() && bytes.Equal(iter.iter.Item().Key(), versionKey) {
+		iter.iter.Next()
+	}
+	if !iter.iter.Valid() {
+		iter.value = nil
+		return false
+	}
+	iter.value, iter.err = iter.iter.Item().ValueCopy(nil)
+	return iter.err == nil
+}
+
+// Error returns any accumulated error. Exhausting all the key/value pairs
+// is not considered to be an error.
+func (iter *badgerIterator) Error() error {
+	return iter.err
+}
+
+// Key returns the key of the current key/value pair, or nil if done. The caller
+// should not modify the contents of the returned slice, and its contents may
+// change on the next call to Next.
+func (iter *badgerIterator) Key() []byte {
+	if iter.released || iter.moved || !iter.iter.Valid() {
+		return nil
+	}
+	return iter.iter.Item().Key()
+}
+
+// Value returns the value of the current key/value pair, or nil if done. The
+// caller should not modify the contents of the returned slice, and its contents
+// may change on the next call to Next.
+func (iter *badgerIterator) Value() []byte {
+	return iter.value
+}
+
+// Release releases associated resources. Release should always succeed and can
+// be called multiple times without causing error.
+func (iter *badgerIterator) Release() {
+	if !iter.released {
+		iter.iter.Close()
+		if iter.release != nil {
+			iter.release()
+		}
+		iter.released = true
+	}
 }
-
-func (d *Database) Update(number uint64, hash common.Hash, header []byte, body []byte, receipts []byte, td []byte) error {
-	return nil
-}
\ No newline at end of file
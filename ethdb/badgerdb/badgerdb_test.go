@@ -0,0 +1,210 @@
+// Copyright (C) 2025, Lux Industries, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package badgerdb
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/luxfi/geth/ethdb"
+	"github.com/luxfi/geth/ethdb/dbtest"
+	"github.com/luxfi/geth/log"
+)
+
+func newInMemory(tb testing.TB) *Database {
+	db, err := badger.OpenManaged(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	d, err := newDatabase(db, log.Root())
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return d
+}
+
+func TestBadgerDB(t *testing.T) {
+	t.Run("DatabaseSuite", func(t *testing.T) {
+		dbtest.TestDatabaseSuite(t, func() ethdb.KeyValueStore {
+			return newInMemory(t)
+		})
+	})
+}
+
+func BenchmarkBadgerDB(b *testing.B) {
+	dbtest.BenchDatabaseSuite(b, func() ethdb.KeyValueStore {
+		return newInMemory(b)
+	})
+}
+
+func TestBadgerSnapshot(t *testing.T) {
+	db := newInMemory(t)
+	defer db.Close()
+
+	db.Put([]byte("a"), []byte("1"))
+	db.Put([]byte("b"), []byte("2"))
+
+	snap, err := db.NewSnapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer snap.Release()
+
+	db.Put([]byte("a"), []byte("3"))
+	db.Delete([]byte("b"))
+	db.Put([]byte("c"), []byte("4"))
+
+	if val, err := snap.Get([]byte("a")); err != nil || !bytes.Equal(val, []byte("1")) {
+		t.Fatalf("wrong snapshot value: %q, %v", val, err)
+	}
+	if has, err := snap.Has([]byte("b")); err != nil || !has {
+		t.Fatalf("deleted key missing from snapshot: %v", err)
+	}
+	if has, err := snap.Has([]byte("c")); err != nil || has {
+		t.Fatalf("new key present in snapshot: %v", err)
+	}
+	it := snap.NewIterator(nil, nil)
+	var keys []string
+	for it.Next() {
+		keys = append(keys, string(it.Key()))
+	}
+	it.Release()
+	if len(keys) != 2 || keys[0] != "a" || keys[1] != "b" {
+		t.Fatalf("wrong snapshot keys: %v", keys)
+	}
+}
+
+func TestBadgerLargeBatch(t *testing.T) {
+	db, err := New(t.TempDir(), 16, 16, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// Write a batch too large for a single transaction, then delete most of it
+	// in a range spanning several transactions as well.
+	var (
+		b     = db.NewBatch()
+		value = make([]byte, 256)
+		n     = 50000
+	)
+	for i := 0; i < n; i++ {
+		b.Put([]byte{byte(i >> 16), byte(i >> 8), byte(i)}, value)
+	}
+	if err := b.Write(); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.DeleteRange([]byte{0, 0, 1}, nil); err != nil {
+		t.Fatal(err)
+	}
+	it := db.NewIterator(nil, nil)
+	count := 0
+	for it.Next() {
+		count++
+	}
+	it.Release()
+	if count != 1 {
+		t.Fatalf("wrong number of keys left: have %d, want 1", count)
+	}
+	if err := db.Compact(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Stat(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestBadgerInterruptedWrite checks that a write split over several transactions
+// is rolled back if the database is closed before it completes.
+func TestBadgerInterruptedWrite(t *testing.T) {
+	dir := t.TempDir()
+	db, err := New(dir, 16, 16, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Put([]byte{0, 0, 0}, []byte("old"))
+
+	// Commit parts of a write too large for a single transaction, then close
+	// the database as if the process crashed.
+	db.writeLock.Lock()
+	w := db.newWriter()
+	value := make([]byte, 256)
+	for i := 0; i < 50000; i++ {
+		if err := w.set([]byte{byte(i >> 16), byte(i >> 8), byte(i)}, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !w.flushed {
+		t.Fatal("write fits into a single transaction")
+	}
+	// The committed parts are not visible yet.
+	if val, err := db.Get([]byte{0, 0, 0}); err != nil || string(val) != "old" {
+		t.Fatalf("wrong value during write: %q, %v", val, err)
+	}
+	if has, _ := db.Has([]byte{0, 0, 1}); has {
+		t.Fatal("key of incomplete write visible")
+	}
+	w.txn.Discard()
+	db.writeLock.Unlock()
+	db.Close()
+
+	db, err = New(dir, 16, 16, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if val, err := db.Get([]byte{0, 0, 0}); err != nil || string(val) != "old" {
+		t.Fatalf("wrong value after rollback: %q, %v", val, err)
+	}
+	it := db.NewIterator(nil, nil)
+	count := 0
+	for it.Next() {
+		count++
+	}
+	it.Release()
+	if count != 1 {
+		t.Fatalf("wrong number of keys after rollback: have %d, want 1", count)
+	}
+	// Writes continue after the rolled back version.
+	if err := db.Put([]byte{0, 0, 1}, []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+	if val, err := db.Get([]byte{0, 0, 1}); err != nil || string(val) != "new" {
+		t.Fatalf("wrong value after rollback: %q, %v", val, err)
+	}
+	if has, _ := db.Has([]byte{0, 0, 2}); has {
+		t.Fatal("key of rolled back write visible")
+	}
+}
+
+func TestBadgerCheckpoint(t *testing.T) {
+	db := newInMemory(t)
+	defer db.Close()
+
+	for i := 0; i < 1000; i++ {
+		db.Put([]byte(fmt.Sprintf("key-%04d", i)), bytes.Repeat([]byte{byte(i)}, 64))
+	}
+	dir := filepath.Join(t.TempDir(), "checkpoint")
+	if err := db.Checkpoint(dir); err != nil {
+		t.Fatalf("checkpoint failed: %v", err)
+	}
+	if err := db.Checkpoint(dir); err == nil {
+		t.Fatal("checkpoint into existing directory succeeded")
+	}
+	cp, err := New(dir, 16, 16, "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cp.Close()
+
+	for i := 0; i < 1000; i++ {
+		val, err := cp.Get([]byte(fmt.Sprintf("key-%04d", i)))
+		if err != nil || !bytes.Equal(val, bytes.Repeat([]byte{byte(i)}, 64)) {
+			t.Fatalf("key %d: wrong value %x, %v", i, val, err)
+		}
+	}
+}
@@ -94,6 +94,34 @@ type Compacter interface {
 	Compact(start []byte, limit []byte) error
 }
 
+// Checkpointer wraps the Checkpoint method of a backing data store.
+type Checkpointer interface {
+	// Checkpoint creates a consistent copy of the data store in the given
+	// directory, which must not exist yet. The data store may be written to
+	// while the checkpoint is taken; the copy reflects its content at a single
+	// point in time.
+	Checkpoint(dir string) error
+}
+
+// Snapshot is a read-only view of a key-value data store at the time it was
+// taken, unaffected by any later writes.
+type Snapshot interface {
+	KeyValueReader
+	Iteratee
+
+	// Release releases associated resources. Release should always succeed and
+	// can be called multiple times without causing error.
+	Release()
+}
+
+// Snapshotter wraps the NewSnapshot method of a backing data store.
+type Snapshotter interface {
+	// NewSnapshot creates a database snapshot based on the current state.
+	// The created snapshot will not be affected by all following mutations
+	// happened on the database.
+	NewSnapshot() (Snapshot, error)
+}
+
 // KeyValueStore contains all the methods required to allow handling different
 // key-value data stores backing the high level database.
 type KeyValueStore interface {
@@ -704,6 +704,50 @@ func TestDatabaseSuite(t *testing.T, New func() ethdb.KeyValueStore) {
 			t.Fatalf("key 3 should still be deleted from previous operation")
 		}
 	})
+
+	t.Run("Snapshot", func(t *testing.T) {
+		db := New()
+		defer db.Close()
+
+		snapshotter, ok := db.(ethdb.Snapshotter)
+		if !ok {
+			t.Skip("snapshots not supported")
+		}
+		for _, k := range []string{"1", "2", "3"} {
+			if err := db.Put([]byte(k), []byte("val-"+k)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		snap, err := snapshotter.NewSnapshot()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer snap.Release()
+
+		// Mutations after the snapshot was taken are not visible
+		db.Put([]byte("1"), []byte("changed"))
+		db.Delete([]byte("2"))
+		db.Put([]byte("4"), []byte("val-4"))
+
+		if val, err := snap.Get([]byte("1")); err != nil || !bytes.Equal(val, []byte("val-1")) {
+			t.Fatalf("wrong snapshot value: %q, %v", val, err)
+		}
+		if has, err := snap.Has([]byte("2")); err != nil || !has {
+			t.Fatalf("deleted key missing from snapshot: %v", err)
+		}
+		if has, err := snap.Has([]byte("4")); err != nil || has {
+			t.Fatalf("new key visible in snapshot: %v", err)
+		}
+		if _, err := snap.Get([]byte("4")); err == nil {
+			t.Fatal("expected error for new key")
+		}
+		if got, want := iterateKeys(snap.NewIterator(nil, nil)), []string{"1", "2", "3"}; !slices.Equal(got, want) {
+			t.Fatalf("wrong snapshot iteration: %v, want %v", got, want)
+		}
+		if got, want := iterateKeys(snap.NewIterator(nil, []byte("2"))), []string{"2", "3"}; !slices.Equal(got, want) {
+			t.Fatalf("wrong snapshot iteration from start: %v, want %v", got, want)
+		}
+	})
 }
 
 // BenchDatabaseSuite runs a suite of benchmarks against a KeyValueStore database
@@ -0,0 +1,471 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package encdb implements a key-value store wrapper encrypting the data of
+// an arbitrary ethdb.KeyValueStore at rest.
+//
+// Values are encrypted with AES-256-GCM under a random nonce and bound to
+// their key. Keys are stored in plain by default; optionally they are
+// encrypted deterministically in a way that preserves their order and common
+// prefixes, so prefix iteration and range operations keep working.
+package encdb
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/luxfi/geth/ethdb"
+)
+
+// metaKey is the key of the encryption metadata in the wrapped store. It has
+// an odd length, so it can never collide with an encrypted key.
+var metaKey = []byte("encdbMetadata")
+
+// metaVersion is the current version of the encryption metadata.
+const metaVersion = 1
+
+// metaCheck is the plain text encrypted into the metadata to verify the key.
+var metaCheck = []byte("encdb")
+
+var (
+	// ErrNotEncrypted is returned when opening a non-empty store that has not
+	// been created with encryption.
+	ErrNotEncrypted = errors.New("database is not encrypted")
+
+	// ErrWrongKey is returned when opening an encrypted store with a key
+	// other than the one it was created with.
+	ErrWrongKey = errors.New("wrong database encryption key")
+)
+
+// metadata is stored in plain in the wrapped store.
+type metadata struct {
+	Version uint   `json:"version"`
+	Keys    bool   `json:"keys"`  // whether keys are encrypted
+	Check   []byte `json:"check"` // metaCheck sealed with the value cipher
+}
+
+// Database is a key-value store encrypting the data of the wrapped store.
+type Database struct {
+	db     ethdb.KeyValueStore
+	meta   []byte // encoded metadata, restored after range deletions
+	values cipher.AEAD
+	keys   *keyCipher // nil if keys are stored in plain
+}
+
+// IsEncrypted reports whether the given store has been created by New.
+func IsEncrypted(db ethdb.KeyValueReader) bool {
+	has, _ := db.Has(metaKey)
+	return has
+}
+
+// New wraps db, encrypting all data with the given master key. If db is
+// empty, it is initialized for encryption and encryptKeys decides whether
+// keys are encrypted too. Otherwise db must have been initialized with the
+// same key before, and the key mode it was created with is used.
+func New(db ethdb.KeyValueStore, key []byte, encryptKeys bool) (*Database, error) {
+	values, err := newAEAD(key, valueKeyLabel)
+	if err != nil {
+		return nil, err
+	}
+	d := &Database{db: db, values: values}
+
+	blob, err := db.Get(metaKey)
+	if err != nil {
+		if has, _ := db.Has(metaKey); has {
+			return nil, err
+		}
+		it := db.NewIterator(nil, nil)
+		empty := !it.Next()
+		it.Release()
+		if !empty {
+			return nil, ErrNotEncrypted
+		}
+		meta := metadata{Version: metaVersion, Keys: encryptKeys}
+		if meta.Check, err = seal(values, metaCheck, metaKey); err != nil {
+			return nil, err
+		}
+		if blob, err = json.Marshal(meta); err != nil {
+			return nil, err
+		}
+		if err := db.Put(metaKey, blob); err != nil {
+			return nil, err
+		}
+	}
+	var meta metadata
+	if err := json.Unmarshal(blob, &meta); err != nil {
+		return nil, fmt.Errorf("invalid encryption metadata: %v", err)
+	}
+	if meta.Version != metaVersion {
+		return nil, fmt.Errorf("unsupported encryption metadata version %d", meta.Version)
+	}
+	if check, err := open(values, meta.Check, metaKey); err != nil || !bytes.Equal(check, metaCheck) {
+		return nil, ErrWrongKey
+	}
+	d.meta = blob
+	if meta.Keys {
+		if d.keys, err = newKeyCipher(key); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// EncryptsKeys reports whether the keys are encrypted as well as the values.
+func (d *Database) EncryptsKeys() bool {
+	return d.keys != nil
+}
+
+// encryptKey returns the key as stored in the wrapped store.
+func (d *Database) encryptKey(key []byte) []byte {
+	if d.keys == nil {
+		return key
+	}
+	return d.keys.encrypt(key)
+}
+
+// decryptKey returns the plain key of a key in the wrapped store.
+func (d *Database) decryptKey(key []byte) ([]byte, error) {
+	if d.keys == nil {
+		return key, nil
+	}
+	return d.keys.decrypt(key)
+}
+
+// isMeta reports whether the key in the wrapped store is the metadata.
+func (d *Database) isMeta(key []byte) bool {
+	if d.keys == nil {
+		return bytes.Equal(key, metaKey)
+	}
+	return len(key)%2 != 0
+}
+
+// encryptValue seals the value of the given plain key.
+func (d *Database) encryptValue(key, value []byte) ([]byte, error) {
+	return seal(d.values, value, key)
+}
+
+// decryptValue opens the stored value of the given plain key.
+func (d *Database) decryptValue(key, value []byte) ([]byte, error) {
+	plain, err := open(d.values, value, key)
+	if err != nil {
+		return nil, fmt.Errorf("%w value of key %#x", err, key)
+	}
+	return plain, nil
+}
+
+// Has retrieves if a key is present in the key-value store.
+func (d *Database) Has(key []byte) (bool, error) {
+	return d.db.Has(d.encryptKey(key))
+}
+
+// Get retrieves the given key if it's present in the key-value store.
+func (d *Database) Get(key []byte) ([]byte, error) {
+	value, err := d.db.Get(d.encryptKey(key))
+	if err != nil {
+		return nil, err
+	}
+	return d.decryptValue(key, value)
+}
+
+// Put inserts the given value into the key-value store.
+func (d *Database) Put(key []byte, value []byte) error {
+	enc, err := d.encryptValue(key, value)
+	if err != nil {
+		return err
+	}
+	return d.db.Put(d.encryptKey(key), enc)
+}
+
+// Delete removes the key from the key-value store.
+func (d *Database) Delete(key []byte) error {
+	return d.db.Delete(d.encryptKey(key))
+}
+
+// DeleteRange deletes all of the keys (and values) in the range [start,end)
+// (inclusive on start, exclusive on end).
+func (d *Database) DeleteRange(start, end []byte) error {
+	encStart, encEnd := d.encryptKey(start), d.encryptKey(end)
+	if err := d.db.DeleteRange(encStart, encEnd); err != nil {
+		return err
+	}
+	if coversMeta(encStart, encEnd) {
+		return d.db.Put(metaKey, d.meta)
+	}
+	return nil
+}
+
+// coversMeta reports whether the range of stored keys includes the metadata.
+func coversMeta(start, end []byte) bool {
+	return bytes.Compare(start, metaKey) <= 0 && (end == nil || bytes.Compare(metaKey, end) < 0)
+}
+
+// Stat returns the statistic data of the wrapped store.
+func (d *Database) Stat() (string, error) {
+	return d.db.Stat()
+}
+
+// Compact flattens the underlying data store for the given key range.
+func (d *Database) Compact(start []byte, limit []byte) error {
+	return d.db.Compact(d.encryptKey(start), d.encryptKey(limit))
+}
+
+// SyncKeyValue ensures that all pending writes are flushed to disk.
+func (d *Database) SyncKeyValue() error {
+	return d.db.SyncKeyValue()
+}
+
+// Checkpoint implements ethdb.Checkpointer if the wrapped store does. The
+// checkpoint holds the encrypted data.
+func (d *Database) Checkpoint(dir string) error {
+	if cp, ok := d.db.(ethdb.Checkpointer); ok {
+		return cp.Checkpoint(dir)
+	}
+	return errors.New("database does not support checkpoints")
+}
+
+// NewSnapshot implements ethdb.Snapshotter if the wrapped store does. The
+// snapshot decrypts the entries just like the database itself.
+func (d *Database) NewSnapshot() (ethdb.Snapshot, error) {
+	snapshotter, ok := d.db.(ethdb.Snapshotter)
+	if !ok {
+		return nil, errors.New("database does not support snapshots")
+	}
+	snap, err := snapshotter.NewSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot{db: d, snap: snap}, nil
+}
+
+// Close closes the wrapped store.
+func (d *Database) Close() error {
+	return d.db.Close()
+}
+
+// NewBatch creates a write-only key-value store that buffers changes to its
+// host database until a final write is called.
+func (d *Database) NewBatch() ethdb.Batch {
+	return &batch{db: d, b: d.db.NewBatch()}
+}
+
+// NewBatchWithSize creates a write-only database batch with pre-allocated buffer.
+func (d *Database) NewBatchWithSize(size int) ethdb.Batch {
+	return &batch{db: d, b: d.db.NewBatchWithSize(size)}
+}
+
+// NewIterator creates a binary-alphabetical iterator over a subset
+// of database content with a particular key prefix, starting at a particular
+// initial key (or after, if it does not exist).
+func (d *Database) NewIterator(prefix []byte, start []byte) ethdb.Iterator {
+	return d.newIterator(d.db, prefix, start)
+}
+
+// newIterator creates a decrypting iterator over the given source, which is
+// either the wrapped store or one of its snapshots.
+func (d *Database) newIterator(source ethdb.Iteratee, prefix []byte, start []byte) ethdb.Iterator {
+	var (
+		encPrefix = d.encryptKey(prefix)
+		encStart  []byte
+	)
+	if len(start) > 0 {
+		// The encryption preserves prefixes, strip the encrypted one again.
+		full := d.encryptKey(append(bytes.Clone(prefix), start...))
+		encStart = full[len(encPrefix):]
+	}
+	return &iterator{db: d, it: source.NewIterator(encPrefix, encStart)}
+}
+
+// snapshot decrypts the content of a snapshot of the wrapped store.
+type snapshot struct {
+	db   *Database
+	snap ethdb.Snapshot
+}
+
+// Has retrieves if a key is present in the snapshot.
+func (s *snapshot) Has(key []byte) (bool, error) {
+	return s.snap.Has(s.db.encryptKey(key))
+}
+
+// Get retrieves the given key if it's present in the snapshot.
+func (s *snapshot) Get(key []byte) ([]byte, error) {
+	value, err := s.snap.Get(s.db.encryptKey(key))
+	if err != nil {
+		return nil, err
+	}
+	return s.db.decryptValue(key, value)
+}
+
+// NewIterator creates a binary-alphabetical iterator over a subset of the
+// snapshot content with a particular key prefix, starting at a particular
+// initial key (or after, if it does not exist).
+func (s *snapshot) NewIterator(prefix []byte, start []byte) ethdb.Iterator {
+	return s.db.newIterator(s.snap, prefix, start)
+}
+
+// Release releases the snapshot of the wrapped store.
+func (s *snapshot) Release() {
+	s.snap.Release()
+}
+
+// batch encrypts the writes into a batch of the wrapped store.
+type batch struct {
+	db *Database
+	b  ethdb.Batch
+}
+
+// Put inserts the given value into the batch for later committing.
+func (b *batch) Put(key, value []byte) error {
+	enc, err := b.db.encryptValue(key, value)
+	if err != nil {
+		return err
+	}
+	return b.b.Put(b.db.encryptKey(key), enc)
+}
+
+// Delete inserts the key removal into the batch for later committing.
+func (b *batch) Delete(key []byte) error {
+	return b.b.Delete(b.db.encryptKey(key))
+}
+
+// DeleteRange removes all keys in the range [start, end) from the batch for
+// later committing.
+func (b *batch) DeleteRange(start, end []byte) error {
+	encStart, encEnd := b.db.encryptKey(start), b.db.encryptKey(end)
+	if err := b.b.DeleteRange(encStart, encEnd); err != nil {
+		return err
+	}
+	if coversMeta(encStart, encEnd) {
+		return b.b.Put(metaKey, b.db.meta)
+	}
+	return nil
+}
+
+// ValueSize retrieves the amount of data queued up for writing.
+func (b *batch) ValueSize() int {
+	return b.b.ValueSize()
+}
+
+// Write flushes any accumulated data to disk.
+func (b *batch) Write() error {
+	return b.b.Write()
+}
+
+// Reset resets the batch for reuse.
+func (b *batch) Reset() {
+	b.b.Reset()
+}
+
+// Replay replays the batch contents.
+func (b *batch) Replay(w ethdb.KeyValueWriter) error {
+	return b.b.Replay(&replayer{db: b.db, w: w})
+}
+
+// replayer decrypts the writes of a batch replay.
+type replayer struct {
+	db *Database
+	w  ethdb.KeyValueWriter
+}
+
+func (r *replayer) Put(key, value []byte) error {
+	if r.db.isMeta(key) {
+		return nil
+	}
+	plainKey, err := r.db.decryptKey(key)
+	if err != nil {
+		return err
+	}
+	plain, err := r.db.decryptValue(plainKey, value)
+	if err != nil {
+		return err
+	}
+	return r.w.Put(plainKey, plain)
+}
+
+func (r *replayer) Delete(key []byte) error {
+	plainKey, err := r.db.decryptKey(key)
+	if err != nil {
+		return err
+	}
+	return r.w.Delete(plainKey)
+}
+
+func (r *replayer) DeleteRange(start, end []byte) error {
+	rangeDeleter, ok := r.w.(ethdb.KeyValueRangeDeleter)
+	if !ok {
+		return errors.New("ethdb.KeyValueWriter does not implement DeleteRange")
+	}
+	plainStart, err := r.db.decryptKey(start)
+	if err != nil {
+		return err
+	}
+	var plainEnd []byte
+	if end != nil {
+		if plainEnd, err = r.db.decryptKey(end); err != nil {
+			return err
+		}
+	}
+	return rangeDeleter.DeleteRange(plainStart, plainEnd)
+}
+
+// iterator decrypts the entries of an iterator of the wrapped store.
+type iterator struct {
+	db    *Database
+	it    ethdb.Iterator
+	key   []byte
+	value []byte
+	err   error
+}
+
+// Next moves the iterator to the next key/value pair. It returns whether the
+// iterator is exhausted.
+func (it *iterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	for it.it.Next() {
+		if it.db.isMeta(it.it.Key()) {
+			continue
+		}
+		key, err := it.db.decryptKey(it.it.Key())
+		if err != nil {
+			it.err = fmt.Errorf("%w key %#x", err, it.it.Key())
+			break
+		}
+		value, err := it.db.decryptValue(key, it.it.Value())
+		if err != nil {
+			it.err = err
+			break
+		}
+		it.key, it.value = bytes.Clone(key), value
+		return true
+	}
+	it.key, it.value = nil, nil
+	return false
+}
+
+// Error returns any accumulated error. Exhausting all the key/value pairs
+// is not considered to be an error.
+func (it *iterator) Error() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.it.Error()
+}
+
+// Key returns the key of the current key/value pair, or nil if done.
+func (it *iterator) Key() []byte {
+	return it.key
+}
+
+// Value returns the value of the current key/value pair, or nil if done.
+func (it *iterator) Value() []byte {
+	return it.value
+}
+
+// Release releases associated resources.
+func (it *iterator) Release() {
+	it.it.Release()
+}
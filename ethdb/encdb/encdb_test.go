@@ -0,0 +1,127 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package encdb
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/luxfi/geth/ethdb"
+	"github.com/luxfi/geth/ethdb/dbtest"
+	"github.com/luxfi/geth/ethdb/memorydb"
+)
+
+var testKey = bytes.Repeat([]byte{0x42}, KeySize)
+
+func newTestDatabase(tb testing.TB, encryptKeys bool) *Database {
+	db, err := New(memorydb.New(), testKey, encryptKeys)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return db
+}
+
+func TestEncryptedDB(t *testing.T) {
+	t.Run("DatabaseSuite", func(t *testing.T) {
+		dbtest.TestDatabaseSuite(t, func() ethdb.KeyValueStore {
+			return newTestDatabase(t, false)
+		})
+	})
+	t.Run("EncryptedKeys", func(t *testing.T) {
+		dbtest.TestDatabaseSuite(t, func() ethdb.KeyValueStore {
+			return newTestDatabase(t, true)
+		})
+	})
+}
+
+func BenchmarkEncryptedDB(b *testing.B) {
+	dbtest.BenchDatabaseSuite(b, func() ethdb.KeyValueStore {
+		return newTestDatabase(b, true)
+	})
+}
+
+// Tests that the key encryption round-trips and preserves order and prefixes.
+func TestKeyCipher(t *testing.T) {
+	c, err := newKeyCipher(testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := [][]byte{{}, {0}, {0xff}, {0, 0}, {0xff, 0xff}}
+	for i := 0; i < 1000; i++ {
+		key := make([]byte, rand.Intn(40))
+		rand.Read(key)
+		keys = append(keys, key)
+	}
+	for i, a := range keys {
+		encA := c.encrypt(a)
+		if dec, err := c.decrypt(encA); err != nil || !bytes.Equal(dec, a) {
+			t.Fatalf("key %x: decrypted to %x, %v", a, dec, err)
+		}
+		if i > 0 {
+			b := keys[i-1]
+			encB := c.encrypt(b)
+			if have, want := bytes.Compare(encA, encB), bytes.Compare(a, b); have != want {
+				t.Fatalf("order of %x and %x not preserved: have %d, want %d", a, b, have, want)
+			}
+		}
+		if len(a) > 0 {
+			if prefix := c.encrypt(a[:len(a)/2]); !bytes.HasPrefix(encA, prefix) {
+				t.Fatalf("prefix of %x not preserved", a)
+			}
+		}
+	}
+	if bytes.Contains(c.encrypt([]byte("plain key")), []byte("plain")) {
+		t.Fatal("key stored in plain")
+	}
+}
+
+func TestReopen(t *testing.T) {
+	mem := memorydb.New()
+	db, err := New(mem, testKey, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Put([]byte("secret-key"), []byte("secret-value"))
+
+	// The wrapped store must not contain the plain data
+	it := mem.NewIterator(nil, nil)
+	for it.Next() {
+		if bytes.Contains(it.Key(), []byte("secret")) || bytes.Contains(it.Value(), []byte("secret")) {
+			t.Fatalf("plain data in wrapped store: %q = %q", it.Key(), it.Value())
+		}
+	}
+	it.Release()
+
+	if !IsEncrypted(mem) {
+		t.Fatal("store not detected as encrypted")
+	}
+	// The key mode is taken from the metadata
+	reopened, err := New(mem, testKey, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reopened.EncryptsKeys() {
+		t.Fatal("key encryption lost on reopen")
+	}
+	if value, err := reopened.Get([]byte("secret-key")); err != nil || string(value) != "secret-value" {
+		t.Fatalf("wrong value after reopen: %q, %v", value, err)
+	}
+	if _, err := New(mem, bytes.Repeat([]byte{1}, KeySize), true); !errors.Is(err, ErrWrongKey) {
+		t.Fatalf("wrong key accepted: %v", err)
+	}
+	plain := memorydb.New()
+	plain.Put([]byte("key"), []byte("value"))
+	if _, err := New(plain, testKey, true); !errors.Is(err, ErrNotEncrypted) {
+		t.Fatalf("unencrypted store accepted: %v", err)
+	}
+	// Range deletions must keep the metadata
+	if err := reopened.DeleteRange(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(mem, testKey, true); err != nil {
+		t.Fatalf("failed to reopen after range deletion: %v", err)
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package encdb
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// KeySize is the size of the master encryption key in bytes.
+const KeySize = 32
+
+// KeyEnvVar is the environment variable the master encryption key can be
+// provided in, hex encoded.
+const KeyEnvVar = "GETH_DB_ENCRYPTION_KEY"
+
+// Labels used to derive the purpose specific keys from the master key.
+const (
+	valueKeyLabel   = "encdb values"
+	keyChainLabel   = "encdb key chain"
+	keyStreamLabel  = "encdb key stream"
+	freezerKeyLabel = "encdb freezer"
+)
+
+// ParseKey decodes a hex encoded master encryption key.
+func ParseKey(s string) ([]byte, error) {
+	key, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(s), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %v", err)
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("invalid encryption key length %d, want %d bytes", len(key), KeySize)
+	}
+	return key, nil
+}
+
+// LoadKey reads a hex encoded master encryption key from the given file.
+func LoadKey(file string) ([]byte, error) {
+	blob, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return ParseKey(string(blob))
+}
+
+// deriveKey derives a purpose specific AES-256 key from the master key.
+func deriveKey(master []byte, label string) ([]byte, error) {
+	if len(master) != KeySize {
+		return nil, fmt.Errorf("invalid encryption key length %d, want %d bytes", len(master), KeySize)
+	}
+	return hkdf.Key(sha256.New, master, nil, label, 32)
+}
+
+// newAEAD creates an AES-GCM cipher keyed with the key derived for label.
+func newAEAD(master []byte, label string) (cipher.AEAD, error) {
+	key, err := deriveKey(master, label)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// FreezerCipher returns the cipher used to encrypt freezer items with the
+// given master key.
+func FreezerCipher(master []byte) (cipher.AEAD, error) {
+	return newAEAD(master, freezerKeyLabel)
+}
+
+// seal encrypts data with a random nonce, which is prepended to the result.
+func seal(aead cipher.AEAD, data, ad []byte) ([]byte, error) {
+	out := make([]byte, aead.NonceSize(), aead.NonceSize()+len(data)+aead.Overhead())
+	if _, err := rand.Read(out); err != nil {
+		return nil, err
+	}
+	return aead.Seal(out, out, data, ad), nil
+}
+
+// open decrypts data produced by seal.
+func open(aead cipher.AEAD, data, ad []byte) ([]byte, error) {
+	if len(data) < aead.NonceSize()+aead.Overhead() {
+		return nil, errDecrypt
+	}
+	out, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], ad)
+	if err != nil {
+		return nil, errDecrypt
+	}
+	return out, nil
+}
+
+var errDecrypt = errors.New("failed to decrypt")
+
+// keyCipher deterministically encrypts database keys, preserving both their
+// order and common prefixes so that iteration works on the encrypted keys.
+//
+// Every key byte is mapped to two bytes by a strictly increasing function over
+// [0, 255] that is chosen pseudo-randomly based on the preceding bytes of the
+// key. The encrypted keys therefore reveal the order of the plain keys and the
+// length of their common prefixes, but not the bytes themselves.
+type keyCipher struct {
+	chain  cipher.Block // advances the per-prefix state
+	stream cipher.Block // derives the mapping for a state
+}
+
+func newKeyCipher(master []byte) (*keyCipher, error) {
+	chainKey, err := deriveKey(master, keyChainLabel)
+	if err != nil {
+		return nil, err
+	}
+	streamKey, err := deriveKey(master, keyStreamLabel)
+	if err != nil {
+		return nil, err
+	}
+	chain, err := aes.NewCipher(chainKey)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := aes.NewCipher(streamKey)
+	if err != nil {
+		return nil, err
+	}
+	return &keyCipher{chain: chain, stream: stream}, nil
+}
+
+// advance moves the state past the plain key byte b.
+func (c *keyCipher) advance(state *[aes.BlockSize]byte, b byte) {
+	state[0] ^= b
+	c.chain.Encrypt(state[:], state[:])
+}
+
+// mapping calls fn with the mapped value of every plain byte in order until
+// fn returns false. The mapped value of byte j is j plus the sum of the first
+// j+1 pseudo-random bytes derived from the state, which is strictly increasing
+// and at most 255 + 256*255 = 65535.
+func (c *keyCipher) mapping(state *[aes.BlockSize]byte, fn func(b byte, mapped uint16) bool) {
+	var (
+		block [aes.BlockSize]byte
+		sum   uint16
+	)
+	for j := 0; j < 256; j++ {
+		if j%aes.BlockSize == 0 {
+			block = *state
+			block[aes.BlockSize-1] ^= byte(j / aes.BlockSize)
+			c.stream.Encrypt(block[:], block[:])
+		}
+		sum += uint16(block[j%aes.BlockSize])
+		if !fn(byte(j), uint16(j)+sum) {
+			return
+		}
+	}
+}
+
+// encrypt returns the encrypted form of the plain key.
+func (c *keyCipher) encrypt(key []byte) []byte {
+	if key == nil {
+		return nil
+	}
+	var (
+		state [aes.BlockSize]byte
+		out   = make([]byte, 2*len(key))
+	)
+	for i, b := range key {
+		c.mapping(&state, func(j byte, mapped uint16) bool {
+			if j < b {
+				return true
+			}
+			binary.BigEndian.PutUint16(out[2*i:], mapped)
+			return false
+		})
+		c.advance(&state, b)
+	}
+	return out
+}
+
+// decrypt returns the plain key of an encrypted one.
+func (c *keyCipher) decrypt(enc []byte) ([]byte, error) {
+	if len(enc)%2 != 0 {
+		return nil, errDecrypt
+	}
+	var (
+		state [aes.BlockSize]byte
+		out   = make([]byte, len(enc)/2)
+	)
+	for i := range out {
+		var (
+			want  = binary.BigEndian.Uint16(enc[2*i:])
+			found bool
+		)
+		c.mapping(&state, func(j byte, mapped uint16) bool {
+			if mapped < want {
+				return true
+			}
+			out[i], found = j, mapped == want
+			return false
+		})
+		if !found {
+			return nil, errDecrypt
+		}
+		c.advance(&state, out[i])
+	}
+	return out, nil
+}
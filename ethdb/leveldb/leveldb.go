@@ -341,6 +341,51 @@ func (db *Database) SyncKeyValue() error {
 	return nil
 }
 
+// Checkpoint creates a consistent copy of the database in dir, which must not
+// exist yet. LevelDB has no native checkpoints, so the content of a snapshot is
+// copied into a fresh database.
+func (db *Database) Checkpoint(dir string) error {
+	if common.FileExist(dir) {
+		return fmt.Errorf("checkpoint directory %s already exists", dir)
+	}
+	snap, err := db.db.GetSnapshot()
+	if err != nil {
+		return err
+	}
+	defer snap.Release()
+
+	cp, err := leveldb.OpenFile(dir, &opt.Options{
+		ErrorIfExist: true,
+		Filter:       filter.NewBloomFilter(10),
+	})
+	if err != nil {
+		return err
+	}
+	it := snap.NewIterator(nil, nil)
+	defer it.Release()
+
+	batch := new(leveldb.Batch)
+	for it.Next() {
+		batch.Put(it.Key(), it.Value())
+		if len(batch.Dump()) >= ethdb.IdealBatchSize {
+			if err := cp.Write(batch, nil); err != nil {
+				cp.Close()
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	if err := it.Error(); err != nil {
+		cp.Close()
+		return err
+	}
+	if err := cp.Write(batch, &opt.WriteOptions{Sync: true}); err != nil {
+		cp.Close()
+		return err
+	}
+	return cp.Close()
+}
+
 // meter periodically retrieves internal leveldb counters and reports them to
 // the metrics subsystem.
 func (db *Database) meter(refresh time.Duration, namespace string) {
@@ -553,6 +598,47 @@ func (r *replayer) DeleteRange(start, end []byte) {
 	}
 }
 
+// NewSnapshot creates a database snapshot based on the current state.
+// The created snapshot will not be affected by all following mutations
+// happened on the database.
+func (db *Database) NewSnapshot() (ethdb.Snapshot, error) {
+	snap, err := db.db.GetSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot{db: snap}, nil
+}
+
+// snapshot wraps a leveldb snapshot for implementing the Snapshot interface.
+type snapshot struct {
+	db *leveldb.Snapshot
+}
+
+// Has retrieves if a key is present in the snapshot backing by a key-value
+// data store.
+func (snap *snapshot) Has(key []byte) (bool, error) {
+	return snap.db.Has(key, nil)
+}
+
+// Get retrieves the given key if it's present in the snapshot backing by
+// key-value data store.
+func (snap *snapshot) Get(key []byte) ([]byte, error) {
+	return snap.db.Get(key, nil)
+}
+
+// NewIterator creates a binary-alphabetical iterator over a subset of the
+// snapshot content with a particular key prefix, starting at a particular
+// initial key (or after, if it does not exist).
+func (snap *snapshot) NewIterator(prefix []byte, start []byte) ethdb.Iterator {
+	return snap.db.NewIterator(bytesPrefixRange(prefix, start), nil)
+}
+
+// Release releases associated resources. Release should always succeed and can
+// be called multiple times without causing error.
+func (snap *snapshot) Release() {
+	snap.db.Release()
+}
+
 // bytesPrefixRange returns key range that satisfy
 // - the given prefix, and
 // - the given seek position
@@ -17,6 +17,9 @@
 package leveldb
 
 import (
+	"bytes"
+	"fmt"
+	"path/filepath"
 	"testing"
 
 	"github.com/luxfi/geth/ethdb"
@@ -50,3 +53,35 @@ func BenchmarkLevelDB(b *testing.B) {
 		}
 	})
 }
+
+func TestLevelDBCheckpoint(t *testing.T) {
+	mem, err := leveldb.Open(storage.NewMemStorage(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := &Database{db: mem}
+	defer db.Close()
+
+	for i := 0; i < 1000; i++ {
+		db.Put([]byte(fmt.Sprintf("key-%04d", i)), bytes.Repeat([]byte{byte(i)}, 64))
+	}
+	dir := filepath.Join(t.TempDir(), "checkpoint")
+	if err := db.Checkpoint(dir); err != nil {
+		t.Fatalf("checkpoint failed: %v", err)
+	}
+	if err := db.Checkpoint(dir); err == nil {
+		t.Fatal("checkpoint into existing directory succeeded")
+	}
+	cp, err := New(dir, 16, 16, "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cp.Close()
+
+	for i := 0; i < 1000; i++ {
+		val, err := cp.Get([]byte(fmt.Sprintf("key-%04d", i)))
+		if err != nil || !bytes.Equal(val, bytes.Repeat([]byte{byte(i)}, 64)) {
+			t.Fatalf("key %d: wrong value %x, %v", i, val, err)
+		}
+	}
+}
@@ -20,6 +20,7 @@ package memorydb
 import (
 	"bytes"
 	"errors"
+	"maps"
 	"sort"
 	"strings"
 	"sync"
@@ -160,6 +161,33 @@ func (db *Database) NewBatchWithSize(size int) ethdb.Batch {
 	}
 }
 
+// NewSnapshot creates a database snapshot based on the current state.
+// The created snapshot will not be affected by all following mutations
+// happened on the database.
+func (db *Database) NewSnapshot() (ethdb.Snapshot, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.db == nil {
+		return nil, errMemorydbClosed
+	}
+	// The stored values are never modified in place, so copying the map
+	// is enough to detach the snapshot from later writes.
+	return &snapshot{&Database{db: maps.Clone(db.db)}}, nil
+}
+
+// snapshot is a copy of the memory database implementing the Snapshot
+// interface.
+type snapshot struct {
+	*Database
+}
+
+// Release releases associated resources. Release should always succeed and
+// can be called multiple times without causing error.
+func (snap *snapshot) Release() {
+	snap.Database.Close()
+}
+
 // NewIterator creates a binary-alphabetical iterator over a subset
 // of database content with a particular key prefix, starting at a particular
 // initial key (or after, if it does not exist).
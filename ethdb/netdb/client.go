@@ -0,0 +1,533 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package netdb
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/luxfi/geth/ethdb"
+)
+
+// iteratorPageSize is the number of entries requested from the server at once
+// by iterators.
+const iteratorPageSize = 4096
+
+var (
+	errReadOnly     = errors.New("read-only database")
+	errNotSupported = errors.New("not supported by network database")
+	errClientClosed = errors.New("database closed")
+)
+
+// clientConn is a connection to the server along with its buffers.
+type clientConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	w    *bufio.Writer
+}
+
+// Database is a read-only ethdb.Database served by a remote Server. It is safe
+// for concurrent use, concurrent requests are sent over separate connections.
+//
+// The ancient store is accessed directly, ReadAncients provides no isolation
+// from concurrent freezer writes of the serving process.
+type Database struct {
+	dial func() (net.Conn, error)
+
+	lock   sync.Mutex
+	idle   []*clientConn
+	closed bool
+}
+
+// Dial connects to a database server listening on the given unix socket.
+func Dial(path string) (*Database, error) {
+	return newDatabase(func() (net.Conn, error) {
+		return net.Dial("unix", path)
+	})
+}
+
+// newDatabase creates a client using the given dialer, ensuring the server is
+// reachable.
+func newDatabase(dial func() (net.Conn, error)) (*Database, error) {
+	db := &Database{dial: dial}
+	conn, err := db.get()
+	if err != nil {
+		return nil, err
+	}
+	db.put(conn)
+	return db, nil
+}
+
+// get returns an idle connection or dials a new one.
+func (db *Database) get() (*clientConn, error) {
+	db.lock.Lock()
+	if db.closed {
+		db.lock.Unlock()
+		return nil, errClientClosed
+	}
+	if n := len(db.idle); n > 0 {
+		conn := db.idle[n-1]
+		db.idle = db.idle[:n-1]
+		db.lock.Unlock()
+		return conn, nil
+	}
+	db.lock.Unlock()
+
+	conn, err := db.dial()
+	if err != nil {
+		return nil, err
+	}
+	return &clientConn{conn: conn, r: bufio.NewReader(conn), w: bufio.NewWriter(conn)}, nil
+}
+
+// put returns a connection to the idle pool.
+func (db *Database) put(conn *clientConn) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.closed {
+		conn.conn.Close()
+		return
+	}
+	db.idle = append(db.idle, conn)
+}
+
+// call sends a request to the server and waits for the response. Remote
+// errors are returned as is, connection failures are wrapped.
+func (db *Database) call(op byte, payload []byte) ([]byte, error) {
+	conn, err := db.get()
+	if err != nil {
+		return nil, err
+	}
+	status, result, err := conn.call(op, payload)
+	if err != nil {
+		conn.conn.Close()
+		return nil, fmt.Errorf("database connection failed: %w", err)
+	}
+	db.put(conn)
+
+	if status != statusOK {
+		return nil, errors.New(string(result))
+	}
+	return result, nil
+}
+
+// call performs a single request-response exchange.
+func (c *clientConn) call(op byte, payload []byte) (byte, []byte, error) {
+	if err := writeFrame(c.w, op, payload); err != nil {
+		return 0, nil, err
+	}
+	if err := c.w.Flush(); err != nil {
+		return 0, nil, err
+	}
+	return readFrame(c.r)
+}
+
+// getKey retrieves a key from the live database or from a snapshot.
+func (db *Database) getKey(snap uint64, key []byte) ([]byte, error) {
+	req := new(encoder)
+	req.uint(snap)
+	req.bytes(key)
+	return db.call(opGet, req.buf)
+}
+
+// hasKey checks the presence of a key in the live database or in a snapshot.
+func (db *Database) hasKey(snap uint64, key []byte) (bool, error) {
+	req := new(encoder)
+	req.uint(snap)
+	req.bytes(key)
+	res, err := db.call(opHas, req.buf)
+	if err != nil {
+		return false, err
+	}
+	d := &decoder{buf: res}
+	has := d.bool()
+	return has, d.finish()
+}
+
+// batchGet retrieves a set of keys in a single round trip.
+func (db *Database) batchGet(snap uint64, keys [][]byte) ([][]byte, error) {
+	req := new(encoder)
+	req.uint(snap)
+	req.uint(uint64(len(keys)))
+	for _, key := range keys {
+		req.bytes(key)
+	}
+	res, err := db.call(opBatchGet, req.buf)
+	if err != nil {
+		return nil, err
+	}
+	d := &decoder{buf: res}
+	if count := d.uint(); count != uint64(len(keys)) && d.err == nil {
+		return nil, fmt.Errorf("invalid value count %d, want %d", count, len(keys))
+	}
+	values := make([][]byte, len(keys))
+	for i := range values {
+		found, value := d.bool(), d.bytes()
+		if found {
+			values[i] = value
+		}
+	}
+	if err := d.finish(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// newIterator creates an iterator over the live database or a snapshot.
+func (db *Database) newIterator(snap uint64, prefix []byte, start []byte) ethdb.Iterator {
+	req := new(encoder)
+	req.uint(snap)
+	req.bytes(prefix)
+	req.bytes(start)
+	res, err := db.call(opIterNew, req.buf)
+	if err != nil {
+		return &iterator{err: err}
+	}
+	d := &decoder{buf: res}
+	id := d.uint()
+	if err := d.finish(); err != nil {
+		return &iterator{err: err}
+	}
+	return &iterator{db: db, id: id}
+}
+
+// Has retrieves if a key is present in the key-value data store.
+func (db *Database) Has(key []byte) (bool, error) {
+	return db.hasKey(0, key)
+}
+
+// Get retrieves the given key if it's present in the key-value data store.
+func (db *Database) Get(key []byte) ([]byte, error) {
+	return db.getKey(0, key)
+}
+
+// BatchGet retrieves the values of multiple keys in a single round trip. The
+// values of missing keys are nil.
+func (db *Database) BatchGet(keys [][]byte) ([][]byte, error) {
+	return db.batchGet(0, keys)
+}
+
+// NewIterator creates a binary-alphabetical iterator over a subset
+// of database content with a particular key prefix, starting at a particular
+// initial key (or after, if it does not exist).
+func (db *Database) NewIterator(prefix []byte, start []byte) ethdb.Iterator {
+	return db.newIterator(0, prefix, start)
+}
+
+// NewSnapshot creates a snapshot of the remote database, unaffected by any
+// later writes of the serving process.
+func (db *Database) NewSnapshot() (ethdb.Snapshot, error) {
+	res, err := db.call(opSnapshot, nil)
+	if err != nil {
+		return nil, err
+	}
+	d := &decoder{buf: res}
+	id := d.uint()
+	if err := d.finish(); err != nil {
+		return nil, err
+	}
+	return &snapshot{db: db, id: id}, nil
+}
+
+// Stat returns the statistic data of the remote database.
+func (db *Database) Stat() (string, error) {
+	res, err := db.call(opStat, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(res), nil
+}
+
+// Ancient retrieves an ancient binary blob from the append-only immutable files.
+func (db *Database) Ancient(kind string, number uint64) ([]byte, error) {
+	req := new(encoder)
+	req.string(kind)
+	req.uint(number)
+	return db.call(opAncient, req.buf)
+}
+
+// AncientRange retrieves multiple items in sequence, starting from the index
+// 'start'. The items are limited to maxMessageSize in total.
+func (db *Database) AncientRange(kind string, start, count, maxBytes uint64) ([][]byte, error) {
+	req := new(encoder)
+	req.string(kind)
+	req.uint(start)
+	req.uint(count)
+	req.uint(maxBytes)
+	res, err := db.call(opAncientRange, req.buf)
+	if err != nil {
+		return nil, err
+	}
+	d := &decoder{buf: res}
+	n := d.uint()
+	if n > count {
+		return nil, fmt.Errorf("invalid item count %d, requested %d", n, count)
+	}
+	items := make([][]byte, n)
+	for i := range items {
+		items[i] = d.bytes()
+	}
+	if err := d.finish(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// callUint performs a request returning a single integer.
+func (db *Database) callUint(op byte, payload []byte) (uint64, error) {
+	res, err := db.call(op, payload)
+	if err != nil {
+		return 0, err
+	}
+	d := &decoder{buf: res}
+	n := d.uint()
+	return n, d.finish()
+}
+
+// Ancients returns the ancient item numbers in the ancient store.
+func (db *Database) Ancients() (uint64, error) {
+	return db.callUint(opAncients, nil)
+}
+
+// Tail returns the number of first stored item in the ancient store.
+func (db *Database) Tail() (uint64, error) {
+	return db.callUint(opTail, nil)
+}
+
+// AncientSize returns the ancient size of the specified category.
+func (db *Database) AncientSize(kind string) (uint64, error) {
+	req := new(encoder)
+	req.string(kind)
+	return db.callUint(opAncientSize, req.buf)
+}
+
+// ReadAncients runs the given read operation on the remote ancient store.
+func (db *Database) ReadAncients(fn func(ethdb.AncientReaderOp) error) error {
+	return fn(db)
+}
+
+// AncientDatadir returns an error as the ancient store is not local.
+func (db *Database) AncientDatadir() (string, error) {
+	return "", errNotSupported
+}
+
+// Put returns an error as the database is read-only.
+func (db *Database) Put(key []byte, value []byte) error {
+	return errReadOnly
+}
+
+// Delete returns an error as the database is read-only.
+func (db *Database) Delete(key []byte) error {
+	return errReadOnly
+}
+
+// DeleteRange returns an error as the database is read-only.
+func (db *Database) DeleteRange(start, end []byte) error {
+	return errReadOnly
+}
+
+// NewBatch returns a batch rejecting all writes.
+func (db *Database) NewBatch() ethdb.Batch {
+	return readOnlyBatch{}
+}
+
+// NewBatchWithSize returns a batch rejecting all writes.
+func (db *Database) NewBatchWithSize(size int) ethdb.Batch {
+	return readOnlyBatch{}
+}
+
+// Compact returns an error as the database is read-only.
+func (db *Database) Compact(start []byte, limit []byte) error {
+	return errReadOnly
+}
+
+// SyncKeyValue is a no-op, nothing is ever written.
+func (db *Database) SyncKeyValue() error {
+	return nil
+}
+
+// ModifyAncients returns an error as the database is read-only.
+func (db *Database) ModifyAncients(func(ethdb.AncientWriteOp) error) (int64, error) {
+	return 0, errReadOnly
+}
+
+// SyncAncient is a no-op, nothing is ever written.
+func (db *Database) SyncAncient() error {
+	return nil
+}
+
+// TruncateHead returns an error as the database is read-only.
+func (db *Database) TruncateHead(n uint64) (uint64, error) {
+	return 0, errReadOnly
+}
+
+// TruncateTail returns an error as the database is read-only.
+func (db *Database) TruncateTail(n uint64) (uint64, error) {
+	return 0, errReadOnly
+}
+
+// Close closes all connections to the server. The server releases the
+// iterators and snapshots still held by them.
+func (db *Database) Close() error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.closed {
+		return nil
+	}
+	db.closed = true
+	for _, conn := range db.idle {
+		conn.conn.Close()
+	}
+	db.idle = nil
+	return nil
+}
+
+// snapshot is a snapshot held by the server.
+type snapshot struct {
+	db       *Database
+	id       uint64
+	lock     sync.Mutex
+	released bool
+}
+
+// Has retrieves if a key is present in the snapshot.
+func (s *snapshot) Has(key []byte) (bool, error) {
+	return s.db.hasKey(s.id, key)
+}
+
+// Get retrieves the given key if it's present in the snapshot.
+func (s *snapshot) Get(key []byte) ([]byte, error) {
+	return s.db.getKey(s.id, key)
+}
+
+// BatchGet retrieves the values of multiple keys in a single round trip. The
+// values of missing keys are nil.
+func (s *snapshot) BatchGet(keys [][]byte) ([][]byte, error) {
+	return s.db.batchGet(s.id, keys)
+}
+
+// NewIterator creates a binary-alphabetical iterator over a subset of the
+// snapshot content with a particular key prefix, starting at a particular
+// initial key (or after, if it does not exist).
+func (s *snapshot) NewIterator(prefix []byte, start []byte) ethdb.Iterator {
+	return s.db.newIterator(s.id, prefix, start)
+}
+
+// Release releases the snapshot on the server, along with all iterators
+// created on it.
+func (s *snapshot) Release() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.released {
+		return
+	}
+	s.released = true
+
+	req := new(encoder)
+	req.uint(s.id)
+	s.db.call(opSnapRelease, req.buf)
+}
+
+// iterator iterates over the entries of the server-side iterator, fetching
+// them page by page.
+type iterator struct {
+	db   *Database
+	id   uint64
+	done bool // whether the server-side iterator is exhausted and released
+
+	keys   [][]byte
+	values [][]byte
+	pos    int
+
+	key, value []byte
+	err        error
+}
+
+// Next moves the iterator to the next key/value pair. It returns whether the
+// iterator is exhausted.
+func (it *iterator) Next() bool {
+	if it.err != nil || it.db == nil {
+		return false
+	}
+	for it.pos >= len(it.keys) {
+		if it.done {
+			it.key, it.value = nil, nil
+			return false
+		}
+		if err := it.fetch(); err != nil {
+			it.key, it.value, it.err = nil, nil, err
+			return false
+		}
+	}
+	it.key, it.value = it.keys[it.pos], it.values[it.pos]
+	it.pos++
+	return true
+}
+
+// fetch retrieves the next page of entries.
+func (it *iterator) fetch() error {
+	req := new(encoder)
+	req.uint(it.id)
+	req.uint(iteratorPageSize)
+	res, err := it.db.call(opIterNext, req.buf)
+	if err != nil {
+		it.done = true // the server releases failed iterators
+		return err
+	}
+	d := &decoder{buf: res}
+	n := d.uint()
+	if n > iteratorPageSize {
+		return fmt.Errorf("invalid page size %d", n)
+	}
+	it.keys, it.values, it.pos = make([][]byte, n), make([][]byte, n), 0
+	for i := range it.keys {
+		it.keys[i], it.values[i] = d.bytes(), d.bytes()
+	}
+	it.done = d.bool()
+	return d.finish()
+}
+
+// Error returns any accumulated error. Exhausting all the key/value pairs
+// is not considered to be an error.
+func (it *iterator) Error() error {
+	return it.err
+}
+
+// Key returns the key of the current key/value pair, or nil if done.
+func (it *iterator) Key() []byte {
+	return it.key
+}
+
+// Value returns the value of the current key/value pair, or nil if done.
+func (it *iterator) Value() []byte {
+	return it.value
+}
+
+// Release releases the server-side iterator if it is not exhausted yet.
+func (it *iterator) Release() {
+	if it.db != nil && !it.done {
+		req := new(encoder)
+		req.uint(it.id)
+		it.db.call(opIterRelease, req.buf)
+	}
+	it.db, it.keys, it.values = nil, nil, nil
+	it.key, it.value = nil, nil
+}
+
+// readOnlyBatch is the batch of a read-only database, rejecting all writes.
+type readOnlyBatch struct{}
+
+func (readOnlyBatch) Put(key, value []byte) error         { return errReadOnly }
+func (readOnlyBatch) Delete(key []byte) error             { return errReadOnly }
+func (readOnlyBatch) DeleteRange(start, end []byte) error { return errReadOnly }
+func (readOnlyBatch) ValueSize() int                      { return 0 }
+func (readOnlyBatch) Write() error                        { return errReadOnly }
+func (readOnlyBatch) Reset()                              {}
+func (readOnlyBatch) Replay(w ethdb.KeyValueWriter) error { return nil }
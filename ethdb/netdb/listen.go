@@ -0,0 +1,102 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package netdb
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/luxfi/geth/ethdb"
+	"github.com/luxfi/geth/log"
+)
+
+// Listen creates a unix socket at the given path that only the current user
+// can connect to. The socket is created inside a private directory and moved
+// into place once its permissions are restricted, so it is never reachable by
+// other users, whatever the umask. A stale socket at the path is replaced.
+func Listen(path string) (net.Listener, error) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	tmpdir, err := os.MkdirTemp(dir, ".netdb-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpdir)
+
+	tmp := filepath.Join(tmpdir, "db.ipc")
+	listener, err := net.Listen("unix", tmp)
+	if err != nil {
+		return nil, err
+	}
+	// The socket is renamed below, it must not be unlinked from its old path
+	// when the listener is closed.
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+
+	if err := os.Chmod(tmp, 0600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to restrict socket permissions: %w", err)
+	}
+	if fi, err := os.Lstat(path); err == nil && fi.Mode()&os.ModeType != os.ModeSocket {
+		listener.Close()
+		return nil, fmt.Errorf("refusing to replace non-socket file %s", path)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return &socketListener{Listener: listener, path: path}, nil
+}
+
+// socketListener removes the socket file when closed.
+type socketListener struct {
+	net.Listener
+	path string
+}
+
+func (l *socketListener) Close() error {
+	err := l.Listener.Close()
+	os.Remove(l.path)
+	return err
+}
+
+// Service serves a database on a unix socket for the lifetime of a node. It
+// implements node.Lifecycle, the database is neither written to nor closed.
+type Service struct {
+	db     ethdb.Database
+	path   string
+	server *Server
+}
+
+// NewService creates a service serving the database on the given socket path.
+func NewService(db ethdb.Database, path string) *Service {
+	return &Service{db: db, path: path}
+}
+
+// Start opens the socket and starts accepting connections.
+func (s *Service) Start() error {
+	listener, err := Listen(s.path)
+	if err != nil {
+		return err
+	}
+	s.server = NewServer(s.db)
+	go func() {
+		if err := s.server.Serve(listener); err != nil {
+			log.Error("Database server failed", "err", err)
+		}
+	}()
+	log.Info("Serving database", "socket", s.path)
+	return nil
+}
+
+// Stop disconnects all clients and removes the socket.
+func (s *Service) Stop() error {
+	if s.server == nil {
+		return nil
+	}
+	return s.server.Close()
+}
@@ -0,0 +1,272 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package netdb
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/luxfi/geth/core/rawdb"
+	"github.com/luxfi/geth/core/types"
+	"github.com/luxfi/geth/ethdb"
+)
+
+// newTestServer serves the given database on a unix socket and returns a
+// client connected to it.
+func newTestServer(t *testing.T, db ethdb.Database) *Database {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "db.ipc")
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := NewServer(db)
+	go server.Serve(listener)
+	t.Cleanup(func() { server.Close() })
+
+	client, err := Dial(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// collect returns all the entries of an iterator.
+func collect(t *testing.T, it ethdb.Iterator) (keys, values [][]byte) {
+	t.Helper()
+
+	defer it.Release()
+	for it.Next() {
+		keys = append(keys, bytes.Clone(it.Key()))
+		values = append(values, bytes.Clone(it.Value()))
+	}
+	if err := it.Error(); err != nil {
+		t.Fatalf("iteration failed: %v", err)
+	}
+	return keys, values
+}
+
+func TestKeyValue(t *testing.T) {
+	db, _ := rawdb.Open(rawdb.NewMemoryDatabase(), rawdb.OpenOptions{})
+	defer db.Close()
+
+	for i := 0; i < 10000; i++ {
+		db.Put([]byte(fmt.Sprintf("key-%05d", i)), bytes.Repeat([]byte{byte(i)}, i%300))
+	}
+	client := newTestServer(t, db)
+
+	if val, err := client.Get([]byte("key-00042")); err != nil || !bytes.Equal(val, bytes.Repeat([]byte{42}, 42)) {
+		t.Fatalf("wrong value: %x, %v", val, err)
+	}
+	if _, err := client.Get([]byte("missing")); err == nil {
+		t.Fatal("expected error for missing key")
+	}
+	if has, err := client.Has([]byte("key-00001")); err != nil || !has {
+		t.Fatalf("key missing: %v", err)
+	}
+	if has, err := client.Has([]byte("missing")); err != nil || has {
+		t.Fatalf("missing key present: %v", err)
+	}
+	values, err := client.BatchGet([][]byte{[]byte("key-00003"), []byte("missing"), []byte("key-00000")})
+	if err != nil {
+		t.Fatalf("batch get failed: %v", err)
+	}
+	if !bytes.Equal(values[0], []byte{3, 3, 3}) || values[1] != nil || values[2] == nil || len(values[2]) != 0 {
+		t.Fatalf("wrong batch values: %x", values)
+	}
+	// Iteration spans multiple pages and matches the local database
+	for _, tt := range []struct{ prefix, start string }{
+		{"", ""}, {"key-", "05"}, {"key-099", ""}, {"missing", ""},
+	} {
+		wantKeys, wantValues := collect(t, db.NewIterator([]byte(tt.prefix), []byte(tt.start)))
+		haveKeys, haveValues := collect(t, client.NewIterator([]byte(tt.prefix), []byte(tt.start)))
+		if len(haveKeys) != len(wantKeys) {
+			t.Fatalf("prefix %q start %q: have %d entries, want %d", tt.prefix, tt.start, len(haveKeys), len(wantKeys))
+		}
+		for i := range wantKeys {
+			if !bytes.Equal(haveKeys[i], wantKeys[i]) || !bytes.Equal(haveValues[i], wantValues[i]) {
+				t.Fatalf("prefix %q start %q: entry %d mismatch", tt.prefix, tt.start, i)
+			}
+		}
+	}
+	// Abandoned iterators are released on the server
+	it := client.NewIterator(nil, nil)
+	it.Next()
+	it.Release()
+
+	if err := client.Put([]byte("key"), nil); err == nil {
+		t.Fatal("write to read-only database succeeded")
+	}
+	if err := client.NewBatch().Write(); err == nil {
+		t.Fatal("batch write to read-only database succeeded")
+	}
+	if _, err := client.Stat(); err != nil {
+		t.Fatalf("failed to retrieve stats: %v", err)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	db, _ := rawdb.Open(rawdb.NewMemoryDatabase(), rawdb.OpenOptions{})
+	defer db.Close()
+
+	db.Put([]byte("a"), []byte("1"))
+	db.Put([]byte("b"), []byte("2"))
+
+	client := newTestServer(t, db)
+	snap, err := client.NewSnapshot()
+	if err != nil {
+		t.Fatalf("failed to create snapshot: %v", err)
+	}
+	db.Put([]byte("a"), []byte("3"))
+	db.Delete([]byte("b"))
+	db.Put([]byte("c"), []byte("4"))
+
+	if val, err := snap.Get([]byte("a")); err != nil || !bytes.Equal(val, []byte("1")) {
+		t.Fatalf("wrong snapshot value: %q, %v", val, err)
+	}
+	if val, err := client.Get([]byte("a")); err != nil || !bytes.Equal(val, []byte("3")) {
+		t.Fatalf("wrong live value: %q, %v", val, err)
+	}
+	if has, _ := snap.Has([]byte("c")); has {
+		t.Fatal("new key visible in snapshot")
+	}
+	keys, _ := collect(t, snap.NewIterator(nil, nil))
+	if len(keys) != 2 || string(keys[0]) != "a" || string(keys[1]) != "b" {
+		t.Fatalf("wrong snapshot keys: %q", keys)
+	}
+	// Iterators are released along with the snapshot
+	it := snap.NewIterator(nil, nil)
+	snap.Release()
+	if it.Next() || it.Error() == nil {
+		t.Fatal("iterator of released snapshot still usable")
+	}
+	it.Release()
+
+	if _, err := snap.Get([]byte("a")); err == nil {
+		t.Fatal("released snapshot still usable")
+	}
+}
+
+func TestAncients(t *testing.T) {
+	db, _ := rawdb.Open(rawdb.NewMemoryDatabase(), rawdb.OpenOptions{})
+	defer db.Close()
+
+	var headers []*types.Header
+	for i := 0; i < 10; i++ {
+		headers = append(headers, &types.Header{Number: big.NewInt(int64(i)), Difficulty: big.NewInt(0)})
+	}
+	if _, err := rawdb.WriteAncientHeaderChain(db, headers); err != nil {
+		t.Fatalf("failed to write ancients: %v", err)
+	}
+	client := newTestServer(t, db)
+
+	if n, err := client.Ancients(); err != nil || n != 10 {
+		t.Fatalf("wrong ancients: %d, %v", n, err)
+	}
+	if tail, err := client.Tail(); err != nil || tail != 0 {
+		t.Fatalf("wrong tail: %d, %v", tail, err)
+	}
+	want, _ := db.Ancient(rawdb.ChainFreezerHeaderTable, 5)
+	if have, err := client.Ancient(rawdb.ChainFreezerHeaderTable, 5); err != nil || !bytes.Equal(have, want) {
+		t.Fatalf("wrong ancient: %x, %v", have, err)
+	}
+	if _, err := client.Ancient(rawdb.ChainFreezerHeaderTable, 10); err == nil {
+		t.Fatal("expected error for missing ancient")
+	}
+	items, err := client.AncientRange(rawdb.ChainFreezerHeaderTable, 2, 20, 0)
+	if err != nil || len(items) != 8 {
+		t.Fatalf("wrong ancient range: %d items, %v", len(items), err)
+	}
+	if header := rawdb.ReadHeader(client, headers[7].Hash(), 7); header == nil || header.Hash() != headers[7].Hash() {
+		t.Fatal("failed to read header through the client")
+	}
+	if size, err := client.AncientSize(rawdb.ChainFreezerHeaderTable); err != nil || size == 0 {
+		t.Fatalf("wrong ancient size: %d, %v", size, err)
+	}
+}
+
+func TestListen(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "sockets")
+	path := filepath.Join(dir, "db.ipc")
+
+	// A stale socket left behind by a crashed server is replaced
+	stale, err := Listen(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*socketListener).Listener.Close()
+
+	listener, err := Listen(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi, err := os.Stat(dir); err != nil {
+		t.Fatal(err)
+	} else if perm := fi.Mode().Perm(); perm != 0700 {
+		t.Errorf("socket directory permissions: have %o, want 700", perm)
+	}
+	if fi, err := os.Stat(path); err != nil {
+		t.Fatal(err)
+	} else if perm := fi.Mode().Perm(); perm != 0600 {
+		t.Errorf("socket permissions: have %o, want 600", perm)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("temporary files left behind: %v", entries)
+	}
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	conn.Close()
+
+	listener.Close()
+	if _, err := os.Lstat(path); !os.IsNotExist(err) {
+		t.Errorf("socket not removed on close: %v", err)
+	}
+	// Regular files are never replaced
+	if err := os.WriteFile(path, []byte("data"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Listen(path); err == nil {
+		t.Error("replaced a regular file")
+	}
+}
+
+func TestService(t *testing.T) {
+	db, _ := rawdb.Open(rawdb.NewMemoryDatabase(), rawdb.OpenOptions{})
+	defer db.Close()
+	db.Put([]byte("key"), []byte("value"))
+
+	path := filepath.Join(t.TempDir(), "db.ipc")
+	service := NewService(db, path)
+	if err := service.Start(); err != nil {
+		t.Fatal(err)
+	}
+	client, err := Dial(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if value, err := client.Get([]byte("key")); err != nil || string(value) != "value" {
+		t.Fatalf("wrong value: %q, %v", value, err)
+	}
+	if err := service.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Lstat(path); !os.IsNotExist(err) {
+		t.Errorf("socket not removed on stop: %v", err)
+	}
+	// The database stays usable for its owner
+	if value, err := db.Get([]byte("key")); err != nil || string(value) != "value" {
+		t.Fatalf("database closed by the service: %q, %v", value, err)
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package netdb implements a read-only database server exposing an
+// ethdb.Database to other processes over a local socket, along with a client
+// implementing ethdb.Database on top of it.
+//
+// The protocol is a simple request-response exchange of binary frames. Every
+// frame is a 4-byte big-endian length followed by a code byte and a payload:
+// the operation for requests, the status for responses. Integers in payloads
+// are uvarints, byte strings are prefixed with their uvarint length.
+//
+// Iterators and snapshots live on the server and are addressed by an id; they
+// are released explicitly by the client, or when the connection they were
+// created on is closed.
+package netdb
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// maxMessageSize is the maximum size of a frame accepted by either side.
+const maxMessageSize = 128 * 1024 * 1024
+
+// iteratorPageBytes is the soft limit of the entry bytes the server returns in
+// a single iterator page.
+const iteratorPageBytes = 1024 * 1024
+
+// Request operations.
+const (
+	opGet          byte = iota + 1 // snapshot, key -> value
+	opHas                          // snapshot, key -> bool
+	opBatchGet                     // snapshot, count, keys -> count, (found, value)s
+	opSnapshot                     // -> snapshot
+	opSnapRelease                  // snapshot ->
+	opIterNew                      // snapshot, prefix, start -> iterator
+	opIterNext                     // iterator, max -> count, (key, value)s, done
+	opIterRelease                  // iterator ->
+	opAncient                      // kind, number -> blob
+	opAncientRange                 // kind, start, count, maxBytes -> count, blobs
+	opAncients                     // -> items
+	opTail                         // -> tail
+	opAncientSize                  // kind -> size
+	opStat                         // -> stats
+)
+
+// Response statuses.
+const (
+	statusOK    byte = iota // payload is the result of the operation
+	statusError             // payload is the error message
+)
+
+var errMessageTooLarge = errors.New("message too large")
+
+// writeFrame writes a single frame to w. The caller is responsible for
+// flushing the writer.
+func writeFrame(w *bufio.Writer, code byte, payload []byte) error {
+	if len(payload)+1 > maxMessageSize {
+		return errMessageTooLarge
+	}
+	var header [5]byte
+	binary.BigEndian.PutUint32(header[:4], uint32(len(payload)+1))
+	header[4] = code
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+// readFrame reads a single frame from r. The returned payload is freshly
+// allocated and owned by the caller.
+func readFrame(r *bufio.Reader) (byte, []byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	size := binary.BigEndian.Uint32(header[:])
+	if size == 0 {
+		return 0, nil, errors.New("empty frame")
+	}
+	if size > maxMessageSize {
+		return 0, nil, errMessageTooLarge
+	}
+	frame := make([]byte, size)
+	if _, err := io.ReadFull(r, frame); err != nil {
+		return 0, nil, err
+	}
+	return frame[0], frame[1:], nil
+}
+
+// encoder assembles the payload of a frame.
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) uint(v uint64) {
+	e.buf = binary.AppendUvarint(e.buf, v)
+}
+
+func (e *encoder) bool(v bool) {
+	if v {
+		e.buf = append(e.buf, 1)
+	} else {
+		e.buf = append(e.buf, 0)
+	}
+}
+
+func (e *encoder) bytes(b []byte) {
+	e.uint(uint64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+func (e *encoder) string(s string) {
+	e.uint(uint64(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+// decoder disassembles the payload of a frame. Decoding errors are sticky,
+// after the first one all further values are zero.
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) fail(err error) {
+	if d.err == nil {
+		d.err = err
+	}
+	d.buf = nil
+}
+
+func (d *decoder) uint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		d.fail(errors.New("invalid integer"))
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return v
+}
+
+func (d *decoder) bool() bool {
+	if d.err != nil {
+		return false
+	}
+	if len(d.buf) == 0 || d.buf[0] > 1 {
+		d.fail(errors.New("invalid boolean"))
+		return false
+	}
+	v := d.buf[0] == 1
+	d.buf = d.buf[1:]
+	return v
+}
+
+// bytes returns the next byte string, sharing the memory of the payload.
+func (d *decoder) bytes() []byte {
+	size := d.uint()
+	if d.err != nil {
+		return nil
+	}
+	if size > uint64(len(d.buf)) {
+		d.fail(fmt.Errorf("byte string too long: %d > %d", size, len(d.buf)))
+		return nil
+	}
+	b := d.buf[:size:size]
+	d.buf = d.buf[size:]
+	return b
+}
+
+func (d *decoder) string() string {
+	return string(d.bytes())
+}
+
+// finish returns the first decoding error, or an error if the payload was not
+// consumed entirely.
+func (d *decoder) finish() error {
+	if d.err != nil {
+		return d.err
+	}
+	if len(d.buf) != 0 {
+		return fmt.Errorf("%d trailing bytes", len(d.buf))
+	}
+	return nil
+}
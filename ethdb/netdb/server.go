@@ -0,0 +1,537 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package netdb
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/luxfi/geth/ethdb"
+	"github.com/luxfi/geth/log"
+)
+
+var (
+	errServerClosed     = errors.New("server closed")
+	errUnknownSnapshot  = errors.New("unknown snapshot")
+	errUnknownIterator  = errors.New("unknown iterator")
+	errNoSnapshots      = errors.New("database does not support snapshots")
+	errResponseTooLarge = errors.New("response too large")
+)
+
+// reader is the part of the database, or of one of its snapshots, that the
+// key-value operations are served from.
+type reader interface {
+	ethdb.KeyValueReader
+	ethdb.Iteratee
+}
+
+// snapshotEntry is a snapshot opened by a client.
+type snapshotEntry struct {
+	lock     sync.RWMutex // held for reading while the snapshot is in use
+	snap     ethdb.Snapshot
+	owner    net.Conn
+	released bool
+}
+
+// iteratorEntry is an iterator opened by a client. Iterators are not safe for
+// concurrent use, every access is serialized by the lock.
+type iteratorEntry struct {
+	lock     sync.Mutex
+	it       ethdb.Iterator
+	snap     uint64 // snapshot the iterator was created on, 0 for the live database
+	owner    net.Conn
+	released bool
+}
+
+// Server serves read-only access to a database over any number of listeners.
+type Server struct {
+	db ethdb.Database
+
+	lock      sync.Mutex
+	nextID    uint64
+	snapshots map[uint64]*snapshotEntry
+	iterators map[uint64]*iteratorEntry
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+// NewServer creates a server for the given database. The database is never
+// written to and is not closed by the server.
+func NewServer(db ethdb.Database) *Server {
+	return &Server{
+		db:        db,
+		snapshots: make(map[uint64]*snapshotEntry),
+		iterators: make(map[uint64]*iteratorEntry),
+		listeners: make(map[net.Listener]struct{}),
+		conns:     make(map[net.Conn]struct{}),
+	}
+}
+
+// Serve accepts connections on the listener and serves them until the listener
+// or the server is closed.
+func (s *Server) Serve(listener net.Listener) error {
+	s.lock.Lock()
+	if s.closed {
+		s.lock.Unlock()
+		return errServerClosed
+	}
+	s.listeners[listener] = struct{}{}
+	s.lock.Unlock()
+
+	defer func() {
+		s.lock.Lock()
+		delete(s.listeners, listener)
+		s.lock.Unlock()
+	}()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			s.lock.Lock()
+			closed := s.closed
+			s.lock.Unlock()
+			if closed || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		s.lock.Lock()
+		if s.closed {
+			s.lock.Unlock()
+			conn.Close()
+			return nil
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.lock.Unlock()
+
+		go s.serveConn(conn)
+	}
+}
+
+// Close stops all listeners, disconnects the clients and releases all
+// resources they still hold.
+func (s *Server) Close() error {
+	s.lock.Lock()
+	if s.closed {
+		s.lock.Unlock()
+		return nil
+	}
+	s.closed = true
+	for listener := range s.listeners {
+		listener.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.lock.Unlock()
+
+	// The connections release their own resources before terminating
+	s.wg.Wait()
+	return nil
+}
+
+// serveConn processes the requests of a single connection sequentially.
+func (s *Server) serveConn(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		conn.Close()
+		s.releaseOwned(conn)
+
+		s.lock.Lock()
+		delete(s.conns, conn)
+		s.lock.Unlock()
+	}()
+	var (
+		r = bufio.NewReader(conn)
+		w = bufio.NewWriter(conn)
+	)
+	for {
+		op, payload, err := readFrame(r)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				log.Debug("Database client disconnected", "err", err)
+			}
+			return
+		}
+		status, result := statusOK, []byte(nil)
+		if result, err = s.handle(conn, op, payload); err == nil && len(result)+1 > maxMessageSize {
+			err = errResponseTooLarge
+		}
+		if err != nil {
+			status, result = statusError, []byte(err.Error())
+		}
+		if err := writeFrame(w, status, result); err != nil {
+			return
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// handle executes a single request.
+func (s *Server) handle(conn net.Conn, op byte, payload []byte) ([]byte, error) {
+	var (
+		d   = &decoder{buf: payload}
+		out = new(encoder)
+	)
+	switch op {
+	case opGet:
+		id, key := d.uint(), d.bytes()
+		if err := d.finish(); err != nil {
+			return nil, err
+		}
+		err := s.withReader(id, func(db reader) error {
+			value, err := db.Get(key)
+			out.buf = value
+			return err
+		})
+		return out.buf, err
+
+	case opHas:
+		id, key := d.uint(), d.bytes()
+		if err := d.finish(); err != nil {
+			return nil, err
+		}
+		err := s.withReader(id, func(db reader) error {
+			has, err := db.Has(key)
+			out.bool(has)
+			return err
+		})
+		return out.buf, err
+
+	case opBatchGet:
+		id, count := d.uint(), d.uint()
+		if count > uint64(len(d.buf)) {
+			return nil, fmt.Errorf("invalid key count %d", count)
+		}
+		keys := make([][]byte, count)
+		for i := range keys {
+			keys[i] = d.bytes()
+		}
+		if err := d.finish(); err != nil {
+			return nil, err
+		}
+		err := s.withReader(id, func(db reader) error {
+			out.uint(count)
+			for _, key := range keys {
+				// Lookup failures are reported as missing keys, the stores
+				// don't distinguish them from other errors either.
+				value, err := db.Get(key)
+				out.bool(err == nil)
+				out.bytes(value)
+			}
+			return nil
+		})
+		return out.buf, err
+
+	case opSnapshot:
+		if err := d.finish(); err != nil {
+			return nil, err
+		}
+		id, err := s.newSnapshot(conn)
+		if err != nil {
+			return nil, err
+		}
+		out.uint(id)
+		return out.buf, nil
+
+	case opSnapRelease:
+		id := d.uint()
+		if err := d.finish(); err != nil {
+			return nil, err
+		}
+		return nil, s.releaseSnapshot(id)
+
+	case opIterNew:
+		id, prefix, start := d.uint(), d.bytes(), d.bytes()
+		if err := d.finish(); err != nil {
+			return nil, err
+		}
+		iter, err := s.newIterator(conn, id, prefix, start)
+		if err != nil {
+			return nil, err
+		}
+		out.uint(iter)
+		return out.buf, nil
+
+	case opIterNext:
+		id, limit := d.uint(), d.uint()
+		if err := d.finish(); err != nil {
+			return nil, err
+		}
+		return s.nextPage(id, limit)
+
+	case opIterRelease:
+		id := d.uint()
+		if err := d.finish(); err != nil {
+			return nil, err
+		}
+		return nil, s.releaseIterator(id)
+
+	case opAncient:
+		kind, number := d.string(), d.uint()
+		if err := d.finish(); err != nil {
+			return nil, err
+		}
+		return s.db.Ancient(kind, number)
+
+	case opAncientRange:
+		kind, start, count, maxBytes := d.string(), d.uint(), d.uint(), d.uint()
+		if err := d.finish(); err != nil {
+			return nil, err
+		}
+		items, err := s.db.AncientRange(kind, start, count, maxBytes)
+		if err != nil {
+			return nil, err
+		}
+		out.uint(uint64(len(items)))
+		for _, item := range items {
+			out.bytes(item)
+		}
+		return out.buf, nil
+
+	case opAncients, opTail:
+		if err := d.finish(); err != nil {
+			return nil, err
+		}
+		var (
+			n   uint64
+			err error
+		)
+		if op == opAncients {
+			n, err = s.db.Ancients()
+		} else {
+			n, err = s.db.Tail()
+		}
+		if err != nil {
+			return nil, err
+		}
+		out.uint(n)
+		return out.buf, nil
+
+	case opAncientSize:
+		kind := d.string()
+		if err := d.finish(); err != nil {
+			return nil, err
+		}
+		size, err := s.db.AncientSize(kind)
+		if err != nil {
+			return nil, err
+		}
+		out.uint(size)
+		return out.buf, nil
+
+	case opStat:
+		if err := d.finish(); err != nil {
+			return nil, err
+		}
+		stats, err := s.db.Stat()
+		if err != nil {
+			return nil, err
+		}
+		return []byte(stats), nil
+
+	default:
+		return nil, fmt.Errorf("unknown operation %d", op)
+	}
+}
+
+// withReader runs fn on the live database if id is zero, or on the snapshot
+// with the given id otherwise, which is kept alive until fn returns.
+func (s *Server) withReader(id uint64, fn func(db reader) error) error {
+	if id == 0 {
+		return fn(s.db)
+	}
+	s.lock.Lock()
+	entry := s.snapshots[id]
+	s.lock.Unlock()
+	if entry == nil {
+		return errUnknownSnapshot
+	}
+	entry.lock.RLock()
+	defer entry.lock.RUnlock()
+	if entry.released {
+		return errUnknownSnapshot
+	}
+	return fn(entry.snap)
+}
+
+// newSnapshot creates a snapshot of the live database owned by conn.
+func (s *Server) newSnapshot(conn net.Conn) (uint64, error) {
+	snapshotter, ok := s.db.(ethdb.Snapshotter)
+	if !ok {
+		return 0, errNoSnapshots
+	}
+	snap, err := snapshotter.NewSnapshot()
+	if err != nil {
+		return 0, err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.nextID++
+	s.snapshots[s.nextID] = &snapshotEntry{snap: snap, owner: conn}
+	return s.nextID, nil
+}
+
+// releaseSnapshot releases a snapshot along with all iterators created on it.
+func (s *Server) releaseSnapshot(id uint64) error {
+	s.lock.Lock()
+	entry := s.snapshots[id]
+	if entry == nil {
+		s.lock.Unlock()
+		return errUnknownSnapshot
+	}
+	delete(s.snapshots, id)
+
+	var iterators []*iteratorEntry
+	for iterID, iter := range s.iterators {
+		if iter.snap == id {
+			iterators = append(iterators, iter)
+			delete(s.iterators, iterID)
+		}
+	}
+	s.lock.Unlock()
+
+	for _, iter := range iterators {
+		iter.release()
+	}
+	entry.lock.Lock()
+	defer entry.lock.Unlock()
+	if !entry.released {
+		entry.released = true
+		entry.snap.Release()
+	}
+	return nil
+}
+
+// newIterator creates an iterator owned by conn on the live database or on a
+// snapshot.
+func (s *Server) newIterator(conn net.Conn, snap uint64, prefix, start []byte) (uint64, error) {
+	var (
+		id    uint64
+		entry = &iteratorEntry{snap: snap, owner: conn}
+	)
+	err := s.withReader(snap, func(db reader) error {
+		entry.it = db.NewIterator(prefix, start)
+
+		// Register the iterator while the snapshot is still pinned, so that
+		// a concurrent release of the snapshot releases it too.
+		s.lock.Lock()
+		defer s.lock.Unlock()
+
+		s.nextID++
+		id = s.nextID
+		s.iterators[id] = entry
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// nextPage returns up to limit entries of an iterator, bounded in size by
+// iteratorPageBytes. Exhausted iterators are released.
+func (s *Server) nextPage(id uint64, limit uint64) ([]byte, error) {
+	s.lock.Lock()
+	entry := s.iterators[id]
+	s.lock.Unlock()
+	if entry == nil {
+		return nil, errUnknownIterator
+	}
+	entry.lock.Lock()
+	if entry.released {
+		entry.lock.Unlock()
+		return nil, errUnknownIterator
+	}
+	var (
+		page  = new(encoder)
+		count uint64
+		size  int
+		done  bool
+	)
+	for count < limit && size < iteratorPageBytes {
+		if !entry.it.Next() {
+			done = true
+			break
+		}
+		page.bytes(entry.it.Key())
+		page.bytes(entry.it.Value())
+		count++
+		size += len(entry.it.Key()) + len(entry.it.Value())
+	}
+	err := entry.it.Error()
+	entry.lock.Unlock()
+
+	if err != nil || done {
+		s.releaseIterator(id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	out := new(encoder)
+	out.uint(count)
+	out.buf = append(out.buf, page.buf...)
+	out.bool(done)
+	return out.buf, nil
+}
+
+// releaseIterator releases the iterator with the given id.
+func (s *Server) releaseIterator(id uint64) error {
+	s.lock.Lock()
+	entry := s.iterators[id]
+	delete(s.iterators, id)
+	s.lock.Unlock()
+
+	if entry == nil {
+		return errUnknownIterator
+	}
+	entry.release()
+	return nil
+}
+
+// release releases the underlying iterator if not done yet.
+func (e *iteratorEntry) release() {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if !e.released {
+		e.released = true
+		e.it.Release()
+	}
+}
+
+// releaseOwned releases all iterators and snapshots created on a connection.
+func (s *Server) releaseOwned(conn net.Conn) {
+	var iterators, snapshots []uint64
+
+	s.lock.Lock()
+	for id, iter := range s.iterators {
+		if iter.owner == conn {
+			iterators = append(iterators, id)
+		}
+	}
+	for id, snap := range s.snapshots {
+		if snap.owner == conn {
+			snapshots = append(snapshots, id)
+		}
+	}
+	s.lock.Unlock()
+
+	// Either may have been released concurrently meanwhile, ignore the errors
+	for _, id := range iterators {
+		s.releaseIterator(id)
+	}
+	for _, id := range snapshots {
+		s.releaseSnapshot(id)
+	}
+}
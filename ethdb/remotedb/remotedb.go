@@ -15,18 +15,28 @@
 // along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
 
 // Package remotedb implements the key-value database layer based on a remote geth
-// node. Under the hood, it utilises the `debug_dbGet` method to implement a
-// read-only database.
+// node. Under the hood, it utilises the `debug_dbGet` and `debug_dbIterate`
+// methods to implement a read-only database.
 // There really are no guarantees in this database, since the local geth does not
 // exclusive access, but it can be used for basic diagnostics of a remote node.
 package remotedb
 
 import (
+	"errors"
+
 	"github.com/luxfi/geth/common/hexutil"
 	"github.com/luxfi/geth/ethdb"
 	"github.com/luxfi/geth/rpc"
 )
 
+// iteratorPageSize is the number of entries requested from the remote node at
+// once by iterators.
+const iteratorPageSize = 4096
+
+// errNotSupported is returned for operations requiring local access to the
+// remote database.
+var errNotSupported = errors.New("not supported by remote database")
+
 // Database is a key-value lookup for a remote database via debug_dbGet.
 type Database struct {
 	remote *rpc.Client
@@ -68,11 +78,15 @@ func (db *Database) Ancients() (uint64, error) {
 }
 
 func (db *Database) Tail() (uint64, error) {
-	panic("not supported")
+	var resp uint64
+	err := db.remote.Call(&resp, "debug_dbTail")
+	return resp, err
 }
 
 func (db *Database) AncientSize(kind string) (uint64, error) {
-	panic("not supported")
+	var resp uint64
+	err := db.remote.Call(&resp, "debug_dbAncientSize", kind)
+	return resp, err
 }
 
 func (db *Database) ReadAncients(fn func(op ethdb.AncientReaderOp) error) (err error) {
@@ -116,15 +130,21 @@ func (db *Database) NewBatchWithSize(size int) ethdb.Batch {
 }
 
 func (db *Database) NewIterator(prefix []byte, start []byte) ethdb.Iterator {
-	panic("not supported")
+	return &iterator{
+		db:     db,
+		prefix: prefix,
+		next:   start,
+	}
 }
 
 func (db *Database) Stat() (string, error) {
-	return "", nil
+	var resp string
+	err := db.remote.Call(&resp, "debug_dbStat")
+	return resp, err
 }
 
 func (db *Database) AncientDatadir() (string, error) {
-	panic("not supported")
+	return "", errNotSupported
 }
 
 func (db *Database) Compact(start []byte, limit []byte) error {
@@ -146,3 +166,84 @@ func New(client *rpc.Client) ethdb.Database {
 	}
 	return &Database{remote: client}
 }
+
+// iteratorPage is a page of database entries returned by debug_dbIterate.
+type iteratorPage struct {
+	Keys   []hexutil.Bytes `json:"keys"`
+	Values []hexutil.Bytes `json:"values"`
+	Next   *hexutil.Bytes  `json:"next"`
+}
+
+// iterator iterates over the entries of a remote database with a given key
+// prefix, fetching them page by page through debug_dbIterate. Entries changed
+// in the remote database during the iteration may or may not be visible.
+type iterator struct {
+	db     *Database
+	prefix []byte
+	next   []byte // Start of the next page, relative to the prefix
+	done   bool   // Flag whether the last page was fetched
+
+	page *iteratorPage
+	pos  int
+	err  error
+}
+
+// Next moves the iterator to the next key/value pair, fetching the next page
+// from the remote node if needed. It returns whether the iterator is exhausted.
+func (it *iterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if it.page != nil && it.pos+1 < len(it.page.Keys) {
+		it.pos++
+		return true
+	}
+	for !it.done {
+		var page iteratorPage
+		if err := it.db.remote.Call(&page, "debug_dbIterate", hexutil.Bytes(it.prefix), hexutil.Bytes(it.next), iteratorPageSize); err != nil {
+			it.err = err
+			return false
+		}
+		if len(page.Keys) != len(page.Values) {
+			it.err = errors.New("invalid page returned by remote database")
+			return false
+		}
+		if page.Next == nil {
+			it.done = true
+		} else {
+			it.next = *page.Next
+		}
+		if len(page.Keys) > 0 {
+			it.page, it.pos = &page, 0
+			return true
+		}
+	}
+	it.page = nil
+	return false
+}
+
+// Error returns any accumulated error.
+func (it *iterator) Error() error {
+	return it.err
+}
+
+// Key returns the key of the current key/value pair, or nil if done.
+func (it *iterator) Key() []byte {
+	if it.page == nil {
+		return nil
+	}
+	return it.page.Keys[it.pos]
+}
+
+// Value returns the value of the current key/value pair, or nil if done.
+func (it *iterator) Value() []byte {
+	if it.page == nil {
+		return nil
+	}
+	return it.page.Values[it.pos]
+}
+
+// Release releases associated resources.
+func (it *iterator) Release() {
+	it.page, it.done = nil, true
+}
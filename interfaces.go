@@ -127,6 +127,9 @@ type SyncProgress struct {
 
 	// "historical state indexing" fields
 	StateIndexRemaining uint64 // Number of states remain unindexed
+
+	// "chain history" fields
+	EarliestBlock uint64 // Earliest block whose bodies and receipts are available
 }
 
 // Done returns the indicator if the initial sync is finished or not.
@@ -35,6 +35,7 @@ import (
 	"github.com/luxfi/geth/consensus"
 	"github.com/luxfi/geth/consensus/misc/eip1559"
 	"github.com/luxfi/geth/core"
+	"github.com/luxfi/geth/core/rawdb"
 	"github.com/luxfi/geth/core/state"
 	"github.com/luxfi/geth/core/types"
 	"github.com/luxfi/geth/core/vm"
@@ -47,6 +48,7 @@ import (
 	"github.com/luxfi/geth/rlp"
 	"github.com/luxfi/geth/rpc"
 	"github.com/luxfi/geth/trie"
+	"github.com/luxfi/geth/triedb/database"
 )
 
 // estimateGasErrorRatio is the amount of overestimation eth_estimateGas is
@@ -171,9 +173,19 @@ func (api *EthereumAPI) Syncing(ctx context.Context) (interface{}, error) {
 		"txIndexFinishedBlocks":  hexutil.Uint64(progress.TxIndexFinishedBlocks),
 		"txIndexRemainingBlocks": hexutil.Uint64(progress.TxIndexRemainingBlocks),
 		"stateIndexRemaining":    hexutil.Uint64(progress.StateIndexRemaining),
+		"earliestBlock":          hexutil.Uint64(progress.EarliestBlock),
 	}, nil
 }
 
+// HistoryRange returns the range of blocks whose bodies and receipts are
+// available locally. Blocks before the earliest one may have been pruned.
+func (api *EthereumAPI) HistoryRange() map[string]hexutil.Uint64 {
+	return map[string]hexutil.Uint64{
+		"earliestBlock": hexutil.Uint64(api.b.HistoryPruningCutoff()),
+		"latestBlock":   hexutil.Uint64(api.b.CurrentBlock().Number.Uint64()),
+	}
+}
+
 // TxPoolAPI offers and API for the transaction pool. It only operates on data that is non-confidential.
 type TxPoolAPI struct {
 	b Backend
@@ -384,13 +396,22 @@ func (api *BlockChainAPI) GetProof(ctx context.Context, address common.Address,
 	codeHash := statedb.GetCodeHash(address)
 	storageRoot := statedb.GetStorageRoot(address)
 
+	// The tries of historic states are no longer persisted by the path-based
+	// database, rebuild them from the state histories if allowed.
+	tdb := statedb.Database().TrieDB()
+	var nodes database.NodeDatabase = tdb
+	if _, err := tdb.NodeReader(header.Root); err != nil && tdb.Scheme() == rawdb.PathScheme {
+		if nodes, err = tdb.HistoricNodes(header.Root); err != nil {
+			return nil, err
+		}
+	}
 	if len(keys) > 0 {
 		var storageTrie state.Trie
 		if storageRoot != types.EmptyRootHash && storageRoot != (common.Hash{}) {
 			cryptoHash := crypto.Keccak256Hash(address.Bytes())
 			addressHash := common.Hash(cryptoHash)
 			id := trie.StorageTrieID(header.Root, addressHash, storageRoot)
-			st, err := trie.NewStateTrie(id, statedb.Database().TrieDB())
+			st, err := trie.NewStateTrie(id, nodes)
 			if err != nil {
 				return nil, err
 			}
@@ -421,7 +442,7 @@ func (api *BlockChainAPI) GetProof(ctx context.Context, address common.Address,
 		}
 	}
 	// Create the accountProof.
-	tr, err := trie.NewStateTrie(trie.StateTrieID(header.Root), statedb.Database().TrieDB())
+	tr, err := trie.NewStateTrie(trie.StateTrieID(header.Root), nodes)
 	if err != nil {
 		return nil, err
 	}
@@ -969,6 +990,9 @@ type RPCTransaction struct {
 	R                   *hexutil.Big                 `json:"r"`
 	S                   *hexutil.Big                 `json:"s"`
 	YParity             *hexutil.Uint64              `json:"yParity,omitempty"`
+	PQAlgorithm         *hexutil.Uint64              `json:"pqAlgorithm,omitempty"`
+	PQPublicKey         hexutil.Bytes                `json:"pqPublicKey,omitempty"`
+	PQSignature         hexutil.Bytes                `json:"pqSignature,omitempty"`
 }
 
 // newRPCTransaction returns a transaction that will serialize to the RPC
@@ -1059,6 +1083,26 @@ func newRPCTransaction(tx *types.Transaction, blockHash common.Hash, blockNumber
 			result.GasPrice = (*hexutil.Big)(tx.GasFeeCap())
 		}
 		result.AuthorizationList = tx.SetCodeAuthorizations()
+
+	case types.PQTxType:
+		al := tx.AccessList()
+		result.Accesses = &al
+		result.ChainID = (*hexutil.Big)(tx.ChainId())
+		result.GasFeeCap = (*hexutil.Big)(tx.GasFeeCap())
+		result.GasTipCap = (*hexutil.Big)(tx.GasTipCap())
+		// if the transaction has been mined, compute the effective gas price
+		if baseFee != nil && blockHash != (common.Hash{}) {
+			result.GasPrice = (*hexutil.Big)(effectiveGasPrice(tx, baseFee))
+		} else {
+			result.GasPrice = (*hexutil.Big)(tx.GasFeeCap())
+		}
+		// PQ transactions carry no secp256k1 signature values
+		result.V, result.R, result.S = nil, nil, nil
+		algo, pub, sig := tx.PQSignature()
+		result.PQAlgorithm = (*hexutil.Uint64)(new(uint64))
+		*result.PQAlgorithm = hexutil.Uint64(algo)
+		result.PQPublicKey = pub
+		result.PQSignature = sig
 	}
 	return result
 }
@@ -53,6 +53,7 @@ import (
 	"github.com/luxfi/geth/core/vm"
 	"github.com/luxfi/crypto"
 	"github.com/luxfi/crypto/kzg4844"
+	"github.com/luxfi/geth/crypto/pqcrypto"
 	"github.com/luxfi/geth/ethdb"
 	"github.com/luxfi/geth/event"
 	"github.com/luxfi/geth/internal/blocktest"
@@ -898,6 +899,28 @@ func TestEstimateGas(t *testing.T) {
 			},
 			expectErr: core.ErrSetCodeTxCreate,
 		},
+		// PQ transfer, the public key and signature are charged as intrinsic gas
+		{
+			blockNumber: rpc.LatestBlockNumber,
+			call: TransactionArgs{
+				From:        &accounts[0].addr,
+				To:          &accounts[1].addr,
+				Value:       (*hexutil.Big)(big.NewInt(1000)),
+				PQAlgorithm: newUint64(uint64(pqcrypto.AlgoMLDSA65)),
+			},
+			want: params.TxGas + uint64(pqcrypto.PublicKeySize(pqcrypto.AlgoMLDSA65)+pqcrypto.SignatureSize(pqcrypto.AlgoMLDSA65))*params.TxPQSignatureByteGas,
+		},
+		// PQ transfer with an algorithm not accepted in transactions should fail.
+		{
+			blockNumber: rpc.LatestBlockNumber,
+			call: TransactionArgs{
+				From:        &accounts[0].addr,
+				To:          &accounts[1].addr,
+				Value:       (*hexutil.Big)(big.NewInt(1000)),
+				PQAlgorithm: newUint64(uint64(pqcrypto.AlgoSLHDSA128f)),
+			},
+			expectErr: types.ErrPQAlgorithmNotSupported,
+		},
 	}
 	for i, tc := range testSuite {
 		result, err := api.EstimateGas(context.Background(), tc.call, &rpc.BlockNumberOrHash{BlockNumber: &tc.blockNumber}, &tc.overrides, &tc.blockOverrides)
@@ -917,7 +940,7 @@ func TestEstimateGas(t *testing.T) {
 			t.Errorf("test %d: want no error, have %v", i, err)
 			continue
 		}
-		if float64(result) > float64(tc.want)*(1+estimateGasErrorRatio) {
+		if uint64(result) < tc.want || float64(result) > float64(tc.want)*(1+estimateGasErrorRatio) {
 			t.Errorf("test %d, result mismatch, have\n%v\n, want\n%v\n", i, uint64(result), tc.want)
 		}
 	}
@@ -21,6 +21,16 @@ import (
 	"github.com/luxfi/geth/common/hexutil"
 )
 
+const (
+	// maxDbIterateItems is the maximum number of entries returned by a single
+	// DbIterate call.
+	maxDbIterateItems = 10000
+
+	// maxDbIterateBytes is the soft limit of the total size of the entries
+	// returned by a single DbIterate call.
+	maxDbIterateBytes = 4 * 1024 * 1024
+)
+
 // DbGet returns the raw value of a key stored in the database.
 func (api *DebugAPI) DbGet(key string) (hexutil.Bytes, error) {
 	blob, err := common.ParseHexOrString(key)
@@ -41,3 +51,69 @@ func (api *DebugAPI) DbAncient(kind string, number uint64) (hexutil.Bytes, error
 func (api *DebugAPI) DbAncients() (uint64, error) {
 	return api.b.ChainDb().Ancients()
 }
+
+// DbAncientSize returns the size of the specified category in the ancient store.
+// It is a mapping to the `AncientReaderOp.AncientSize` method
+func (api *DebugAPI) DbAncientSize(kind string) (uint64, error) {
+	return api.b.ChainDb().AncientSize(kind)
+}
+
+// DbTail returns the number of the first stored item in the ancient store.
+// It is a mapping to the `AncientReaderOp.Tail` method
+func (api *DebugAPI) DbTail() (uint64, error) {
+	return api.b.ChainDb().Tail()
+}
+
+// DbStat returns the statistics of the key-value store.
+// It is a mapping to the `KeyValueStater.Stat` method
+func (api *DebugAPI) DbStat() (string, error) {
+	return api.b.ChainDb().Stat()
+}
+
+// DbIteratorPage is a page of database entries in ascending key order.
+type DbIteratorPage struct {
+	Keys   []hexutil.Bytes `json:"keys"`
+	Values []hexutil.Bytes `json:"values"`
+	Next   *hexutil.Bytes  `json:"next"` // Start of the next page, relative to the prefix; nil if exhausted
+}
+
+// DbIterate returns a page of at most limit entries of the database with the
+// given key prefix, starting at the given key relative to the prefix. The page
+// is also cut short if its size exceeds a few megabytes.
+func (api *DebugAPI) DbIterate(prefix string, start string, limit int) (*DbIteratorPage, error) {
+	var (
+		pre, from []byte
+		err       error
+	)
+	if prefix != "" {
+		if pre, err = common.ParseHexOrString(prefix); err != nil {
+			return nil, err
+		}
+	}
+	if start != "" {
+		if from, err = common.ParseHexOrString(start); err != nil {
+			return nil, err
+		}
+	}
+	if limit <= 0 || limit > maxDbIterateItems {
+		limit = maxDbIterateItems
+	}
+	it := api.b.ChainDb().NewIterator(pre, from)
+	defer it.Release()
+
+	var (
+		page = &DbIteratorPage{Keys: []hexutil.Bytes{}, Values: []hexutil.Bytes{}}
+		size int
+	)
+	for it.Next() {
+		if len(page.Keys) >= limit || size >= maxDbIterateBytes {
+			next := hexutil.Bytes(common.CopyBytes(it.Key()[len(pre):]))
+			page.Next = &next
+			break
+		}
+		page.Keys = append(page.Keys, common.CopyBytes(it.Key()))
+		page.Values = append(page.Values, common.CopyBytes(it.Value()))
+		size += len(it.Key()) + len(it.Value())
+	}
+	return page, it.Error()
+}
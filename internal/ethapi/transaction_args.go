@@ -30,6 +30,7 @@ import (
 	"github.com/luxfi/geth/consensus/misc/eip4844"
 	"github.com/luxfi/geth/core"
 	"github.com/luxfi/geth/core/types"
+	"github.com/luxfi/geth/crypto/pqcrypto"
 	"github.com/luxfi/crypto/kzg4844"
 	"github.com/luxfi/geth/log"
 	"github.com/luxfi/geth/params"
@@ -71,6 +72,11 @@ type TransactionArgs struct {
 	// For SetCodeTxType
 	AuthorizationList []types.SetCodeAuthorization `json:"authorizationList"`
 
+	// For PQTxType, the signature algorithm of the sender. The public key and
+	// signature sizes it implies are charged as intrinsic gas in calls and gas
+	// estimations.
+	PQAlgorithm *hexutil.Uint64 `json:"pqAlgorithm,omitempty"`
+
 	// This configures whether blobs are allowed to be passed.
 	blobSidecarAllowed bool
 }
@@ -406,6 +412,9 @@ func (args *TransactionArgs) CallDefaults(globalGasCap uint64, baseFee *big.Int,
 	if args.BlobFeeCap == nil && args.BlobHashes != nil {
 		args.BlobFeeCap = new(hexutil.Big)
 	}
+	if args.PQAlgorithm != nil && (*args.PQAlgorithm > 0xff || !types.IsPQAlgorithmSupported(pqcrypto.Algorithm(*args.PQAlgorithm))) {
+		return fmt.Errorf("%w: %d", types.ErrPQAlgorithmNotSupported, uint64(*args.PQAlgorithm))
+	}
 
 	return nil
 }
@@ -447,6 +456,11 @@ func (args *TransactionArgs) ToMessage(baseFee *big.Int, skipNonceCheck, skipEoA
 	if args.AccessList != nil {
 		accessList = *args.AccessList
 	}
+	var pqSignatureSize uint64
+	if args.PQAlgorithm != nil {
+		algo := pqcrypto.Algorithm(*args.PQAlgorithm)
+		pqSignatureSize = uint64(pqcrypto.PublicKeySize(algo) + pqcrypto.SignatureSize(algo))
+	}
 	return &core.Message{
 		From:                  args.from(),
 		To:                    args.To,
@@ -461,6 +475,7 @@ func (args *TransactionArgs) ToMessage(baseFee *big.Int, skipNonceCheck, skipEoA
 		BlobGasFeeCap:         (*big.Int)(args.BlobFeeCap),
 		BlobHashes:            args.BlobHashes,
 		SetCodeAuthorizations: args.AuthorizationList,
+		PQSignatureSize:       pqSignatureSize,
 		SkipNonceChecks:       skipNonceCheck,
 		SkipFromEOACheck:      skipEoACheck,
 	}
@@ -121,6 +121,11 @@ web3._extend({
 			call: 'admin_importChain',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'backupDatabase',
+			call: 'admin_backupDatabase',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'sleepBlocks',
 			call: 'admin_sleepBlocks',
@@ -420,6 +425,36 @@ web3._extend({
 			call: 'debug_storageRangeAt',
 			params: 5,
 		}),
+		new web3._extend.Method({
+			name: 'accountHistory',
+			call: 'debug_accountHistory',
+			params: 3,
+			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter],
+		}),
+		new web3._extend.Method({
+			name: 'storageHistory',
+			call: 'debug_storageHistory',
+			params: 4,
+			inputFormatter: [null, null, web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter],
+		}),
+		new web3._extend.Method({
+			name: 'stateDiff',
+			call: 'debug_stateDiff',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter, null],
+		}),
+		new web3._extend.Method({
+			name: 'executionWitness',
+			call: 'debug_executionWitness',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter],
+		}),
+		new web3._extend.Method({
+			name: 'executionWitnessRLP',
+			call: 'debug_executionWitnessRLP',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter],
+		}),
 		new web3._extend.Method({
 			name: 'getModifiedAccountsByNumber',
 			call: 'debug_getModifiedAccountsByNumber',
@@ -458,6 +493,26 @@ web3._extend({
 			call: 'debug_dbAncients',
 			params: 0
 		}),
+		new web3._extend.Method({
+			name: 'dbAncientSize',
+			call: 'debug_dbAncientSize',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'dbTail',
+			call: 'debug_dbTail',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'dbStat',
+			call: 'debug_dbStat',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'dbIterate',
+			call: 'debug_dbIterate',
+			params: 3
+		}),
 		new web3._extend.Method({
 			name: 'setTrieFlushInterval',
 			call: 'debug_setTrieFlushInterval',
@@ -491,7 +546,7 @@ web3._extend({
 			name: 'sign',
 			call: 'eth_sign',
 			params: 2,
-			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null]
+			inputFormatter: [null, null]
 		}),
 		new web3._extend.Method({
 			name: 'resend',
@@ -512,6 +567,11 @@ web3._extend({
 			inputFormatter: [web3._extend.formatters.inputCallFormatter, web3._extend.formatters.inputBlockNumberFormatter, null, null],
 			outputFormatter: web3._extend.utils.toDecimal
 		}),
+		new web3._extend.Method({
+			name: 'historyRange',
+			call: 'eth_historyRange',
+			params: 0
+		}),
 		new web3._extend.Method({
 			name: 'submitTransaction',
 			call: 'eth_submitTransaction',
@@ -564,7 +624,7 @@ web3._extend({
 			name: 'getProof',
 			call: 'eth_getProof',
 			params: 3,
-			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null, web3._extend.formatters.inputBlockNumberFormatter]
+			inputFormatter: [null, null, web3._extend.formatters.inputBlockNumberFormatter]
 		}),
 		new web3._extend.Method({
 			name: 'createAccessList',
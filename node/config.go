@@ -211,6 +211,15 @@ type Config struct {
 	EnablePersonal bool `toml:"-"`
 
 	DBEngine string `toml:",omitempty"`
+
+	// DBEncryptionKey is the master key the databases are encrypted with at
+	// rest. It is never written to the config file. Encryption is disabled if
+	// the key is nil.
+	DBEncryptionKey []byte `toml:"-"`
+
+	// DBEncryptKeys enables the encryption of keys in addition to values when
+	// creating a new encrypted database.
+	DBEncryptKeys bool `toml:",omitempty"`
 }
 
 // IPCEndpoint resolves an IPC endpoint based on a configured value, taking into
@@ -21,6 +21,8 @@ import (
 
 	"github.com/luxfi/geth/core/rawdb"
 	"github.com/luxfi/geth/ethdb"
+	"github.com/luxfi/geth/ethdb/badgerdb"
+	"github.com/luxfi/geth/ethdb/encdb"
 	"github.com/luxfi/geth/ethdb/leveldb"
 	"github.com/luxfi/geth/ethdb/pebble"
 	"github.com/luxfi/geth/log"
@@ -39,15 +41,18 @@ type DatabaseOptions struct {
 	Cache            int    // the capacity(in megabytes) of the data caching
 	Handles          int    // number of files to be open simultaneously
 	ReadOnly         bool   // if true, no writes can be performed
+
+	EncryptionKey []byte // master key to encrypt the data at rest with, if set
+	EncryptKeys   bool   // whether keys of a new encrypted database are encrypted too
 }
 
 type internalOpenOptions struct {
 	directory string
-	dbEngine  string // "leveldb" | "pebble"
+	dbEngine  string // "leveldb" | "pebble" | "badger"
 	DatabaseOptions
 }
 
-// openDatabase opens both a disk-based key-value database such as leveldb or pebble, but also
+// openDatabase opens both a disk-based key-value database such as leveldb, pebble or badger, but also
 // integrates it with a freezer database -- if the AncientDir option has been
 // set on the provided OpenOptions.
 // The passed o.AncientDir indicates the path of root ancient directory where
@@ -63,6 +68,12 @@ func openDatabase(o internalOpenOptions) (ethdb.Database, error) {
 		MetricsNamespace: o.MetricsNamespace,
 		ReadOnly:         o.ReadOnly,
 	}
+	if o.EncryptionKey != nil {
+		if opts.Cipher, err = encdb.FreezerCipher(o.EncryptionKey); err != nil {
+			kvdb.Close()
+			return nil, err
+		}
+	}
 	frdb, err := rawdb.Open(kvdb, opts)
 	if err != nil {
 		kvdb.Close()
@@ -71,15 +82,50 @@ func openDatabase(o internalOpenOptions) (ethdb.Database, error) {
 	return frdb, nil
 }
 
-// openKeyValueDatabase opens a disk-based key-value database, e.g. leveldb or pebble.
+// OpenKeyValueDatabase opens the disk-based key-value database in directory
+// with the given engine, without a freezer. An empty engine selects the engine
+// of a pre-existing database, or pebble if there is none.
+func OpenKeyValueDatabase(directory string, engine string, opt DatabaseOptions) (ethdb.KeyValueStore, error) {
+	return openKeyValueDatabase(internalOpenOptions{
+		directory:       directory,
+		dbEngine:        engine,
+		DatabaseOptions: opt,
+	})
+}
+
+// openKeyValueDatabase opens a disk-based key-value database, e.g. leveldb, pebble
+// or badger, and wraps it for encryption at rest if an encryption key is set.
+func openKeyValueDatabase(o internalOpenOptions) (ethdb.KeyValueStore, error) {
+	db, err := openRawKeyValueDatabase(o)
+	if err != nil {
+		return nil, err
+	}
+	if o.EncryptionKey == nil {
+		if encdb.IsEncrypted(db) {
+			db.Close()
+			return nil, fmt.Errorf("database is encrypted, provide the key with --db.encryption.keyfile or %s", encdb.KeyEnvVar)
+		}
+		return db, nil
+	}
+	encrypted, err := encdb.New(db, o.EncryptionKey, o.EncryptKeys)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	log.Info("Encrypting database at rest", "keys", encrypted.EncryptsKeys())
+	return encrypted, nil
+}
+
+// openRawKeyValueDatabase opens a disk-based key-value database without
+// encryption.
 //
 //						  type == null          type != null
 //					   +----------------------------------------
 //	db is non-existent |  pebble default  |  specified type
 //	db is existent     |  from db         |  specified type (if compatible)
-func openKeyValueDatabase(o internalOpenOptions) (ethdb.KeyValueStore, error) {
+func openRawKeyValueDatabase(o internalOpenOptions) (ethdb.KeyValueStore, error) {
 	// Reject any unsupported database type
-	if len(o.dbEngine) != 0 && o.dbEngine != rawdb.DBLeveldb && o.dbEngine != rawdb.DBPebble {
+	if len(o.dbEngine) != 0 && o.dbEngine != rawdb.DBLeveldb && o.dbEngine != rawdb.DBPebble && o.dbEngine != rawdb.DBBadger {
 		return nil, fmt.Errorf("unknown db.engine %v", o.dbEngine)
 	}
 	// Retrieve any pre-existing database's type and use that or the requested one
@@ -96,6 +142,10 @@ func openKeyValueDatabase(o internalOpenOptions) (ethdb.KeyValueStore, error) {
 		log.Info("Using leveldb as the backing database")
 		return newLevelDBDatabase(o.directory, o.Cache, o.Handles, o.MetricsNamespace, o.ReadOnly)
 	}
+	if o.dbEngine == rawdb.DBBadger || existingDb == rawdb.DBBadger {
+		log.Info("Using badger as the backing database")
+		return newBadgerDatabase(o.directory, o.Cache, o.Handles, o.MetricsNamespace, o.ReadOnly)
+	}
 	// No pre-existing database, no user-requested one either. Default to Pebble.
 	log.Info("Defaulting to pebble as the backing database")
 	return newPebbleDBDatabase(o.directory, o.Cache, o.Handles, o.MetricsNamespace, o.ReadOnly)
@@ -121,3 +171,13 @@ func newPebbleDBDatabase(file string, cache int, handles int, namespace string,
 	}
 	return db, nil
 }
+
+// newBadgerDatabase creates a persistent key-value database without a freezer
+// moving immutable chain segments into cold storage.
+func newBadgerDatabase(file string, cache int, handles int, namespace string, readonly bool) (ethdb.KeyValueStore, error) {
+	db, err := badgerdb.New(file, cache, handles, namespace, readonly)
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
@@ -17,6 +17,7 @@
 package node
 
 import (
+	"crypto/cipher"
 	crand "crypto/rand"
 	"errors"
 	"fmt"
@@ -714,6 +715,9 @@ func (n *Node) OpenDatabaseWithOptions(name string, opt DatabaseOptions) (ethdb.
 		})
 	} else {
 		opt.AncientsDirectory = n.ResolveAncient(name, opt.AncientsDirectory)
+		if opt.EncryptionKey == nil {
+			opt.EncryptionKey, opt.EncryptKeys = n.config.DBEncryptionKey, n.config.DBEncryptKeys
+		}
 		db, err = openDatabase(internalOpenOptions{
 			directory:       n.ResolvePath(name),
 			dbEngine:        n.config.DBEngine,
@@ -784,6 +788,28 @@ func (db *closeTrackingDB) Close() error {
 	return db.Database.Close()
 }
 
+// Checkpoint implements ethdb.Checkpointer if the wrapped database does.
+func (db *closeTrackingDB) Checkpoint(dir string) error {
+	if cp, ok := db.Database.(ethdb.Checkpointer); ok {
+		return cp.Checkpoint(dir)
+	}
+	return errors.New("database does not support checkpoints")
+}
+
+// NewSnapshot implements ethdb.Snapshotter if the wrapped database does.
+func (db *closeTrackingDB) NewSnapshot() (ethdb.Snapshot, error) {
+	if snapshotter, ok := db.Database.(ethdb.Snapshotter); ok {
+		return snapshotter.NewSnapshot()
+	}
+	return nil, errors.New("database does not support snapshots")
+}
+
+// AncientCipher returns the cipher the freezers of the wrapped database are
+// encrypted with, if any.
+func (db *closeTrackingDB) AncientCipher() cipher.AEAD {
+	return rawdb.AncientCipher(db.Database)
+}
+
 // wrapDatabase ensures the database will be auto-closed when Node is closed.
 func (n *Node) wrapDatabase(db ethdb.Database) ethdb.Database {
 	wrapper := &closeTrackingDB{db, n}
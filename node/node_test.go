@@ -28,6 +28,7 @@ import (
 	"testing"
 
 	"github.com/luxfi/crypto"
+	"github.com/luxfi/geth/core/rawdb"
 	"github.com/luxfi/geth/ethdb"
 	"github.com/luxfi/geth/p2p"
 	"github.com/luxfi/geth/rpc"
@@ -209,6 +210,49 @@ func TestNodeOpenDatabaseFromLifecycleStop(t *testing.T) {
 	stack.Close()
 }
 
+// Tests that a badger database can be selected through the config and is
+// detected when reopened without an explicit engine.
+func TestNodeOpenDatabaseBadger(t *testing.T) {
+	datadir := t.TempDir()
+	open := func(engine string) (*Node, ethdb.Database, error) {
+		conf := testNodeConfig()
+		conf.DataDir = datadir
+		conf.DBEngine = engine
+		stack, err := New(conf)
+		if err != nil {
+			t.Fatal("can't create node:", err)
+		}
+		db, err := stack.OpenDatabaseWithOptions("mydb", DatabaseOptions{})
+		return stack, db, err
+	}
+	stack, db, err := open(rawdb.DBBadger)
+	if err != nil {
+		t.Fatal("can't open DB:", err)
+	}
+	if err := db.Put([]byte("key"), []byte("value")); err != nil {
+		t.Fatal("can't Put on open DB:", err)
+	}
+	stack.Close()
+
+	if engine := rawdb.PreexistingDatabase(stack.ResolvePath("mydb")); engine != rawdb.DBBadger {
+		t.Fatalf("wrong database engine detected: %q", engine)
+	}
+	stack, db, err = open("")
+	if err != nil {
+		t.Fatal("can't reopen DB:", err)
+	}
+	if val, err := db.Get([]byte("key")); err != nil || string(val) != "value" {
+		t.Fatalf("wrong value after reopening: %q, %v", val, err)
+	}
+	stack.Close()
+
+	stack, _, err = open(rawdb.DBPebble)
+	if err == nil {
+		t.Fatal("opened badger database as pebble")
+	}
+	stack.Close()
+}
+
 // Tests that registered Lifecycles get started and stopped correctly.
 func TestLifecycleLifeCycle(t *testing.T) {
 	stack, _ := New(testNodeConfig())
@@ -118,6 +118,11 @@ type Config struct {
 	// If NoDial is true, the server will not dial any peers.
 	NoDial bool `toml:",omitempty"`
 
+	// PostQuantumHandshake enables the hybrid RLPx handshake, which mixes an
+	// ML-KEM-768 secret into the session keys of connections to peers that
+	// support it. Connections to other peers use the legacy handshake.
+	PostQuantumHandshake bool `toml:",omitempty"`
+
 	// If EnableMsgEvents is set then the server will emit PeerEvents
 	// whenever a message is sent to or received from a peer
 	EnableMsgEvents bool
@@ -16,28 +16,29 @@ var _ = (*configMarshaling)(nil)
 // MarshalTOML marshals as TOML.
 func (c Config) MarshalTOML() (interface{}, error) {
 	type Config struct {
-		PrivateKey       *ecdsa.PrivateKey `toml:"-"`
-		MaxPeers         int
-		MaxPendingPeers  int `toml:",omitempty"`
-		DialRatio        int `toml:",omitempty"`
-		NoDiscovery      bool
-		DiscoveryV4      bool   `toml:",omitempty"`
-		DiscoveryV5      bool   `toml:",omitempty"`
-		Name             string `toml:"-"`
-		BootstrapNodes   []*enode.Node
-		BootstrapNodesV5 []*enode.Node `toml:",omitempty"`
-		StaticNodes      []*enode.Node
-		TrustedNodes     []*enode.Node
-		NetRestrict      *netutil.Netlist `toml:",omitempty"`
-		NodeDatabase     string           `toml:",omitempty"`
-		Protocols        []Protocol       `toml:"-" json:"-"`
-		ListenAddr       string
-		DiscAddr         string
-		NAT              nat.Interface `toml:",omitempty"`
-		Dialer           NodeDialer    `toml:"-"`
-		NoDial           bool          `toml:",omitempty"`
-		EnableMsgEvents  bool
-		Logger           log.Logger `toml:"-"`
+		PrivateKey           *ecdsa.PrivateKey `toml:"-"`
+		MaxPeers             int
+		MaxPendingPeers      int `toml:",omitempty"`
+		DialRatio            int `toml:",omitempty"`
+		NoDiscovery          bool
+		DiscoveryV4          bool   `toml:",omitempty"`
+		DiscoveryV5          bool   `toml:",omitempty"`
+		Name                 string `toml:"-"`
+		BootstrapNodes       []*enode.Node
+		BootstrapNodesV5     []*enode.Node `toml:",omitempty"`
+		StaticNodes          []*enode.Node
+		TrustedNodes         []*enode.Node
+		NetRestrict          *netutil.Netlist `toml:",omitempty"`
+		NodeDatabase         string           `toml:",omitempty"`
+		Protocols            []Protocol       `toml:"-" json:"-"`
+		ListenAddr           string
+		DiscAddr             string
+		NAT                  nat.Interface `toml:",omitempty"`
+		Dialer               NodeDialer    `toml:"-"`
+		NoDial               bool          `toml:",omitempty"`
+		PostQuantumHandshake bool          `toml:",omitempty"`
+		EnableMsgEvents      bool
+		Logger               log.Logger `toml:"-"`
 	}
 	var enc Config
 	enc.PrivateKey = c.PrivateKey
@@ -60,6 +61,7 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.NAT = c.NAT
 	enc.Dialer = c.Dialer
 	enc.NoDial = c.NoDial
+	enc.PostQuantumHandshake = c.PostQuantumHandshake
 	enc.EnableMsgEvents = c.EnableMsgEvents
 	enc.Logger = c.Logger
 	return &enc, nil
@@ -68,28 +70,29 @@ func (c Config) MarshalTOML() (interface{}, error) {
 // UnmarshalTOML unmarshals from TOML.
 func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	type Config struct {
-		PrivateKey       *ecdsa.PrivateKey `toml:"-"`
-		MaxPeers         *int
-		MaxPendingPeers  *int `toml:",omitempty"`
-		DialRatio        *int `toml:",omitempty"`
-		NoDiscovery      *bool
-		DiscoveryV4      *bool   `toml:",omitempty"`
-		DiscoveryV5      *bool   `toml:",omitempty"`
-		Name             *string `toml:"-"`
-		BootstrapNodes   []*enode.Node
-		BootstrapNodesV5 []*enode.Node `toml:",omitempty"`
-		StaticNodes      []*enode.Node
-		TrustedNodes     []*enode.Node
-		NetRestrict      *netutil.Netlist `toml:",omitempty"`
-		NodeDatabase     *string          `toml:",omitempty"`
-		Protocols        []Protocol       `toml:"-" json:"-"`
-		ListenAddr       *string
-		DiscAddr         *string
-		NAT              *configNAT `toml:",omitempty"`
-		Dialer           NodeDialer `toml:"-"`
-		NoDial           *bool      `toml:",omitempty"`
-		EnableMsgEvents  *bool
-		Logger           log.Logger `toml:"-"`
+		PrivateKey           *ecdsa.PrivateKey `toml:"-"`
+		MaxPeers             *int
+		MaxPendingPeers      *int `toml:",omitempty"`
+		DialRatio            *int `toml:",omitempty"`
+		NoDiscovery          *bool
+		DiscoveryV4          *bool   `toml:",omitempty"`
+		DiscoveryV5          *bool   `toml:",omitempty"`
+		Name                 *string `toml:"-"`
+		BootstrapNodes       []*enode.Node
+		BootstrapNodesV5     []*enode.Node `toml:",omitempty"`
+		StaticNodes          []*enode.Node
+		TrustedNodes         []*enode.Node
+		NetRestrict          *netutil.Netlist `toml:",omitempty"`
+		NodeDatabase         *string          `toml:",omitempty"`
+		Protocols            []Protocol       `toml:"-" json:"-"`
+		ListenAddr           *string
+		DiscAddr             *string
+		NAT                  *configNAT `toml:",omitempty"`
+		Dialer               NodeDialer `toml:"-"`
+		NoDial               *bool      `toml:",omitempty"`
+		PostQuantumHandshake *bool      `toml:",omitempty"`
+		EnableMsgEvents      *bool
+		Logger               log.Logger `toml:"-"`
 	}
 	var dec Config
 	if err := unmarshal(&dec); err != nil {
@@ -155,6 +158,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.NoDial != nil {
 		c.NoDial = *dec.NoDial
 	}
+	if dec.PostQuantumHandshake != nil {
+		c.PostQuantumHandshake = *dec.PostQuantumHandshake
+	}
 	if dec.EnableMsgEvents != nil {
 		c.EnableMsgEvents = *dec.EnableMsgEvents
 	}
@@ -53,6 +53,12 @@ type Config struct {
 	Unhandled     chan<- ReadPacket // unhandled packets are sent on this channel
 	V5RespTimeout time.Duration     // timeout for v5 queries
 
+	// PostQuantum enables the "mldsa" identity scheme in discovery v5. Its records
+	// and handshakes don't fit the regular packet size, so this also makes the
+	// listener accept packets up to v5wire.MaxLargePacketSize. It must be set when
+	// the local node uses the scheme.
+	PostQuantum bool
+
 	// Node table configuration:
 	Bootnodes               []*enode.Node // list of bootstrap nodes
 	PingInterval            time.Duration // speed of node liveness check
@@ -84,6 +90,9 @@ func (cfg Config) withDefaults() Config {
 	}
 	if cfg.ValidSchemes == nil {
 		cfg.ValidSchemes = enode.ValidSchemes
+		if cfg.PostQuantum {
+			cfg.ValidSchemes = enode.ValidSchemesPostQuantum
+		}
 	}
 	if cfg.Clock == nil {
 		cfg.Clock = mclock.System{}
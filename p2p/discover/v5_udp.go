@@ -82,6 +82,7 @@ type UDPv5 struct {
 	clock        mclock.Clock
 	validSchemes enr.IdentityScheme
 	respTimeout  time.Duration
+	packetSize   int // maximum size of received packets
 
 	// misc buffers used during message handling
 	logcontext []interface{}
@@ -157,8 +158,16 @@ func ListenV5(conn UDPConn, ln *enode.LocalNode, cfg Config) (*UDPv5, error) {
 
 // newUDPv5 creates a UDPv5 transport, but doesn't start any goroutines.
 func newUDPv5(conn UDPConn, ln *enode.LocalNode, cfg Config) (*UDPv5, error) {
+	packetSize := maxPacketSize
+	if cfg.PostQuantum {
+		packetSize = v5wire.MaxLargePacketSize
+	} else if ln.MLDSAKey() != nil {
+		return nil, errors.New("local node uses the mldsa identity scheme, but post-quantum discovery is disabled")
+	}
 	closeCtx, cancelCloseCtx := context.WithCancel(context.Background())
 	cfg = cfg.withDefaults()
+	codec := v5wire.NewCodec(ln, cfg.PrivateKey, cfg.Clock, cfg.V5ProtocolID)
+	codec.SetValidSchemes(cfg.ValidSchemes)
 	t := &UDPv5{
 		// static fields
 		conn:         newMeteredConn(conn),
@@ -170,6 +179,7 @@ func newUDPv5(conn UDPConn, ln *enode.LocalNode, cfg Config) (*UDPv5, error) {
 		validSchemes: cfg.ValidSchemes,
 		clock:        cfg.Clock,
 		respTimeout:  cfg.V5RespTimeout,
+		packetSize:   packetSize,
 		// channels into dispatch
 		packetInCh:    make(chan ReadPacket, 1),
 		readNextCh:    make(chan struct{}, 1),
@@ -179,7 +189,7 @@ func newUDPv5(conn UDPConn, ln *enode.LocalNode, cfg Config) (*UDPv5, error) {
 		respTimeoutCh: make(chan *callTimeout),
 		unhandled:     cfg.Unhandled,
 		// state of dispatch
-		codec:            v5wire.NewCodec(ln, cfg.PrivateKey, cfg.Clock, cfg.V5ProtocolID),
+		codec:            codec,
 		activeCallByNode: make(map[enode.ID]*callV5),
 		activeCallByAuth: make(map[v5wire.Nonce]*callV5),
 		callQueue:        make(map[enode.ID][]*callV5),
@@ -711,7 +721,7 @@ func (t *UDPv5) send(toID enode.ID, toAddr netip.AddrPort, packet v5wire.Packet,
 func (t *UDPv5) readLoop() {
 	defer t.wg.Done()
 
-	buf := make([]byte, maxPacketSize)
+	buf := make([]byte, t.packetSize)
 	for range t.readNextCh {
 		nbytes, from, err := t.conn.ReadFromUDPAddrPort(buf)
 		if netutil.IsTemporaryError(err) {
@@ -962,7 +972,8 @@ func packNodes(reqid []byte, nodes []*enode.Node) []*v5wire.Nodes {
 	// This limit represents the available space for nodes in output packets. Maximum
 	// packet size is 1280, and out of this ~80 bytes will be taken up by the packet
 	// frame. So limiting to 1000 bytes here leaves 200 bytes for other fields of the
-	// NODES message, which is a lot.
+	// NODES message, which is a lot. Records of the "mldsa" identity scheme exceed
+	// the limit on their own, they are sent one per packet.
 	const sizeLimit = 1000
 
 	var resp []*v5wire.Nodes
@@ -971,7 +982,7 @@ func packNodes(reqid []byte, nodes []*enode.Node) []*v5wire.Nodes {
 		size := uint64(0)
 		for len(nodes) > 0 {
 			r := nodes[0].Record()
-			if size += r.Size(); size > sizeLimit {
+			if size += r.Size(); size > sizeLimit && len(p.Nodes) > 0 {
 				break
 			}
 			p.Nodes = append(p.Nodes, r)
@@ -29,6 +29,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/luxfi/geth/crypto/pqcrypto"
 	"github.com/luxfi/geth/internal/testlog"
 	"github.com/luxfi/geth/log"
 	"github.com/luxfi/geth/p2p/discover/v4wire"
@@ -78,6 +79,13 @@ func startLocalhostV5(t *testing.T, cfg Config) *UDPv5 {
 	cfg.PrivateKey = newkey()
 	db, _ := enode.OpenDB("")
 	ln := enode.NewLocalNode(db, cfg.PrivateKey)
+	if cfg.PostQuantum {
+		pqkey, err := pqcrypto.NewPQSigner(pqcrypto.AlgoMLDSA65)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ln = enode.NewLocalNodeMLDSA(db, cfg.PrivateKey, pqkey)
+	}
 
 	// Prefix logs with node ID.
 	lprefix := fmt.Sprintf("(%s)", ln.ID().TerminalString())
@@ -712,6 +720,76 @@ func TestUDPv5_LocalNode(t *testing.T) {
 	}
 }
 
+// Real sockets, real crypto: this test checks that nodes using the "mldsa" identity
+// scheme can perform handshakes, whose packets exceed the regular size limit.
+func TestUDPv5_handshakeMLDSA(t *testing.T) {
+	t.Parallel()
+
+	a := startLocalhostV5(t, Config{PostQuantum: true})
+	defer a.Close()
+	b := startLocalhostV5(t, Config{PostQuantum: true})
+	defer b.Close()
+
+	n, err := b.RequestENR(a.Self())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Record().IdentityScheme() != "mldsa" || n.ID() != a.Self().ID() {
+		t.Fatalf("wrong record returned: %v", n)
+	}
+	if n.ID() != enode.PubkeyToIDV4(&a.priv.PublicKey) {
+		t.Fatal("node ID differs from the v4 ID")
+	}
+}
+
+// This test checks that the "mldsa" identity scheme must be enabled explicitly.
+func TestUDPv5_MLDSANotEnabled(t *testing.T) {
+	t.Parallel()
+
+	key := newkey()
+	pqkey, err := pqcrypto.NewPQSigner(pqcrypto.AlgoMLDSA65)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, _ := enode.OpenDB("")
+	defer db.Close()
+	ln := enode.NewLocalNodeMLDSA(db, key, pqkey)
+	if _, err := ListenV5(newpipe(), ln, Config{PrivateKey: key}); err == nil {
+		t.Fatal("listener started without PostQuantum")
+	}
+}
+
+// This test checks that NODES responses carry records larger than the size limit.
+func TestUDPv5_packNodesMLDSA(t *testing.T) {
+	t.Parallel()
+
+	var nodes []*enode.Node
+	for i := 0; i < 3; i++ {
+		pqkey, err := pqcrypto.NewPQSigner(pqcrypto.AlgoMLDSA65)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var r enr.Record
+		if err := enode.SignMLDSA(&r, newkey(), pqkey); err != nil {
+			t.Fatal(err)
+		}
+		n, err := enode.New(enode.ValidSchemesPostQuantum, &r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nodes = append(nodes, n)
+	}
+	resp := packNodes(nil, nodes)
+	if len(resp) != len(nodes) {
+		t.Fatalf("wrong number of responses %d, want %d", len(resp), len(nodes))
+	}
+	for _, p := range resp {
+		if len(p.Nodes) != 1 || p.RespCount != uint8(len(nodes)) {
+			t.Fatalf("wrong response: %d nodes, count %d", len(p.Nodes), p.RespCount)
+		}
+	}
+}
+
 func TestUDPv5_PingWithIPV4MappedAddress(t *testing.T) {
 	t.Parallel()
 	test := newUDPV5Test(t)
@@ -27,6 +27,7 @@ import (
 
 	"github.com/luxfi/geth/common/math"
 	"github.com/luxfi/crypto"
+	"github.com/luxfi/geth/crypto/pqcrypto"
 	"github.com/luxfi/geth/p2p/enode"
 	"golang.org/x/crypto/hkdf"
 )
@@ -88,6 +89,13 @@ func makeIDSignature(hash hash.Hash, key *ecdsa.PrivateKey, challenge, ephkey []
 	}
 }
 
+// makeMLDSAIDSignature creates the ID nonce signature of a node using the "mldsa"
+// identity scheme.
+func makeMLDSAIDSignature(hash hash.Hash, key *pqcrypto.PQSigner, challenge, ephkey []byte, destID enode.ID) ([]byte, error) {
+	input := idNonceHash(hash, challenge, ephkey, destID)
+	return key.Sign(input)
+}
+
 // s256raw is an unparsed secp256k1 public key ENR entry.
 type s256raw []byte
 
@@ -106,6 +114,16 @@ func verifyIDSignature(hash hash.Hash, sig []byte, n *enode.Node, challenge, eph
 			return errInvalidNonceSig
 		}
 		return nil
+	case "mldsa":
+		var pubkey enode.MLDSA
+		if n.Load(&pubkey) != nil {
+			return errors.New("no mldsa public key in record")
+		}
+		input := idNonceHash(hash, challenge, ephkey, destID)
+		if !pqcrypto.Verify(pqcrypto.AlgoMLDSA65, pubkey, input, sig) {
+			return errInvalidNonceSig
+		}
+		return nil
 	default:
 		return fmt.Errorf("can't verify ID nonce signature against scheme %q", idscheme)
 	}
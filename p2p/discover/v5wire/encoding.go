@@ -65,7 +65,7 @@ type (
 	handshakeAuthData struct {
 		h struct {
 			SrcID      enode.ID
-			SigSize    byte // signature data
+			SigSize    byte // signature data, see longSigSize
 			PubkeySize byte // offset of
 		}
 		// Trailing variable-size data.
@@ -96,6 +96,17 @@ const (
 
 	maxPacketSize = 1280
 
+	// MaxLargePacketSize is the maximum size of packets carrying the records and
+	// signatures of the "mldsa" identity scheme. These don't fit in maxPacketSize,
+	// so networks which enable the scheme rely on IP fragmentation.
+	MaxLargePacketSize = 16384
+
+	// longSigSize is the SigSize value of handshakes whose ID nonce signature
+	// doesn't fit the one-byte size field, like those of the "mldsa" scheme. The
+	// signature size is then encoded as a big-endian uint16 in front of the
+	// signature.
+	longSigSize = 0
+
 	minMessageSize      = 48 // this refers to data after static headers
 	randomPacketMsgSize = 20
 )
@@ -148,6 +159,7 @@ type Codec struct {
 	privkey    *ecdsa.PrivateKey
 	sc         *SessionCache
 	protocolID [6]byte
+	schemes    enr.IdentityScheme // identity schemes accepted in handshakes
 
 	// encoder buffers
 	buf      bytes.Buffer // whole packet
@@ -168,6 +180,7 @@ func NewCodec(ln *enode.LocalNode, key *ecdsa.PrivateKey, clock mclock.Clock, pr
 		privkey:    key,
 		sc:         NewSessionCache(1024, clock),
 		protocolID: DefaultProtocolID,
+		schemes:    enode.ValidSchemes,
 		decbuf:     make([]byte, maxPacketSize),
 	}
 	if protocolID != nil {
@@ -176,6 +189,12 @@ func NewCodec(ln *enode.LocalNode, key *ecdsa.PrivateKey, clock mclock.Clock, pr
 	return c
 }
 
+// SetValidSchemes sets the identity schemes accepted for node records in
+// handshakes. The default is enode.ValidSchemes.
+func (c *Codec) SetValidSchemes(schemes enr.IdentityScheme) {
+	c.schemes = schemes
+}
+
 // Encode encodes a packet to a node. 'id' and 'addr' specify the destination node. The
 // 'challenge' parameter should be the most recently received WHOAREYOU packet from that
 // node.
@@ -364,10 +383,17 @@ func (c *Codec) encodeHandshakeHeader(toID enode.ID, addr string, challenge *Who
 	// Encode the auth header.
 	var (
 		authsizeExtra = len(auth.pubkey) + len(auth.signature) + len(auth.record)
-		head          = c.makeHeader(toID, flagHandshake, authsizeExtra)
+		head          Header
 	)
+	if auth.h.SigSize == longSigSize {
+		authsizeExtra += 2
+	}
+	head = c.makeHeader(toID, flagHandshake, authsizeExtra)
 	c.headbuf.Reset()
 	binary.Write(&c.headbuf, binary.BigEndian, &auth.h)
+	if auth.h.SigSize == longSigSize {
+		binary.Write(&c.headbuf, binary.BigEndian, uint16(len(auth.signature)))
+	}
 	c.headbuf.Write(auth.signature)
 	c.headbuf.Write(auth.pubkey)
 	c.headbuf.Write(auth.record)
@@ -396,13 +422,24 @@ func (c *Codec) makeHandshakeAuth(toID enode.ID, addr string, challenge *Whoarey
 	auth.h.PubkeySize = byte(len(auth.pubkey))
 
 	// Add ID nonce signature to response.
-	cdata := challenge.ChallengeData
-	idsig, err := makeIDSignature(c.sha256, c.privkey, cdata, ephpubkey[:], toID)
+	var (
+		cdata = challenge.ChallengeData
+		idsig []byte
+	)
+	if pqkey := c.localnode.MLDSAKey(); pqkey != nil {
+		idsig, err = makeMLDSAIDSignature(c.sha256, pqkey, cdata, ephpubkey[:], toID)
+	} else {
+		idsig, err = makeIDSignature(c.sha256, c.privkey, cdata, ephpubkey[:], toID)
+	}
 	if err != nil {
 		return nil, nil, fmt.Errorf("can't sign: %v", err)
 	}
 	auth.signature = idsig
-	auth.h.SigSize = byte(len(auth.signature))
+	if len(idsig) > 0xff {
+		auth.h.SigSize = longSigSize
+	} else {
+		auth.h.SigSize = byte(len(auth.signature))
+	}
 
 	// Add our record to response if it's newer than what remote side has.
 	ln := c.localnode.Node()
@@ -584,9 +621,19 @@ func (c *Codec) decodeHandshakeAuthData(head *Header) (auth handshakeAuthData, e
 
 	// Decode variable-size part.
 	var (
-		vardata       = head.AuthData[sizeofHandshakeAuthData:]
-		sigAndKeySize = int(auth.h.SigSize) + int(auth.h.PubkeySize)
-		keyOffset     = int(auth.h.SigSize)
+		vardata = head.AuthData[sizeofHandshakeAuthData:]
+		sigSize = int(auth.h.SigSize)
+	)
+	if auth.h.SigSize == longSigSize {
+		if len(vardata) < 2 {
+			return auth, errTooShort
+		}
+		sigSize = int(binary.BigEndian.Uint16(vardata))
+		vardata = vardata[2:]
+	}
+	var (
+		sigAndKeySize = sigSize + int(auth.h.PubkeySize)
+		keyOffset     = sigSize
 		recOffset     = keyOffset + int(auth.h.PubkeySize)
 	)
 	if len(vardata) < sigAndKeySize {
@@ -609,7 +656,7 @@ func (c *Codec) decodeHandshakeRecord(local *enode.Node, wantID enode.ID, remote
 			return nil, err
 		}
 		if local == nil || local.Seq() < record.Seq() {
-			n, err := enode.New(enode.ValidSchemes, &record)
+			n, err := enode.New(c.schemes, &record)
 			if err != nil {
 				return nil, fmt.Errorf("invalid node record: %v", err)
 			}
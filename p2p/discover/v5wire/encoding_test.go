@@ -34,6 +34,7 @@ import (
 	"github.com/luxfi/geth/common/hexutil"
 	"github.com/luxfi/geth/common/mclock"
 	"github.com/luxfi/crypto"
+	"github.com/luxfi/geth/crypto/pqcrypto"
 	"github.com/luxfi/geth/p2p/enode"
 )
 
@@ -97,6 +98,79 @@ func TestHandshake(t *testing.T) {
 	net.nodeA.expectDecode(t, NodesMsg, nodes)
 }
 
+// This test checks the handshake of a node using the "mldsa" identity scheme, whose
+// signature and record don't fit the regular size limits.
+func TestHandshake_mldsa(t *testing.T) {
+	t.Parallel()
+	net := newHandshakeTest()
+	defer net.close()
+	net.nodeA.ln.Database().Close()
+	net.nodeA.initMLDSA(t, testKeyA, &net.clock)
+	net.nodeB.c.SetValidSchemes(enode.ValidSchemesPostQuantum)
+
+	// A -> B   RANDOM PACKET
+	packet, _ := net.nodeA.encode(t, net.nodeB, &Findnode{})
+	resp := net.nodeB.expectDecode(t, UnknownPacket, packet)
+
+	// A <- B   WHOAREYOU
+	challenge := &Whoareyou{
+		Nonce:     resp.(*Unknown).Nonce,
+		IDNonce:   testIDnonce,
+		RecordSeq: 0,
+	}
+	whoareyou, _ := net.nodeB.encode(t, net.nodeA, challenge)
+	net.nodeA.expectDecode(t, WhoareyouPacket, whoareyou)
+
+	// A -> B   FINDNODE (handshake packet)
+	findnode, _ := net.nodeA.encodeWithChallenge(t, net.nodeB, challenge, &Findnode{})
+	if len(findnode) <= maxPacketSize || len(findnode) > MaxLargePacketSize {
+		t.Fatalf("unexpected handshake packet size %d", len(findnode))
+	}
+	_, n, p, err := net.nodeB.c.Decode(findnode, "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Kind() != FindnodeMsg {
+		t.Fatalf("expected packet type %d, got %d", FindnodeMsg, p.Kind())
+	}
+	if n == nil || n.ID() != net.nodeA.id() || n.Record().IdentityScheme() != "mldsa" {
+		t.Fatalf("wrong node in handshake: %v", n)
+	}
+
+	// A <- B   NODES
+	nodes, _ := net.nodeB.encode(t, net.nodeA, &Nodes{RespCount: 1})
+	net.nodeA.expectDecode(t, NodesMsg, nodes)
+}
+
+// This test checks that handshakes of the "mldsa" identity scheme are rejected
+// unless the scheme is enabled.
+func TestHandshake_mldsaDisabled(t *testing.T) {
+	t.Parallel()
+	net := newHandshakeTest()
+	defer net.close()
+	net.nodeA.ln.Database().Close()
+	net.nodeA.initMLDSA(t, testKeyA, &net.clock)
+
+	// A -> B   RANDOM PACKET
+	packet, _ := net.nodeA.encode(t, net.nodeB, &Findnode{})
+	resp := net.nodeB.expectDecode(t, UnknownPacket, packet)
+
+	// A <- B   WHOAREYOU
+	challenge := &Whoareyou{
+		Nonce:     resp.(*Unknown).Nonce,
+		IDNonce:   testIDnonce,
+		RecordSeq: 0,
+	}
+	whoareyou, _ := net.nodeB.encode(t, net.nodeA, challenge)
+	net.nodeA.expectDecode(t, WhoareyouPacket, whoareyou)
+
+	// A -> B   FINDNODE (handshake packet)
+	findnode, _ := net.nodeA.encodeWithChallenge(t, net.nodeB, challenge, &Findnode{})
+	if _, _, _, err := net.nodeB.c.Decode(findnode, "127.0.0.1"); err == nil {
+		t.Fatal("mldsa record accepted by default")
+	}
+}
+
 // This test checks that handshake attempts are removed within the timeout.
 func TestHandshake_timeout(t *testing.T) {
 	t.Parallel()
@@ -550,6 +624,17 @@ func (n *handshakeTestNode) init(key *ecdsa.PrivateKey, ip net.IP, clock mclock.
 	n.c = NewCodec(n.ln, key, clock, nil)
 }
 
+func (n *handshakeTestNode) initMLDSA(t testing.TB, key *ecdsa.PrivateKey, clock mclock.Clock) {
+	pqkey, err := pqcrypto.NewPQSigner(pqcrypto.AlgoMLDSA65)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, _ := enode.OpenDB("")
+	n.ln = enode.NewLocalNodeMLDSA(db, key, pqkey)
+	n.ln.SetStaticIP(net.IP{127, 0, 0, 1})
+	n.c = NewCodec(n.ln, key, clock, nil)
+}
+
 func (n *handshakeTestNode) encode(t testing.TB, to handshakeTestNode, p Packet) ([]byte, Nonce) {
 	t.Helper()
 	return n.encodeWithChallenge(t, to, nil, p)
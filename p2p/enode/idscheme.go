@@ -23,6 +23,7 @@ import (
 
 	"github.com/luxfi/geth/common/math"
 	"github.com/luxfi/crypto"
+	"github.com/luxfi/geth/crypto/pqcrypto"
 	"github.com/luxfi/geth/p2p/enr"
 	"github.com/luxfi/geth/rlp"
 	"golang.org/x/crypto/sha3"
@@ -33,10 +34,19 @@ var ValidSchemes = enr.SchemeMap{
 	"v4": V4ID{},
 }
 
+// ValidSchemesPostQuantum is ValidSchemes extended by the post-quantum "mldsa"
+// scheme. It is only used by networks which opt into the scheme, because its
+// records exceed the regular size limits of discovery packets.
+var ValidSchemesPostQuantum = enr.SchemeMap{
+	"v4":    V4ID{},
+	"mldsa": MLDSAID{},
+}
+
 // ValidSchemesForTesting is a List of identity schemes for testing.
 var ValidSchemesForTesting = enr.SchemeMap{
-	"v4":   V4ID{},
-	"null": NullID{},
+	"v4":    V4ID{},
+	"mldsa": MLDSAID{},
+	"null":  NullID{},
 }
 
 // V4ID is the "v4" identity scheme.
@@ -137,6 +147,87 @@ func signV4Compat(r *enr.Record, pubkey *ecdsa.PublicKey) {
 	}
 }
 
+// MLDSAID is the "mldsa" identity scheme. Records carry both a "secp256k1" and
+// an "mldsa" key and are signed by both: the signature is the 64 byte secp256k1
+// signature of the "v4" scheme followed by the ML-DSA-65 (FIPS 204) signature
+// over the RLP encoding of the record content.
+//
+// The node address is the one of the "v4" scheme, so a node has the same ID in
+// discovery and in RLPx, which derives it from the secp256k1 key. The ML-DSA key
+// is bound to that ID by the secp256k1 signature, and the discovery and RLPx
+// handshakes still perform their key agreement with the secp256k1 key.
+type MLDSAID struct{}
+
+// SignMLDSA signs a record using the mldsa scheme.
+func SignMLDSA(r *enr.Record, key *ecdsa.PrivateKey, pqkey *pqcrypto.PQSigner) error {
+	if pqkey.Algorithm() != pqcrypto.AlgoMLDSA65 {
+		return errors.New("mldsa identity scheme requires an ML-DSA-65 key")
+	}
+	// Copy r to avoid modifying it if signing fails.
+	cpy := *r
+	cpy.Set(enr.ID("mldsa"))
+	cpy.Set(Secp256k1(key.PublicKey))
+	cpy.Set(MLDSA(pqkey.PublicKey()))
+
+	input := mldsaSigningInput(&cpy)
+	sig, err := crypto.Sign(crypto.Keccak256(input), key)
+	if err != nil {
+		return err
+	}
+	pqsig, err := pqkey.Sign(input)
+	if err != nil {
+		return err
+	}
+	sig = append(sig[:len(sig)-1], pqsig...) // remove v
+	if err = cpy.SetSig(MLDSAID{}, sig); err == nil {
+		*r = cpy
+	}
+	return err
+}
+
+func (MLDSAID) Verify(r *enr.Record, sig []byte) error {
+	var (
+		entry  s256raw
+		pubkey MLDSA
+	)
+	if err := r.Load(&entry); err != nil {
+		return err
+	} else if len(entry) != 33 {
+		return errors.New("invalid public key")
+	}
+	if err := r.Load(&pubkey); err != nil {
+		return err
+	} else if len(pubkey) != pqcrypto.PublicKeySize(pqcrypto.AlgoMLDSA65) {
+		return errors.New("invalid public key")
+	}
+	if len(sig) < 64 {
+		return enr.ErrInvalidSig
+	}
+	input := mldsaSigningInput(r)
+	if !crypto.VerifySignature(entry, crypto.Keccak256(input), sig[:64]) {
+		return enr.ErrInvalidSig
+	}
+	if !pqcrypto.Verify(pqcrypto.AlgoMLDSA65, pubkey, input, sig[64:]) {
+		return enr.ErrInvalidSig
+	}
+	return nil
+}
+
+func (MLDSAID) NodeAddr(r *enr.Record) []byte {
+	return V4ID{}.NodeAddr(r)
+}
+
+// mldsaSigningInput returns the RLP encoding of the record content.
+func mldsaSigningInput(r *enr.Record) []byte {
+	enc, _ := rlp.EncodeToBytes(r.AppendElements(nil))
+	return enc
+}
+
+// MLDSA is the "mldsa" key, which holds an encoded ML-DSA-65 public key.
+type MLDSA []byte
+
+func (MLDSA) ENRKey() string { return "mldsa" }
+
 // NullID is the "null" ENR identity scheme. This scheme stores the node
 // ID in the record without any signature.
 type NullID struct{}
@@ -24,6 +24,7 @@ import (
 	"testing"
 
 	"github.com/luxfi/crypto"
+	"github.com/luxfi/geth/crypto/pqcrypto"
 	"github.com/luxfi/geth/p2p/enr"
 	"github.com/luxfi/geth/rlp"
 	"github.com/stretchr/testify/assert"
@@ -72,3 +73,48 @@ func TestGetSetSecp256k1(t *testing.T) {
 	require.NoError(t, r.Load(&pk))
 	assert.EqualValues(t, pubkey, &pk)
 }
+
+// TestSignMLDSA checks that records signed with the mldsa scheme round-trip
+// through the encoding and that tampering invalidates them.
+func TestSignMLDSA(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	pqkey, err := pqcrypto.NewPQSigner(pqcrypto.AlgoMLDSA65)
+	require.NoError(t, err)
+
+	var r enr.Record
+	r.Set(enr.UDP(30303))
+	require.NoError(t, SignMLDSA(&r, key, pqkey))
+	assert.Equal(t, "mldsa", r.IdentityScheme())
+	assert.Greater(t, r.Size(), uint64(enr.SizeLimit))
+
+	enc, err := rlp.EncodeToBytes(&r)
+	require.NoError(t, err)
+	n, err := New(ValidSchemesPostQuantum, decodeRecord(t, enc))
+	require.NoError(t, err)
+	assert.Equal(t, PubkeyToIDV4(&key.PublicKey), n.ID(), "node ID differs from the v4 ID")
+
+	// The scheme is not accepted by default.
+	_, err = New(ValidSchemes, decodeRecord(t, enc))
+	require.Error(t, err)
+
+	// Both signatures are required.
+	sig := r.Signature()
+	require.ErrorIs(t, MLDSAID{}.Verify(&r, sig[64:]), enr.ErrInvalidSig)
+	forged := append(make([]byte, 64), sig[64:]...)
+	require.ErrorIs(t, MLDSAID{}.Verify(&r, forged), enr.ErrInvalidSig)
+
+	// Changing an entry invalidates the signature.
+	r.Set(enr.UDP(30304))
+	require.ErrorIs(t, MLDSAID{}.Verify(&r, sig), enr.ErrInvalidSig)
+
+	// Keys of other algorithms are rejected.
+	other, err := pqcrypto.NewPQSigner(pqcrypto.AlgoMLDSA44)
+	require.NoError(t, err)
+	require.Error(t, SignMLDSA(new(enr.Record), key, other))
+}
+
+func decodeRecord(t *testing.T, enc []byte) *enr.Record {
+	var r enr.Record
+	require.NoError(t, rlp.DecodeBytes(enc, &r))
+	return &r
+}
@@ -26,6 +26,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/luxfi/geth/crypto/pqcrypto"
 	"github.com/luxfi/geth/log"
 	"github.com/luxfi/geth/p2p/enr"
 	"github.com/luxfi/geth/p2p/netutil"
@@ -47,9 +48,10 @@ const (
 type LocalNode struct {
 	cur atomic.Pointer[Node] // holds a non-nil node pointer while the record is up-to-date
 
-	id  ID
-	key *ecdsa.PrivateKey
-	db  *DB
+	id    ID
+	key   *ecdsa.PrivateKey
+	pqkey *pqcrypto.PQSigner // identity key of "mldsa" records, nil for "v4"
+	db    *DB
 
 	// everything below is protected by a lock
 	mu        sync.RWMutex
@@ -68,10 +70,25 @@ type lnEndpoint struct {
 
 // NewLocalNode creates a local node.
 func NewLocalNode(db *DB, key *ecdsa.PrivateKey) *LocalNode {
+	return newLocalNode(db, key, nil)
+}
+
+// NewLocalNodeMLDSA creates a local node using the "mldsa" identity scheme. The
+// record is signed by both the secp256k1 key and the ML-DSA-65 key pqkey. The
+// node ID is derived from the secp256k1 key, like for the "v4" scheme.
+func NewLocalNodeMLDSA(db *DB, key *ecdsa.PrivateKey, pqkey *pqcrypto.PQSigner) *LocalNode {
+	if pqkey.Algorithm() != pqcrypto.AlgoMLDSA65 {
+		panic(fmt.Errorf("enode: invalid identity key algorithm %v", pqkey.Algorithm()))
+	}
+	return newLocalNode(db, key, pqkey)
+}
+
+func newLocalNode(db *DB, key *ecdsa.PrivateKey, pqkey *pqcrypto.PQSigner) *LocalNode {
 	ln := &LocalNode{
 		id:      PubkeyToIDV4(&key.PublicKey),
 		db:      db,
 		key:     key,
+		pqkey:   pqkey,
 		entries: make(map[string]enr.Entry),
 		endpoint4: lnEndpoint{
 			track: netutil.NewIPTracker(iptrackWindow, iptrackContactWindow, iptrackMinStatements),
@@ -137,6 +154,12 @@ func (ln *LocalNode) ID() ID {
 	return ln.id
 }
 
+// MLDSAKey returns the identity key of a local node using the "mldsa" identity
+// scheme, or nil if the node uses the "v4" scheme.
+func (ln *LocalNode) MLDSAKey() *pqcrypto.PQSigner {
+	return ln.pqkey
+}
+
 // Set puts the given entry into the local record, overwriting any existing value.
 // Use Set*IP and SetFallbackUDP to set IP addresses and UDP port, otherwise they'll
 // be overwritten by the endpoint predictor.
@@ -290,10 +313,20 @@ func (ln *LocalNode) sign() {
 	}
 	ln.bumpSeq()
 	r.SetSeq(ln.seq)
-	if err := SignV4(&r, ln.key); err != nil {
+	var (
+		schemes = ValidSchemes
+		err     error
+	)
+	if ln.pqkey != nil {
+		schemes = ValidSchemesPostQuantum
+		err = SignMLDSA(&r, ln.key, ln.pqkey)
+	} else {
+		err = SignV4(&r, ln.key)
+	}
+	if err != nil {
 		panic(fmt.Errorf("enode: can't sign record: %v", err))
 	}
-	n, err := New(ValidSchemes, &r)
+	n, err := New(schemes, &r)
 	if err != nil {
 		panic(fmt.Errorf("enode: can't verify local record: %v", err))
 	}
@@ -23,6 +23,7 @@ import (
 	"testing"
 
 	"github.com/luxfi/crypto"
+	"github.com/luxfi/geth/crypto/pqcrypto"
 	"github.com/luxfi/geth/p2p/enr"
 	"github.com/luxfi/geth/p2p/netutil"
 	"github.com/stretchr/testify/assert"
@@ -135,3 +136,31 @@ func TestLocalNodeEndpoint(t *testing.T) {
 	assert.Equal(t, fallback.Port, ln.Node().UDP())
 	assert.Equal(t, initialSeq+3, ln.Node().Seq())
 }
+
+func TestLocalNodeMLDSA(t *testing.T) {
+	db, _ := OpenDB("")
+	defer db.Close()
+	key, _ := crypto.GenerateKey()
+	pqkey, err := pqcrypto.NewPQSigner(pqcrypto.AlgoMLDSA65)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln := NewLocalNodeMLDSA(db, key, pqkey)
+
+	n := ln.Node()
+	if n.ID() != ln.ID() {
+		t.Fatal("inconsistent ID")
+	}
+	if n.ID() != PubkeyToIDV4(&key.PublicKey) {
+		t.Fatal("node ID differs from the v4 ID")
+	}
+	if scheme := n.Record().IdentityScheme(); scheme != "mldsa" {
+		t.Fatalf("wrong identity scheme %q", scheme)
+	}
+	if n.Pubkey() == nil || !n.Pubkey().Equal(&key.PublicKey) {
+		t.Fatal("record doesn't contain the secp256k1 key")
+	}
+	if ln.MLDSAKey() != pqkey {
+		t.Fatal("wrong identity key")
+	}
+}
@@ -31,6 +31,10 @@
 // the identity scheme to add the signature. Modifying a record invalidates the signature.
 //
 // Package enr supports the "secp256k1-keccak" identity scheme.
+//
+// Records are limited to SizeLimit bytes, except for records using the "mldsa"
+// identity scheme: ML-DSA keys and signatures are larger than that on their own,
+// so these records may take up to PostQuantumSizeLimit bytes.
 package enr
 
 import (
@@ -43,7 +47,10 @@ import (
 	"github.com/luxfi/geth/rlp"
 )
 
-const SizeLimit = 300 // maximum encoded size of a node record in bytes
+const (
+	SizeLimit            = 300  // maximum encoded size of a node record in bytes
+	PostQuantumSizeLimit = 8192 // maximum encoded size of an "mldsa" node record in bytes
+)
 
 var (
 	ErrInvalidSig     = errors.New("invalid signature on node record")
@@ -52,6 +59,7 @@ var (
 	errIncompletePair = errors.New("record contains incomplete k/v pair")
 	errIncompleteList = errors.New("record contains less than two list elements")
 	errTooBig         = fmt.Errorf("record bigger than %d bytes", SizeLimit)
+	errTooBigPQ       = fmt.Errorf("record bigger than %d bytes", PostQuantumSizeLimit)
 	errEncodeUnsigned = errors.New("can't encode unsigned record")
 	errNotFound       = errors.New("no such key in record")
 )
@@ -218,8 +226,8 @@ func decodeRecord(s *rlp.Stream) (dec Record, raw []byte, err error) {
 	if err != nil {
 		return dec, raw, err
 	}
-	if len(raw) > SizeLimit {
-		return dec, raw, errTooBig
+	if len(raw) > PostQuantumSizeLimit {
+		return dec, raw, errTooBigPQ
 	}
 
 	// Decode the RLP container.
@@ -266,7 +274,25 @@ func decodeRecord(s *rlp.Stream) (dec Record, raw []byte, err error) {
 		dec.pairs = append(dec.pairs, kv)
 		prevkey = kv.k
 	}
-	return dec, raw, s.ListEnd()
+	if err := s.ListEnd(); err != nil {
+		return dec, raw, err
+	}
+	return dec, raw, checkSize(&dec, raw)
+}
+
+// checkSize verifies that the encoded record is within the size limit of its
+// identity scheme.
+func checkSize(r *Record, raw []byte) error {
+	if r.IdentityScheme() == "mldsa" {
+		if len(raw) > PostQuantumSizeLimit {
+			return errTooBigPQ
+		}
+		return nil
+	}
+	if len(raw) > SizeLimit {
+		return errTooBig
+	}
+	return nil
 }
 
 // IdentityScheme returns the name of the identity scheme in the record.
@@ -328,8 +354,8 @@ func (r *Record) encode(sig []byte) (raw []byte, err error) {
 	if raw, err = rlp.EncodeToBytes(list); err != nil {
 		return nil, err
 	}
-	if len(raw) > SizeLimit {
-		return nil, errTooBig
+	if err := checkSize(r, raw); err != nil {
+		return nil, err
 	}
 	return raw, nil
 }
@@ -257,6 +257,31 @@ func TestRecordTooBig(t *testing.T) {
 	require.NoError(t, signTest([]byte{5}, &r))
 }
 
+// TestRecordTooBigPostQuantum tests that records of the "mldsa" identity scheme may
+// exceed SizeLimit, up to PostQuantumSizeLimit bytes.
+func TestRecordTooBigPostQuantum(t *testing.T) {
+	var r Record
+	key := randomString(10)
+	sign := func() error {
+		r.Set(ID("mldsa"))
+		r.Set(testID([]byte{5}))
+		return r.SetSig(testSig{}, makeTestSig([]byte{5}, r.Seq()))
+	}
+
+	r.Set(WithEntry(key, randomString(PostQuantumSizeLimit)))
+	if err := sign(); err != errTooBigPQ {
+		t.Fatalf("expected to get errTooBigPQ, got %#v", err)
+	}
+
+	r.Set(WithEntry(key, randomString(4000)))
+	require.NoError(t, sign())
+	blob, err := rlp.EncodeToBytes(&r)
+	require.NoError(t, err)
+	var r2 Record
+	require.NoError(t, rlp.DecodeBytes(blob, &r2))
+	assert.Equal(t, r.Size(), r2.Size())
+}
+
 // This checks that incomplete RLP inputs are handled correctly.
 func TestDecodeIncomplete(t *testing.T) {
 	type decTest struct {
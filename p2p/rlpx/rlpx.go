@@ -36,6 +36,7 @@ import (
 	"github.com/luxfi/geth/common/bitutil"
 	"github.com/luxfi/crypto"
 	"github.com/luxfi/crypto/ecies"
+	"github.com/luxfi/geth/crypto/pqcrypto"
 	"github.com/luxfi/geth/rlp"
 	"github.com/golang/snappy"
 	"golang.org/x/crypto/sha3"
@@ -48,9 +49,11 @@ import (
 // This type is not generally safe for concurrent use, but reading and writing of messages
 // may happen concurrently after the handshake.
 type Conn struct {
-	dialDest *ecdsa.PublicKey
-	conn     net.Conn
-	session  *sessionState
+	dialDest  *ecdsa.PublicKey
+	conn      net.Conn
+	session   *sessionState
+	hybrid    bool          // offer and accept the hybrid handshake
+	handshake HandshakeType // key exchange used by the handshake
 
 	// These are the buffers for snappy compression.
 	// Compression is enabled if they are non-nil.
@@ -98,6 +101,24 @@ func NewConn(conn net.Conn, dialDest *ecdsa.PublicKey) *Conn {
 	}
 }
 
+// SetHybridHandshake enables or disables the hybrid post-quantum handshake. When
+// enabled, the initiator offers an ephemeral ML-KEM-768 key in addition to the
+// secp256k1 ephemeral key, and the recipient answers the offer with an
+// encapsulated secret which is mixed into the session keys. Peers which don't
+// support the hybrid handshake ignore the offer, and the connection falls back
+// to the secp256k1 handshake. This must be called before Handshake.
+func (c *Conn) SetHybridHandshake(enabled bool) {
+	if c.session != nil {
+		panic("can't change handshake after handshake")
+	}
+	c.hybrid = enabled
+}
+
+// HandshakeType returns the key exchange used by the handshake.
+func (c *Conn) HandshakeType() HandshakeType {
+	return c.handshake
+}
+
 // SetSnappy enables or disables snappy compression of messages. This is usually called
 // after the devp2p Hello message exchange when the negotiated version indicates that
 // compression is available on both ends of the connection.
@@ -301,7 +322,7 @@ func (c *Conn) Handshake(prv *ecdsa.PrivateKey) (*ecdsa.PublicKey, error) {
 	var (
 		sec Secrets
 		err error
-		h   handshakeState
+		h   = handshakeState{hybrid: c.hybrid}
 	)
 	if c.dialDest != nil {
 		sec, err = h.runInitiator(c.conn, prv, c.dialDest)
@@ -314,6 +335,9 @@ func (c *Conn) Handshake(prv *ecdsa.PrivateKey) (*ecdsa.PublicKey, error) {
 	c.InitWithSecrets(sec)
 	c.session.rbuf = h.rbuf
 	c.session.wbuf = h.wbuf
+	if h.kemSecret != nil {
+		c.handshake = HandshakeHybrid
+	}
 	return sec.remote, err
 }
 
@@ -355,8 +379,34 @@ const (
 	shaLen = 32                     // hash length (for nonce etc)
 
 	eciesOverhead = 65 /* pubkey */ + 16 /* IV */ + 32 /* MAC */
+
+	// kemAlgorithm is the key encapsulation mechanism of the hybrid handshake.
+	kemAlgorithm = pqcrypto.AlgoMLKEM768
+)
+
+// HandshakeType identifies the key exchange negotiated by the handshake.
+type HandshakeType uint8
+
+const (
+	// HandshakeLegacy is the secp256k1 ECDHE handshake of RLPx v4.
+	HandshakeLegacy HandshakeType = iota
+	// HandshakeHybrid additionally mixes an ML-KEM-768 secret into the session
+	// keys, so recorded traffic stays confidential unless both key exchanges
+	// are broken.
+	HandshakeHybrid
 )
 
+func (t HandshakeType) String() string {
+	switch t {
+	case HandshakeLegacy:
+		return "secp256k1"
+	case HandshakeHybrid:
+		return "secp256k1+mlkem768"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(t))
+	}
+}
+
 var (
 	// this is used in place of actual frame header data.
 	// TODO: replace this when Msg contains the protocol type code.
@@ -382,6 +432,11 @@ type handshakeState struct {
 	randomPrivKey        *ecies.PrivateKey // ecdhe-random
 	remoteRandomPub      *ecies.PublicKey  // ecdhe-random-pubk
 
+	hybrid        bool               // offer or accept the hybrid handshake
+	kemKey        *pqcrypto.PQSigner // ephemeral ML-KEM key of the initiator
+	kemCiphertext []byte             // secret encapsulated by the recipient
+	kemSecret     []byte             // ML-KEM shared secret, set if the handshake is hybrid
+
 	rbuf readBuffer
 	wbuf writeBuffer
 }
@@ -445,6 +500,15 @@ func (h *handshakeState) handleAuthMsg(msg *authMsgV4, prv *ecdsa.PrivateKey) er
 	h.initNonce = msg.Nonce[:]
 	h.remote = rpub
 
+	// Accept the hybrid handshake if offered. The offer is ignored if the
+	// hybrid handshake is disabled, just like legacy peers do.
+	if kemPub := hybridField(msg.Rest, pqcrypto.PublicKeySize(kemAlgorithm)); h.hybrid && kemPub != nil {
+		h.kemCiphertext, h.kemSecret, err = pqcrypto.Encapsulate(kemAlgorithm, kemPub, rand.Reader)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Generate random keypair for ECDH.
 	// If a private key is already set, use it instead of generating one (for testing).
 	if h.randomPrivKey == nil {
@@ -476,6 +540,11 @@ func (h *handshakeState) secrets(auth, authResp []byte) (Secrets, error) {
 		return Secrets{}, err
 	}
 
+	// mix in the post-quantum secret of the hybrid handshake
+	if h.kemSecret != nil {
+		ecdheSecret = crypto.Keccak256(ecdheSecret, h.kemSecret)
+	}
+
 	// derive base secrets from ephemeral key agreement
 	sharedSecret := crypto.Keccak256(ecdheSecret, crypto.Keccak256(h.respNonce, h.initNonce))
 	aesSecret := crypto.Keccak256(ecdheSecret, sharedSecret)
@@ -570,12 +639,32 @@ func (h *handshakeState) makeAuthMsg(prv *ecdsa.PrivateKey) (*authMsgV4, error)
 	copy(msg.InitiatorPubkey[:], crypto.FromECDSAPub(&prv.PublicKey)[1:])
 	copy(msg.Nonce[:], h.initNonce)
 	msg.Version = 4
+
+	// Offer the hybrid handshake.
+	if h.hybrid {
+		if h.kemKey == nil {
+			if h.kemKey, err = pqcrypto.NewPQSigner(kemAlgorithm); err != nil {
+				return nil, err
+			}
+		}
+		msg.Rest = appendHybridField(msg.Rest, h.kemKey.PublicKey())
+	}
 	return msg, nil
 }
 
 func (h *handshakeState) handleAuthResp(msg *authRespV4) (err error) {
 	h.respNonce = msg.Nonce[:]
 	h.remoteRandomPub, err = importPublicKey(msg.RandomPubkey[:])
+	if err != nil {
+		return err
+	}
+	// A missing ciphertext means the recipient doesn't support the hybrid
+	// handshake, continue with the legacy one.
+	if h.kemKey != nil {
+		if ct := hybridField(msg.Rest, pqcrypto.CiphertextSize(kemAlgorithm)); ct != nil {
+			h.kemSecret, err = h.kemKey.Decapsulate(ct)
+		}
+	}
 	return err
 }
 
@@ -590,6 +679,9 @@ func (h *handshakeState) makeAuthResp() (msg *authRespV4, err error) {
 	copy(msg.Nonce[:], h.respNonce)
 	copy(msg.RandomPubkey[:], exportPubkey(&h.randomPrivKey.PublicKey))
 	msg.Version = 4
+	if h.kemCiphertext != nil {
+		msg.Rest = appendHybridField(msg.Rest, h.kemCiphertext)
+	}
 	return msg, nil
 }
 
@@ -645,6 +737,32 @@ func (h *handshakeState) sealEIP8(msg interface{}) ([]byte, error) {
 	return append(prefix, enc...), err
 }
 
+// The hybrid handshake is negotiated through the first of the additional fields
+// allowed by EIP-8, which legacy implementations ignore. The initiator offers it
+// by sending its ephemeral ML-KEM public key, and the recipient accepts it by
+// answering with the secret encapsulated to that key. Anything else in that
+// position is ignored for forward-compatibility.
+
+// appendHybridField adds the hybrid handshake field to the additional fields
+// of a handshake message.
+func appendHybridField(rest []rlp.RawValue, field []byte) []rlp.RawValue {
+	enc, _ := rlp.EncodeToBytes(field)
+	return append([]rlp.RawValue{enc}, rest...)
+}
+
+// hybridField returns the hybrid handshake field of a handshake message, or nil
+// if the first additional field is not a byte string of the given size.
+func hybridField(rest []rlp.RawValue, size int) []byte {
+	if len(rest) == 0 {
+		return nil
+	}
+	kind, content, _, err := rlp.Split(rest[0])
+	if err != nil || kind != rlp.String || len(content) != size {
+		return nil
+	}
+	return content
+}
+
 // importPublicKey unmarshals 512 bit public keys.
 func importPublicKey(pubKey []byte) (*ecies.PublicKey, error) {
 	var pubKey65 []byte
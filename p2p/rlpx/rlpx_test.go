@@ -48,6 +48,38 @@ func TestHandshake(t *testing.T) {
 	p2.Close()
 }
 
+// This test checks that the hybrid handshake is used if both sides support it,
+// and that it falls back to the legacy handshake otherwise.
+func TestHybridHandshake(t *testing.T) {
+	tests := []struct {
+		dialer, listener bool
+		want             HandshakeType
+	}{
+		{dialer: false, listener: false, want: HandshakeLegacy},
+		{dialer: true, listener: false, want: HandshakeLegacy},
+		{dialer: false, listener: true, want: HandshakeLegacy},
+		{dialer: true, listener: true, want: HandshakeHybrid},
+	}
+	for _, test := range tests {
+		conn1, conn2 := net.Pipe()
+		key1, key2 := newkey(), newkey()
+		peer1 := NewConn(conn1, &key2.PublicKey) // dialer
+		peer2 := NewConn(conn2, nil)             // listener
+		peer1.SetHybridHandshake(test.dialer)
+		peer2.SetHybridHandshake(test.listener)
+		doHandshake(t, peer1, peer2, key1, key2)
+
+		if peer1.HandshakeType() != test.want || peer2.HandshakeType() != test.want {
+			t.Errorf("dialer %v, listener %v: wrong handshake type: dialer %v, listener %v, want %v",
+				test.dialer, test.listener, peer1.HandshakeType(), peer2.HandshakeType(), test.want)
+		}
+		checkMsgReadWrite(t, peer1, peer2, 23, []byte("test"))
+		checkMsgReadWrite(t, peer2, peer1, 24, []byte("test"))
+		peer1.Close()
+		peer2.Close()
+	}
+}
+
 // This test checks that messages can be sent and received through WriteMsg/ReadMsg.
 func TestReadWriteMsg(t *testing.T) {
 	peer1, peer2 := createPeers(t)
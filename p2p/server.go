@@ -40,6 +40,7 @@ import (
 	"github.com/luxfi/geth/p2p/enode"
 	"github.com/luxfi/geth/p2p/enr"
 	"github.com/luxfi/geth/p2p/netutil"
+	"github.com/luxfi/geth/p2p/rlpx"
 )
 
 const (
@@ -141,6 +142,8 @@ type conn struct {
 	cont  chan error // The run loop uses cont to signal errors to SetupConn.
 	caps  []Cap      // valid after the protocol handshake
 	name  string     // valid after the protocol handshake
+
+	handshake rlpx.HandshakeType // valid after the encryption handshake
 }
 
 type transport interface {
@@ -382,6 +385,9 @@ func (srv *Server) Start() (err error) {
 	}
 	if srv.newTransport == nil {
 		srv.newTransport = newRLPX
+		if srv.PostQuantumHandshake {
+			srv.newTransport = newHybridRLPX
+		}
 	}
 	if srv.listenFunc == nil {
 		srv.listenFunc = net.Listen
@@ -911,7 +917,10 @@ func (srv *Server) setupConn(c *conn, dialDest *enode.Node) error {
 	} else {
 		c.node = nodeFromConn(remotePubkey, c.fd)
 	}
-	clog := srv.log.New("id", c.node.ID(), "addr", c.fd.RemoteAddr(), "conn", c.flags)
+	if t, ok := c.transport.(interface{ handshakeType() rlpx.HandshakeType }); ok {
+		c.handshake = t.handshakeType()
+	}
+	clog := srv.log.New("id", c.node.ID(), "addr", c.fd.RemoteAddr(), "conn", c.flags, "handshake", c.handshake)
 	err = srv.checkpoint(c, srv.checkpointPostHandshake)
 	if err != nil {
 		clog.Trace("Rejected peer", "err", err)
@@ -629,3 +629,56 @@ func syncAddPeer(srv *Server, node *enode.Node) bool {
 		}
 	}
 }
+
+// This test checks that the hybrid RLPx handshake is negotiated between servers
+// and reported in the peer info.
+func TestServerPostQuantumHandshake(t *testing.T) {
+	tests := []struct {
+		dialer, listener bool
+		want             string
+	}{
+		{dialer: true, listener: true, want: "secp256k1+mlkem768"},
+		{dialer: true, listener: false, want: "secp256k1"},
+		{dialer: false, listener: true, want: "secp256k1"},
+	}
+	for _, test := range tests {
+		var (
+			dialerPeers   = make(chan *Peer, 1)
+			listenerPeers = make(chan *Peer, 1)
+		)
+		start := func(pq bool, peers chan *Peer) *Server {
+			srv := &Server{
+				Config: Config{
+					Name:                 "test",
+					MaxPeers:             10,
+					ListenAddr:           "127.0.0.1:0",
+					NoDiscovery:          true,
+					PrivateKey:           newkey(),
+					PostQuantumHandshake: pq,
+					Logger:               testlog.Logger(t, log.LvlTrace),
+				},
+				newPeerHook: func(p *Peer) { peers <- p },
+			}
+			if err := srv.Start(); err != nil {
+				t.Fatalf("could not start server: %v", err)
+			}
+			return srv
+		}
+		listener := start(test.listener, listenerPeers)
+		dialer := start(test.dialer, dialerPeers)
+		dialer.AddPeer(listener.Self())
+
+		for _, peers := range []chan *Peer{dialerPeers, listenerPeers} {
+			select {
+			case p := <-peers:
+				if have := p.Info().Network.Handshake; have != test.want {
+					t.Errorf("dialer %v, listener %v: wrong handshake %q, want %q", test.dialer, test.listener, have, test.want)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("peer not connected")
+			}
+		}
+		dialer.Stop()
+		listener.Stop()
+	}
+}
@@ -56,6 +56,14 @@ func newRLPX(conn net.Conn, dialDest *ecdsa.PublicKey) transport {
 	return &rlpxTransport{conn: rlpx.NewConn(conn, dialDest)}
 }
 
+// newHybridRLPX is like newRLPX, but the transport offers and accepts the hybrid
+// post-quantum handshake.
+func newHybridRLPX(conn net.Conn, dialDest *ecdsa.PublicKey) transport {
+	t := &rlpxTransport{conn: rlpx.NewConn(conn, dialDest)}
+	t.conn.SetHybridHandshake(true)
+	return t
+}
+
 func (t *rlpxTransport) ReadMsg() (Msg, error) {
 	t.rmu.Lock()
 	defer t.rmu.Unlock()
@@ -131,6 +139,11 @@ func (t *rlpxTransport) doEncHandshake(prv *ecdsa.PrivateKey) (*ecdsa.PublicKey,
 	return t.conn.Handshake(prv)
 }
 
+// handshakeType returns the key exchange negotiated by the encryption handshake.
+func (t *rlpxTransport) handshakeType() rlpx.HandshakeType {
+	return t.conn.HandshakeType()
+}
+
 func (t *rlpxTransport) doProtoHandshake(our *protoHandshake) (their *protoHandshake, err error) {
 	// Writing our handshake happens concurrently, we prefer
 	// returning the handshake read error. If the remote side
@@ -417,6 +417,11 @@ type ChainConfig struct {
 	BPO4Time     *uint64 `json:"bpo4Time,omitempty"`     // BPO4 switch time (nil = no fork, 0 = already on bpo4)
 	BPO5Time     *uint64 `json:"bpo5Time,omitempty"`     // BPO5 switch time (nil = no fork, 0 = already on bpo5)
 
+	// PostQuantumTime is the Lux specific switch time activating the
+	// post-quantum precompiled contracts (ML-DSA, ML-KEM, SLH-DSA). It is
+	// independent of the Ethereum fork schedule, but requires Prague.
+	PostQuantumTime *uint64 `json:"postQuantumTime,omitempty"` // PostQuantum switch time (nil = no fork, 0 = already on post-quantum)
+
 	// TerminalTotalDifficulty is the amount of total difficulty reached by
 	// the network that triggers the consensus upgrade.
 	TerminalTotalDifficulty *big.Int `json:"terminalTotalDifficulty,omitempty"`
@@ -551,6 +556,9 @@ func (c *ChainConfig) Description() string {
 	if c.BPO5Time != nil {
 		banner += fmt.Sprintf(" - BPO5:                      @%-10v\n", *c.BPO5Time)
 	}
+	if c.PostQuantumTime != nil {
+		banner += fmt.Sprintf(" - PostQuantum (Lux):         @%-10v\n", *c.PostQuantumTime)
+	}
 	return banner
 }
 
@@ -704,6 +712,12 @@ func (c *ChainConfig) IsBPO5(num *big.Int, time uint64) bool {
 	return c.IsLondon(num) && isTimestampForked(c.BPO5Time, time)
 }
 
+// IsPostQuantum returns whether time is either equal to the Lux post-quantum
+// fork time or greater.
+func (c *ChainConfig) IsPostQuantum(num *big.Int, time uint64) bool {
+	return c.IsLondon(num) && isTimestampForked(c.PostQuantumTime, time)
+}
+
 // IsVerkleGenesis checks whether the verkle fork is activated at the genesis block.
 //
 // Verkle mode is considered enabled if the verkle fork time is configured,
@@ -820,6 +834,25 @@ func (c *ChainConfig) CheckConfigForkOrder() error {
 		}
 	}
 
+	// The post-quantum fork is not part of the Ethereum fork sequence, so it
+	// is checked separately: it extends the Prague precompile set and hence
+	// must not be scheduled before Prague. Verkle replaces the precompile set
+	// altogether, so the two cannot be combined.
+	if c.PostQuantumTime != nil {
+		if c.VerkleTime != nil {
+			return fmt.Errorf("unsupported fork ordering: postQuantumTime enabled at timestamp %v, but verkleTime enabled at timestamp %v",
+				*c.PostQuantumTime, *c.VerkleTime)
+		}
+		if c.PragueTime == nil {
+			return fmt.Errorf("unsupported fork ordering: pragueTime not enabled, but postQuantumTime enabled at timestamp %v",
+				*c.PostQuantumTime)
+		}
+		if *c.PragueTime > *c.PostQuantumTime {
+			return fmt.Errorf("unsupported fork ordering: pragueTime enabled at timestamp %v, but postQuantumTime enabled at timestamp %v",
+				*c.PragueTime, *c.PostQuantumTime)
+		}
+	}
+
 	// Check that all forks with blobs explicitly define the blob schedule configuration.
 	bsc := c.BlobScheduleConfig
 	if bsc == nil {
@@ -953,6 +986,9 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, headNumber *big.Int,
 	if isForkTimestampIncompatible(c.BPO5Time, newcfg.BPO5Time, headTimestamp) {
 		return newTimestampCompatError("BPO5 fork timestamp", c.BPO5Time, newcfg.BPO5Time)
 	}
+	if isForkTimestampIncompatible(c.PostQuantumTime, newcfg.PostQuantumTime, headTimestamp) {
+		return newTimestampCompatError("PostQuantum fork timestamp", c.PostQuantumTime, newcfg.PostQuantumTime)
+	}
 	return nil
 }
 
@@ -1144,6 +1180,7 @@ type Rules struct {
 	IsBerlin, IsLondon                                      bool
 	IsMerge, IsShanghai, IsCancun, IsPrague, IsOsaka        bool
 	IsVerkle                                                bool
+	IsPostQuantum                                           bool
 }
 
 // Rules ensures c's ChainID is not nil.
@@ -1175,5 +1212,6 @@ func (c *ChainConfig) Rules(num *big.Int, isMerge bool, timestamp uint64) Rules
 		IsOsaka:          isMerge && c.IsOsaka(num, timestamp),
 		IsVerkle:         isVerkle,
 		IsEIP4762:        isVerkle,
+		IsPostQuantum:    isMerge && !isVerkle && c.IsPostQuantum(num, timestamp),
 	}
 }
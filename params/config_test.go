@@ -110,6 +110,17 @@ func TestCheckCompatible(t *testing.T) {
 				RewindToTime: 9,
 			},
 		},
+		{
+			stored:        &ChainConfig{PostQuantumTime: newUint64(10)},
+			new:           &ChainConfig{PostQuantumTime: nil},
+			headTimestamp: 25,
+			wantErr: &ConfigCompatError{
+				What:         "PostQuantum fork timestamp",
+				StoredTime:   newUint64(10),
+				NewTime:      nil,
+				RewindToTime: 9,
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -139,6 +150,48 @@ func TestConfigRules(t *testing.T) {
 	}
 }
 
+func TestPostQuantumForkOrder(t *testing.T) {
+	base := func() *ChainConfig {
+		c := *AllDevChainProtocolChanges
+		return &c
+	}
+	// Post-quantum may be scheduled at or after Prague.
+	c := base()
+	c.PostQuantumTime = newUint64(0)
+	require.NoError(t, c.CheckConfigForkOrder())
+
+	// Post-quantum without Prague is rejected.
+	c = base()
+	c.PragueTime = nil
+	c.PostQuantumTime = newUint64(10)
+	require.Error(t, c.CheckConfigForkOrder())
+
+	// Post-quantum before Prague is rejected.
+	c = base()
+	c.PragueTime = newUint64(20)
+	c.PostQuantumTime = newUint64(10)
+	require.Error(t, c.CheckConfigForkOrder())
+
+	// Post-quantum cannot be combined with Verkle.
+	c = base()
+	c.PostQuantumTime = newUint64(10)
+	c.VerkleTime = newUint64(20)
+	require.Error(t, c.CheckConfigForkOrder())
+	if r := c.Rules(big.NewInt(0), true, 20); r.IsPostQuantum {
+		t.Errorf("expected verkle rules to not be post-quantum")
+	}
+
+	// The rules only report post-quantum after the fork time.
+	c = base()
+	c.PostQuantumTime = newUint64(100)
+	if r := c.Rules(big.NewInt(0), true, 99); r.IsPostQuantum {
+		t.Errorf("expected %v to not be post-quantum", 99)
+	}
+	if r := c.Rules(big.NewInt(0), true, 100); !r.IsPostQuantum {
+		t.Errorf("expected %v to be post-quantum", 100)
+	}
+}
+
 func TestTimestampCompatError(t *testing.T) {
 	require.Equal(t, new(ConfigCompatError).Error(), "")
 
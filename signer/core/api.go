@@ -44,9 +44,9 @@ const (
 	// numberOfAccountsToDerive For hardware wallets, the number of accounts to derive
 	numberOfAccountsToDerive = 10
 	// ExternalAPIVersion -- see extapi_changelog.md
-	ExternalAPIVersion = "6.1.0"
+	ExternalAPIVersion = "6.2.0"
 	// InternalAPIVersion -- see intapi_changelog.md
-	InternalAPIVersion = "7.0.1"
+	InternalAPIVersion = "7.1.0"
 )
 
 // ExternalAPI defines the external API through which signing requests are made.
@@ -224,6 +224,7 @@ type (
 	// SignTxRequest contains info about a Transaction to sign
 	SignTxRequest struct {
 		Transaction apitypes.SendTxArgs       `json:"transaction"`
+		Algorithm   string                    `json:"algorithm"`
 		Callinfo    []apitypes.ValidationInfo `json:"call_info"`
 		Meta        Metadata                  `json:"meta"`
 	}
@@ -236,6 +237,7 @@ type (
 	SignDataRequest struct {
 		ContentType string                    `json:"content_type"`
 		Address     common.MixedcaseAddress   `json:"address"`
+		Algorithm   string                    `json:"algorithm"`
 		Rawdata     []byte                    `json:"raw_data"`
 		Messages    []*apitypes.NameValueType `json:"messages"`
 		Callinfo    []apitypes.ValidationInfo `json:"call_info"`
@@ -419,12 +421,12 @@ func (api *SignerAPI) New(ctx context.Context) (common.Address, error) {
 	} else if !resp.Approved {
 		return common.Address{}, ErrRequestDenied
 	}
-	return api.newAccount()
+	return api.newAccount(keystore.SignatureECDSA)
 }
 
-// newAccount is the internal method to create a new account. It should be used
-// _after_ user-approval has been obtained
-func (api *SignerAPI) newAccount() (common.Address, error) {
+// newAccount is the internal method to create a new account of the given
+// signature algorithm. It should be used _after_ user-approval has been obtained
+func (api *SignerAPI) newAccount(algorithm keystore.SignatureAlgorithm) (common.Address, error) {
 	be := api.am.Backends(keystore.KeyStoreType)
 	if len(be) == 0 {
 		return common.Address{}, errors.New("password based accounts not supported")
@@ -443,8 +445,19 @@ func (api *SignerAPI) newAccount() (common.Address, error) {
 			api.UI.ShowError(fmt.Sprintf("Account creation attempt #%d failed due to password requirements: %v", i+1, pwErr))
 		} else {
 			// No error
-			acc, err := be[0].(*keystore.KeyStore).NewAccount(resp.Text)
-			log.Info("Your new key was generated", "address", acc.Address)
+			var (
+				ks  = be[0].(*keystore.KeyStore)
+				acc accounts.Account
+			)
+			if algorithm == keystore.SignatureECDSA {
+				acc, err = ks.NewAccount(resp.Text)
+			} else {
+				acc, err = ks.NewPostQuantumAccount(algorithm, resp.Text)
+			}
+			if err != nil {
+				return common.Address{}, err
+			}
+			log.Info("Your new key was generated", "address", acc.Address, "algorithm", algorithm)
 			log.Warn("Please backup your key file!", "path", acc.URL.Path)
 			log.Warn("Please remember your password!")
 			return acc.Address, err
@@ -518,6 +531,47 @@ func logDiff(original *SignTxRequest, new *SignTxResponse) bool {
 	return modified
 }
 
+// accountAlgorithm returns the signature algorithm of an account. Accounts which
+// are not in the keystore are secp256k1 accounts.
+func (api *SignerAPI) accountAlgorithm(address common.Address) (keystore.SignatureAlgorithm, error) {
+	ks := fetchKeystore(api.am)
+	if ks == nil || !ks.HasAddress(address) {
+		return keystore.SignatureECDSA, nil
+	}
+	return ks.Algorithm(accounts.Account{Address: address})
+}
+
+// setPQTxArgs turns the transaction into a post-quantum transaction if the
+// sender is a post-quantum account, and rejects transactions whose algorithm
+// does not match the sender's.
+func (api *SignerAPI) setPQTxArgs(args *apitypes.SendTxArgs, algorithm keystore.SignatureAlgorithm) error {
+	if algorithm == keystore.SignatureECDSA {
+		if args.PQAlgorithm != nil {
+			return errors.New("post-quantum transaction requested for a secp256k1 account")
+		}
+		return nil
+	}
+	txAlgo, ok := algorithm.TxAlgorithm()
+	if !ok {
+		return fmt.Errorf("%s accounts cannot sign transactions", algorithm)
+	}
+	// Post-quantum transactions are dynamic fee transactions, reject anything
+	// else before bothering the user
+	if args.GasPrice != nil || args.MaxFeePerGas == nil || args.MaxPriorityFeePerGas == nil {
+		return errors.New("post-quantum accounts require maxFeePerGas and maxPriorityFeePerGas to be set, and gasPrice to be unset")
+	}
+	if args.PQAlgorithm == nil {
+		args.PQAlgorithm = (*hexutil.Uint64)(new(uint64))
+		*args.PQAlgorithm = hexutil.Uint64(txAlgo)
+	} else if uint64(*args.PQAlgorithm) != uint64(txAlgo) {
+		return fmt.Errorf("transaction algorithm %d does not match %s account", uint64(*args.PQAlgorithm), algorithm)
+	}
+	if args.ChainID == nil {
+		args.ChainID = (*hexutil.Big)(new(big.Int).Set(api.chainID))
+	}
+	return nil
+}
+
 func (api *SignerAPI) lookupPassword(address common.Address) (string, error) {
 	return api.credentials.Get(address.Hex())
 }
@@ -555,6 +609,13 @@ func (api *SignerAPI) SignTransaction(ctx context.Context, args apitypes.SendTxA
 			return nil, err
 		}
 	}
+	algorithm, err := api.accountAlgorithm(args.From.Address())
+	if err != nil {
+		return nil, err
+	}
+	if err := api.setPQTxArgs(&args, algorithm); err != nil {
+		return nil, err
+	}
 	if args.ChainID != nil {
 		requestedChainId := (*big.Int)(args.ChainID)
 		if api.chainID.Cmp(requestedChainId) != 0 {
@@ -565,6 +626,7 @@ func (api *SignerAPI) SignTransaction(ctx context.Context, args apitypes.SendTxA
 	}
 	req := SignTxRequest{
 		Transaction: args,
+		Algorithm:   algorithm.String(),
 		Meta:        MetadataFromContext(ctx),
 		Callinfo:    msgs.Messages,
 	}
@@ -31,6 +31,7 @@ import (
 	"github.com/luxfi/geth/common"
 	"github.com/luxfi/geth/common/hexutil"
 	"github.com/luxfi/geth/core/types"
+	"github.com/luxfi/geth/crypto/pqcrypto"
 	"github.com/luxfi/geth/internal/ethapi"
 	"github.com/luxfi/geth/rlp"
 	"github.com/luxfi/geth/signer/core"
@@ -320,3 +321,70 @@ func TestSignTx(t *testing.T) {
 		t.Error("Expected tx to be modified by UI")
 	}
 }
+
+func TestSignPostQuantum(t *testing.T) {
+	t.Parallel()
+	api, control := setup(t)
+	algorithm := "mldsa44"
+	control.inputCh <- "a_long_password"
+	addr, err := core.NewUIServerAPI(api).New(context.Background(), &algorithm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Some time to allow changes to propagate
+	time.Sleep(250 * time.Millisecond)
+	a := common.NewMixedcaseAddress(addr)
+
+	// Sign a transaction, leaving the algorithm and chain id up to clef
+	var (
+		to     = common.NewMixedcaseAddress(common.HexToAddress("0x1337"))
+		feeCap = (hexutil.Big)(*big.NewInt(2000000000))
+		tip    = (hexutil.Big)(*big.NewInt(1))
+		tx     = apitypes.SendTxArgs{
+			From:                 a,
+			To:                   &to,
+			Gas:                  21000,
+			MaxFeePerGas:         &feeCap,
+			MaxPriorityFeePerGas: &tip,
+			Value:                (hexutil.Big)(*big.NewInt(1e18)),
+		}
+	)
+	control.approveCh <- "Y"
+	control.inputCh <- "a_long_password"
+	res, err := api.SignTransaction(context.Background(), tx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsedTx := new(types.Transaction)
+	if err := parsedTx.UnmarshalBinary(res.Raw); err != nil {
+		t.Fatal(err)
+	}
+	if parsedTx.Type() != types.PQTxType {
+		t.Fatalf("wrong transaction type: have %d, want %d", parsedTx.Type(), types.PQTxType)
+	}
+	if from, err := types.Sender(types.LatestSignerForChainID(big.NewInt(1337)), parsedTx); err != nil || from != addr {
+		t.Fatalf("wrong sender: have %v (%v), want %v", from, err, addr)
+	}
+	algo, pub, _ := parsedTx.PQSignature()
+	if pqcrypto.Algorithm(algo) != pqcrypto.AlgoMLDSA44 {
+		t.Fatalf("wrong algorithm: have %d, want %d", algo, pqcrypto.AlgoMLDSA44)
+	}
+
+	// Legacy transactions can't be signed by post-quantum accounts
+	legacy := mkTestTx(a)
+	if _, err := api.SignTransaction(context.Background(), legacy, nil); err == nil {
+		t.Fatal("expected error signing legacy transaction")
+	}
+
+	// Data signatures are plain ML-DSA signatures of the hash, without V
+	msg := []byte("EHLO world")
+	control.approveCh <- "Y"
+	control.inputCh <- "a_long_password"
+	signature, err := api.SignData(context.Background(), apitypes.TextPlain.Mime, a, hexutil.Encode(msg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pqcrypto.Verify(pqcrypto.AlgoMLDSA44, pub, accounts.TextHash(msg), signature) {
+		t.Fatalf("invalid signature %x", signature)
+	}
+}
@@ -111,6 +111,9 @@ type SendTxArgs struct {
 	Blobs       []kzg4844.Blob       `json:"blobs,omitempty"`
 	Commitments []kzg4844.Commitment `json:"commitments,omitempty"`
 	Proofs      []kzg4844.Proof      `json:"proofs,omitempty"`
+
+	// For PQTxType, the pqcrypto.Algorithm of the signing key
+	PQAlgorithm *hexutil.Uint64 `json:"pqAlgorithm,omitempty"`
 }
 
 func (args SendTxArgs) String() string {
@@ -145,6 +148,29 @@ func (args *SendTxArgs) ToTransaction() (*types.Transaction, error) {
 	}
 	var data types.TxData
 	switch {
+	case args.PQAlgorithm != nil:
+		al := types.AccessList{}
+		if args.AccessList != nil {
+			al = *args.AccessList
+		}
+		if args.MaxFeePerGas == nil || args.MaxPriorityFeePerGas == nil {
+			return nil, errors.New("maxFeePerGas and maxPriorityFeePerGas must be set for post-quantum transactions")
+		}
+		if args.ChainID == nil {
+			return nil, errors.New("chainId must be set for post-quantum transactions")
+		}
+		data = &types.PQTx{
+			To:         to,
+			ChainID:    uint256.MustFromBig((*big.Int)(args.ChainID)),
+			Nonce:      uint64(args.Nonce),
+			Gas:        uint64(args.Gas),
+			GasFeeCap:  uint256.MustFromBig((*big.Int)(args.MaxFeePerGas)),
+			GasTipCap:  uint256.MustFromBig((*big.Int)(args.MaxPriorityFeePerGas)),
+			Value:      uint256.MustFromBig((*big.Int)(&args.Value)),
+			Data:       args.data(),
+			AccessList: al,
+			Algorithm:  uint8(*args.PQAlgorithm),
+		}
 	case args.BlobHashes != nil:
 		al := types.AccessList{}
 		if args.AccessList != nil {
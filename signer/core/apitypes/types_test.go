@@ -67,6 +67,12 @@ func TestTxArgs(t *testing.T) {
 			want:     common.HexToHash("0x7919e2b0b9b543cb87a137b6ff66491ec7ae937cb88d3c29db4d9b28073dce53"),
 			wantType: types.DynamicFeeTxType,
 		},
+		{
+			// the signing algorithm selects a post-quantum transaction
+			data:     []byte(`{"from":"0x1b442286e32ddcaa6e2570ce9ed85f4b4fc87425","accessList":[],"chainId":"0x7","gas":"0x124f8","input":"0x","maxFeePerGas":"0x6fc23ac00","maxPriorityFeePerGas":"0x3b9aca00","nonce":"0x0","pqAlgorithm":"0x2","to":"0x1b442286e32ddcaa6e2570ce9ed85f4b4fc87425","value":"0x0"}`),
+			want:     common.HexToHash("0x99325a9517a47b195b911adaba0442ae1e0e3db96e15570c86076739631fbd95"),
+			wantType: types.PQTxType,
+		},
 	} {
 		var txArgs SendTxArgs
 		if err := json.Unmarshal(tc.data, &txArgs); err != nil {
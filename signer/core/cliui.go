@@ -25,6 +25,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/luxfi/geth/accounts/keystore"
 	"github.com/luxfi/geth/common/hexutil"
 	"github.com/luxfi/geth/console/prompt"
 	"github.com/luxfi/geth/internal/ethapi"
@@ -94,6 +95,15 @@ func sanitize(txt string, limit int) string {
 	return fmt.Sprintf("%q", txt)
 }
 
+// algorithmName returns the display name of a signature algorithm short name.
+func algorithmName(name string) string {
+	alg, err := keystore.ParseSignatureAlgorithm(name)
+	if err != nil {
+		return sanitize(name, 20)
+	}
+	return keystore.GetAlgorithmName(alg)
+}
+
 func showMetadata(metadata Metadata) {
 	fmt.Printf("Request context:\n\t%v -> %v -> %v\n", metadata.Remote, metadata.Scheme, metadata.Local)
 	fmt.Printf("\nAdditional HTTP header data, provided by the external caller:\n")
@@ -115,6 +125,9 @@ func (ui *CommandlineUI) ApproveTx(request *SignTxRequest) (SignTxResponse, erro
 		fmt.Printf("to:    <contact creation>\n")
 	}
 	fmt.Printf("from:               %v\n", request.Transaction.From.String())
+	if request.Algorithm != "" {
+		fmt.Printf("algorithm:          %v\n", algorithmName(request.Algorithm))
+	}
 	fmt.Printf("value:              %v wei\n", weival)
 	fmt.Printf("gas:                %v (%v)\n", request.Transaction.Gas, uint64(request.Transaction.Gas))
 	if request.Transaction.MaxFeePerGas != nil {
@@ -171,6 +184,9 @@ func (ui *CommandlineUI) ApproveSignData(request *SignDataRequest) (SignDataResp
 
 	fmt.Printf("-------- Sign data request--------------\n")
 	fmt.Printf("Account:  %s\n", request.Address.String())
+	if request.Algorithm != "" {
+		fmt.Printf("Algorithm:  %s\n", algorithmName(request.Algorithm))
+	}
 	if len(request.Callinfo) != 0 {
 		fmt.Printf("\nValidation messages:\n")
 		for _, m := range request.Callinfo {
@@ -24,6 +24,7 @@ import (
 	"mime"
 
 	"github.com/luxfi/geth/accounts"
+	"github.com/luxfi/geth/accounts/keystore"
 	"github.com/luxfi/geth/common"
 	"github.com/luxfi/geth/common/hexutil"
 	"github.com/luxfi/geth/consensus/clique"
@@ -35,9 +36,17 @@ import (
 
 // sign receives a request and produces a signature
 //
-// Note, the produced signature conforms to the secp256k1 curve R, S and V values,
-// where the V value will be 27 or 28 for legacy reasons, if legacyV==true.
+// Note, for secp256k1 accounts the produced signature conforms to the secp256k1
+// curve R, S and V values, where the V value will be 27 or 28 for legacy reasons,
+// if legacyV==true. Post-quantum accounts sign the same hash with their own
+// algorithm, and their signatures are returned as is.
 func (api *SignerAPI) sign(req *SignDataRequest, legacyV bool) (hexutil.Bytes, error) {
+	algorithm, err := api.accountAlgorithm(req.Address.Address())
+	if err != nil {
+		return nil, err
+	}
+	req.Algorithm = algorithm.String()
+
 	// We make the request prior to looking up if we actually have the account, to prevent
 	// account-enumeration via the API
 	res, err := api.UI.ApproveSignData(req)
@@ -53,6 +62,9 @@ func (api *SignerAPI) sign(req *SignDataRequest, legacyV bool) (hexutil.Bytes, e
 	if err != nil {
 		return nil, err
 	}
+	if algorithm != keystore.SignatureECDSA && req.ContentType == apitypes.ApplicationClique.Mime {
+		return nil, errors.New("clique headers can only be signed by secp256k1 accounts")
+	}
 	pw, err := api.lookupOrQueryPassword(account.Address,
 		"Password for signing",
 		fmt.Sprintf("Please enter password for signing data with account %s", account.Address.Hex()))
@@ -64,7 +76,7 @@ func (api *SignerAPI) sign(req *SignDataRequest, legacyV bool) (hexutil.Bytes, e
 	if err != nil {
 		return nil, err
 	}
-	if legacyV {
+	if legacyV && algorithm == keystore.SignatureECDSA {
 		signature[64] += 27 // Transform V from 0/1 to 27/28 according to the yellow paper
 	}
 	return signature, nil
@@ -300,7 +312,8 @@ func (api *SignerAPI) EcRecover(ctx context.Context, data hexutil.Bytes, sig hex
 	// addr = ecrecover(hash, signature)
 	//
 	// Note, the signature must conform to the secp256k1 curve R, S and V values, where
-	// the V value must be 27 or 28 for legacy reasons.
+	// the V value must be 27 or 28 for legacy reasons. Post-quantum signatures do not
+	// allow recovering the signer and have to be verified against its public key.
 	//
 	// https://geth.ethereum.org/docs/tools/clef/apis#account-ecrecover
 	if len(sig) != 65 {
@@ -204,9 +204,19 @@ func (api *UIServerAPI) Import(ctx context.Context, keyJSON json.RawMessage, old
 // in the keystore location that was specified when this API was created.
 // This method is the same as New on the external API, the difference being that
 // this implementation does not ask for confirmation, since it's initiated by
-// the user
-func (api *UIServerAPI) New(ctx context.Context) (common.Address, error) {
-	return api.extApi.newAccount()
+// the user, and that it can create post-quantum accounts: the optional algorithm
+// is a short name such as "mldsa65", defaulting to "secp256k1".
+// Example call
+// {"jsonrpc":"2.0","method":"clef_new","params":["mldsa65"], "id":9}
+func (api *UIServerAPI) New(ctx context.Context, algorithm *string) (common.Address, error) {
+	alg := keystore.SignatureECDSA
+	if algorithm != nil {
+		var err error
+		if alg, err = keystore.ParseSignatureAlgorithm(*algorithm); err != nil {
+			return common.Address{}, err
+		}
+	}
+	return api.extApi.newAccount(alg)
 }
 
 // Other methods to be added, not yet implemented are:
@@ -624,3 +624,70 @@ function ApproveSignData(r){
 		t.Fatalf("Expected approved")
 	}
 }
+
+func TestAlgorithmRestriction(t *testing.T) {
+	t.Parallel()
+	js := `
+var allowed = {
+	"0x0000000000000000000000000000000000001337": ["mldsa65", "slhdsa128s"],
+}
+function algorithmAllowed(address, algorithm) {
+	var list = allowed[address.toLowerCase()]
+	if (list === undefined) {
+		return algorithm == "secp256k1"
+	}
+	return list.indexOf(algorithm) >= 0
+}
+function ApproveTx(r) {
+	if (!algorithmAllowed(r.transaction.from, r.algorithm)) {
+		return "Reject"
+	}
+	return "Approve"
+}
+function ApproveSignData(r) {
+	if (!algorithmAllowed(r.address, r.algorithm)) {
+		return "Reject"
+	}
+	return "Approve"
+}`
+	r, err := initRuleEngine(js)
+	if err != nil {
+		t.Fatalf("Couldn't create evaluator %v", err)
+	}
+	pq, _ := mixAddr("0x0000000000000000000000000000000000001337")
+	other, _ := mixAddr("0x000000000000000000000000000000000000dead")
+
+	for i, tt := range []struct {
+		from      *common.MixedcaseAddress
+		algorithm string
+		want      bool
+	}{
+		{pq, "mldsa65", true},
+		{pq, "slhdsa128s", true},
+		{pq, "mldsa44", false},
+		{pq, "secp256k1", false},
+		{other, "secp256k1", true},
+		{other, "mldsa65", false},
+	} {
+		txResp, err := r.ApproveTx(&core.SignTxRequest{
+			Transaction: apitypes.SendTxArgs{From: *tt.from},
+			Algorithm:   tt.algorithm,
+		})
+		if err != nil {
+			t.Fatalf("test %d: unexpected error %v", i, err)
+		}
+		if txResp.Approved != tt.want {
+			t.Errorf("test %d: tx approval mismatch: have %v, want %v", i, txResp.Approved, tt.want)
+		}
+		dataResp, err := r.ApproveSignData(&core.SignDataRequest{
+			Address:   *tt.from,
+			Algorithm: tt.algorithm,
+		})
+		if err != nil {
+			t.Fatalf("test %d: unexpected error %v", i, err)
+		}
+		if dataResp.Approved != tt.want {
+			t.Errorf("test %d: data approval mismatch: have %v, want %v", i, dataResp.Approved, tt.want)
+		}
+	}
+}
@@ -141,6 +141,17 @@ func (db *Database) HistoricReader(root common.Hash) (*pathdb.HistoricalStateRea
 	return pdb.HistoricReader(root)
 }
 
+// HistoricNodes returns a node database holding the trie nodes of the requested
+// historic state, rebuilt from the state histories. It's only supported by
+// path-based database and will return an error for others.
+func (db *Database) HistoricNodes(root common.Hash) (database.NodeDatabase, error) {
+	pdb, ok := db.backend.(*pathdb.Database)
+	if !ok {
+		return nil, errors.New("not supported")
+	}
+	return pdb.HistoricNodes(root)
+}
+
 // Update performs a state transition by committing dirty nodes contained in the
 // given set in order to update state from the specified parent to the specified
 // root. The held pre-images accumulated up to this point will be flushed in case
@@ -70,3 +70,42 @@ func (db *Database) HistoryRange() (uint64, uint64, error) {
 	}
 	return pdb.HistoryRange()
 }
+
+// AccountChanges returns the mutations of the account made by the blocks in the
+// range [from, to], at most limit of them, and whether the range holds more.
+//
+// This function is only supported by path mode database.
+func (db *Database) AccountChanges(address common.Address, from, to uint64, limit int) ([]pathdb.StateChange, bool, error) {
+	pdb, ok := db.backend.(*pathdb.Database)
+	if !ok {
+		return nil, false, errors.New("not supported")
+	}
+	return pdb.AccountChanges(address, from, to, limit)
+}
+
+// StorageChanges returns the mutations of the storage slot made by the blocks in
+// the range [from, to], at most limit of them, and whether the range holds more.
+//
+// Note, slot refers to the raw slot key.
+//
+// This function is only supported by path mode database.
+func (db *Database) StorageChanges(address common.Address, slot common.Hash, from, to uint64, limit int) ([]pathdb.StateChange, bool, error) {
+	pdb, ok := db.backend.(*pathdb.Database)
+	if !ok {
+		return nil, false, errors.New("not supported")
+	}
+	return pdb.StorageChanges(address, slot, from, to, limit)
+}
+
+// StateDiff returns a page of the net difference between the state specified
+// by the root from and its descendant state specified by to, starting from the
+// account start, along with the account to resume from.
+//
+// This function is only supported by path mode database.
+func (db *Database) StateDiff(from, to common.Hash, start common.Address, limit int) ([]pathdb.AccountDiff, *common.Address, error) {
+	pdb, ok := db.backend.(*pathdb.Database)
+	if !ok {
+		return nil, nil, errors.New("not supported")
+	}
+	return pdb.StateDiff(from, to, start, limit)
+}
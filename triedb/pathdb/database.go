@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/luxfi/geth/common"
+	"github.com/luxfi/geth/common/lru"
 	"github.com/luxfi/geth/core/rawdb"
 	"github.com/luxfi/geth/core/types"
 	"github.com/luxfi/crypto"
@@ -122,6 +123,7 @@ type Config struct {
 	WriteBufferSize     int    // Maximum memory allowance (in bytes) for write buffer
 	ReadOnly            bool   // Flag whether the database is opened in read only mode
 	JournalDirectory    string // Absolute path of journal directory (null means the journal data is persisted in key-value store)
+	ProofHistory        uint64 // Maximum number of state histories reverted to rebuild the tries of a historic state (0 = disabled)
 
 	// Testing configurations
 	SnapshotNoBuild   bool // Flag Whether the state generation is allowed
@@ -161,6 +163,9 @@ func (c *Config) fields() []interface{} {
 	if c.JournalDirectory != "" {
 		list = append(list, "journal-dir", c.JournalDirectory)
 	}
+	if c.ProofHistory != 0 {
+		list = append(list, "proof-history", c.ProofHistory)
+	}
 	return list
 }
 
@@ -225,6 +230,8 @@ type Database struct {
 	freezer ethdb.ResettableAncientStore // Freezer for storing trie histories, nil possible in tests
 	lock    sync.RWMutex                 // Lock to prevent mutations from happening at the same time
 	indexer *historyIndexer              // History indexer
+
+	historic *lru.Cache[common.Hash, *historicNodes] // Cache of the rebuilt tries of historic states
 }
 
 // New attempts to load an already existing layer from a persistent key-value
@@ -242,6 +249,7 @@ func New(diskdb ethdb.Database, config *Config, isVerkle bool) *Database {
 		config:   config,
 		diskdb:   diskdb,
 		hasher:   merkleNodeHasher,
+		historic: lru.NewCache[common.Hash, *historicNodes](historicNodesCacheSize),
 	}
 	// Establish a dedicated database namespace tailored for verkle-specific
 	// data, ensuring the isolation of both verkle and merkle tree data. It's
@@ -300,7 +308,7 @@ func (db *Database) repairHistory() error {
 		// all of them. Fix the tests first.
 		return nil
 	}
-	freezer, err := rawdb.NewStateFreezer(ancient, db.isVerkle, db.readOnly)
+	freezer, err := rawdb.NewStateFreezer(ancient, db.isVerkle, db.readOnly, rawdb.AncientCipher(db.diskdb))
 	if err != nil {
 		log.Crit("Failed to open state history freezer", "err", err)
 	}
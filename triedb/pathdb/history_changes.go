@@ -0,0 +1,157 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package pathdb
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/luxfi/crypto"
+	"github.com/luxfi/geth/common"
+	"github.com/luxfi/geth/core/rawdb"
+	"github.com/luxfi/geth/ethdb"
+)
+
+// StateChange describes a mutation of an account or a storage slot made by
+// the state transition of a single block.
+type StateChange struct {
+	ID    uint64 // ID of the state history recording the mutation
+	Block uint64 // Number of the block making the mutation
+	Prev  []byte // Value before the mutation, empty if the state didn't exist
+	Post  []byte // Value after the mutation, empty if the state was deleted
+}
+
+// AccountChanges returns the mutations of the account made by the blocks in the
+// range [from, to], oldest first and at most limit of them.
+// The boolean result reports whether the range holds more mutations than the
+// ones returned. The account values are in the slim RLP format.
+//
+// Only the blocks with a state history in the local store are covered, which
+// excludes the pruned ones and the ones above the disk layer. The lookup is
+// served by the state history index, which must be complete.
+func (db *Database) AccountChanges(address common.Address, from, to uint64, limit int) ([]StateChange, bool, error) {
+	addrHash := common.BytesToHash(crypto.Keccak256(address.Bytes()))
+	return db.stateChanges(newAccountIdentQuery(address, addrHash), from, to, limit)
+}
+
+// StorageChanges returns the mutations of the storage slot made by the blocks in
+// the range [from, to], oldest first and at most limit of them.
+// The boolean result reports whether the range holds more mutations than the
+// ones returned. The slot values are RLP encoded.
+//
+// Note, slot refers to the raw slot key, not its hash.
+func (db *Database) StorageChanges(address common.Address, slot common.Hash, from, to uint64, limit int) ([]StateChange, bool, error) {
+	var (
+		addrHash = common.BytesToHash(crypto.Keccak256(address.Bytes()))
+		slotHash = common.BytesToHash(crypto.Keccak256(slot.Bytes()))
+	)
+	return db.stateChanges(newStorageIdentQuery(address, addrHash, slot, slotHash), from, to, limit)
+}
+
+// stateChanges walks the history index of the given state element, pairing the
+// original value recorded by every history with the one recorded by the next
+// history mutating the element, or the disk layer value for the last one.
+func (db *Database) stateChanges(state stateIdentQuery, from, to uint64, limit int) ([]StateChange, bool, error) {
+	if db.indexer == nil || !db.indexer.inited() {
+		return nil, false, errors.New("state histories haven't been fully indexed yet")
+	}
+	if db.freezer == nil {
+		return nil, false, errors.New("state histories are not available")
+	}
+	var (
+		dl     = db.tree.bottom()
+		lastID = dl.stateID()
+		latest []byte
+		err    error
+	)
+	if state.account {
+		latest, err = dl.account(state.addressHash, 0)
+	} else {
+		latest, err = dl.storage(state.addressHash, state.storageHash, 0)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	metadata := loadIndexMetadata(db.diskdb)
+	if metadata == nil || metadata.Last < lastID {
+		return nil, false, fmt.Errorf("state history is not fully indexed, last-state-id: %d", lastID)
+	}
+	after, end, err := historyIDRange(db.freezer, from, to, lastID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	ir, err := newIndexReaderWithLimitTag(db.diskdb, state.stateIdent, metadata.Last)
+	if err != nil {
+		return nil, false, err
+	}
+	var (
+		reader  = newHistoryReader(db.diskdb, db.freezer)
+		changes []StateChange
+		prev    []byte
+	)
+	id, err := ir.readGreaterThan(after, lastID)
+	if err != nil {
+		return nil, false, err
+	}
+	if id <= end {
+		if prev, err = reader.readOrigin(state, id); err != nil {
+			return nil, false, err
+		}
+	}
+	for id <= end {
+		if len(changes) >= limit {
+			return changes, true, nil
+		}
+		var m meta
+		if err := m.decode(rawdb.ReadStateHistoryMeta(db.freezer, id)); err != nil {
+			return nil, false, err
+		}
+		next, err := ir.readGreaterThan(id, lastID)
+		if err != nil {
+			return nil, false, err
+		}
+		post := latest
+		if next != math.MaxUint64 {
+			if post, err = reader.readOrigin(state, next); err != nil {
+				return nil, false, err
+			}
+		}
+		changes = append(changes, StateChange{ID: id, Block: m.block, Prev: prev, Post: post})
+		id, prev = next, post
+	}
+	return changes, false, nil
+}
+
+// historyIDRange maps the block range [from, to] to the range of the state
+// histories (after, end] recorded for the blocks within it, relying on the
+// block numbers of the histories increasing along with their IDs.
+func historyIDRange(freezer ethdb.AncientReader, from, to uint64, lastID uint64) (uint64, uint64, error) {
+	tail, err := freezer.Tail()
+	if err != nil {
+		return 0, 0, err
+	}
+	if lastID < tail {
+		return tail, tail, nil
+	}
+	var (
+		n     = int(lastID - tail)
+		fail  error
+		block = func(i int) uint64 {
+			var m meta
+			if err := m.decode(rawdb.ReadStateHistoryMeta(freezer, tail+uint64(i)+1)); err != nil && fail == nil {
+				fail = err
+			}
+			return m.block
+		}
+		first = sort.Search(n, func(i int) bool { return block(i) >= from })
+		last  = sort.Search(n, func(i int) bool { return block(i) > to })
+	)
+	if fail != nil {
+		return 0, 0, fail
+	}
+	return tail + uint64(first), tail + uint64(last), nil
+}
@@ -0,0 +1,133 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package pathdb
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/luxfi/geth/common"
+)
+
+// expectedChanges derives the mutations of a state element from the state
+// snapshots of the tester, up to and including the given state.
+func expectedChanges(env *tester, bottom int, value func(root common.Hash) []byte) []StateChange {
+	var (
+		changes []StateChange
+		prev    []byte
+	)
+	for i := 0; i <= bottom; i++ {
+		post := value(env.roots[i])
+		if !bytes.Equal(prev, post) {
+			changes = append(changes, StateChange{ID: uint64(i + 1), Block: uint64(i), Prev: prev, Post: post})
+		}
+		prev = post
+	}
+	return changes
+}
+
+// collectChanges retrieves all the mutations in the given block range, page by page.
+func collectChanges(from, to uint64, limit int, query func(from, to uint64, limit int) ([]StateChange, bool, error)) ([]StateChange, error) {
+	var changes []StateChange
+	for {
+		page, more, err := query(from, to, limit)
+		if err != nil {
+			return nil, err
+		}
+		if len(page) > limit || (more && len(page) != limit) {
+			return nil, fmt.Errorf("invalid page size %d, limit %d, more %t", len(page), limit, more)
+		}
+		changes = append(changes, page...)
+		if !more {
+			return changes, nil
+		}
+		from = page[len(page)-1].Block + 1
+	}
+}
+
+func checkChanges(have, want []StateChange) error {
+	if len(have) != len(want) {
+		return fmt.Errorf("change count mismatch, want: %d, got: %d", len(want), len(have))
+	}
+	for i := range want {
+		if have[i].ID != want[i].ID || have[i].Block != want[i].Block {
+			return fmt.Errorf("change %d mismatch, want: #%d (%d), got: #%d (%d)", i, want[i].Block, want[i].ID, have[i].Block, have[i].ID)
+		}
+		if !bytes.Equal(have[i].Prev, want[i].Prev) || !bytes.Equal(have[i].Post, want[i].Post) {
+			return fmt.Errorf("change %d value mismatch, want: %x -> %x, got: %x -> %x", i, want[i].Prev, want[i].Post, have[i].Prev, have[i].Post)
+		}
+	}
+	return nil
+}
+
+func TestStateChanges(t *testing.T) {
+	maxDiffLayers = 4
+	defer func() {
+		maxDiffLayers = 128
+	}()
+	env := newTester(t, 0, false, 32, true, "")
+	defer env.release()
+	waitIndexing(env.db)
+
+	var (
+		bottom  = env.bottomIndex()
+		checked int
+	)
+	for addrHash := range env.preimages {
+		address := env.accountPreimage(addrHash)
+		if _, ok := env.snapAccounts[env.roots[bottom]][addrHash]; !ok {
+			continue
+		}
+		checked++
+
+		want := expectedChanges(env, bottom, func(root common.Hash) []byte {
+			return env.snapAccounts[root][addrHash]
+		})
+		for _, limit := range []int{1, 3, len(want) + 1} {
+			have, err := collectChanges(0, uint64(bottom), limit, func(from, to uint64, limit int) ([]StateChange, bool, error) {
+				return env.db.AccountChanges(address, from, to, limit)
+			})
+			if err != nil {
+				t.Fatalf("failed to retrieve account changes: %v", err)
+			}
+			if err := checkChanges(have, want); err != nil {
+				t.Fatalf("account %x, limit %d: %v", address, limit, err)
+			}
+		}
+		// Ensure a partial range only includes the mutations made within it
+		from, to := uint64(bottom/3), uint64(2*bottom/3)
+		have, _, err := env.db.AccountChanges(address, from, to, len(want))
+		if err != nil {
+			t.Fatalf("failed to retrieve account changes: %v", err)
+		}
+		var partial []StateChange
+		for _, change := range want {
+			if change.Block >= from && change.Block <= to {
+				partial = append(partial, change)
+			}
+		}
+		if err := checkChanges(have, partial); err != nil {
+			t.Fatalf("account %x, range [%d, %d]: %v", address, from, to, err)
+		}
+		// Check the storage slots of the account as well
+		for slotHash := range env.snapStorages[env.roots[bottom]][addrHash] {
+			want := expectedChanges(env, bottom, func(root common.Hash) []byte {
+				return env.snapStorages[root][addrHash][slotHash]
+			})
+			have, err := collectChanges(0, uint64(bottom), 2, func(from, to uint64, limit int) ([]StateChange, bool, error) {
+				return env.db.StorageChanges(address, env.hashPreimage(slotHash), from, to, limit)
+			})
+			if err != nil {
+				t.Fatalf("failed to retrieve storage changes: %v", err)
+			}
+			if err := checkChanges(have, want); err != nil {
+				t.Fatalf("account %x, slot %x: %v", address, slotHash, err)
+			}
+		}
+	}
+	if checked == 0 {
+		t.Fatal("no account checked")
+	}
+}
@@ -0,0 +1,278 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package pathdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/luxfi/crypto"
+	"github.com/luxfi/geth/common"
+	"github.com/luxfi/geth/core/rawdb"
+)
+
+// AccountDiff describes the net difference of an account between two states.
+type AccountDiff struct {
+	Address common.Address
+	Prev    []byte     // Account in the slim RLP format at the origin state, empty if absent
+	Post    []byte     // Account in the slim RLP format at the target state, empty if absent
+	Storage []SlotDiff // Changed storage slots, sorted by slot hash
+}
+
+// SlotDiff describes the net difference of a storage slot between two states.
+type SlotDiff struct {
+	Key    common.Hash // Raw slot key, or the slot hash if Hashed is set
+	Hashed bool        // Flag whether the raw key is unknown, as the transitions before Cancun only record the slot hash
+	Prev   []byte      // RLP encoded slot value at the origin state, empty if absent
+	Post   []byte      // RLP encoded slot value at the target state, empty if absent
+}
+
+// diffSlot is a storage slot touched within the diffed range, along with its
+// value at the origin state.
+type diffSlot struct {
+	key    common.Hash
+	raw    bool
+	origin []byte
+}
+
+// diffAccount is an account touched within the diffed range, along with its
+// value at the origin state and the touched storage slots keyed by slot hash.
+type diffAccount struct {
+	touched bool
+	origin  []byte
+	slots   map[common.Hash]*diffSlot
+}
+
+// stateDiffer accumulates the state elements touched by a sequence of state
+// transitions, retaining the original value of the first transition.
+type stateDiffer struct {
+	accounts map[common.Address]*diffAccount
+}
+
+// add merges the original values of a state transition, which must be applied
+// in ascending order.
+func (s *stateDiffer) add(accounts map[common.Address][]byte, storages map[common.Address]map[common.Hash][]byte, rawStorageKey bool) {
+	get := func(address common.Address) *diffAccount {
+		acct, ok := s.accounts[address]
+		if !ok {
+			acct = &diffAccount{slots: make(map[common.Hash]*diffSlot)}
+			s.accounts[address] = acct
+		}
+		return acct
+	}
+	for address, blob := range accounts {
+		acct := get(address)
+		if !acct.touched {
+			acct.touched, acct.origin = true, blob
+		}
+	}
+	for address, slots := range storages {
+		acct := get(address)
+		for key, blob := range slots {
+			hash := key
+			if rawStorageKey {
+				hash = common.BytesToHash(crypto.Keccak256(key.Bytes()))
+			}
+			slot, ok := acct.slots[hash]
+			if !ok {
+				acct.slots[hash] = &diffSlot{key: key, raw: rawStorageKey, origin: blob}
+				continue
+			}
+			// Learn the raw slot key from the newer transitions if possible
+			if !slot.raw && rawStorageKey {
+				slot.key, slot.raw = key, true
+			}
+		}
+	}
+}
+
+// stateIDOf returns the state id of the given state root, if it's known.
+func (db *Database) stateIDOf(root common.Hash) (uint64, bool) {
+	if l := db.tree.get(root); l != nil {
+		return l.stateID(), true
+	}
+	id := rawdb.ReadStateID(db.diskdb, root)
+	if id == nil {
+		return 0, false
+	}
+	return *id, true
+}
+
+// StateDiff returns the net difference between the state specified by the root
+// from and its descendant state specified by to, as the accounts changed in
+// between, sorted by address. The elements changed and then restored within
+// the range are excluded.
+//
+// The touched elements are collected from the state histories and the diff
+// layers in the range, the value of each at the origin state is the one recorded
+// by the first transition touching it. The values at the target state are read
+// from the layer of the target state, or from the state histories if it's below
+// the disk layer, which requires the state history index to be complete.
+//
+// The result is paged: the accounts are returned starting from the address
+// start, until the number of accounts and storage slots returned reaches limit,
+// the storage slots of an account are never split. The returned address is the
+// one to resume from, or nil if the diff is exhausted. Note, the whole range is
+// scanned for every page, callers are responsible for bounding it.
+func (db *Database) StateDiff(from, to common.Hash, start common.Address, limit int) ([]AccountDiff, *common.Address, error) {
+	if db.isVerkle {
+		return nil, nil, errors.New("state diff is not supported in verkle")
+	}
+	if from == to {
+		return nil, nil, nil
+	}
+	fromID, ok := db.stateIDOf(from)
+	if !ok {
+		return nil, nil, fmt.Errorf("state %#x is not available", from)
+	}
+	toID, ok := db.stateIDOf(to)
+	if !ok {
+		return nil, nil, fmt.Errorf("state %#x is not available", to)
+	}
+	if fromID >= toID {
+		return nil, nil, fmt.Errorf("state %#x is not an ancestor of %#x", from, to)
+	}
+	var (
+		dl     = db.tree.bottom()
+		diskID = dl.stateID()
+		differ = &stateDiffer{accounts: make(map[common.Address]*diffAccount)}
+	)
+	// Collect the transitions persisted as state histories, oldest first
+	if fromID < diskID {
+		if db.freezer == nil {
+			return nil, nil, errors.New("state histories are not available")
+		}
+		tail, err := db.freezer.Tail()
+		if err != nil {
+			return nil, nil, err
+		}
+		if fromID < tail {
+			return nil, nil, errors.New("historical state has been pruned")
+		}
+		parent := from
+		for id := fromID + 1; id <= min(toID, diskID); id++ {
+			h, err := readHistory(db.freezer, id)
+			if err != nil {
+				return nil, nil, err
+			}
+			if h.meta.parent != parent {
+				return nil, nil, fmt.Errorf("state %#x is not an ancestor of %#x", from, to)
+			}
+			differ.add(h.accounts, h.storages, h.meta.version != stateHistoryV0)
+			parent = h.meta.root
+		}
+		if toID <= diskID && parent != to {
+			return nil, nil, fmt.Errorf("state %#x is not an ancestor of %#x", from, to)
+		}
+		if toID > diskID && parent != dl.rootHash() {
+			return nil, nil, errUnexpectedHistory
+		}
+	}
+	// Collect the transitions still held by the diff layers, oldest first
+	target := db.tree.get(to)
+	if toID > diskID {
+		if target == nil {
+			return nil, nil, fmt.Errorf("state %#x is not available", to)
+		}
+		var layers []*diffLayer
+		for current := target; current.stateID() > max(fromID, diskID); current = current.parentLayer() {
+			diff, ok := current.(*diffLayer)
+			if !ok {
+				return nil, nil, errUnexpectedHistory
+			}
+			layers = append(layers, diff)
+		}
+		for i := len(layers) - 1; i >= 0; i-- {
+			states := layers[i].states
+			differ.add(states.accountOrigin, states.storageOrigin, states.rawStorageKey)
+		}
+		if fromID >= diskID && layers[len(layers)-1].parentLayer().rootHash() != from {
+			return nil, nil, fmt.Errorf("state %#x is not an ancestor of %#x", from, to)
+		}
+	}
+	// Resolve the values at the target state
+	var (
+		readAccount func(address common.Address, addrHash common.Hash) ([]byte, error)
+		readStorage func(address common.Address, addrHash common.Hash, slot *diffSlot, slotHash common.Hash) ([]byte, error)
+	)
+	if target != nil {
+		readAccount = func(address common.Address, addrHash common.Hash) ([]byte, error) {
+			return target.account(addrHash, 0)
+		}
+		readStorage = func(address common.Address, addrHash common.Hash, slot *diffSlot, slotHash common.Hash) ([]byte, error) {
+			return target.storage(addrHash, slotHash, 0)
+		}
+	} else {
+		if db.indexer == nil || !db.indexer.inited() {
+			return nil, nil, errors.New("state histories haven't been fully indexed yet")
+		}
+		reader := newHistoryReader(db.diskdb, db.freezer)
+		readAccount = func(address common.Address, addrHash common.Hash) ([]byte, error) {
+			latest, err := dl.account(addrHash, 0)
+			if err != nil {
+				return nil, err
+			}
+			return reader.read(newAccountIdentQuery(address, addrHash), toID, diskID, latest)
+		}
+		readStorage = func(address common.Address, addrHash common.Hash, slot *diffSlot, slotHash common.Hash) ([]byte, error) {
+			latest, err := dl.storage(addrHash, slotHash, 0)
+			if err != nil {
+				return nil, err
+			}
+			return reader.read(newStorageIdentQuery(address, addrHash, slot.key, slotHash), toID, diskID, latest)
+		}
+	}
+	var (
+		addresses = slices.SortedFunc(maps.Keys(differ.accounts), common.Address.Cmp)
+		diffs     []AccountDiff
+		size      int
+	)
+	for i, address := range addresses {
+		if address.Cmp(start) < 0 {
+			continue
+		}
+		if size >= limit {
+			return diffs, &addresses[i], nil
+		}
+		var (
+			acct     = differ.accounts[address]
+			addrHash = common.BytesToHash(crypto.Keccak256(address.Bytes()))
+		)
+		post, err := readAccount(address, addrHash)
+		if err != nil {
+			return nil, nil, err
+		}
+		prev := post
+		if acct.touched {
+			prev = acct.origin
+		}
+		var storage []SlotDiff
+		for _, slotHash := range slices.SortedFunc(maps.Keys(acct.slots), common.Hash.Cmp) {
+			slot := acct.slots[slotHash]
+			if !slot.raw {
+				// Resolve the raw slot key from the preimage store if it's recorded
+				if preimage := rawdb.ReadPreimage(db.diskdb, slotHash); len(preimage) == common.HashLength {
+					slot.key, slot.raw = common.BytesToHash(preimage), true
+				}
+			}
+			value, err := readStorage(address, addrHash, slot, slotHash)
+			if err != nil {
+				return nil, nil, err
+			}
+			if bytes.Equal(slot.origin, value) {
+				continue
+			}
+			storage = append(storage, SlotDiff{Key: slot.key, Hashed: !slot.raw, Prev: slot.origin, Post: value})
+		}
+		if bytes.Equal(prev, post) && len(storage) == 0 {
+			continue
+		}
+		diffs = append(diffs, AccountDiff{Address: address, Prev: prev, Post: post, Storage: storage})
+		size += 1 + len(storage)
+	}
+	return diffs, nil, nil
+}
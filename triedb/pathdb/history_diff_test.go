@@ -0,0 +1,147 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package pathdb
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/luxfi/crypto"
+	"github.com/luxfi/geth/common"
+)
+
+// testerState returns the accounts and storage slots of the tester at the
+// given state, keyed by hash.
+func testerState(env *tester, index int) (map[common.Hash][]byte, map[common.Hash]map[common.Hash][]byte) {
+	if index == len(env.roots)-1 {
+		return env.accounts, env.storages
+	}
+	return env.snapAccounts[env.roots[index]], env.snapStorages[env.roots[index]]
+}
+
+// checkStateDiff collects the state diff between the given states page by page
+// and compares it with the one derived from the tester.
+func checkStateDiff(env *tester, from, to int, limit int) error {
+	var (
+		diffs []AccountDiff
+		start common.Address
+	)
+	for {
+		page, next, err := env.db.StateDiff(env.roots[from], env.roots[to], start, limit)
+		if err != nil {
+			return err
+		}
+		size := 0
+		for _, diff := range page {
+			size += 1 + len(diff.Storage)
+			if len(diffs) > 0 && diffs[len(diffs)-1].Address.Cmp(diff.Address) >= 0 {
+				return fmt.Errorf("account %x is out of order", diff.Address)
+			}
+			diffs = append(diffs, diff)
+		}
+		if next != nil && size < limit {
+			return fmt.Errorf("incomplete page size %d, limit %d", size, limit)
+		}
+		if next == nil {
+			break
+		}
+		start = *next
+	}
+	var (
+		prevAccounts, prevStorages = testerState(env, from)
+		postAccounts, postStorages = testerState(env, to)
+		want                       = make(map[common.Hash]bool)
+	)
+	for addrHash, blob := range prevAccounts {
+		if !bytes.Equal(blob, postAccounts[addrHash]) {
+			want[addrHash] = true
+		}
+	}
+	for addrHash, blob := range postAccounts {
+		if !bytes.Equal(blob, prevAccounts[addrHash]) {
+			want[addrHash] = true
+		}
+	}
+	if len(diffs) != len(want) {
+		return fmt.Errorf("changed account count mismatch, want: %d, got: %d", len(want), len(diffs))
+	}
+	for _, diff := range diffs {
+		addrHash := common.BytesToHash(crypto.Keccak256(diff.Address.Bytes()))
+		if !want[addrHash] {
+			return fmt.Errorf("unexpected account %x", diff.Address)
+		}
+		if !bytes.Equal(diff.Prev, prevAccounts[addrHash]) || !bytes.Equal(diff.Post, postAccounts[addrHash]) {
+			return fmt.Errorf("account %x mismatch, want: %x -> %x, got: %x -> %x", diff.Address, prevAccounts[addrHash], postAccounts[addrHash], diff.Prev, diff.Post)
+		}
+		slots := make(map[common.Hash]bool)
+		for slotHash, blob := range prevStorages[addrHash] {
+			if !bytes.Equal(blob, postStorages[addrHash][slotHash]) {
+				slots[slotHash] = true
+			}
+		}
+		for slotHash, blob := range postStorages[addrHash] {
+			if !bytes.Equal(blob, prevStorages[addrHash][slotHash]) {
+				slots[slotHash] = true
+			}
+		}
+		if len(diff.Storage) != len(slots) {
+			return fmt.Errorf("account %x: changed slot count mismatch, want: %d, got: %d", diff.Address, len(slots), len(diff.Storage))
+		}
+		for _, slot := range diff.Storage {
+			slotHash := slot.Key
+			if !slot.Hashed {
+				slotHash = common.BytesToHash(crypto.Keccak256(slot.Key.Bytes()))
+			}
+			if !slots[slotHash] {
+				return fmt.Errorf("account %x: unexpected slot %x", diff.Address, slot.Key)
+			}
+			prev, post := prevStorages[addrHash][slotHash], postStorages[addrHash][slotHash]
+			if !bytes.Equal(slot.Prev, prev) || !bytes.Equal(slot.Post, post) {
+				return fmt.Errorf("account %x: slot %x mismatch, want: %x -> %x, got: %x -> %x", diff.Address, slot.Key, prev, post, slot.Prev, slot.Post)
+			}
+		}
+	}
+	return nil
+}
+
+func TestStateDiff(t *testing.T) {
+	maxDiffLayers = 4
+	defer func() {
+		maxDiffLayers = 128
+	}()
+	env := newTester(t, 0, false, 32, true, "")
+	defer env.release()
+	waitIndexing(env.db)
+
+	var (
+		bottom = env.bottomIndex()
+		last   = len(env.roots) - 1
+	)
+	// The ranges are taken from the state histories, the diff layers or both.
+	// The first histories use the hashed slot keys, so the target states of
+	// the ranges below the disk layer are chosen above them.
+	for _, r := range [][2]int{
+		{0, bottom},
+		{2, last},
+		{3, bottom + 2},
+		{8, bottom - 3},
+		{bottom, last},
+		{bottom + 1, last},
+	} {
+		for _, limit := range []int{1, 5, 1 << 20} {
+			if err := checkStateDiff(env, r[0], r[1], limit); err != nil {
+				t.Fatalf("range [%d, %d], limit %d: %v", r[0], r[1], limit, err)
+			}
+		}
+	}
+	// Ensure the states not in the ancestry are rejected
+	if _, _, err := env.db.StateDiff(env.roots[bottom-1], env.roots[2], common.Address{}, 1); err == nil {
+		t.Fatal("expected error for a reversed range")
+	}
+	diffs, next, err := env.db.StateDiff(env.roots[5], env.roots[5], common.Address{}, 1)
+	if err != nil || len(diffs) != 0 || next != nil {
+		t.Fatalf("unexpected diff of the same state: %v %v %v", diffs, next, err)
+	}
+}
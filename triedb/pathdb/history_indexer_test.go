@@ -29,7 +29,7 @@ import (
 func TestHistoryIndexerShortenDeadlock(t *testing.T) {
 	//log.SetDefault(log.NewLogger(log.NewTerminalHandlerWithLevel(os.Stderr, log.LevelInfo, true)))
 	db := rawdb.NewMemoryDatabase()
-	freezer, _ := rawdb.NewStateFreezer(t.TempDir(), false, false)
+	freezer, _ := rawdb.NewStateFreezer(t.TempDir(), false, false, nil)
 	defer freezer.Close()
 
 	histories := makeHistories(100)
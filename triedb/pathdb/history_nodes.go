@@ -0,0 +1,246 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package pathdb
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/luxfi/geth/common"
+	"github.com/luxfi/geth/core/rawdb"
+	"github.com/luxfi/geth/ethdb"
+	"github.com/luxfi/geth/log"
+	"github.com/luxfi/geth/trie/trienode"
+	"github.com/luxfi/geth/triedb/database"
+)
+
+// historicNodesCacheSize is the number of historic states whose rebuilt trie
+// nodes are cached, as proofs are usually requested in bursts against the same
+// few states.
+const historicNodesCacheSize = 4
+
+// historicNodes is an in-memory overlay of the trie nodes reverted by a range of
+// state histories on top of the disk layer, representing a historic state. It
+// implements database.NodeDatabase, so that tries of the historic state can be
+// opened, traversed and proven.
+//
+// The disk layer may be replaced while the overlay is in use. The histories
+// between the stale and the new disk layer are then reverted as well, and the
+// nodes not yet present in the overlay are added to it.
+type historicNodes struct {
+	db   *Database   // Database the overlay is rebased in, nil while being built
+	id   uint64      // State ID of the state represented
+	root common.Hash // Root of the state represented
+
+	lock  sync.RWMutex
+	base  *diskLayer                                // Disk layer the histories are reverted from
+	nodes map[common.Hash]map[string]*trienode.Node // Reverted nodes, keyed by owner and path
+}
+
+// NodeReader implements database.NodeDatabase, returning the overlay itself if
+// the requested state is the one it represents.
+func (h *historicNodes) NodeReader(root common.Hash) (database.NodeReader, error) {
+	if root != h.root {
+		return nil, fmt.Errorf("state %#x is not available", root)
+	}
+	return h, nil
+}
+
+// Node implements database.NodeReader, retrieving the node from the overlay or
+// from the disk layer if it was not reverted. The overlay is rebased on the
+// current disk layer if the one it was built on became stale.
+func (h *historicNodes) Node(owner common.Hash, path []byte, hash common.Hash) ([]byte, error) {
+	for {
+		h.lock.RLock()
+		base := h.base
+		blob, err := h.node(owner, path, hash)
+		h.lock.RUnlock()
+
+		if h.db == nil || !errors.Is(err, errSnapshotStale) {
+			return blob, err
+		}
+		if err := h.rebase(base); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// node retrieves the node from the overlay or from the disk layer, the caller
+// must hold the lock.
+func (h *historicNodes) node(owner common.Hash, path []byte, hash common.Hash) ([]byte, error) {
+	var (
+		blob []byte
+		got  common.Hash
+	)
+	if n, ok := h.nodes[owner][string(path)]; ok {
+		blob, got = n.Blob, n.Hash
+	} else {
+		var err error
+		blob, got, _, err = h.base.node(owner, path, 0)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if got != hash {
+		return nil, fmt.Errorf("unexpected node: (%x %v), %x!=%x", owner, path, hash, got)
+	}
+	return blob, nil
+}
+
+// rebase moves the overlay from the given stale disk layer onto the current
+// one, reverting the histories in between. The nodes already in the overlay
+// were reverted further and take precedence.
+func (h *historicNodes) rebase(stale *diskLayer) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	// Bail out if the overlay was rebased concurrently
+	if h.base != stale {
+		return nil
+	}
+	for {
+		dl, err := h.db.historicBase(h.root, h.id)
+		if err != nil {
+			return err
+		}
+		// The histories above the overlay are gone if the state was rewound
+		if dl.stateID() < h.base.stateID() {
+			return fmt.Errorf("state %#x is no longer available", h.root)
+		}
+		nodes, err := revertNodes(h.db.freezer, dl, h.base.stateID())
+		if errors.Is(err, errSnapshotStale) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		for owner, subset := range nodes {
+			if h.nodes[owner] == nil {
+				h.nodes[owner] = make(map[string]*trienode.Node)
+			}
+			for path, n := range subset {
+				if _, ok := h.nodes[owner][path]; !ok {
+					h.nodes[owner][path] = n
+				}
+			}
+		}
+		h.base = dl
+		return nil
+	}
+}
+
+// revertNodes reverts the state histories from the disk layer down to the
+// given state ID, returning the trie nodes of the reverted state that differ
+// from the ones in the disk layer. It fails with errSnapshotStale if the disk
+// layer is replaced in the meantime.
+func revertNodes(freezer ethdb.AncientReader, dl *diskLayer, id uint64) (map[common.Hash]map[string]*trienode.Node, error) {
+	overlay := &historicNodes{
+		base:  dl,
+		root:  dl.rootHash(),
+		nodes: make(map[common.Hash]map[string]*trienode.Node),
+	}
+	for current := dl.stateID(); current > id; current-- {
+		h, err := readHistory(freezer, current)
+		if err != nil {
+			return nil, err
+		}
+		if h.meta.root != overlay.root {
+			return nil, errUnexpectedHistory
+		}
+		nodes, err := apply(overlay, h.meta.parent, h.meta.root, h.meta.version != stateHistoryV0, h.accounts, h.storages)
+		if err != nil {
+			return nil, err
+		}
+		for owner, subset := range nodes {
+			if overlay.nodes[owner] == nil {
+				overlay.nodes[owner] = make(map[string]*trienode.Node)
+			}
+			maps.Copy(overlay.nodes[owner], subset)
+		}
+		overlay.root = h.meta.parent
+	}
+	return overlay.nodes, nil
+}
+
+// historicBase returns the current disk layer to rebuild the trie nodes of the
+// given historic state from, ensuring the state is within the proof history
+// and its histories are not pruned.
+func (db *Database) historicBase(root common.Hash, id uint64) (*diskLayer, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	dl := db.tree.bottom()
+	if id >= dl.stateID() {
+		return dl, nil
+	}
+	if dl.stateID()-id > db.config.ProofHistory {
+		return nil, fmt.Errorf("state %#x is %d histories below the disk layer, limit %d", root, dl.stateID()-id, db.config.ProofHistory)
+	}
+	tail, err := db.freezer.Tail()
+	if err != nil {
+		return nil, err
+	}
+	if id < tail {
+		return nil, errors.New("historical state has been pruned")
+	}
+	return dl, nil
+}
+
+// HistoricNodes returns a node database holding the trie nodes of the specified
+// historic state, which are no longer persisted. They are rebuilt by reverting
+// the state histories from the disk layer down to the requested state, without
+// touching the database, and retained for further requests.
+//
+// The number of histories reverted is capped by the ProofHistory config, the
+// feature is disabled if it's zero.
+func (db *Database) HistoricNodes(root common.Hash) (database.NodeDatabase, error) {
+	if db.config.ProofHistory == 0 {
+		return nil, errors.New("historic trie nodes are disabled")
+	}
+	if db.isVerkle {
+		return nil, errors.New("historic trie nodes are not supported in verkle")
+	}
+	if db.freezer == nil {
+		return nil, errors.New("state histories are not available")
+	}
+	id := rawdb.ReadStateID(db.diskdb, root)
+	if id == nil {
+		return nil, fmt.Errorf("state %#x is not available", root)
+	}
+	for {
+		dl, err := db.historicBase(root, *id)
+		if err != nil {
+			return nil, err
+		}
+		if *id >= dl.stateID() {
+			return db, nil
+		}
+		// Cached overlays are rebased lazily once their disk layer is stale
+		if cached, ok := db.historic.Get(root); ok {
+			return cached, nil
+		}
+		start := time.Now()
+		nodes, err := revertNodes(db.freezer, dl, *id)
+		if errors.Is(err, errSnapshotStale) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		overlay := &historicNodes{
+			db:    db,
+			id:    *id,
+			root:  root,
+			base:  dl,
+			nodes: nodes,
+		}
+		db.historic.Add(root, overlay)
+
+		log.Debug("Rebuilt historic trie nodes", "root", root, "histories", dl.stateID()-*id, "elapsed", common.PrettyDuration(time.Since(start)))
+		return overlay, nil
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package pathdb
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"testing"
+
+	"github.com/luxfi/geth/core/types"
+	"github.com/luxfi/geth/rlp"
+	"github.com/luxfi/geth/trie"
+	"github.com/luxfi/geth/triedb/database"
+)
+
+func TestHistoricNodes(t *testing.T) {
+	maxDiffLayers = 4
+	defer func() {
+		maxDiffLayers = 128
+	}()
+	env := newTester(t, 0, false, 32, false, "")
+	defer env.release()
+
+	bottom := env.bottomIndex()
+	if _, err := env.db.HistoricNodes(env.roots[0]); err == nil {
+		t.Fatal("expected error with historic nodes disabled")
+	}
+	env.db.config.ProofHistory = uint64(bottom - 1)
+
+	for i := 0; i <= bottom; i++ {
+		root := env.roots[i]
+		nodes, err := env.db.HistoricNodes(root)
+		if i == 0 {
+			if err == nil {
+				t.Fatal("expected error beyond the proof history limit")
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("state %d: failed to rebuild trie nodes: %v", i, err)
+		}
+		tr, err := trie.New(trie.StateTrieID(root), nodes)
+		if err != nil {
+			t.Fatalf("state %d: failed to open trie: %v", i, err)
+		}
+		for addrHash, account := range env.snapAccounts[root] {
+			blob, err := tr.Get(addrHash.Bytes())
+			if err != nil {
+				t.Fatalf("state %d: failed to read account: %v", i, err)
+			}
+			if len(account) == 0 {
+				if len(blob) != 0 {
+					t.Fatalf("state %d: unexpected account %x", i, addrHash)
+				}
+				continue
+			}
+			full, _ := types.FullAccountRLP(account)
+			if !bytes.Equal(blob, full) {
+				t.Fatalf("state %d: account %x mismatch", i, addrHash)
+			}
+			// Ensure the storage tries are rebuilt along with the account trie
+			slots := env.snapStorages[root][addrHash]
+			if len(slots) == 0 {
+				continue
+			}
+			var acct types.StateAccount
+			if err := rlp.DecodeBytes(full, &acct); err != nil {
+				t.Fatal(err)
+			}
+			st, err := trie.New(trie.StorageTrieID(root, addrHash, acct.Root), nodes)
+			if err != nil {
+				t.Fatalf("state %d: failed to open storage trie: %v", i, err)
+			}
+			for slotHash, slot := range slots {
+				blob, err := st.Get(slotHash.Bytes())
+				if err != nil {
+					t.Fatalf("state %d: failed to read slot: %v", i, err)
+				}
+				if !bytes.Equal(blob, slot) {
+					t.Fatalf("state %d: slot %x mismatch", i, slotHash)
+				}
+			}
+		}
+		// Only the requested state is accessible
+		if i < bottom {
+			if _, err := nodes.NodeReader(env.roots[i+1]); err == nil {
+				t.Fatalf("state %d: unexpected reader for another state", i)
+			}
+		}
+	}
+}
+
+// Tests that the tries of a historic state remain accessible while new states
+// are committed and the disk layer the trie nodes were rebuilt from is replaced.
+func TestHistoricNodesRebase(t *testing.T) {
+	maxDiffLayers = 4
+	defer func() {
+		maxDiffLayers = 128
+	}()
+	env := newTester(t, 0, false, 32, false, "")
+	defer env.release()
+	env.db.config.ProofHistory = 64
+
+	var (
+		index    = env.bottomIndex() - 4
+		root     = env.roots[index]
+		accounts = maps.Clone(env.snapAccounts[root])
+	)
+	verify := func(nodes database.NodeDatabase) error {
+		tr, err := trie.New(trie.StateTrieID(root), nodes)
+		if err != nil {
+			return err
+		}
+		for addrHash, account := range accounts {
+			blob, err := tr.Get(addrHash.Bytes())
+			if err != nil {
+				return err
+			}
+			if len(account) == 0 {
+				if len(blob) != 0 {
+					return fmt.Errorf("unexpected account %x", addrHash)
+				}
+				continue
+			}
+			full, _ := types.FullAccountRLP(account)
+			if !bytes.Equal(blob, full) {
+				return fmt.Errorf("account %x mismatch", addrHash)
+			}
+		}
+		return nil
+	}
+	nodes, err := env.db.HistoricNodes(root)
+	if err != nil {
+		t.Fatalf("failed to rebuild trie nodes: %v", err)
+	}
+	if err := verify(nodes); err != nil {
+		t.Fatalf("failed to verify state: %v", err)
+	}
+	// Commit new states while the state is being read
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 16; i++ {
+			env.extend(1)
+		}
+	}()
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		default:
+		}
+		if err := verify(nodes); err != nil {
+			t.Fatalf("failed to verify state while committing: %v", err)
+		}
+	}
+	if err := verify(nodes); err != nil {
+		t.Fatalf("failed to verify state after committing: %v", err)
+	}
+	// The cached overlay is served again after the disk layer moved on
+	cached, err := env.db.HistoricNodes(root)
+	if err != nil {
+		t.Fatalf("failed to retrieve trie nodes: %v", err)
+	}
+	if cached != nodes {
+		t.Fatal("trie nodes rebuilt instead of rebased")
+	}
+	if err := verify(cached); err != nil {
+		t.Fatalf("failed to verify cached state: %v", err)
+	}
+}
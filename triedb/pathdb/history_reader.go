@@ -363,6 +363,12 @@ func (r *historyReader) read(state stateIdentQuery, stateID uint64, lastID uint6
 	// that the associated state histories are no longer available due to a rollback.
 	// Such truncation should be captured by the state resolver below, rather than returning
 	// invalid data.
+	return r.readOrigin(state, historyID)
+}
+
+// readOrigin retrieves the original value of the state element recorded in
+// the specified state history.
+func (r *historyReader) readOrigin(state stateIdentQuery, historyID uint64) ([]byte, error) {
 	if state.account {
 		return r.readAccount(state.address, historyID)
 	}
@@ -135,7 +135,7 @@ func TestTruncateHeadHistory(t *testing.T) {
 		roots      []common.Hash
 		hs         = makeHistories(10)
 		db         = rawdb.NewMemoryDatabase()
-		freezer, _ = rawdb.NewStateFreezer(t.TempDir(), false, false)
+		freezer, _ = rawdb.NewStateFreezer(t.TempDir(), false, false, nil)
 	)
 	defer freezer.Close()
 
@@ -163,7 +163,7 @@ func TestTruncateTailHistory(t *testing.T) {
 		roots      []common.Hash
 		hs         = makeHistories(10)
 		db         = rawdb.NewMemoryDatabase()
-		freezer, _ = rawdb.NewStateFreezer(t.TempDir(), false, false)
+		freezer, _ = rawdb.NewStateFreezer(t.TempDir(), false, false, nil)
 	)
 	defer freezer.Close()
 
@@ -206,7 +206,7 @@ func TestTruncateTailHistories(t *testing.T) {
 			roots      []common.Hash
 			hs         = makeHistories(10)
 			db         = rawdb.NewMemoryDatabase()
-			freezer, _ = rawdb.NewStateFreezer(t.TempDir()+fmt.Sprintf("%d", i), false, false)
+			freezer, _ = rawdb.NewStateFreezer(t.TempDir()+fmt.Sprintf("%d", i), false, false, nil)
 		)
 		defer freezer.Close()
 
@@ -234,7 +234,7 @@ func TestTruncateOutOfRange(t *testing.T) {
 	var (
 		hs         = makeHistories(10)
 		db         = rawdb.NewMemoryDatabase()
-		freezer, _ = rawdb.NewStateFreezer(t.TempDir(), false, false)
+		freezer, _ = rawdb.NewStateFreezer(t.TempDir(), false, false, nil)
 	)
 	defer freezer.Close()
 
@@ -0,0 +1,381 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package pathdb
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"time"
+
+	"github.com/luxfi/crypto"
+	"github.com/luxfi/geth/common"
+	"github.com/luxfi/geth/core/rawdb"
+	"github.com/luxfi/geth/core/types"
+	"github.com/luxfi/geth/ethdb"
+	"github.com/luxfi/geth/log"
+	"github.com/luxfi/geth/rlp"
+	"github.com/luxfi/geth/trie"
+	"github.com/luxfi/geth/triedb/database"
+)
+
+// PruneConfig contains the settings for offline state pruning.
+type PruneConfig struct {
+	HistoryDepth uint64 // Number of recent state histories to retain, 0 means keep all
+	DryRun       bool   // Flag whether to only report what would be pruned
+}
+
+// PruneStats contains the outcome of an offline state pruning.
+type PruneStats struct {
+	Root    common.Hash // Root of the persistent state
+	StateID uint64      // State id of the persistent state
+
+	Nodes      uint64             // Number of trie nodes referenced by the persistent state
+	NodeSize   common.StorageSize // Total size of the referenced trie nodes
+	Orphans    uint64             // Number of unreferenced trie nodes deleted
+	OrphanSize common.StorageSize // Total size of the unreferenced trie nodes deleted
+
+	Histories   uint64             // Number of state histories deleted
+	HistorySize common.StorageSize // Disk space released by the state history freezer
+}
+
+// Reclaimed returns the total disk space reclaimed by the pruning, ignoring
+// the effects of the subsequent database compaction.
+func (s *PruneStats) Reclaimed() common.StorageSize {
+	return s.OrphanSize + s.HistorySize
+}
+
+// verifiedReader is a database.NodeReader reading trie nodes directly from
+// the persistent state, ensuring they match the hash referenced by the parent.
+type verifiedReader struct{ db ethdb.KeyValueReader }
+
+// Node retrieves the trie node blob with the provided trie identifier, node
+// path and the corresponding node hash.
+func (r *verifiedReader) Node(owner common.Hash, path []byte, hash common.Hash) ([]byte, error) {
+	var blob []byte
+	if owner == (common.Hash{}) {
+		blob = rawdb.ReadAccountTrieNode(r.db, path)
+	} else {
+		blob = rawdb.ReadStorageTrieNode(r.db, owner, path)
+	}
+	if len(blob) == 0 {
+		return nil, fmt.Errorf("missing trie node, owner: %x, path: %x", owner, path)
+	}
+	if have := common.BytesToHash(crypto.Keccak256(blob)); have != hash {
+		return nil, fmt.Errorf("unexpected trie node, owner: %x, path: %x, want: %x, have: %x", owner, path, hash, have)
+	}
+	return blob, nil
+}
+
+// verifiedStore is a database.NodeDatabase serving the persistent state only.
+type verifiedStore struct {
+	db   ethdb.KeyValueReader
+	root common.Hash
+}
+
+// NodeReader returns a node reader associated with the specific state.
+func (s *verifiedStore) NodeReader(stateRoot common.Hash) (database.NodeReader, error) {
+	if stateRoot != s.root {
+		return nil, fmt.Errorf("state %x is not available", stateRoot)
+	}
+	return &verifiedReader{s.db}, nil
+}
+
+// orphanSweeper walks the trie nodes stored under a key prefix in ascending
+// order, alongside the trie traversal which visits the referenced nodes in the
+// same order. Every stored node skipped over by the traversal is unreferenced
+// and gets deleted.
+type orphanSweeper struct {
+	db     ethdb.KeyValueStore
+	prefix []byte
+	isNode func(key []byte) bool
+	iter   ethdb.Iterator
+	valid  bool
+	batch  ethdb.Batch
+	stats  *PruneStats
+	dryRun bool
+}
+
+func newOrphanSweeper(db ethdb.KeyValueStore, prefix []byte, isNode func([]byte) bool, stats *PruneStats, dryRun bool) *orphanSweeper {
+	s := &orphanSweeper{
+		db:     db,
+		prefix: prefix,
+		isNode: isNode,
+		iter:   db.NewIterator(prefix, nil),
+		batch:  db.NewBatch(),
+		stats:  stats,
+		dryRun: dryRun,
+	}
+	s.valid = s.iter.Next()
+	return s
+}
+
+// keep marks the node with the given key as referenced, deleting all the
+// stored nodes before it. A nil key deletes all the remaining nodes.
+func (s *orphanSweeper) keep(key []byte) error {
+	for s.valid && (key == nil || bytes.Compare(s.iter.Key(), key) < 0) {
+		if k := s.iter.Key(); s.isNode(k) {
+			s.stats.Orphans++
+			s.stats.OrphanSize += common.StorageSize(len(k) + len(s.iter.Value()))
+			if !s.dryRun {
+				s.batch.Delete(k)
+			}
+			// Recreate the iterator after every batch commit in order
+			// to allow the underlying compactor to delete the entries.
+			if s.batch.ValueSize() >= ethdb.IdealBatchSize {
+				if err := s.batch.Write(); err != nil {
+					return err
+				}
+				s.batch.Reset()
+
+				start := common.CopyBytes(k[len(s.prefix):])
+				s.iter.Release()
+				s.iter = s.db.NewIterator(s.prefix, start)
+			}
+		}
+		s.valid = s.iter.Next()
+	}
+	if key != nil && s.valid && bytes.Equal(s.iter.Key(), key) {
+		s.stats.Nodes++
+		s.stats.NodeSize += common.StorageSize(len(key) + len(s.iter.Value()))
+		s.valid = s.iter.Next()
+	}
+	return nil
+}
+
+// finish deletes all the remaining nodes and flushes the pending deletions.
+func (s *orphanSweeper) finish() error {
+	defer s.iter.Release()
+
+	if err := s.keep(nil); err != nil {
+		return err
+	}
+	if err := s.iter.Error(); err != nil {
+		return err
+	}
+	return s.batch.Write()
+}
+
+// sweepTrie traverses the trie with the given id, marking all the embedded
+// nodes as referenced. The onLeaf callback is invoked for every trie leaf.
+func sweepTrie(store *verifiedStore, id *trie.ID, sweeper *orphanSweeper, key func(path []byte) []byte, onLeaf func(key, blob []byte) error) error {
+	tr, err := trie.New(id, store)
+	if err != nil {
+		return err
+	}
+	it, err := tr.NodeIterator(nil)
+	if err != nil {
+		return err
+	}
+	for it.Next(true) {
+		// Nodes embedded in their parent are not stored on their own
+		if it.Hash() != (common.Hash{}) {
+			if err := sweeper.keep(key(it.Path())); err != nil {
+				return err
+			}
+		}
+		if it.Leaf() && onLeaf != nil {
+			if err := onLeaf(it.LeafKey(), it.LeafBlob()); err != nil {
+				return err
+			}
+		}
+	}
+	return it.Error()
+}
+
+// Prune verifies the persistent state of a path-based database, and deletes
+// all the trie nodes which are not referenced by it, as well as the state
+// histories beyond the configured depth. Orphaned nodes can be left behind by
+// a crash or a database corruption, and are never accessed or deleted again
+// otherwise.
+//
+// The database must not be in use. Only the persistent state is retained, the
+// state changes aggregated in the journal are applied on top of it as usual.
+func Prune(diskdb ethdb.Database, config PruneConfig) (*PruneStats, error) {
+	var (
+		start = time.Now()
+		stats = &PruneStats{
+			Root:    types.EmptyRootHash,
+			StateID: rawdb.ReadPersistentStateID(diskdb),
+		}
+	)
+	if blob := rawdb.ReadAccountTrieNode(diskdb, nil); len(blob) > 0 {
+		stats.Root = common.BytesToHash(crypto.Keccak256(blob))
+	}
+	// Check the persistent state against the state id it's tracked with
+	if stats.StateID != 0 {
+		if id := rawdb.ReadStateID(diskdb, stats.Root); id == nil || *id != stats.StateID {
+			return nil, fmt.Errorf("persistent state %x is not tracked with state id %d", stats.Root, stats.StateID)
+		}
+	}
+	// Open the state history freezer, read only for dry runs
+	var (
+		freezer ethdb.ResettableAncientStore
+		dir     string
+	)
+	if ancient, err := diskdb.AncientDatadir(); err == nil && ancient != "" {
+		freezer, err = rawdb.NewStateFreezer(ancient, false, config.DryRun, rawdb.AncientCipher(diskdb))
+		if err != nil {
+			return nil, err
+		}
+		defer freezer.Close()
+		dir = filepath.Join(ancient, rawdb.MerkleStateFreezerName)
+
+		if stats.StateID != 0 {
+			if blob := rawdb.ReadStateHistoryMeta(freezer, stats.StateID); len(blob) > 0 {
+				var m meta
+				if err := m.decode(blob); err != nil {
+					return nil, err
+				}
+				if m.root != stats.Root {
+					return nil, fmt.Errorf("persistent state %x mismatches state history %d (%x)", stats.Root, stats.StateID, m.root)
+				}
+			}
+		}
+	}
+	log.Info("Pruning persistent state", "root", stats.Root, "id", stats.StateID)
+
+	// Traverse the persistent state and drop all the unreferenced trie nodes
+	var (
+		store    = &verifiedStore{db: diskdb, root: stats.Root}
+		accounts = newOrphanSweeper(diskdb, rawdb.TrieNodeAccountPrefix, rawdb.IsAccountTrieNode, stats, config.DryRun)
+		storages = newOrphanSweeper(diskdb, rawdb.TrieNodeStoragePrefix, rawdb.IsStorageTrieNode, stats, config.DryRun)
+		logged   = time.Now()
+	)
+	accountKey := func(path []byte) []byte {
+		return append(common.CopyBytes(rawdb.TrieNodeAccountPrefix), path...)
+	}
+	onAccount := func(key, blob []byte) error {
+		var account types.StateAccount
+		if err := rlp.DecodeBytes(blob, &account); err != nil {
+			return err
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Pruning state data", "at", common.BytesToHash(key), "nodes", stats.Nodes, "orphans", stats.Orphans,
+				"size", stats.OrphanSize, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+		if account.Root == types.EmptyRootHash {
+			return nil
+		}
+		owner := common.BytesToHash(key)
+		storageKey := func(path []byte) []byte {
+			return append(append(common.CopyBytes(rawdb.TrieNodeStoragePrefix), owner.Bytes()...), path...)
+		}
+		return sweepTrie(store, trie.StorageTrieID(stats.Root, owner, account.Root), storages, storageKey, nil)
+	}
+	if stats.Root != types.EmptyRootHash {
+		if err := sweepTrie(store, trie.StateTrieID(stats.Root), accounts, accountKey, onAccount); err != nil {
+			accounts.iter.Release()
+			storages.iter.Release()
+			return nil, fmt.Errorf("persistent state %x is corrupted: %v", stats.Root, err)
+		}
+	}
+	if err := accounts.finish(); err != nil {
+		storages.iter.Release()
+		return nil, err
+	}
+	if err := storages.finish(); err != nil {
+		return nil, err
+	}
+	log.Info("Pruned state data", "nodes", stats.Nodes, "orphans", stats.Orphans, "size", stats.OrphanSize, "elapsed", common.PrettyDuration(time.Since(start)))
+
+	// Drop the state histories beyond the configured depth
+	if freezer != nil && config.HistoryDepth != 0 {
+		head, err := freezer.Ancients()
+		if err != nil {
+			return nil, err
+		}
+		tail, err := freezer.Tail()
+		if err != nil {
+			return nil, err
+		}
+		if head > tail+config.HistoryDepth {
+			ntail := head - config.HistoryDepth
+			stats.Histories = ntail - tail
+			if !config.DryRun {
+				before := dirSize(dir)
+				if _, err := truncateFromTail(diskdb, freezer, ntail); err != nil {
+					return nil, err
+				}
+				if err := freezer.SyncAncient(); err != nil {
+					return nil, err
+				}
+				if after := dirSize(dir); after < before {
+					stats.HistorySize = before - after
+				}
+			}
+			log.Info("Pruned state histories", "number", stats.Histories, "tail", ntail+1, "head", head, "size", stats.HistorySize)
+		}
+	}
+	// Compact the trie node ranges to release the deleted entries
+	if stats.Orphans > 0 && !config.DryRun {
+		cstart := time.Now()
+		for _, prefix := range [][]byte{rawdb.TrieNodeAccountPrefix, rawdb.TrieNodeStoragePrefix} {
+			log.Info("Compacting database", "range", fmt.Sprintf("%#x", prefix), "elapsed", common.PrettyDuration(time.Since(cstart)))
+			if err := diskdb.Compact(prefix, incrementPrefix(prefix)); err != nil {
+				return nil, err
+			}
+		}
+		log.Info("Database compaction finished", "elapsed", common.PrettyDuration(time.Since(cstart)))
+	}
+	return stats, nil
+}
+
+// ResetHistory discards the state histories along with their index and resets
+// the persistent state id, making the persistent state the base of the state
+// histories written afterwards. It's meant for the persistent state replaced
+// out of band, such as by a flat state import, which the existing histories
+// and the state journal no longer apply to.
+//
+// The database must not be in use.
+func ResetHistory(diskdb ethdb.Database) error {
+	batch := diskdb.NewBatch()
+	rawdb.WritePersistentStateID(batch, 0)
+	rawdb.DeleteTrieJournal(batch)
+	rawdb.DeleteStateHistoryIndexMetadata(batch)
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	rawdb.DeleteStateHistoryIndex(diskdb)
+
+	ancient, err := diskdb.AncientDatadir()
+	if err != nil || ancient == "" {
+		return nil
+	}
+	freezer, err := rawdb.NewStateFreezer(ancient, false, false, rawdb.AncientCipher(diskdb))
+	if err != nil {
+		return err
+	}
+	defer freezer.Close()
+	return freezer.Reset()
+}
+
+// incrementPrefix returns the smallest key larger than all the keys with the
+// given prefix.
+func incrementPrefix(prefix []byte) []byte {
+	next := common.CopyBytes(prefix)
+	for i := len(next) - 1; i >= 0; i-- {
+		if next[i] != 0xff {
+			next[i]++
+			return next[:i+1]
+		}
+	}
+	return nil
+}
+
+// dirSize returns the total size of the files in the given directory.
+func dirSize(dir string) common.StorageSize {
+	var size common.StorageSize
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			if info, err := d.Info(); err == nil {
+				size += common.StorageSize(info.Size())
+			}
+		}
+		return nil
+	})
+	return size
+}
@@ -0,0 +1,135 @@
+// Copyright (C) 2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package pathdb
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/luxfi/geth/common"
+	"github.com/luxfi/geth/core/rawdb"
+	"github.com/luxfi/geth/ethdb"
+	"github.com/luxfi/geth/internal/testrand"
+)
+
+// Tests that unreferenced trie nodes and state histories beyond the depth are
+// pruned, while the persistent state and the retained histories are kept.
+func TestPrune(t *testing.T) {
+	// Redefine the diff layer depth allowance for faster testing.
+	maxDiffLayers = 4
+	defer func() {
+		maxDiffLayers = 128
+	}()
+
+	tester := newTester(t, 0, false, 12, false, "")
+	defer tester.release()
+
+	if err := tester.db.Commit(tester.lastHash(), false); err != nil {
+		t.Fatalf("Failed to commit database, err: %v", err)
+	}
+	tester.db.Close()
+	disk := tester.db.diskdb
+
+	// Inject unreferenced nodes into both the account and storage tries
+	var (
+		accountPath = bytes.Repeat([]byte{0xf}, 40)
+		owner       = testrand.Hash()
+	)
+	rawdb.WriteAccountTrieNode(disk, accountPath, testrand.Bytes(100))
+	rawdb.WriteStorageTrieNode(disk, owner, nil, testrand.Bytes(100))
+	rawdb.WriteStorageTrieNode(disk, owner, []byte{0x1}, testrand.Bytes(100))
+
+	stats, err := Prune(disk, PruneConfig{HistoryDepth: 4, DryRun: true})
+	if err != nil {
+		t.Fatalf("Failed to prune state, err: %v", err)
+	}
+	if stats.Root != tester.lastHash() || stats.StateID != 12 {
+		t.Fatalf("Unexpected persistent state, root: %x, id: %d", stats.Root, stats.StateID)
+	}
+	if stats.Orphans != 3 || stats.Histories != 8 || stats.Nodes == 0 {
+		t.Fatalf("Unexpected dry run stats: %+v", stats)
+	}
+	if len(rawdb.ReadAccountTrieNode(disk, accountPath)) == 0 {
+		t.Fatal("Unreferenced node deleted in dry run")
+	}
+	stats, err = Prune(disk, PruneConfig{HistoryDepth: 4})
+	if err != nil {
+		t.Fatalf("Failed to prune state, err: %v", err)
+	}
+	if stats.Orphans != 3 || stats.Histories != 8 || stats.Reclaimed() == 0 {
+		t.Fatalf("Unexpected stats: %+v", stats)
+	}
+	if len(rawdb.ReadAccountTrieNode(disk, accountPath)) != 0 || len(rawdb.ReadStorageTrieNode(disk, owner, nil)) != 0 {
+		t.Fatal("Unreferenced node not deleted")
+	}
+	// Pruning again finds nothing to delete
+	if stats, err = Prune(disk, PruneConfig{HistoryDepth: 4}); err != nil || stats.Orphans != 0 || stats.Histories != 0 {
+		t.Fatalf("Unexpected repeated pruning: %+v, %v", stats, err)
+	}
+	// The state and the retained histories are still intact
+	tester.db = New(disk, &Config{NoAsyncFlush: true}, false)
+	if err := tester.verifyState(tester.lastHash()); err != nil {
+		t.Fatalf("State is invalid, err: %v", err)
+	}
+	for id := uint64(1); id <= 12; id++ {
+		if _, err := readHistory(tester.db.freezer, id); (err == nil) != (id > 8) {
+			t.Fatalf("Unexpected state history %d, err: %v", id, err)
+		}
+	}
+}
+
+// Tests that nodes of a corrupted persistent state are never deleted.
+func TestPruneCorrupted(t *testing.T) {
+	maxDiffLayers = 4
+	defer func() {
+		maxDiffLayers = 128
+	}()
+
+	tester := newTester(t, 0, false, 8, false, "")
+	defer tester.release()
+
+	if err := tester.db.Commit(tester.lastHash(), false); err != nil {
+		t.Fatalf("Failed to commit database, err: %v", err)
+	}
+	tester.db.Close()
+	disk := tester.db.diskdb
+
+	// Corrupt a storage trie node of the persistent state
+	var (
+		owner common.Hash
+		path  []byte
+	)
+	it := disk.NewIterator(rawdb.TrieNodeStoragePrefix, nil)
+	for it.Next() {
+		if ok, o, p := rawdb.ResolveStorageTrieNode(it.Key()); ok {
+			owner, path = o, common.CopyBytes(p)
+			break
+		}
+	}
+	it.Release()
+	rawdb.WriteStorageTrieNode(disk, owner, path, testrand.Bytes(100))
+
+	nodes := countTrieNodes(disk)
+	if _, err := Prune(disk, PruneConfig{}); err == nil {
+		t.Fatal("Corrupted state is not detected")
+	}
+	if have := countTrieNodes(disk); have != nodes {
+		t.Fatalf("Trie nodes deleted from corrupted state, have %d, want %d", have, nodes)
+	}
+	tester.db = New(disk, &Config{NoAsyncFlush: true, ReadOnly: true}, false)
+}
+
+// countTrieNodes returns the number of trie nodes stored in the database.
+func countTrieNodes(db ethdb.KeyValueStore) int {
+	var count int
+	it := db.NewIterator(nil, nil)
+	defer it.Release()
+
+	for it.Next() {
+		if rawdb.IsAccountTrieNode(it.Key()) || rawdb.IsStorageTrieNode(it.Key()) {
+			count++
+		}
+	}
+	return count
+}